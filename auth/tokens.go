@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/rand"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+var (
+	// ErrInvalidToken is entity.ErrInvalidToken, which the API clients share.
+	ErrInvalidToken = entity.ErrInvalidToken
+)
+
+const (
+	// Issuer identifies the tokens signed by GoDoIt.
+	Issuer = "godoit"
+
+	// DefaultTokenTTL is how long a token stays valid unless configured
+	// otherwise.
+	DefaultTokenTTL = 24 * time.Hour
+)
+
+// Claims are the contents of a token. The subject is the ID of the User.
+type Claims struct {
+	Username    string    `json:"username"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the ID of the User the token was issued to.
+func (c *Claims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// Tokens issues and verifies the HMAC-signed JSON Web Tokens identifying
+// Users.
+type Tokens struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewTokens creates Tokens signed with secret and valid for ttl.
+func NewTokens(secret []byte, ttl time.Duration) *Tokens {
+	return &Tokens{secret: secret, ttl: ttl}
+}
+
+// Default signs the tokens of the API. It uses a random secret until Init
+// configures it.
+var Default = NewTokens(randomSecret(), DefaultTokenTTL)
+
+// Init configures Default from the JWT_SECRET and TOKEN_TTL environment
+// variables. Without a secret, tokens do not survive a restart.
+func Init() {
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	if len(secret) == 0 {
+		log.Println("JWT_SECRET is not set, tokens will be invalidated on restart.")
+		secret = randomSecret()
+	}
+
+	ttl := DefaultTokenTTL
+	if value := os.Getenv("TOKEN_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			log.Printf("Ignoring invalid TOKEN_TTL %q.\n", value)
+		} else {
+			ttl = parsed
+		}
+	}
+
+	Default = NewTokens(secret, ttl)
+}
+
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal("Failed to generate a token secret. \n", err)
+	}
+	return secret
+}
+
+// Issue returns a token identifying the User, along with its expiry.
+func (t *Tokens) Issue(user entity.User) (string, time.Time, error) {
+	now := time.Now()
+	expires := now.Add(t.ttl)
+
+	claims := Claims{
+		Username:    user.Username,
+		WorkspaceID: user.WorkspaceID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+	return token, expires, err
+}
+
+// Verify returns the Claims of a token if it was signed by t and has not
+// expired.
+func (t *Tokens) Verify(token string) (*Claims, error) {
+	claims := new(Claims)
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return t.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if _, err := claims.UserID(); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,50 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokens(t *testing.T) {
+	user, err := entity.NewUser("alice", "correct horse")
+	assert.NoError(t, err)
+
+	tokens := auth.NewTokens([]byte("secret"), time.Hour)
+	token, expires, err := tokens.Issue(*user)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+
+	claims, err := tokens.Verify(token)
+	assert.NoError(t, err)
+	id, err := claims.UserID()
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, id)
+	assert.Equal(t, "alice", claims.Username)
+	assert.Equal(t, user.WorkspaceID, claims.WorkspaceID)
+
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	expired, _, _ := auth.NewTokens([]byte("secret"), -time.Hour).Issue(*user)
+	forged, _, _ := auth.NewTokens([]byte("another secret"), time.Hour).Issue(*user)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"Reject a malformed token", "not-a-token"},
+		{"Reject an unsigned token", unsigned},
+		{"Reject an expired token", expired},
+		{"Reject a token signed with another secret", forged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tokens.Verify(tt.token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps the blobs as files of a directory, the key being their path
+// in it.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore in dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (store *FileStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(store.dir, filepath.FromSlash(key)), nil
+}
+
+// Put satisfies the Put Store interface method
+func (store *FileStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// The contents are written aside then renamed, so that a failed Put never
+	// leaves a partial file.
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	written, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written != size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Get satisfies the Get Store interface method
+func (store *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete satisfies the Delete Store interface method
+func (store *FileStore) Delete(ctx context.Context, key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
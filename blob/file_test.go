@@ -0,0 +1,50 @@
+package blob_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore puts, gets and deletes a blob of a Store.
+func testStore(t *testing.T, store blob.Store) {
+	ctx := context.Background()
+	key := "tasks/1/notes file.txt"
+
+	_, err := store.Get(ctx, key)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+
+	assert.NoError(t, store.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"))
+	assert.NoError(t, store.Put(ctx, key, strings.NewReader("hello, world"), 12, "text/plain"), "a Put replaces the contents")
+
+	contents, err := store.Get(ctx, key)
+	if assert.NoError(t, err) {
+		data, _ := io.ReadAll(contents)
+		contents.Close()
+		assert.Equal(t, "hello, world", string(data))
+	}
+
+	assert.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, key), "deleting a missing blob succeeds")
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := blob.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "tasks/../../outside", "tasks//1"} {
+		assert.ErrorIs(t, store.Put(context.Background(), key, strings.NewReader("x"), 1, ""), blob.ErrInvalidKey, key)
+	}
+
+	err = store.Put(context.Background(), "short", strings.NewReader("abc"), 10, "")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = store.Get(context.Background(), "short")
+	assert.ErrorIs(t, err, blob.ErrNotFound, "a failed Put leaves nothing behind")
+}
@@ -0,0 +1,219 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config locates a bucket of an S3-compatible object storage, such as AWS
+// S3 or MinIO.
+type S3Config struct {
+	// Endpoint is the base URL of the service, such as
+	// https://s3.eu-west-1.amazonaws.com. Buckets are addressed by path.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3Store keeps the blobs as objects of a bucket, signing its requests with
+// AWS Signature Version 4.
+type S3Store struct {
+	config S3Config
+	client *http.Client
+
+	// now is the time at which the requests are signed.
+	now func() time.Time
+}
+
+// NewS3Store creates an S3Store sending its requests with client, or
+// http.DefaultClient if it is nil.
+func NewS3Store(config S3Config, client *http.Client) (*S3Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("the S3 endpoint, bucket and credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3Store{config: config, client: client, now: time.Now}, nil
+}
+
+// NewS3StoreFromEnv creates an S3Store configured by the S3_ENDPOINT,
+// S3_REGION, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY environment
+// variables.
+func NewS3StoreFromEnv() (*S3Store, error) {
+	return NewS3Store(S3Config{
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		Region:          os.Getenv("S3_REGION"),
+		Bucket:          os.Getenv("S3_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+	}, nil)
+}
+
+// Put satisfies the Put Store interface method
+func (s3 *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s3.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s3.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Get satisfies the Get Store interface method
+func (s3 *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s3.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s3.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete satisfies the Delete Store interface method
+func (s3 *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s3.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s3.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// request creates an unsigned request for the object with the given key.
+func (s3 *S3Store) request(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	path := "/" + escapePath(s3.config.Bucket) + "/" + escapePath(key)
+	req, err := http.NewRequestWithContext(ctx, method, s3.config.Endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	// The path is already escaped as it is signed.
+	req.URL.RawPath = path
+	return req, nil
+}
+
+// do signs and sends a request, turning the error responses into errors.
+func (s3 *S3Store) do(req *http.Request) (*http.Response, error) {
+	s3.sign(req)
+	resp, err := s3.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, message)
+}
+
+// sign adds the AWS Signature Version 4 of a request to its headers. The body
+// is streamed, so it is left out of the signature.
+func (s3 *S3Store) sign(req *http.Request) {
+	now := s3.now().UTC()
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	request := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonical.String(),
+		signed,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	scope := date + "/" + s3.config.Region + "/s3/aws4_request"
+	digest := sha256.Sum256([]byte(request))
+	toSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+
+	key := []byte("AWS4" + s3.config.SecretAccessKey)
+	for _, part := range []string{date, s3.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3.config.AccessKeyID, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath escapes every byte of a path but the unreserved characters of
+// RFC 3986 and slashes, as Signature Version 4 requires.
+func escapePath(path string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,77 @@
+package blob_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/stretchr/testify/assert"
+)
+
+// s3StandIn is a local stand-in for the object API of S3, keeping objects by
+// their escaped path.
+type s3StandIn struct {
+	sync.Mutex
+	t       *testing.T
+	objects map[string][]byte
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	auth := r.Header.Get("Authorization")
+	assert.True(s.t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/"), auth)
+	assert.Contains(s.t, auth, "/eu-west-1/s3/aws4_request, SignedHeaders=")
+	assert.Contains(s.t, auth, "host;x-amz-content-sha256;x-amz-date")
+	assert.NotEmpty(s.t, r.Header.Get("X-Amz-Date"))
+
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, "/attachments/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.objects[path] = data
+	case http.MethodGet:
+		data, ok := s.objects[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(s.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	standIn := &s3StandIn{t: t, objects: make(map[string][]byte)}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	store, err := blob.NewS3Store(blob.S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "attachments",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio123",
+	}, server.Client())
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	assert.NoError(t, store.Put(context.Background(), "tasks/a b+c", strings.NewReader("x"), 1, ""))
+	assert.Contains(t, standIn.objects, "/attachments/tasks/a%20b%2Bc", "keys are escaped as signed")
+
+	_, err = blob.NewS3Store(blob.S3Config{Endpoint: server.URL}, nil)
+	assert.Error(t, err, "a bucket and credentials are required")
+}
@@ -0,0 +1,72 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("the blob was not found in the store")
+	ErrInvalidKey = errors.New("the blob key must be a relative slash-separated path")
+)
+
+// Store keeps the contents of files, such as the attachments of Tasks, under
+// slash-separated keys.
+type Store interface {
+	// Put writes size bytes read from r under key, replacing any previous
+	// contents.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get fails with ErrNotFound if nothing is stored under key. The caller
+	// must close the contents.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete succeeds if nothing is stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+// Default keeps the contents of the attachments. It is nil until Init
+// configures it.
+var Default Store
+
+// Init configures Default from the BLOB_STORE environment variable: "s3"
+// stores the contents in the bucket configured by NewS3StoreFromEnv, and
+// anything else in the BLOB_DIR directory, "attachments" by default.
+func Init() {
+	if os.Getenv("BLOB_STORE") == "s3" {
+		store, err := NewS3StoreFromEnv()
+		if err != nil {
+			log.Fatal("Failed to configure the S3 blob store. \n", err)
+		}
+		Default = store
+		return
+	}
+
+	dir := os.Getenv("BLOB_DIR")
+	if dir == "" {
+		dir = "attachments"
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		log.Fatal("Failed to open the blob store. \n", err)
+	}
+	Default = store
+}
+
+// validateKey rejects the keys which could escape the store, such as absolute
+// paths or paths with "..".
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
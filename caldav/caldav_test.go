@@ -0,0 +1,106 @@
+package caldav_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/caldav"
+	"github.com/omaciel/GoDoIt/formats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePropfind(t *testing.T) {
+	request, err := caldav.ParsePropfind(nil)
+	assert.NoError(t, err)
+	assert.True(t, request.AllProp, "an empty body asks for every property")
+
+	request, err = caldav.ParsePropfind([]byte(`<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+  <d:prop><d:getetag/><cs:getctag/></d:prop>
+</d:propfind>`))
+	assert.NoError(t, err)
+	assert.Equal(t, []xml.Name{caldav.DAV("getetag"), caldav.CalendarServer("getctag")}, request.Prop)
+
+	_, err = caldav.ParsePropfind([]byte(`<propfind xmlns="urn:other"><prop/></propfind>`))
+	assert.ErrorIs(t, err, caldav.ErrInvalidBody)
+}
+
+func TestParseReport(t *testing.T) {
+	request, err := caldav.ParseReport([]byte(`<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <D:href> /caldav/tasks/a.ics </D:href>
+  <D:href>/caldav/tasks/b.ics</D:href>
+</C:calendar-multiget>`))
+	assert.NoError(t, err)
+	assert.Equal(t, caldav.CalendarMultiget, request.Name)
+	assert.Equal(t, []string{"/caldav/tasks/a.ics", "/caldav/tasks/b.ics"}, request.Hrefs)
+	assert.Equal(t, []xml.Name{caldav.DAV("getetag"), caldav.CalDAV("calendar-data")}, request.Prop)
+
+	_, err = caldav.ParseReport([]byte(`<D:sync-collection xmlns:D="DAV:"/>`))
+	assert.ErrorIs(t, err, caldav.ErrUnsupportedReport)
+}
+
+func TestFilter(t *testing.T) {
+	calendar, err := formats.ParseICalendar(strings.NewReader("BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:Call Mom\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"))
+	assert.NoError(t, err)
+
+	query := func(filter string) *caldav.Filter {
+		request, err := caldav.ParseReport([]byte(`<C:calendar-query xmlns:C="urn:ietf:params:xml:ns:caldav"><C:filter>` +
+			filter + `</C:filter></C:calendar-query>`))
+		assert.NoError(t, err)
+		return request.Filter
+	}
+	tests := []struct {
+		name     string
+		filter   *caldav.Filter
+		expected bool
+	}{
+		{"Select everything without a filter", nil, true},
+		{"Select the tasks", query(`<C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO"/></C:comp-filter>`), true},
+		{"Select the events", query(`<C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT"/></C:comp-filter>`), false},
+		{"Select the open tasks", query(`<C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO">` +
+			`<C:prop-filter name="COMPLETED"><C:is-not-defined/></C:prop-filter></C:comp-filter></C:comp-filter>`), true},
+		{"Select the tasks which are not completed", query(`<C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO">` +
+			`<C:prop-filter name="STATUS"><C:text-match negate-condition="yes">completed</C:text-match></C:prop-filter>` +
+			`</C:comp-filter></C:comp-filter>`), true},
+		{"Select the tasks about Dad", query(`<C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO">` +
+			`<C:prop-filter name="SUMMARY"><C:text-match>dad</C:text-match></C:prop-filter></C:comp-filter></C:comp-filter>`), false},
+		{"Select the calendars without tasks", query(`<C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO">` +
+			`<C:is-not-defined/></C:comp-filter></C:comp-filter>`), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Match(calendar))
+		})
+	}
+}
+
+func TestNewResponse(t *testing.T) {
+	properties := []caldav.Property{
+		caldav.Elements(caldav.DAV("resourcetype"), caldav.DAV("collection"), caldav.CalDAV("calendar")),
+		caldav.Text(caldav.DAV("displayname"), "Tasks & more"),
+	}
+
+	response := caldav.NewResponse("/caldav/tasks/", caldav.Propfind{Prop: []xml.Name{caldav.DAV("displayname"), caldav.DAV("getetag")}}, properties)
+	if assert.Len(t, response.Propstats, 2) {
+		assert.Equal(t, caldav.Status(http.StatusOK), response.Propstats[0].Status)
+		assert.Equal(t, []caldav.Property{properties[1]}, response.Propstats[0].Prop.Properties)
+		assert.Equal(t, caldav.Status(http.StatusNotFound), response.Propstats[1].Status)
+	}
+
+	body, err := xml.Marshal(caldav.Multistatus{Responses: []caldav.Response{
+		caldav.NewResponse("/caldav/tasks/", caldav.Propfind{AllProp: true}, properties),
+	}})
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `<displayname xmlns="DAV:">Tasks &amp; more</displayname>`)
+	assert.Contains(t, string(body), `<collection xmlns="DAV:"/><calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`)
+	assert.Contains(t, string(body), `<status>HTTP/1.1 200 OK</status>`)
+}
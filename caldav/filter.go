@@ -0,0 +1,100 @@
+package caldav
+
+import (
+	"strings"
+
+	"github.com/omaciel/GoDoIt/formats"
+)
+
+// Filter selects the calendar objects of a calendar-query report, as in
+// section 9.7 of RFC 4791. Time ranges are not supported, and match every
+// component.
+type Filter struct {
+	CompFilter CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// CompFilter matches the components with the given Name, or their absence.
+type CompFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	CompFilters  []CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	PropFilters  []PropFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// PropFilter matches the properties with the given Name, or their absence.
+type PropFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *TextMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// TextMatch matches the values containing a text, regardless of their case,
+// or the ones not containing it when negated.
+type TextMatch struct {
+	Text   string `xml:",chardata"`
+	Negate string `xml:"negate-condition,attr"`
+}
+
+// Match reports whether a VCALENDAR object is selected by the Filter. A nil
+// Filter selects every object.
+func (f *Filter) Match(calendar formats.Component) bool {
+	return f == nil || f.CompFilter.match([]formats.Component{calendar})
+}
+
+func (cf CompFilter) match(components []formats.Component) bool {
+	var named []formats.Component
+	for _, component := range components {
+		if strings.EqualFold(component.Name, cf.Name) {
+			named = append(named, component)
+		}
+	}
+	if cf.IsNotDefined != nil {
+		return len(named) == 0
+	}
+
+	for _, component := range named {
+		if cf.matchComponent(component) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchComponent reports whether a component matches every nested filter.
+func (cf CompFilter) matchComponent(component formats.Component) bool {
+	for _, filter := range cf.CompFilters {
+		if !filter.match(component.Components) {
+			return false
+		}
+	}
+	for _, filter := range cf.PropFilters {
+		if !filter.match(component) {
+			return false
+		}
+	}
+	return true
+}
+
+func (pf PropFilter) match(component formats.Component) bool {
+	var values []string
+	for _, property := range component.Properties {
+		if strings.EqualFold(property.Name, pf.Name) {
+			values = append(values, property.Text())
+		}
+	}
+	if pf.IsNotDefined != nil {
+		return len(values) == 0
+	}
+	if pf.TextMatch == nil {
+		return len(values) > 0
+	}
+
+	text := strings.ToLower(pf.TextMatch.Text)
+	negate := pf.TextMatch.Negate == "yes"
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), text) != negate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+// Package caldav holds the WebDAV and CalDAV parts of the calendar access to
+// the Tasks: the XML bodies of the requests and of the multi-status responses.
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// The namespaces of the properties, from WebDAV, CalDAV and the extensions
+// of Apple's calendar server which clients rely on.
+const (
+	NamespaceDAV            = "DAV:"
+	NamespaceCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	NamespaceCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// The WebDAV methods, which are not among the default methods of Fiber. The
+// API tokens allow them as reads.
+const (
+	MethodPropfind = entity.MethodPropfind
+	MethodReport   = entity.MethodReport
+)
+
+var (
+	ErrInvalidBody       = errors.New("the body is not a valid WebDAV request")
+	ErrUnsupportedReport = errors.New("only the calendar-query and calendar-multiget reports are supported")
+)
+
+// The names of the supported reports.
+var (
+	CalendarQuery    = xml.Name{Space: NamespaceCalDAV, Local: "calendar-query"}
+	CalendarMultiget = xml.Name{Space: NamespaceCalDAV, Local: "calendar-multiget"}
+)
+
+// DAV returns the name of a property of WebDAV.
+func DAV(local string) xml.Name {
+	return xml.Name{Space: NamespaceDAV, Local: local}
+}
+
+// CalDAV returns the name of a property of CalDAV.
+func CalDAV(local string) xml.Name {
+	return xml.Name{Space: NamespaceCalDAV, Local: local}
+}
+
+// CalendarServer returns the name of a property of Apple's calendar server.
+func CalendarServer(local string) xml.Name {
+	return xml.Name{Space: NamespaceCalendarServer, Local: local}
+}
+
+// element is an XML element of which only the name matters.
+type element struct {
+	XMLName xml.Name
+}
+
+// propBody is a DAV:prop element listing the names of properties.
+type propBody struct {
+	Names []element `xml:",any"`
+}
+
+func (p *propBody) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, len(p.Names))
+	for i, name := range p.Names {
+		names[i] = name.XMLName
+	}
+	return names
+}
+
+// Propfind is what a PROPFIND request asks for: every property, only their
+// names, or the properties of Prop.
+type Propfind struct {
+	AllProp  bool
+	PropName bool
+	Prop     []xml.Name
+}
+
+// ParsePropfind parses the body of a PROPFIND request. An empty body asks for
+// every property.
+func ParsePropfind(body []byte) (Propfind, error) {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return Propfind{AllProp: true}, nil
+	}
+
+	var request struct {
+		XMLName  xml.Name  `xml:"DAV: propfind"`
+		AllProp  *struct{} `xml:"DAV: allprop"`
+		PropName *struct{} `xml:"DAV: propname"`
+		Prop     *propBody `xml:"DAV: prop"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil {
+		return Propfind{}, fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	if request.AllProp == nil && request.PropName == nil && request.Prop == nil {
+		return Propfind{}, ErrInvalidBody
+	}
+	return Propfind{
+		AllProp:  request.AllProp != nil,
+		PropName: request.PropName != nil,
+		Prop:     request.Prop.names(),
+	}, nil
+}
+
+// Report is what a REPORT request asks for. Calendar queries select the
+// calendar objects with their Filter, and multigets list their Hrefs.
+type Report struct {
+	Name xml.Name
+	Propfind
+	Hrefs  []string
+	Filter *Filter
+}
+
+// ParseReport parses the body of a calendar-query or calendar-multiget
+// REPORT request.
+func ParseReport(body []byte) (Report, error) {
+	var request struct {
+		XMLName xml.Name
+		AllProp *struct{} `xml:"DAV: allprop"`
+		Prop    *propBody `xml:"DAV: prop"`
+		Hrefs   []string  `xml:"DAV: href"`
+		Filter  *Filter   `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil {
+		return Report{}, fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	if request.XMLName != CalendarQuery && request.XMLName != CalendarMultiget {
+		return Report{}, ErrUnsupportedReport
+	}
+
+	report := Report{
+		Name:     request.XMLName,
+		Propfind: Propfind{AllProp: request.AllProp != nil || request.Prop == nil, Prop: request.Prop.names()},
+		Hrefs:    request.Hrefs,
+		Filter:   request.Filter,
+	}
+	for i, href := range report.Hrefs {
+		report.Hrefs[i] = strings.TrimSpace(href)
+	}
+	return report, nil
+}
+
+// Property is a property of a resource, whose value is already encoded as
+// XML.
+type Property struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+// Text returns a property whose value is a text.
+func Text(name xml.Name, text string) Property {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return Property{XMLName: name, Inner: escaped.String()}
+}
+
+// Elements returns a property whose value is a list of empty elements, such
+// as the types of a resource.
+func Elements(name xml.Name, elements ...xml.Name) Property {
+	var inner strings.Builder
+	for _, element := range elements {
+		fmt.Fprintf(&inner, `<%s xmlns="%s"/>`, element.Local, element.Space)
+	}
+	return Property{XMLName: name, Inner: inner.String()}
+}
+
+// Href returns a property whose value is the URL of another resource.
+func Href(name xml.Name, href string) Property {
+	text := Text(DAV("href"), href)
+	return Property{XMLName: name, Inner: `<href xmlns="DAV:">` + text.Inner + `</href>`}
+}
+
+// SupportedComponents returns the supported-calendar-component-set property
+// of a calendar holding the components with the given names.
+func SupportedComponents(names ...string) Property {
+	var inner strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&inner, `<comp xmlns="%s" name="%s"/>`, NamespaceCalDAV, name)
+	}
+	return Property{XMLName: CalDAV("supported-calendar-component-set"), Inner: inner.String()}
+}
+
+// SupportedReports returns the supported-report-set property of a calendar,
+// listing the reports which ParseReport accepts.
+func SupportedReports() Property {
+	var inner strings.Builder
+	for _, report := range []xml.Name{CalendarQuery, CalendarMultiget} {
+		fmt.Fprintf(&inner, `<supported-report xmlns="DAV:"><report>%s</report></supported-report>`, Elements(xml.Name{}, report).Inner)
+	}
+	return Property{XMLName: DAV("supported-report-set"), Inner: inner.String()}
+}
+
+// Privileges returns the current-user-privilege-set property of a resource.
+func Privileges(privileges ...xml.Name) Property {
+	var inner strings.Builder
+	for _, privilege := range privileges {
+		fmt.Fprintf(&inner, `<privilege xmlns="DAV:">%s</privilege>`, Elements(xml.Name{}, privilege).Inner)
+	}
+	return Property{XMLName: DAV("current-user-privilege-set"), Inner: inner.String()}
+}
+
+// Multistatus is the body of the responses to PROPFIND and REPORT requests.
+type Multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []Response `xml:"response"`
+}
+
+// Response holds the properties of a resource, or only a Status when the
+// resource cannot be read.
+type Response struct {
+	Href      string     `xml:"href"`
+	Propstats []Propstat `xml:"propstat,omitempty"`
+	Status    string     `xml:"status,omitempty"`
+}
+
+// Propstat holds properties sharing the same status.
+type Propstat struct {
+	Prop struct {
+		Properties []Property `xml:",any"`
+	} `xml:"prop"`
+	Status string `xml:"status"`
+}
+
+// Status returns the status line of a Response or Propstat.
+func Status(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// NewResponse answers what a request asks for with the properties of a
+// resource. The properties which are asked for but not found are listed as
+// such.
+func NewResponse(href string, request Propfind, properties []Property) Response {
+	found, missing := Propstat{Status: Status(http.StatusOK)}, Propstat{Status: Status(http.StatusNotFound)}
+	switch {
+	case request.AllProp:
+		found.Prop.Properties = properties
+	case request.PropName:
+		for _, property := range properties {
+			found.Prop.Properties = append(found.Prop.Properties, Property{XMLName: property.XMLName})
+		}
+	default:
+		for _, name := range request.Prop {
+			if property, ok := find(properties, name); ok {
+				found.Prop.Properties = append(found.Prop.Properties, property)
+			} else {
+				missing.Prop.Properties = append(missing.Prop.Properties, Property{XMLName: name})
+			}
+		}
+	}
+
+	response := Response{Href: href}
+	for _, propstat := range []Propstat{found, missing} {
+		if len(propstat.Prop.Properties) > 0 {
+			response.Propstats = append(response.Propstats, propstat)
+		}
+	}
+	if len(response.Propstats) == 0 {
+		response.Propstats = append(response.Propstats, found)
+	}
+	return response
+}
+
+// find returns the property with the given name.
+func find(properties []Property, name xml.Name) (Property, bool) {
+	for _, property := range properties {
+		if property.XMLName == name {
+			return property, true
+		}
+	}
+	return Property{}, false
+}
@@ -0,0 +1,194 @@
+package changes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/domain/eventsource"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// received returns the changes waiting in a Subscription.
+func received(s *changes.Subscription) []changes.Change {
+	var got []changes.Change
+	for {
+		select {
+		case change, ok := <-s.Changes():
+			if !ok {
+				return got
+			}
+			got = append(got, change)
+		default:
+			return got
+		}
+	}
+}
+
+// types returns the types of the changes, along with the descriptions of
+// their Tasks.
+func types(got []changes.Change) []string {
+	var summary []string
+	for _, change := range got {
+		summary = append(summary, string(change.Type)+" "+change.Task.Description)
+	}
+	return summary
+}
+
+func TestHub(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	hub := changes.NewHub(3)
+
+	subscription, missed, err := hub.Subscribe(owner, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, missed)
+	defer subscription.Close()
+
+	first := hub.Publish(changes.Change{Type: changes.Created, Task: entity.Task{Description: "mine"}, Audience: []uuid.UUID{owner}})
+	hub.Publish(changes.Change{Type: changes.Created, Task: entity.Task{Description: "theirs"}, Audience: []uuid.UUID{other}})
+	second := hub.Publish(changes.Change{Type: changes.Updated, Task: entity.Task{Description: "mine"}, Audience: []uuid.UUID{owner}})
+	assert.Greater(t, second.ID, first.ID)
+
+	t.Run("Only the visible changes are received", func(t *testing.T) {
+		assert.Equal(t, []string{"created mine", "updated mine"}, types(received(subscription)))
+	})
+
+	t.Run("Resume after the last change", func(t *testing.T) {
+		resumed, missed, err := hub.Subscribe(owner, first.ID)
+		assert.NoError(t, err)
+		defer resumed.Close()
+		assert.Equal(t, []string{"updated mine"}, types(missed))
+
+		_, missed, err = hub.Subscribe(owner, second.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("Resume after a change which is no longer kept", func(t *testing.T) {
+		hub.Publish(changes.Change{Type: changes.Deleted, Audience: []uuid.UUID{owner}})
+		hub.Publish(changes.Change{Type: changes.Created, Audience: []uuid.UUID{owner}})
+		_, missed, err := hub.Subscribe(owner, first.ID)
+		assert.ErrorIs(t, err, changes.ErrBacklogExceeded)
+		assert.Empty(t, missed)
+
+		_, _, err = hub.Subscribe(owner, second.ID+100)
+		assert.ErrorIs(t, err, changes.ErrBacklogExceeded)
+	})
+
+	t.Run("Subscribers falling behind are dropped", func(t *testing.T) {
+		slow, _, _ := hub.Subscribe(other, 0)
+		for i := 0; i < 100; i++ {
+			hub.Publish(changes.Change{Type: changes.Updated, Audience: []uuid.UUID{other}})
+		}
+		got := received(slow)
+		assert.Less(t, len(got), 100)
+		_, open := <-slow.Changes()
+		assert.False(t, open)
+		slow.Close()
+	})
+
+	t.Run("Closed subscriptions receive nothing", func(t *testing.T) {
+		received(subscription)
+		subscription.Close()
+		hub.Publish(changes.Change{Type: changes.Updated, Audience: []uuid.UUID{owner}})
+		assert.Empty(t, received(subscription))
+	})
+}
+
+func TestWatch(t *testing.T) {
+	owner, collaborator := uuid.New(), uuid.New()
+	ctx := task.WithOwner(context.Background(), owner)
+	hub := changes.NewHub(changes.DefaultBacklog)
+	users := memory.NewMemoryRepository()
+	repo := changes.Watch(users, hub)
+
+	ours, _, _ := hub.Subscribe(owner, 0)
+	defer ours.Close()
+	theirs, _, _ := hub.Subscribe(collaborator, 0)
+	defer theirs.Close()
+
+	t.Run("Writes publish their changes", func(t *testing.T) {
+		created := entity.NewTask("Write the report")
+		assert.NoError(t, repo.Post(ctx, created))
+		created.Completed = true
+		assert.NoError(t, repo.Put(ctx, created))
+		assert.NoError(t, repo.Delete(ctx, created.ID))
+		assert.NoError(t, repo.Restore(ctx, created.ID))
+		_, err := repo.ArchiveCompleted(ctx, time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+
+		got := received(ours)
+		assert.Equal(t, []string{
+			"created Write the report",
+			"updated Write the report",
+			"deleted Write the report",
+			"created Write the report",
+			"updated Write the report",
+		}, types(got))
+		assert.True(t, got[4].Task.Archived)
+		assert.Empty(t, received(theirs))
+	})
+
+	t.Run("Failed writes publish nothing", func(t *testing.T) {
+		assert.Error(t, repo.Delete(ctx, uuid.New()))
+		duplicate := entity.NewTask("Write the report")
+		assert.NoError(t, repo.Post(ctx, duplicate))
+		received(ours)
+		assert.Error(t, repo.Post(ctx, duplicate))
+		assert.Empty(t, received(ours))
+	})
+
+	t.Run("Shared Tasks appear and disappear", func(t *testing.T) {
+		shared := entity.NewTask("Review the report")
+		assert.NoError(t, repo.Post(ctx, shared))
+		assert.NoError(t, repo.Share(ctx, shared.ID, collaborator, entity.RoleEditor))
+		assert.NoError(t, repo.Unshare(ctx, shared.ID, collaborator))
+
+		assert.Equal(t, []string{"created Review the report", "updated Review the report", "updated Review the report"}, types(received(ours)))
+		assert.Equal(t, []string{"created Review the report", "deleted Review the report"}, types(received(theirs)))
+	})
+
+	t.Run("Assigned Tasks appear and disappear", func(t *testing.T) {
+		assert.NoError(t, users.CreateUser(ctx, &entity.User{ID: collaborator, Username: "collaborator"}))
+		assigned := entity.NewTask("Proofread the report")
+		assert.NoError(t, repo.Post(ctx, assigned))
+		assert.NoError(t, repo.Assign(ctx, assigned.ID, collaborator))
+		assert.NoError(t, repo.Unassign(ctx, assigned.ID))
+
+		assert.Equal(t, []string{"created Proofread the report", "updated Proofread the report", "updated Proofread the report"}, types(received(ours)))
+		assert.Equal(t, []string{"created Proofread the report", "deleted Proofread the report"}, types(received(theirs)))
+	})
+
+	t.Run("Transactions publish once committed", func(t *testing.T) {
+		rollback := errors.New("rollback")
+		err := repo.WithTx(ctx, func(tx task.TaskRepository) error {
+			assert.NoError(t, tx.Post(ctx, entity.NewTask("Forgotten")))
+			assert.Empty(t, received(ours))
+			return rollback
+		})
+		assert.ErrorIs(t, err, rollback)
+		assert.Empty(t, received(ours))
+
+		err = repo.WithTx(ctx, func(tx task.TaskRepository) error {
+			return tx.PostMany(ctx, []*entity.Task{entity.NewTask("First"), entity.NewTask("Second")})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"created First", "created Second"}, types(received(ours)))
+	})
+
+	t.Run("Historical repositories stay historical", func(t *testing.T) {
+		_, ok := repo.(task.HistoricalRepository)
+		assert.False(t, ok)
+
+		events, err := eventsource.NewEventSourcedRepository(eventsource.NewMemoryStore())
+		assert.NoError(t, err)
+		_, ok = changes.Watch(events, hub).(task.HistoricalRepository)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,191 @@
+// Package changes notifies the clients of the API of the changes to the
+// Tasks, as they are written to the repository.
+package changes
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+var (
+	ErrBacklogExceeded = errors.New("the changes since the last event are no longer kept")
+)
+
+// Type tells how a Task changed for the Users who receive the Change.
+type Type string
+
+const (
+	// Created is sent when a Task appears in the list of a User: when it is
+	// created, restored from the trash, or shared with them.
+	Created = Type("created")
+
+	// Updated is sent when a Task the User can see changes.
+	Updated = Type("updated")
+
+	// Deleted is sent when a Task disappears from the list of a User: when it
+	// is moved to the trash, or no longer shared with them.
+	Deleted = Type("deleted")
+)
+
+// Change is a write to a Task, along with the Users who can see it.
+type Change struct {
+	ID   uint64      `json:"id"`
+	Type Type        `json:"type"`
+	Task entity.Task `json:"task"`
+
+	// Audience is the owner of the Task and the Users it is shared with.
+	Audience []uuid.UUID `json:"-"`
+}
+
+// Visible reports whether the Change is sent to the User.
+func (c Change) Visible(user uuid.UUID) bool {
+	for _, id := range c.Audience {
+		if id == user {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// DefaultBacklog is how many changes Default keeps for the clients
+	// resuming their stream.
+	DefaultBacklog = 1024
+
+	// subscriptionBuffer is how many changes a subscriber may fall behind
+	// before it is dropped.
+	subscriptionBuffer = 64
+)
+
+// Default is the Hub of the changes to database.Repo.
+var Default = NewHub(DefaultBacklog)
+
+// Hub sends the changes published to it to the subscribers who can see them,
+// and keeps the latest ones for the subscribers resuming their stream.
+type Hub struct {
+	mu          sync.Mutex
+	last        uint64
+	size        int
+	backlog     []Change
+	subscribers map[*Subscription]struct{}
+}
+
+// NewHub returns a Hub keeping the given number of changes. The IDs of the
+// changes start from the current time, so that they keep growing when the
+// server restarts and the clients of the previous one resume with an older
+// ID.
+func NewHub(backlog int) *Hub {
+	return &Hub{
+		last:        uint64(time.Now().UnixMilli()),
+		size:        backlog,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish gives the Change the next ID and sends it to the subscribers who
+// can see it. The subscribers who fell too far behind are dropped instead.
+func (h *Hub) Publish(change Change) Change {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.last++
+	change.ID = h.last
+	if h.size > 0 {
+		if len(h.backlog) == h.size {
+			h.backlog = h.backlog[1:]
+		}
+		h.backlog = append(h.backlog, change)
+	}
+
+	for s := range h.subscribers {
+		if !s.sees(change) {
+			continue
+		}
+		select {
+		case s.changes <- change:
+		default:
+			h.drop(s)
+		}
+	}
+	return change
+}
+
+// Subscribe sends the changes the User can see to the Subscription, from now
+// on. When after is the ID of a previous Change, the ones which followed it
+// are returned first. If they are no longer all kept, the Subscription starts
+// anyway along with ErrBacklogExceeded, and the client should reload the
+// Tasks.
+func (h *Hub) Subscribe(user uuid.UUID, after uint64) (*Subscription, []Change, error) {
+	return h.subscribe(&Subscription{user: user}, after)
+}
+
+// SubscribeAll sends every Change to the Subscription, whoever can see it, as
+// Subscribe does.
+func (h *Hub) SubscribeAll(after uint64) (*Subscription, []Change, error) {
+	return h.subscribe(&Subscription{all: true}, after)
+}
+
+func (h *Hub) subscribe(s *Subscription, after uint64) (*Subscription, []Change, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s.hub, s.changes = h, make(chan Change, subscriptionBuffer)
+	h.subscribers[s] = struct{}{}
+	if after == 0 {
+		return s, nil, nil
+	}
+
+	oldest := h.last + 1
+	if len(h.backlog) > 0 {
+		oldest = h.backlog[0].ID
+	}
+	if after+1 < oldest || after > h.last {
+		return s, nil, ErrBacklogExceeded
+	}
+
+	var missed []Change
+	for _, change := range h.backlog {
+		if change.ID > after && s.sees(change) {
+			missed = append(missed, change)
+		}
+	}
+	return s, missed, nil
+}
+
+// drop removes a subscriber and closes its channel. The caller must hold the
+// lock.
+func (h *Hub) drop(s *Subscription) {
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.changes)
+	}
+}
+
+// Subscription receives the changes a User can see, or every Change.
+type Subscription struct {
+	hub     *Hub
+	user    uuid.UUID
+	all     bool
+	changes chan Change
+}
+
+func (s *Subscription) sees(change Change) bool {
+	return s.all || change.Visible(s.user)
+}
+
+// Changes is closed when the Subscription is closed, or when the subscriber
+// fell too far behind. It may then resume from the last Change it received.
+func (s *Subscription) Changes() <-chan Change {
+	return s.changes
+}
+
+// Close stops sending the changes to the Subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s)
+}
@@ -0,0 +1,336 @@
+package changes
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// repository publishes the changes to the Tasks of a TaskRepository once its
+// writes succeed. The writes which fail publish nothing.
+type repository struct {
+	task.TaskRepository
+	publish func(Change)
+}
+
+// historicalRepository also tells what the Tasks looked like in the past.
+type historicalRepository struct {
+	*repository
+	historical task.HistoricalRepository
+}
+
+// AsOf satisfies the AsOf HistoricalRepository interface method. Changes to
+// past states are not kept, so they are not published either.
+func (hr *historicalRepository) AsOf(ctx context.Context, at time.Time) (task.TaskRepository, error) {
+	return hr.historical.AsOf(ctx, at)
+}
+
+// Watch returns a TaskRepository publishing the changes made through it to
+// the Hub. It is a HistoricalRepository if repo is one.
+func Watch(repo task.TaskRepository, hub *Hub) task.TaskRepository {
+	watched := &repository{TaskRepository: repo, publish: func(change Change) { hub.Publish(change) }}
+	if historical, ok := repo.(task.HistoricalRepository); ok {
+		return &historicalRepository{repository: watched, historical: historical}
+	}
+	return watched
+}
+
+// audience returns the owner of the Task, its assignee and the Users it is
+// shared with.
+func (r *repository) audience(ctx context.Context, t entity.Task) []uuid.UUID {
+	audience := []uuid.UUID{t.OwnerID}
+	if t.AssigneeID != nil && *t.AssigneeID != t.OwnerID {
+		audience = append(audience, *t.AssigneeID)
+	}
+	shares, _ := r.TaskRepository.Shares(ctx, t.ID)
+	for _, share := range shares {
+		audience = append(audience, share.UserID)
+	}
+	return audience
+}
+
+// publishTask publishes the current state of a Task to the Users who can see
+// it.
+func (r *repository) publishTask(ctx context.Context, kind Type, id uuid.UUID) {
+	t, err := r.TaskRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	r.publish(Change{Type: kind, Task: t, Audience: r.audience(ctx, t)})
+}
+
+// Post satisfies the Post TaskRepository interface method
+func (r *repository) Post(ctx context.Context, t *entity.Task) error {
+	if err := r.TaskRepository.Post(ctx, t); err != nil {
+		return err
+	}
+	r.publish(Change{Type: Created, Task: *t, Audience: []uuid.UUID{t.OwnerID}})
+	return nil
+}
+
+// PostMany satisfies the PostMany TaskRepository interface method
+func (r *repository) PostMany(ctx context.Context, tasks []*entity.Task) error {
+	if err := r.TaskRepository.PostMany(ctx, tasks); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		r.publish(Change{Type: Created, Task: *t, Audience: []uuid.UUID{t.OwnerID}})
+	}
+	return nil
+}
+
+// Put satisfies the Put TaskRepository interface method
+func (r *repository) Put(ctx context.Context, t *entity.Task) error {
+	if err := r.TaskRepository.Put(ctx, t); err != nil {
+		return err
+	}
+	r.publishTask(ctx, Updated, t.ID)
+	return nil
+}
+
+// deleted returns the Changes to publish once the Tasks are deleted, which
+// must be read before they are in the trash.
+func (r *repository) deleted(ctx context.Context, ids []uuid.UUID) []Change {
+	var changes []Change
+	for _, id := range ids {
+		if t, err := r.TaskRepository.Get(ctx, id); err == nil {
+			changes = append(changes, Change{Type: Deleted, Task: t, Audience: r.audience(ctx, t)})
+		}
+	}
+	return changes
+}
+
+// Delete satisfies the Delete TaskRepository interface method
+func (r *repository) Delete(ctx context.Context, id uuid.UUID) error {
+	changes := r.deleted(ctx, []uuid.UUID{id})
+	if err := r.TaskRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		r.publish(change)
+	}
+	return nil
+}
+
+// DeleteMany satisfies the DeleteMany TaskRepository interface method
+func (r *repository) DeleteMany(ctx context.Context, ids []uuid.UUID) error {
+	changes := r.deleted(ctx, ids)
+	if err := r.TaskRepository.DeleteMany(ctx, ids); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		r.publish(change)
+	}
+	return nil
+}
+
+// WithTx satisfies the WithTx TaskRepository interface method. The changes
+// made in the transaction are only published once it is committed.
+func (r *repository) WithTx(ctx context.Context, fn func(repo task.TaskRepository) error) error {
+	var pending []Change
+	err := r.TaskRepository.WithTx(ctx, func(tx task.TaskRepository) error {
+		pending = nil
+		return fn(&repository{TaskRepository: tx, publish: func(change Change) {
+			pending = append(pending, change)
+		}})
+	})
+	if err != nil {
+		return err
+	}
+	for _, change := range pending {
+		r.publish(change)
+	}
+	return nil
+}
+
+// Restore satisfies the Restore TaskRepository interface method
+func (r *repository) Restore(ctx context.Context, id uuid.UUID) error {
+	if err := r.TaskRepository.Restore(ctx, id); err != nil {
+		return err
+	}
+	r.publishTask(ctx, Created, id)
+	return nil
+}
+
+// ArchiveCompleted satisfies the ArchiveCompleted TaskRepository interface
+// method
+func (r *repository) ArchiveCompleted(ctx context.Context, before time.Time) (int64, error) {
+	// The archived Tasks are not returned, so the candidates are read first.
+	candidates, _ := r.TaskRepository.All(ctx)
+	archived, err := r.TaskRepository.ArchiveCompleted(ctx, before)
+	if err != nil || archived == 0 {
+		return archived, err
+	}
+	for _, candidate := range candidates {
+		if !candidate.Completed || !task.Owns(ctx, candidate) {
+			continue
+		}
+		if t, err := r.TaskRepository.Get(ctx, candidate.ID); err == nil && t.Archived {
+			r.publish(Change{Type: Updated, Task: t, Audience: r.audience(ctx, t)})
+		}
+	}
+	return archived, nil
+}
+
+// Share satisfies the Share TaskRepository interface method. The Task is
+// created for the User it is shared with, and updated for the others.
+func (r *repository) Share(ctx context.Context, id uuid.UUID, userID uuid.UUID, role entity.Role) error {
+	t, err := r.TaskRepository.Get(ctx, id)
+	if err != nil {
+		return r.TaskRepository.Share(ctx, id, userID, role)
+	}
+	previous := Change{Task: t, Audience: r.audience(ctx, t)}
+	if err := r.TaskRepository.Share(ctx, id, userID, role); err != nil {
+		return err
+	}
+
+	kind := Created
+	if previous.Visible(userID) {
+		kind = Updated
+	}
+	r.publish(Change{Type: kind, Task: t, Audience: []uuid.UUID{userID}})
+	r.publish(Change{Type: Updated, Task: t, Audience: without(previous.Audience, userID)})
+	return nil
+}
+
+// Unshare satisfies the Unshare TaskRepository interface method. The Task is
+// deleted for the User it is no longer shared with, and updated for the
+// others.
+func (r *repository) Unshare(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if err := r.TaskRepository.Unshare(ctx, id, userID); err != nil {
+		return err
+	}
+	t, err := r.TaskRepository.Get(ctx, id)
+	if err != nil {
+		return nil
+	}
+	r.publish(Change{Type: Deleted, Task: t, Audience: []uuid.UUID{userID}})
+	r.publish(Change{Type: Updated, Task: t, Audience: r.audience(ctx, t)})
+	return nil
+}
+
+// without returns the IDs other than id.
+func without(ids []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	var others []uuid.UUID
+	for _, other := range ids {
+		if other != id {
+			others = append(others, other)
+		}
+	}
+	return others
+}
+
+// reassigned publishes a Task whose assignee changed: it is created for the
+// new assignee, deleted for the previous one, and updated for the others.
+func (r *repository) reassigned(ctx context.Context, previous Change) {
+	t, err := r.TaskRepository.Get(ctx, previous.Task.ID)
+	if err != nil {
+		return
+	}
+	current := Change{Task: t, Audience: r.audience(ctx, t)}
+
+	var created, deleted []uuid.UUID
+	for _, id := range current.Audience {
+		if !previous.Visible(id) {
+			created = append(created, id)
+		}
+	}
+	for _, id := range previous.Audience {
+		if !current.Visible(id) {
+			deleted = append(deleted, id)
+		}
+	}
+	updated := current.Audience
+	for _, id := range created {
+		updated = without(updated, id)
+	}
+
+	if len(created) > 0 {
+		r.publish(Change{Type: Created, Task: t, Audience: created})
+	}
+	if len(deleted) > 0 {
+		r.publish(Change{Type: Deleted, Task: t, Audience: deleted})
+	}
+	r.publish(Change{Type: Updated, Task: t, Audience: updated})
+}
+
+// Assign satisfies the Assign TaskRepository interface method
+func (r *repository) Assign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	t, err := r.TaskRepository.Get(ctx, id)
+	if err != nil {
+		return r.TaskRepository.Assign(ctx, id, userID)
+	}
+	previous := Change{Task: t, Audience: r.audience(ctx, t)}
+	if err := r.TaskRepository.Assign(ctx, id, userID); err != nil {
+		return err
+	}
+	r.reassigned(ctx, previous)
+	return nil
+}
+
+// Unassign satisfies the Unassign TaskRepository interface method
+func (r *repository) Unassign(ctx context.Context, id uuid.UUID) error {
+	t, err := r.TaskRepository.Get(ctx, id)
+	if err != nil {
+		return r.TaskRepository.Unassign(ctx, id)
+	}
+	previous := Change{Task: t, Audience: r.audience(ctx, t)}
+	if err := r.TaskRepository.Unassign(ctx, id); err != nil {
+		return err
+	}
+	r.reassigned(ctx, previous)
+	return nil
+}
+
+// AddChecklistItem satisfies the AddChecklistItem TaskRepository interface
+// method
+func (r *repository) AddChecklistItem(ctx context.Context, item *entity.ChecklistItem) error {
+	if err := r.TaskRepository.AddChecklistItem(ctx, item); err != nil {
+		return err
+	}
+	r.publishTask(ctx, Updated, item.TaskID)
+	return nil
+}
+
+// EditChecklistItem satisfies the EditChecklistItem TaskRepository interface
+// method
+func (r *repository) EditChecklistItem(ctx context.Context, taskID, id uuid.UUID, text string) (entity.ChecklistItem, error) {
+	item, err := r.TaskRepository.EditChecklistItem(ctx, taskID, id, text)
+	if err == nil {
+		r.publishTask(ctx, Updated, taskID)
+	}
+	return item, err
+}
+
+// ToggleChecklistItem satisfies the ToggleChecklistItem TaskRepository
+// interface method
+func (r *repository) ToggleChecklistItem(ctx context.Context, taskID, id uuid.UUID) (entity.ChecklistItem, error) {
+	item, err := r.TaskRepository.ToggleChecklistItem(ctx, taskID, id)
+	if err == nil {
+		r.publishTask(ctx, Updated, taskID)
+	}
+	return item, err
+}
+
+// ReorderChecklist satisfies the ReorderChecklist TaskRepository interface
+// method
+func (r *repository) ReorderChecklist(ctx context.Context, taskID uuid.UUID, order []uuid.UUID) ([]entity.ChecklistItem, error) {
+	items, err := r.TaskRepository.ReorderChecklist(ctx, taskID, order)
+	if err == nil {
+		r.publishTask(ctx, Updated, taskID)
+	}
+	return items, err
+}
+
+// DeleteChecklistItem satisfies the DeleteChecklistItem TaskRepository
+// interface method
+func (r *repository) DeleteChecklistItem(ctx context.Context, taskID, id uuid.UUID) error {
+	if err := r.TaskRepository.DeleteChecklistItem(ctx, taskID, id); err != nil {
+		return err
+	}
+	r.publishTask(ctx, Updated, taskID)
+	return nil
+}
@@ -0,0 +1,213 @@
+// Package client calls the GoDoIt API over HTTP, with typed methods, retries
+// of the idempotent requests, and errors which match the errors of entity.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+const (
+	// DefaultMaxAttempts is how many times an idempotent request is sent
+	// before its error is returned.
+	DefaultMaxAttempts = 3
+
+	// DefaultBackoff is how long the first retry waits. Every retry waits
+	// twice as long as the previous one, up to DefaultMaxBackoff.
+	DefaultBackoff    = 200 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+
+	// DefaultTimeout is how long the server has to answer a request.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Client calls the API at BaseURL, as the User of the Token, which is either
+// a session or an API token.
+type Client struct {
+	BaseURL     string
+	Token       string
+	HTTPClient  *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// New returns a Client of the API at baseURL with the default retries and
+// timeout. The token may be left empty until Login.
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+		Token:       token,
+		HTTPClient:  &http.Client{Timeout: DefaultTimeout},
+		MaxAttempts: DefaultMaxAttempts,
+		Backoff:     DefaultBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+	}
+}
+
+// Error is a response of the API which is not a success. Code is the code of
+// the error of entity the server reported, if any.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("godoit: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("godoit: %d %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the error of entity which the server reported by its code, if
+// any, so that errors.Is(err, entity.ErrTaskNotFound) holds for a missing Task.
+func (e *Error) Unwrap() error {
+	return entity.ErrorForCode(e.Code)
+}
+
+// retryable reports whether a request may be sent again after a response
+// with the given status code.
+func retryable(method string, status int) bool {
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait after the given number of attempts.
+func (c *Client) backoff(attempts int) time.Duration {
+	wait := c.Backoff
+	for i := 1; i < attempts && wait < c.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
+}
+
+// retryAfter returns the wait asked for by the Retry-After header of a
+// response, in seconds or as a date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
+
+// sleep waits for the given duration, unless the context is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// do sends a request with the JSON of in, if any, and decodes the JSON of a
+// successful response into out, if any. It returns the status code and the
+// header of the response. The GET, PUT and DELETE requests are sent again
+// when they fail on the network or the server is unavailable.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) (int, http.Header, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return 0, nil, err
+		}
+	}
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempts := 1; ; attempts++ {
+		status, header, err := c.send(ctx, method, target, body, out)
+		var apiErr *Error
+		var wait time.Duration
+		switch {
+		case attempts >= c.MaxAttempts || ctx.Err() != nil:
+			return status, header, err
+		case errors.As(err, &apiErr):
+			if !retryable(method, apiErr.StatusCode) || apiErr.Unwrap() != nil {
+				return status, header, err
+			}
+			var ok bool
+			if wait, ok = retryAfter(header); !ok || wait > c.MaxBackoff {
+				wait = c.backoff(attempts)
+			}
+		case err != nil && status == 0 && method != http.MethodPost:
+			wait = c.backoff(attempts)
+		default:
+			return status, header, err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// send sends a request once.
+func (c *Client) send(ctx context.Context, method, target string, body []byte, out interface{}) (int, http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		failure := &Error{StatusCode: resp.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, failure) != nil {
+			failure.Code, failure.Message = "", strings.TrimSpace(string(data))
+		}
+		return resp.StatusCode, resp.Header, failure
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, resp.Header, fmt.Errorf("godoit: decoding the response: %w", err)
+		}
+	}
+	return resp.StatusCode, resp.Header, nil
+}
@@ -0,0 +1,253 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/client"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = changes.Watch(repo, changes.Default), repo
+	app := fiber.New()
+	router.SetupRoutes(app)
+	server := httptest.NewServer(adaptor.FiberApp(app))
+	defer server.Close()
+
+	c := client.New(server.URL, "")
+	ctx := context.Background()
+
+	t.Run("Authentication", func(t *testing.T) {
+		_, err := c.Me(ctx)
+		var apiErr *client.Error
+		if assert.ErrorAs(t, err, &apiErr) {
+			assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+		}
+
+		registered, err := c.Register(ctx, "client", "a password")
+		assert.NoError(t, err)
+		_, err = c.Register(ctx, "client", "a password")
+		assert.ErrorIs(t, err, entity.ErrUsernameTaken)
+		_, err = c.Login(ctx, "client", "wrong password")
+		assert.ErrorIs(t, err, entity.ErrInvalidCredentials)
+
+		session, err := c.Login(ctx, "client", "a password")
+		assert.NoError(t, err)
+		assert.Equal(t, session.Token, c.Token)
+		me, err := c.Me(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, registered.ID, me.ID)
+
+		_, err = client.New(server.URL, "forged").Me(ctx)
+		assert.ErrorIs(t, err, entity.ErrInvalidToken)
+		if assert.ErrorAs(t, err, &apiErr) {
+			assert.Equal(t, "invalid_token", apiErr.Code)
+		}
+	})
+
+	created, err := c.CreateTask(ctx, *entity.NewTask("Write the client").WithPriority(entity.PriorityHigh))
+	assert.NoError(t, err)
+	assert.Equal(t, "Write the client", created.Description)
+
+	t.Run("Get a Task", func(t *testing.T) {
+		found, err := c.GetTask(ctx, created.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, created.ID, found.ID)
+		assert.Equal(t, entity.PriorityHigh, found.Priority)
+
+		_, err = c.GetTask(ctx, uuid.New())
+		assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	})
+
+	t.Run("Update a Task", func(t *testing.T) {
+		created.Completed = true
+		updated, err := c.UpdateTask(ctx, created)
+		assert.NoError(t, err)
+		assert.True(t, updated.Completed)
+
+		_, err = c.UpdateTask(ctx, *entity.NewTask("Missing"))
+		assert.ErrorIs(t, err, entity.ErrTaskNotFound, "the missing Tasks are not retried")
+	})
+
+	t.Run("List the Tasks", func(t *testing.T) {
+		for i := 1; i <= 4; i++ {
+			_, err := c.CreateTask(ctx, *entity.NewTask(fmt.Sprintf("Task %d", i)))
+			assert.NoError(t, err)
+		}
+
+		all, err := c.ListTasks(ctx, client.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, all.Tasks, 5)
+		assert.Equal(t, int64(5), all.Total)
+
+		page, err := c.ListTasks(ctx, client.ListOptions{Limit: 2, Offset: 1})
+		assert.NoError(t, err)
+		assert.Len(t, page.Tasks, 2)
+		assert.Equal(t, int64(5), page.Total)
+
+		unassigned, err := c.ListTasks(ctx, client.ListOptions{Assignee: "none"})
+		assert.NoError(t, err)
+		assert.Len(t, unassigned.Tasks, 5)
+		mine, err := c.ListTasks(ctx, client.ListOptions{Assignee: "me"})
+		assert.NoError(t, err)
+		assert.Empty(t, mine.Tasks)
+
+		archived, err := c.ListTasks(ctx, client.ListOptions{Archived: true})
+		assert.NoError(t, err)
+		assert.Empty(t, archived.Tasks)
+
+		_, err = c.ListTasks(ctx, client.ListOptions{Limit: 1000})
+		var apiErr *client.Error
+		if assert.ErrorAs(t, err, &apiErr) {
+			assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		}
+	})
+
+	t.Run("Iterate over the Tasks", func(t *testing.T) {
+		var descriptions []string
+		it := c.Tasks(ctx, client.ListOptions{Limit: 2})
+		for it.Next() {
+			descriptions = append(descriptions, it.Task().Description)
+		}
+		assert.NoError(t, it.Err())
+		assert.Len(t, descriptions, 5)
+
+		it = client.New(server.URL, "forged").Tasks(ctx, client.ListOptions{})
+		assert.False(t, it.Next())
+		assert.ErrorIs(t, it.Err(), entity.ErrInvalidToken)
+	})
+
+	t.Run("Search the Tasks", func(t *testing.T) {
+		results, err := c.Search(ctx, "client")
+		assert.NoError(t, err)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, created.ID, results[0].Task.ID)
+		}
+
+		_, err = c.Search(ctx, "")
+		assert.ErrorIs(t, err, entity.ErrInvalidSearchQuery)
+	})
+
+	t.Run("Delete a Task", func(t *testing.T) {
+		assert.NoError(t, c.DeleteTask(ctx, created.ID))
+		assert.ErrorIs(t, c.DeleteTask(ctx, created.ID), entity.ErrTaskNotFound)
+		_, err := c.GetTask(ctx, created.ID)
+		assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	})
+}
+
+func TestRetries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1)%3 != 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"description": "Retried"}`)
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL, "token")
+	c.Backoff, c.MaxBackoff = time.Millisecond, time.Millisecond
+	ctx := context.Background()
+
+	found, err := c.GetTask(ctx, uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, "Retried", found.Description)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	_, err = c.CreateTask(ctx, *entity.NewTask("Not retried"))
+	var apiErr *client.Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests), "POST requests are not retried")
+
+	atomic.StoreInt32(&requests, 0)
+	c.MaxAttempts = 2
+	_, err = c.GetTask(ctx, uuid.New())
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "the requests are sent MaxAttempts times")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = c.GetTask(cancelled, uuid.New())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestErrorCodes(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL, "token")
+	ctx := context.Background()
+
+	// The errors are told apart by their codes, whatever their messages.
+	body = `{"code": "invalid_priority_level", "message": "priority is invalid: reworded"}`
+	_, err := c.CreateTask(ctx, *entity.NewTask("Coded"))
+	assert.ErrorIs(t, err, entity.ErrInvalidPriorityLevel)
+
+	body = `{"message": "invalid priority level"}`
+	_, err = c.CreateTask(ctx, *entity.NewTask("Uncoded"))
+	assert.NotErrorIs(t, err, entity.ErrInvalidPriorityLevel)
+
+	body = `{"code": "from_a_newer_server", "message": "unknown"}`
+	_, err = c.CreateTask(ctx, *entity.NewTask("Unknown"))
+	var apiErr *client.Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "from_a_newer_server", apiErr.Code)
+		assert.Nil(t, apiErr.Unwrap())
+	}
+}
+
+func TestChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "41", r.Header.Get("Last-Event-ID"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": connected\n\n")
+		fmt.Fprint(w, "id: 42\nevent: created\ndata: {\"id\": 42, \"type\": \"created\", \"task\": {\"description\": \"New\"}}\n\n")
+		fmt.Fprint(w, ": heartbeat\n\n")
+		fmt.Fprint(w, "event: reset\ndata: {\"type\": \"reset\"}\n\n")
+	}))
+	defer server.Close()
+
+	stream, err := client.New(server.URL, "token").Changes(context.Background(), 41)
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	change, err := stream.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), change.ID)
+	assert.Equal(t, changes.Created, change.Type)
+	assert.Equal(t, "New", change.Task.Description)
+
+	_, err = stream.Next()
+	assert.ErrorIs(t, err, changes.ErrBacklogExceeded, "the Tasks must be reloaded")
+	_, err = stream.Next()
+	assert.Equal(t, io.EOF, err)
+}
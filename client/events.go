@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/omaciel/GoDoIt/changes"
+)
+
+// ChangeStream reads the changes to the Tasks the User can see, as GET
+// /events sends them.
+type ChangeStream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// Changes opens the stream of the changes after the one with the given ID,
+// or of the new ones if it is zero. The stream is not retried: once Next
+// fails, the caller opens another one after the last change it received.
+func (c *Client) Changes(ctx context.Context, after uint64) (*ChangeStream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/events", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if after != 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(after, 10))
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	// The stream lasts longer than the timeout of the other requests.
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		failure := &Error{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(failure)
+		return nil, failure
+	}
+	return &ChangeStream{body: resp.Body, reader: bufio.NewReader(resp.Body)}, nil
+}
+
+// Next returns the next change. It returns changes.ErrBacklogExceeded when
+// the changes since the last one are no longer kept, so that the Tasks must
+// be reloaded, and io.EOF when the server closed the stream.
+func (s *ChangeStream) Next() (changes.Change, error) {
+	var event, data string
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return changes.Change{}, io.EOF
+			}
+			return changes.Change{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data == "" {
+				continue
+			}
+			if event == "reset" {
+				return changes.Change{}, changes.ErrBacklogExceeded
+			}
+			var change changes.Change
+			if err := json.Unmarshal([]byte(data), &change); err != nil {
+				return changes.Change{}, fmt.Errorf("godoit: decoding a change: %w", err)
+			}
+			return change, nil
+		case strings.HasPrefix(line, ":"):
+			// Comments keep the stream alive.
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+}
+
+// Close closes the stream.
+func (s *ChangeStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// DefaultPageSize is the number of Tasks in the pages of the TaskIterators
+// unless asked otherwise, as for the server.
+const DefaultPageSize = 50
+
+// headerTotalCount is the number of Tasks listed by GET /.
+const headerTotalCount = "X-Total-Count"
+
+// Credentials are the username and password of a User.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Session is a token identifying a User until it expires.
+type Session struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	User      entity.User `json:"user"`
+}
+
+// Page is a page of Tasks along with the total number of Tasks.
+type Page struct {
+	Tasks  []entity.Task `json:"tasks"`
+	Total  int64         `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// ListOptions filter and page the Tasks of ListTasks.
+type ListOptions struct {
+	// Archived lists the archived Tasks instead, which are only paged.
+	Archived bool
+
+	// Assignee is "me" for the Tasks assigned to the User, or "none" for the
+	// unassigned ones.
+	Assignee string
+
+	// AsOf lists the Tasks as they were at a past time, unless it is zero.
+	AsOf time.Time
+
+	// Limit and Offset page through the Tasks, which are all listed when
+	// both are zero, except for the archived ones.
+	Limit  int
+	Offset int
+}
+
+// Register creates a User.
+func (c *Client) Register(ctx context.Context, username, password string) (entity.User, error) {
+	var user entity.User
+	_, _, err := c.do(ctx, http.MethodPost, "/auth/register", nil, Credentials{username, password}, &user)
+	return user, err
+}
+
+// Login opens a Session of a User, whose token the Client then sends.
+func (c *Client) Login(ctx context.Context, username, password string) (Session, error) {
+	var session Session
+	if _, _, err := c.do(ctx, http.MethodPost, "/auth/login", nil, Credentials{username, password}, &session); err != nil {
+		return Session{}, err
+	}
+	c.Token = session.Token
+	return session, nil
+}
+
+// Me returns the User of the token.
+func (c *Client) Me(ctx context.Context) (entity.User, error) {
+	var user entity.User
+	_, _, err := c.do(ctx, http.MethodGet, "/auth/me", nil, nil, &user)
+	return user, err
+}
+
+// CreateTask creates a Task, and returns it as created.
+func (c *Client) CreateTask(ctx context.Context, t entity.Task) (entity.Task, error) {
+	var created entity.Task
+	_, _, err := c.do(ctx, http.MethodPost, "/task", nil, t, &created)
+	return created, err
+}
+
+// GetTask returns a Task, or an error matching entity.ErrTaskNotFound.
+func (c *Client) GetTask(ctx context.Context, id uuid.UUID) (entity.Task, error) {
+	var t entity.Task
+	status, _, err := c.do(ctx, http.MethodGet, "/task/"+id.String(), nil, nil, &t)
+	if err != nil {
+		return entity.Task{}, err
+	}
+	// The server answers without content when the Task is missing.
+	if status == http.StatusNoContent {
+		return entity.Task{}, &Error{StatusCode: status, Code: entity.ErrorCode(entity.ErrTaskNotFound), Message: entity.ErrTaskNotFound.Error()}
+	}
+	return t, nil
+}
+
+// UpdateTask replaces the Task with the ID of t, and returns it as updated.
+func (c *Client) UpdateTask(ctx context.Context, t entity.Task) (entity.Task, error) {
+	var updated entity.Task
+	_, _, err := c.do(ctx, http.MethodPut, "/task/"+t.ID.String(), nil, t, &updated)
+	return updated, err
+}
+
+// DeleteTask moves a Task to the trash, or returns an error matching
+// entity.ErrTaskNotFound.
+func (c *Client) DeleteTask(ctx context.Context, id uuid.UUID) error {
+	status, _, err := c.do(ctx, http.MethodDelete, "/task/"+id.String(), nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	// The server answers without content when the Task is missing.
+	if status == http.StatusNoContent {
+		return &Error{StatusCode: status, Code: entity.ErrorCode(entity.ErrTaskNotFound), Message: entity.ErrTaskNotFound.Error()}
+	}
+	return nil
+}
+
+// ListTasks returns a Page of the Tasks.
+func (c *Client) ListTasks(ctx context.Context, opts ListOptions) (Page, error) {
+	query := url.Values{}
+	if opts.Limit != 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset != 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+
+	if opts.Archived {
+		var page Page
+		_, _, err := c.do(ctx, http.MethodGet, "/archive", query, nil, &page)
+		return page, err
+	}
+
+	if opts.Assignee != "" {
+		query.Set("assignee", opts.Assignee)
+	}
+	if !opts.AsOf.IsZero() {
+		query.Set("as_of", opts.AsOf.Format(time.RFC3339))
+	}
+	page := Page{Limit: opts.Limit, Offset: opts.Offset}
+	_, header, err := c.do(ctx, http.MethodGet, "/", query, nil, &page.Tasks)
+	if err != nil {
+		return Page{}, err
+	}
+	page.Total = int64(len(page.Tasks))
+	if total, err := strconv.ParseInt(header.Get(headerTotalCount), 10, 64); err == nil {
+		page.Total = total
+	}
+	return page, nil
+}
+
+// Search returns the Tasks matching a query, best first.
+func (c *Client) Search(ctx context.Context, query string) ([]entity.SearchResult, error) {
+	var results []entity.SearchResult
+	_, _, err := c.do(ctx, http.MethodGet, "/search", url.Values{"q": {query}}, nil, &results)
+	return results, err
+}
+
+// Tasks returns an iterator over the Tasks listed with the options, which
+// fetches them a page at a time from the Offset.
+func (c *Client) Tasks(ctx context.Context, opts ListOptions) *TaskIterator {
+	if opts.Limit == 0 {
+		opts.Limit = DefaultPageSize
+	}
+	return &TaskIterator{client: c, ctx: ctx, opts: opts}
+}
+
+// TaskIterator iterates over the pages of Tasks:
+//
+//	it := c.Tasks(ctx, client.ListOptions{})
+//	for it.Next() {
+//		fmt.Println(it.Task().Description)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type TaskIterator struct {
+	client *Client
+	ctx    context.Context
+	opts   ListOptions
+	page   []entity.Task
+	task   entity.Task
+	done   bool
+	err    error
+}
+
+// Next advances to the next Task, fetching the next page if needed. It
+// returns false at the end of the Tasks or on an error.
+func (it *TaskIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		page, err := it.client.ListTasks(it.ctx, it.opts)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page.Tasks
+		it.opts.Offset += len(page.Tasks)
+		it.done = len(page.Tasks) < it.opts.Limit || int64(it.opts.Offset) >= page.Total
+	}
+	it.task, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Task returns the current Task.
+func (it *TaskIterator) Task() entity.Task {
+	return it.task
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *TaskIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/client"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/tui"
+	"golang.org/x/term"
+)
+
+const (
+	loginUsage = "usage: godoit login <username>"
+	addUsage   = "usage: godoit add <description> [-p low|medium|high] [-due <date>]"
+	listUsage  = "usage: godoit ls [-open] [-done] [-mine] [-archived] [-json]"
+	showUsage  = "usage: godoit show [-json] <id>"
+	doneUsage  = "usage: godoit done <id>..."
+	rmUsage    = "usage: godoit rm <id>..."
+	uiUsage    = "usage: godoit ui [-local <dir> -user <username> [-create]]"
+)
+
+// shortIDLength is how much of the IDs of the Tasks is shown in the tables.
+const shortIDLength = 8
+
+// cli runs the commands with a client of the configured server.
+type cli struct {
+	config     Config
+	configPath string
+	stdin      io.Reader
+	stdout     io.Writer
+}
+
+// client returns a client of the server, sending the configured token.
+func (cli *cli) client() *client.Client {
+	return client.New(cli.config.Server, cli.config.Token)
+}
+
+// login opens a session with the password read from the input, and keeps
+// its token in the configuration file.
+func (cli *cli) login(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	args, err := parse(flags, args)
+	if err != nil || len(args) != 1 {
+		return errors.New(loginUsage)
+	}
+
+	fmt.Fprint(cli.stdout, "Password: ")
+	password, err := cli.readPassword()
+	if err != nil {
+		return errors.New("a password is required")
+	}
+	fmt.Fprintln(cli.stdout)
+
+	session, err := cli.client().Login(ctx, args[0], password)
+	if err != nil {
+		return err
+	}
+	cli.config.Token = session.Token
+	if err := cli.config.save(cli.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.stdout, "Logged in as %s until %s.\n", session.User.Username, session.ExpiresAt.Local().Format(time.RFC1123))
+	return nil
+}
+
+// readPassword reads a line of the input without echoing it when the input
+// is a terminal. Piped input is read as it is.
+func (cli *cli) readPassword() (string, error) {
+	if f, ok := cli.stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		password, err := term.ReadPassword(int(f.Fd()))
+		return string(password), err
+	}
+
+	password, err := bufio.NewReader(cli.stdin).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && password != "") {
+		return "", err
+	}
+	return strings.TrimRight(password, "\r\n"), nil
+}
+
+// add creates a Task.
+func (cli *cli) add(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("add", flag.ContinueOnError)
+	priority := flags.String("p", "low", "the priority of the Task")
+	due := flags.String("due", "", "when the Task is due, as a date or an RFC 3339 time")
+	asJSON := flags.Bool("json", false, "print the Task as JSON")
+	args, err := parse(flags, args)
+	if err != nil || len(args) == 0 {
+		return errors.New(addUsage)
+	}
+
+	t := entity.NewTask(strings.Join(args, " "))
+	if t.Priority, err = entity.ParsePriority(*priority); err != nil {
+		return err
+	}
+	if *due != "" {
+		at, err := parseDue(*due)
+		if err != nil {
+			return err
+		}
+		t.DueAt = &at
+	}
+
+	created, err := cli.client().CreateTask(ctx, *t)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return cli.printJSON(created)
+	}
+	fmt.Fprintf(cli.stdout, "Added %s: %s\n", shortID(created.ID), created.Description)
+	return nil
+}
+
+// parseDue parses a due date, which is either a day in the local time zone or
+// an RFC 3339 time.
+func parseDue(s string) (time.Time, error) {
+	if at, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return at, nil
+	}
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("the due date must be YYYY-MM-DD or an RFC 3339 time: %q", s)
+	}
+	return at, nil
+}
+
+// list prints the Tasks as a table, or as JSON.
+func (cli *cli) list(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	open := flags.Bool("open", false, "list only the Tasks which are not completed")
+	done := flags.Bool("done", false, "list only the completed Tasks")
+	mine := flags.Bool("mine", false, "list only the Tasks assigned to me")
+	archived := flags.Bool("archived", false, "list the archived Tasks instead")
+	asJSON := flags.Bool("json", false, "print the Tasks as JSON")
+	args, err := parse(flags, args)
+	if err != nil || len(args) != 0 || (*open && *done) {
+		return errors.New(listUsage)
+	}
+
+	opts := client.ListOptions{Archived: *archived}
+	if *mine {
+		opts.Assignee = "me"
+	}
+	tasks := []entity.Task{}
+	it := cli.client().Tasks(ctx, opts)
+	for it.Next() {
+		t := it.Task()
+		if (*open && t.Completed) || (*done && !t.Completed) {
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return cli.printJSON(tasks)
+	}
+	w := tabwriter.NewWriter(cli.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPRIORITY\tDONE\tDUE\tDESCRIPTION")
+	for _, t := range tasks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", shortID(t.ID), t.Priority, check(t.Completed), dueDate(t.DueAt), t.Description)
+	}
+	return w.Flush()
+}
+
+// show prints a Task.
+func (cli *cli) show(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("show", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the Task as JSON")
+	args, err := parse(flags, args)
+	if err != nil || len(args) != 1 {
+		return errors.New(showUsage)
+	}
+
+	c := cli.client()
+	id, err := resolve(ctx, c, args[0])
+	if err != nil {
+		return err
+	}
+	t, err := c.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return cli.printJSON(t)
+	}
+	w := tabwriter.NewWriter(cli.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", t.ID)
+	fmt.Fprintf(w, "Description:\t%s\n", t.Description)
+	fmt.Fprintf(w, "Priority:\t%s\n", t.Priority)
+	fmt.Fprintf(w, "Completed:\t%s\n", check(t.Completed))
+	fmt.Fprintf(w, "Due:\t%s\n", dueDate(t.DueAt))
+	fmt.Fprintf(w, "Created:\t%s\n", t.CreatedAt.Local().Format(time.RFC1123))
+	for _, item := range t.Checklist {
+		fmt.Fprintf(w, "\t[%s] %s\n", check(item.Done), item.Text)
+	}
+	return w.Flush()
+}
+
+// done completes Tasks.
+func (cli *cli) done(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("done", flag.ContinueOnError)
+	args, err := parse(flags, args)
+	if err != nil || len(args) == 0 {
+		return errors.New(doneUsage)
+	}
+
+	c := cli.client()
+	for _, prefix := range args {
+		id, err := resolve(ctx, c, prefix)
+		if err != nil {
+			return err
+		}
+		t, err := c.GetTask(ctx, id)
+		if err != nil {
+			return err
+		}
+		t.Completed = true
+		if _, err := c.UpdateTask(ctx, t); err != nil {
+			return err
+		}
+		fmt.Fprintf(cli.stdout, "Completed %s: %s\n", shortID(t.ID), t.Description)
+	}
+	return nil
+}
+
+// remove moves Tasks to the trash.
+func (cli *cli) remove(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("rm", flag.ContinueOnError)
+	args, err := parse(flags, args)
+	if err != nil || len(args) == 0 {
+		return errors.New(rmUsage)
+	}
+
+	c := cli.client()
+	for _, prefix := range args {
+		id, err := resolve(ctx, c, prefix)
+		if err != nil {
+			return err
+		}
+		if err := c.DeleteTask(ctx, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(cli.stdout, "Removed %s\n", shortID(id))
+	}
+	return nil
+}
+
+// ui browses and edits the Tasks full screen, live-updating from the server,
+// or offline in the event store of a local directory.
+func (cli *cli) ui(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ui", flag.ContinueOnError)
+	local := flags.String("local", "", "the directory of a local event store to use instead of the server")
+	username := flags.String("user", "", "the User of the local event store")
+	create := flags.Bool("create", false, "create the User in the local event store")
+	args, err := parse(flags, args)
+	if err != nil || len(args) != 0 || (*local == "") != (*username == "") || (*create && *local == "") {
+		return errors.New(uiUsage)
+	}
+
+	var store tui.Store = &tui.RemoteStore{Client: cli.client()}
+	if *local != "" {
+		localStore, err := tui.OpenLocalStore(*local, *username, *create)
+		if errors.Is(err, entity.ErrUserNotFound) {
+			return fmt.Errorf("%w: %s, add -create to create it", err, *username)
+		} else if err != nil {
+			return err
+		}
+		defer localStore.Close()
+		store = localStore
+	}
+	return tui.Run(ctx, tui.NewModel(ctx, store), os.Stdin)
+}
+
+// resolve returns the ID of the only Task, archived or not, whose ID starts
+// with the prefix.
+func resolve(ctx context.Context, c *client.Client, prefix string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(prefix); err == nil {
+		return id, nil
+	}
+
+	prefix = strings.ToLower(prefix)
+	var matches []uuid.UUID
+	for _, archived := range []bool{false, true} {
+		it := c.Tasks(ctx, client.ListOptions{Archived: archived})
+		for it.Next() {
+			if id := it.Task().ID; strings.HasPrefix(id.String(), prefix) {
+				matches = append(matches, id)
+			}
+		}
+		if err := it.Err(); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return uuid.Nil, fmt.Errorf("%s: %w", prefix, entity.ErrTaskNotFound)
+	case 1:
+		return matches[0], nil
+	}
+	return uuid.Nil, fmt.Errorf("%s: the prefix matches %d tasks", prefix, len(matches))
+}
+
+// printJSON prints a value as indented JSON.
+func (cli *cli) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(cli.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// shortID returns the beginning of an ID, which usually names a single Task.
+func shortID(id uuid.UUID) string {
+	return id.String()[:shortIDLength]
+}
+
+// check marks the things which are done.
+func check(done bool) string {
+	if done {
+		return "x"
+	}
+	return " "
+}
+
+// dueDate returns the day a Task is due, if ever.
+func dueDate(at *time.Time) string {
+	if at == nil {
+		return "-"
+	}
+	return at.Local().Format("2006-01-02")
+}
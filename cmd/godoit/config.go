@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DefaultServer is the URL of the API unless configured otherwise.
+const DefaultServer = "http://localhost:3000"
+
+// Config is where the API is and how to authenticate to it. It is read from
+// a JSON file, and then from the GODOIT_SERVER and GODOIT_TOKEN variables of
+// the environment.
+type Config struct {
+	Server string `json:"server"`
+	Token  string `json:"token"`
+}
+
+// defaultConfigPath returns the path of the configuration file in the
+// configuration directory of the user, such as ~/.config/godoit/config.json.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "godoit.json"
+	}
+	return filepath.Join(dir, "godoit", "config.json")
+}
+
+// loadConfig reads the configuration file at path, which may not exist yet.
+func loadConfig(path string) (Config, error) {
+	config := Config{Server: DefaultServer}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return config, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return config, err
+		}
+	}
+
+	if server := os.Getenv("GODOIT_SERVER"); server != "" {
+		config.Server = server
+	}
+	if token := os.Getenv("GODOIT_TOKEN"); token != "" {
+		config.Token = token
+	}
+	return config, nil
+}
+
+// save writes the configuration file at path, readable only by the user
+// since it holds the token.
+func (c Config) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,91 @@
+// Command godoit manages the Tasks of a GoDoIt server from the terminal.
+//
+//	godoit login alice
+//	godoit add "Write report" -p high -due 2024-04-01
+//	godoit ls -open
+//	godoit done 1a2b
+//	godoit rm 1a2b
+//	godoit ui
+//
+// The Tasks are named by their IDs, or by any prefix matching a single Task.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: godoit [-config <file>] <command> [arguments]
+
+The commands are:
+  login <username>                  log in, reading the password from the input
+  add <description> [-p <priority>] [-due <date>]
+                                    create a Task
+  ls [-open] [-done] [-mine] [-archived] [-json]
+                                    list the Tasks
+  show [-json] <id>                 show a Task
+  done <id>...                      complete Tasks
+  rm <id>...                        move Tasks to the trash
+  ui [-local <dir> -user <username> [-create]]
+                                    browse and edit the Tasks full screen,
+                                    or offline in a local event store`
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "godoit:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command of the arguments, reading the input from stdin and
+// writing the output to stdout.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("godoit", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configPath := flags.String("config", defaultConfigPath(), "the configuration file")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return errors.New(usage)
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", *configPath, err)
+	}
+	cli := &cli{config: config, configPath: *configPath, stdin: stdin, stdout: stdout}
+
+	commands := map[string]func(context.Context, []string) error{
+		"login": cli.login,
+		"add":   cli.add,
+		"ls":    cli.list,
+		"show":  cli.show,
+		"done":  cli.done,
+		"rm":    cli.remove,
+		"ui":    cli.ui,
+	}
+	command, ok := commands[flags.Arg(0)]
+	if !ok {
+		return errors.New(usage)
+	}
+	return command(ctx, flags.Args()[1:])
+}
+
+// parse parses the flags of a command wherever they are among its
+// arguments, and returns the other arguments.
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/client"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommands(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = changes.Watch(repo, changes.Default), repo
+	app := fiber.New()
+	router.SetupRoutes(app)
+	server := httptest.NewServer(adaptor.FiberApp(app))
+	defer server.Close()
+
+	ctx := context.Background()
+	_, err := client.New(server.URL, "").Register(ctx, "terminal", "a password")
+	assert.NoError(t, err)
+
+	configPath := filepath.Join(t.TempDir(), "godoit", "config.json")
+	assert.NoError(t, Config{Server: server.URL}.save(configPath))
+	t.Setenv("GODOIT_SERVER", "")
+	t.Setenv("GODOIT_TOKEN", "")
+
+	// godoit runs a command, and returns its output.
+	godoit := func(stdin string, args ...string) (string, error) {
+		var stdout bytes.Buffer
+		err := run(ctx, append([]string{"-config", configPath}, args...), strings.NewReader(stdin), &stdout)
+		return stdout.String(), err
+	}
+
+	t.Run("Log in", func(t *testing.T) {
+		_, err := godoit("wrong password\n", "login", "terminal")
+		assert.ErrorIs(t, err, entity.ErrInvalidCredentials)
+
+		output, err := godoit("a password\n", "login", "terminal")
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Logged in as terminal")
+		config, err := loadConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, server.URL, config.Server)
+		assert.NotEmpty(t, config.Token)
+	})
+
+	var report entity.Task
+	t.Run("Add Tasks", func(t *testing.T) {
+		output, err := godoit("", "add", "Write", "report", "-p", "high", "-due", "2024-04-01", "-json")
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal([]byte(output), &report))
+		assert.Equal(t, "Write report", report.Description)
+		assert.Equal(t, entity.PriorityHigh, report.Priority)
+		assert.NotNil(t, report.DueAt)
+
+		output, err = godoit("", "add", "Read mail")
+		assert.NoError(t, err)
+		assert.Contains(t, output, ": Read mail")
+
+		_, err = godoit("", "add", "Urgent", "-p", "asap")
+		assert.ErrorIs(t, err, entity.ErrInvalidPriorityLevel)
+		_, err = godoit("", "add")
+		assert.EqualError(t, err, addUsage)
+	})
+
+	t.Run("Complete a Task by a prefix of its ID", func(t *testing.T) {
+		output, err := godoit("", "done", report.ID.String()[:4])
+		assert.NoError(t, err)
+		assert.Equal(t, "Completed "+report.ID.String()[:shortIDLength]+": Write report\n", output)
+
+		_, err = godoit("", "done", "zzz")
+		assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+		_, err = godoit("", "done", "")
+		assert.ErrorContains(t, err, "the prefix matches 2 tasks")
+	})
+
+	t.Run("List the Tasks", func(t *testing.T) {
+		output, err := godoit("", "ls")
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if assert.Len(t, lines, 3) {
+			assert.Equal(t, []string{"ID", "PRIORITY", "DONE", "DUE", "DESCRIPTION"}, strings.Fields(lines[0]))
+		}
+		assert.Contains(t, output, "2024-04-01")
+
+		output, err = godoit("", "ls", "-open")
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Read mail")
+		assert.NotContains(t, output, "Write report")
+
+		var completed []entity.Task
+		output, err = godoit("", "ls", "-done", "-json")
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal([]byte(output), &completed))
+		if assert.Len(t, completed, 1) {
+			assert.Equal(t, report.ID, completed[0].ID)
+		}
+
+		_, err = godoit("", "ls", "-open", "-done")
+		assert.EqualError(t, err, listUsage)
+	})
+
+	t.Run("Show a Task", func(t *testing.T) {
+		output, err := godoit("", "show", report.ID.String())
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Write report")
+		assert.Contains(t, output, "high")
+	})
+
+	t.Run("Remove a Task", func(t *testing.T) {
+		output, err := godoit("", "rm", report.ID.String()[:6])
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Removed "+report.ID.String()[:shortIDLength])
+		_, err = godoit("", "show", report.ID.String())
+		assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	})
+
+	t.Run("Unknown commands", func(t *testing.T) {
+		_, err := godoit("", "frobnicate")
+		assert.EqualError(t, err, usage)
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/formats"
+)
+
+const importUsage = "usage: import -user <username> [-format csv|json|todotxt|ics] [-dry-run] <file>"
+
+// importTasks adds the Tasks of a file to the repository on behalf of a User,
+// as the import endpoint of the API does.
+func importTasks(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	username := flags.String("user", "", "the User owning the imported Tasks")
+	name := flags.String("format", "", "the format of the file, guessed from its extension by default")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without keeping any Task")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *username == "" {
+		return errors.New(importUsage)
+	}
+	path := flags.Arg(0)
+
+	if *name == "" {
+		*name = strings.TrimPrefix(filepath.Ext(path), ".")
+		if *name == "txt" {
+			*name = string(formats.TodoTxt)
+		}
+	}
+	format, err := formats.ParseFormat(*name)
+	if err != nil {
+		return err
+	}
+
+	database.InitDB()
+	ctx := context.Background()
+	user, err := database.Users.UserByName(ctx, *username)
+	if err != nil {
+		return err
+	}
+	ctx = task.WithOwner(ctx, user.ID)
+	ctx = task.WithWorkspace(ctx, user.WorkspaceID)
+	ctx = task.WithActor(ctx, user.Username)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entries, err := formats.Decode(file, format)
+	if err != nil {
+		return err
+	}
+	report, err := formats.Import(ctx, database.Repo, entries, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range report.Duplicates {
+		fmt.Printf("%s:%d: skipped, the task already exists\n", path, line)
+	}
+	for _, lineErr := range report.Errors {
+		fmt.Printf("%s:%d: %s\n", path, lineErr.Line, lineErr.Message)
+	}
+	if report.DryRun {
+		fmt.Printf("Would import %d tasks.\n", report.Imported)
+	} else {
+		fmt.Printf("Imported %d tasks.\n", report.Imported)
+	}
+	return nil
+}
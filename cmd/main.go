@@ -1,20 +1,78 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"os"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/changes"
 	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/jobs"
 	"github.com/omaciel/GoDoIt/router"
+	"github.com/omaciel/GoDoIt/webhooks"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
+	// Import files of Tasks instead of serving the API.
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := importTasks(os.Args[2:]); err != nil {
+			fmt.Println("Could not import the tasks.", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create Sqlite Database
 	database.InitDB()
 
 	// database.ConnectDb()
 
-	app := fiber.New()
+	// Sign the tokens of the API with the configured secret.
+	auth.Init()
+
+	// Keep the contents of the attachments where configured.
+	blob.Init()
+
+	// Empty the trash of the Tasks deleted longer ago than the retention period.
+	go jobs.PurgeTrash(
+		context.Background(),
+		database.Repo,
+		database.Attachments,
+		blob.Default,
+		jobs.DurationFromEnv("TRASH_RETENTION", jobs.DefaultTrashRetention),
+		jobs.DurationFromEnv("TRASH_PURGE_INTERVAL", jobs.DefaultTrashPurgeInterval),
+	)
+
+	// Deliver the changes to the Tasks to the webhooks of the Users, which
+	// only reach the private networks the operator allows.
+	dispatcher := webhooks.NewDispatcher(database.Webhooks)
+	dispatcher.AllowedNetworks = webhooks.NetworksFromEnv("WEBHOOK_ALLOWED_NETWORKS")
+	dispatcher.Start(
+		context.Background(),
+		changes.Default,
+		jobs.DurationFromEnv("WEBHOOK_RETRY_INTERVAL", webhooks.DefaultRetryInterval),
+	)
+
+	// Serve the TaskService to gRPC clients, over TLS when a certificate is
+	// given.
+	if addr := os.Getenv("GRPC_ADDR"); addr != "" {
+		go func() {
+			err := serveGRPC(addr, os.Getenv("GRPC_TLS_CERT"), os.Getenv("GRPC_TLS_KEY"))
+			fmt.Println("Could not start the gRPC server.", err)
+		}()
+	}
+
+	app := fiber.New(fiber.Config{
+		BodyLimit:      handlers.BodyLimit,
+		RequestMethods: handlers.RequestMethods,
+	})
 
 	router.SetupRoutes(app)
 
@@ -24,3 +82,22 @@ func main() {
 		return
 	}
 }
+
+// serveGRPC serves the TaskService on an address, over TLS when the files of
+// a certificate are given.
+func serveGRPC(addr, certFile, keyFile string) error {
+	var opts []grpc.ServerOption
+	if certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return handlers.NewGRPCServer(opts...).Serve(listener)
+}
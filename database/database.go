@@ -1,22 +1,63 @@
 package database
 
 import (
+	"log"
 	"os"
 
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/domain/attachment"
+	"github.com/omaciel/GoDoIt/domain/comment"
+	"github.com/omaciel/GoDoIt/domain/eventsource"
 	postgres "github.com/omaciel/GoDoIt/domain/postgres"
 	sql "github.com/omaciel/GoDoIt/domain/sqlite"
 	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/domain/user"
+	"github.com/omaciel/GoDoIt/domain/webhook"
 )
 
+// Repo holds the Tasks, and publishes their changes to changes.Default.
 var Repo task.TaskRepository
 
+// Users holds the accounts owning the Tasks, next to them in the same backend.
+var Users user.UserRepository
+
+// Comments holds the discussions about the Tasks, next to them in the same
+// backend.
+var Comments comment.CommentRepository
+
+// Attachments records the files attached to the Tasks, whose contents are in
+// the blob.Default store.
+var Attachments attachment.AttachmentRepository
+
+// Webhooks records the webhooks of the Users and their deliveries, next to
+// the Tasks in the same backend.
+var Webhooks webhook.WebhookRepository
+
 func InitDB() {
 	dataLayer := os.Getenv("DATABASE")
 
 	switch dataLayer {
 	case "postgres":
-		Repo, _ = postgres.NewPostgresRepository()
+		repo, _ := postgres.NewPostgresRepository()
+		Repo, Users, Comments, Attachments, Webhooks = repo, repo, repo, repo, repo
+	case "eventsource":
+		dir := os.Getenv("EVENTSOURCE_DIR")
+		if dir == "" {
+			dir = "data"
+		}
+		store, err := eventsource.NewFileStore(dir)
+		if err != nil {
+			log.Fatal("Failed to open the event store. \n", err)
+		}
+		repo, err := eventsource.NewEventSourcedRepository(store)
+		if err != nil {
+			log.Fatal("Failed to replay the event store. \n", err)
+		}
+		Repo, Users, Comments, Attachments, Webhooks = repo, repo, repo, repo, repo
 	default:
-		Repo, _ = sql.NewSqliteDBRepository()
+		repo, _ := sql.NewSqliteDBRepository()
+		Repo, Users, Comments, Attachments, Webhooks = repo, repo, repo, repo, repo
 	}
+
+	Repo = changes.Watch(Repo, changes.Default)
 }
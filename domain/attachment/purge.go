@@ -0,0 +1,30 @@
+package attachment
+
+import (
+	"context"
+
+	"github.com/omaciel/GoDoIt/blob"
+)
+
+// DeletePurged deletes the contents of the Attachments of the purged Tasks
+// from the store, and then the Attachments themselves. The Attachments whose
+// contents could not be deleted are kept, for the next call to try again.
+// It returns how many Attachments were deleted.
+func DeletePurged(ctx context.Context, repo AttachmentRepository, store blob.Store) (int, error) {
+	purged, err := repo.PurgedAttachments(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, attachment := range purged {
+		if err := store.Delete(ctx, attachment.Key()); err != nil {
+			return deleted, err
+		}
+		if err := repo.ForgetAttachment(ctx, attachment.ID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,29 @@
+package attachment
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// AttachmentRepository records the files attached to Tasks, whose contents
+// are kept in a blob.Store. Attachments are seen by whoever may see their
+// Task. Once it is purged, they are only kept until DeletePurged deletes
+// their contents.
+type AttachmentRepository interface {
+	// AddAttachment is uploaded by the User of the context, who must be able
+	// to edit the Task, as for DeleteAttachment.
+	AddAttachment(ctx context.Context, attachment *entity.Attachment) error
+	GetAttachment(ctx context.Context, taskID, id uuid.UUID) (entity.Attachment, error)
+	Attachments(ctx context.Context, taskID uuid.UUID) ([]entity.Attachment, error)
+	DeleteAttachment(ctx context.Context, taskID, id uuid.UUID) error
+
+	// PurgedAttachments returns the Attachments of the purged Tasks of every
+	// User, whose contents are still in the blob.Store.
+	PurgedAttachments(ctx context.Context) ([]entity.Attachment, error)
+
+	// ForgetAttachment deletes a purged Attachment once its contents are
+	// deleted.
+	ForgetAttachment(ctx context.Context, id uuid.UUID) error
+}
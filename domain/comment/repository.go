@@ -0,0 +1,24 @@
+package comment
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CommentRepository keeps the discussions about Tasks. Comments are seen by
+// whoever may see their Task, and deleted along with it once it is purged.
+type CommentRepository interface {
+	// AddComment is written by the User of the context.
+	AddComment(ctx context.Context, comment *entity.Comment) error
+	GetComment(ctx context.Context, taskID, id uuid.UUID) (entity.Comment, error)
+	Comments(ctx context.Context, taskID uuid.UUID) ([]entity.Comment, error)
+
+	// Only their author edits Comments, keeping their previous bodies, which
+	// CommentEdits returns oldest first. The owner of the Task may also delete
+	// them.
+	EditComment(ctx context.Context, taskID, id uuid.UUID, body string) (entity.Comment, error)
+	DeleteComment(ctx context.Context, taskID, id uuid.UUID) error
+	CommentEdits(ctx context.Context, taskID, id uuid.UUID) ([]entity.CommentEdit, error)
+}
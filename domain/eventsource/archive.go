@@ -0,0 +1,38 @@
+package eventsource
+
+import (
+	"context"
+	"time"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Find satisfies the Find TaskRepository interface method
+func (es *EventSourcedRepository) Find(ctx context.Context, filter task.Filter) ([]entity.Task, int64, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.state.records().Find(ctx, filter)
+}
+
+// ArchiveCompleted satisfies the ArchiveCompleted TaskRepository interface method
+func (es *EventSourcedRepository) ArchiveCompleted(ctx context.Context, before time.Time) (int64, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	var events []Event
+	for id, value := range es.state.tasks {
+		if value.IsDeleted() || value.Archived || !value.Completed || !task.InWorkspace(ctx, value) || !task.Owns(ctx, value) {
+			continue
+		}
+		if value.CompletedAt != nil && !value.CompletedAt.Before(before) {
+			continue
+		}
+		events = append(events, Event{Type: TaskArchived, TaskID: id})
+	}
+	if err := es.emit(ctx, events...); err != nil {
+		return 0, err
+	}
+	return int64(len(events)), nil
+}
@@ -0,0 +1,47 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// editable returns the Task with the given ID if the context may edit it. The
+// caller must hold the lock.
+func (es *EventSourcedRepository) editable(ctx context.Context, id uuid.UUID) (entity.Task, error) {
+	t, ok := es.state.live(ctx, id)
+	if !ok {
+		return entity.Task{}, entity.ErrTaskNotFound
+	}
+	if !es.state.can(ctx, t, entity.RoleEditor) {
+		return entity.Task{}, entity.ErrForbidden
+	}
+	return t, nil
+}
+
+// Assign satisfies the Assign TaskRepository interface method
+func (es *EventSourcedRepository) Assign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	t, err := es.editable(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user, ok := es.state.users[userID]; !ok || user.WorkspaceID != t.WorkspaceID {
+		return entity.ErrInvalidAssignee
+	}
+	return es.emit(ctx, Event{Type: TaskAssigned, TaskID: id, UserID: userID})
+}
+
+// Unassign satisfies the Unassign TaskRepository interface method
+func (es *EventSourcedRepository) Unassign(ctx context.Context, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, err := es.editable(ctx, id); err != nil {
+		return err
+	}
+	return es.emit(ctx, Event{Type: TaskUnassigned, TaskID: id})
+}
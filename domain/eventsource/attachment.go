@@ -0,0 +1,106 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// attachment returns an Attachment to a Task which the context may see. The
+// caller must hold the lock.
+func (es *EventSourcedRepository) attachment(ctx context.Context, taskID, id uuid.UUID) (entity.Attachment, error) {
+	if _, ok := es.state.live(ctx, taskID); !ok {
+		return entity.Attachment{}, entity.ErrTaskNotFound
+	}
+	for _, attachment := range es.state.attachments[taskID] {
+		if attachment.ID == id {
+			return attachment, nil
+		}
+	}
+	return entity.Attachment{}, entity.ErrAttachmentNotFound
+}
+
+// AddAttachment satisfies the AddAttachment AttachmentRepository interface method
+func (es *EventSourcedRepository) AddAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if err := attachment.Validate(); err != nil {
+		return err
+	}
+	if _, err := es.editable(ctx, attachment.TaskID); err != nil {
+		return err
+	}
+
+	if attachment.ID == uuid.Nil {
+		attachment.ID = uuid.New()
+	}
+	attachment.UploaderID, _ = task.OwnerFromContext(ctx)
+	added := *attachment
+	if err := es.emit(ctx, Event{Type: AttachmentAdded, TaskID: attachment.TaskID, Attachment: &added}); err != nil {
+		return err
+	}
+
+	*attachment, _ = es.attachment(ctx, attachment.TaskID, attachment.ID)
+	return nil
+}
+
+// GetAttachment satisfies the GetAttachment AttachmentRepository interface method
+func (es *EventSourcedRepository) GetAttachment(ctx context.Context, taskID, id uuid.UUID) (entity.Attachment, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.attachment(ctx, taskID, id)
+}
+
+// Attachments satisfies the Attachments AttachmentRepository interface method
+func (es *EventSourcedRepository) Attachments(ctx context.Context, taskID uuid.UUID) ([]entity.Attachment, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	attachments := make([]entity.Attachment, 0)
+	if _, ok := es.state.live(ctx, taskID); !ok {
+		return attachments, entity.ErrTaskNotFound
+	}
+	return append(attachments, es.state.attachments[taskID]...), nil
+}
+
+// DeleteAttachment satisfies the DeleteAttachment AttachmentRepository interface method
+func (es *EventSourcedRepository) DeleteAttachment(ctx context.Context, taskID, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, err := es.attachment(ctx, taskID, id); err != nil {
+		return err
+	}
+	if _, err := es.editable(ctx, taskID); err != nil {
+		return err
+	}
+	return es.emit(ctx, Event{Type: AttachmentDeleted, TaskID: taskID, Attachment: &entity.Attachment{ID: id}})
+}
+
+// PurgedAttachments satisfies the PurgedAttachments AttachmentRepository
+// interface method
+func (es *EventSourcedRepository) PurgedAttachments(ctx context.Context) ([]entity.Attachment, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	attachments := make([]entity.Attachment, 0)
+	return append(attachments, es.state.purgedAttachments...), nil
+}
+
+// ForgetAttachment satisfies the ForgetAttachment AttachmentRepository
+// interface method
+func (es *EventSourcedRepository) ForgetAttachment(ctx context.Context, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	for _, attachment := range es.state.purgedAttachments {
+		if attachment.ID == id {
+			return es.emit(ctx, Event{Type: AttachmentDeleted, TaskID: attachment.TaskID, Attachment: &entity.Attachment{ID: id}})
+		}
+	}
+	return entity.ErrAttachmentNotFound
+}
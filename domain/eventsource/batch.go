@@ -0,0 +1,63 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// PostMany satisfies the PostMany TaskRepository interface method. The Events
+// of every Task are appended at once, so no Task is added unless all of them
+// are.
+func (es *EventSourcedRepository) PostMany(ctx context.Context, tasks []*entity.Task) error {
+	es.Lock()
+	defer es.Unlock()
+
+	var events []Event
+	seen := make(map[uuid.UUID]bool, len(tasks))
+	for _, t := range tasks {
+		if t.ID == uuid.Nil {
+			t.ID = uuid.New()
+		}
+		own(ctx, t)
+		if _, ok := es.state.tasks[t.ID]; ok || seen[t.ID] {
+			return entity.ErrTaskUniqueConstraint
+		}
+		seen[t.ID] = true
+		events = append(events, changes(nil, *t)...)
+	}
+
+	if err := es.emit(ctx, events...); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		*t = es.state.tasks[t.ID]
+	}
+	return nil
+}
+
+// DeleteMany satisfies the DeleteMany TaskRepository interface method. No Task
+// is moved to the trash unless all of them exist.
+func (es *EventSourcedRepository) DeleteMany(ctx context.Context, ids []uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	events := make([]Event, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		t, ok := es.state.live(ctx, id)
+		if !ok {
+			return entity.ErrTaskNotFound
+		}
+		if !task.Owns(ctx, t) {
+			return entity.ErrForbidden
+		}
+		if !seen[id] {
+			seen[id] = true
+			events = append(events, Event{Type: TaskDeleted, TaskID: id})
+		}
+	}
+	return es.emit(ctx, events...)
+}
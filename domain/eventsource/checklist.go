@@ -0,0 +1,112 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// checklistItem returns an item of the checklist of a Task which the context
+// may edit. The caller must hold the lock.
+func (es *EventSourcedRepository) checklistItem(ctx context.Context, taskID, id uuid.UUID) (entity.ChecklistItem, error) {
+	t, err := es.editable(ctx, taskID)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+	i, err := t.ChecklistItem(id)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+	return t.Checklist[i], nil
+}
+
+// AddChecklistItem satisfies the AddChecklistItem TaskRepository interface method
+func (es *EventSourcedRepository) AddChecklistItem(ctx context.Context, item *entity.ChecklistItem) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if err := entity.ValidateChecklistText(item.Text); err != nil {
+		return err
+	}
+	if _, err := es.editable(ctx, item.TaskID); err != nil {
+		return err
+	}
+
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	added := entity.ChecklistItem{ID: item.ID, Text: item.Text}
+	if err := es.emit(ctx, Event{Type: ChecklistItemAdded, TaskID: item.TaskID, ChecklistItem: &added}); err != nil {
+		return err
+	}
+
+	*item, _ = es.checklistItem(ctx, item.TaskID, item.ID)
+	return nil
+}
+
+// EditChecklistItem satisfies the EditChecklistItem TaskRepository interface method
+func (es *EventSourcedRepository) EditChecklistItem(ctx context.Context, taskID, id uuid.UUID, text string) (entity.ChecklistItem, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	if err := entity.ValidateChecklistText(text); err != nil {
+		return entity.ChecklistItem{}, err
+	}
+	if _, err := es.checklistItem(ctx, taskID, id); err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	edited := entity.ChecklistItem{ID: id, Text: text}
+	if err := es.emit(ctx, Event{Type: ChecklistItemEdited, TaskID: taskID, ChecklistItem: &edited}); err != nil {
+		return entity.ChecklistItem{}, err
+	}
+	return es.checklistItem(ctx, taskID, id)
+}
+
+// ToggleChecklistItem satisfies the ToggleChecklistItem TaskRepository interface method
+func (es *EventSourcedRepository) ToggleChecklistItem(ctx context.Context, taskID, id uuid.UUID) (entity.ChecklistItem, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	item, err := es.checklistItem(ctx, taskID, id)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	toggled := entity.ChecklistItem{ID: id, Done: !item.Done}
+	if err := es.emit(ctx, Event{Type: ChecklistItemToggled, TaskID: taskID, ChecklistItem: &toggled}); err != nil {
+		return entity.ChecklistItem{}, err
+	}
+	return es.checklistItem(ctx, taskID, id)
+}
+
+// ReorderChecklist satisfies the ReorderChecklist TaskRepository interface method
+func (es *EventSourcedRepository) ReorderChecklist(ctx context.Context, taskID uuid.UUID, order []uuid.UUID) ([]entity.ChecklistItem, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	t, err := es.editable(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := entity.Reorder(t.Checklist, order); err != nil {
+		return nil, err
+	}
+
+	if err := es.emit(ctx, Event{Type: ChecklistReordered, TaskID: taskID, Order: order}); err != nil {
+		return nil, err
+	}
+	return append([]entity.ChecklistItem{}, es.state.tasks[taskID].Checklist...), nil
+}
+
+// DeleteChecklistItem satisfies the DeleteChecklistItem TaskRepository interface method
+func (es *EventSourcedRepository) DeleteChecklistItem(ctx context.Context, taskID, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, err := es.checklistItem(ctx, taskID, id); err != nil {
+		return err
+	}
+	return es.emit(ctx, Event{Type: ChecklistItemDeleted, TaskID: taskID, ChecklistItem: &entity.ChecklistItem{ID: id}})
+}
@@ -0,0 +1,121 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// comment returns a Comment about a Task which the context may see, along
+// with the Task. The caller must hold the lock.
+func (es *EventSourcedRepository) comment(ctx context.Context, taskID, id uuid.UUID) (entity.Task, entity.Comment, error) {
+	t, ok := es.state.live(ctx, taskID)
+	if !ok {
+		return t, entity.Comment{}, entity.ErrTaskNotFound
+	}
+	for _, comment := range es.state.comments[taskID] {
+		if comment.ID == id {
+			return t, comment, nil
+		}
+	}
+	return t, entity.Comment{}, entity.ErrCommentNotFound
+}
+
+// AddComment satisfies the AddComment CommentRepository interface method
+func (es *EventSourcedRepository) AddComment(ctx context.Context, comment *entity.Comment) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if err := entity.ValidateCommentBody(comment.Body); err != nil {
+		return err
+	}
+	if _, ok := es.state.live(ctx, comment.TaskID); !ok {
+		return entity.ErrTaskNotFound
+	}
+
+	if comment.ID == uuid.Nil {
+		comment.ID = uuid.New()
+	}
+	comment.AuthorID, _ = task.OwnerFromContext(ctx)
+	added := entity.Comment{ID: comment.ID, AuthorID: comment.AuthorID, Body: comment.Body}
+	if err := es.emit(ctx, Event{Type: CommentAdded, TaskID: comment.TaskID, Comment: &added}); err != nil {
+		return err
+	}
+
+	_, *comment, _ = es.comment(ctx, comment.TaskID, comment.ID)
+	return nil
+}
+
+// GetComment satisfies the GetComment CommentRepository interface method
+func (es *EventSourcedRepository) GetComment(ctx context.Context, taskID, id uuid.UUID) (entity.Comment, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	_, comment, err := es.comment(ctx, taskID, id)
+	return comment, err
+}
+
+// Comments satisfies the Comments CommentRepository interface method
+func (es *EventSourcedRepository) Comments(ctx context.Context, taskID uuid.UUID) ([]entity.Comment, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	comments := make([]entity.Comment, 0)
+	if _, ok := es.state.live(ctx, taskID); !ok {
+		return comments, entity.ErrTaskNotFound
+	}
+	return append(comments, es.state.comments[taskID]...), nil
+}
+
+// EditComment satisfies the EditComment CommentRepository interface method
+func (es *EventSourcedRepository) EditComment(ctx context.Context, taskID, id uuid.UUID, body string) (entity.Comment, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	if err := entity.ValidateCommentBody(body); err != nil {
+		return entity.Comment{}, err
+	}
+	_, comment, err := es.comment(ctx, taskID, id)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+	if author, scoped := task.OwnerFromContext(ctx); scoped && comment.AuthorID != author {
+		return entity.Comment{}, entity.ErrForbidden
+	}
+
+	edited := entity.Comment{ID: id, Body: body}
+	if err := es.emit(ctx, Event{Type: CommentEdited, TaskID: taskID, Comment: &edited}); err != nil {
+		return entity.Comment{}, err
+	}
+	_, comment, err = es.comment(ctx, taskID, id)
+	return comment, err
+}
+
+// DeleteComment satisfies the DeleteComment CommentRepository interface method
+func (es *EventSourcedRepository) DeleteComment(ctx context.Context, taskID, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	t, comment, err := es.comment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	if author, scoped := task.OwnerFromContext(ctx); scoped && comment.AuthorID != author && !task.Owns(ctx, t) {
+		return entity.ErrForbidden
+	}
+	return es.emit(ctx, Event{Type: CommentDeleted, TaskID: taskID, Comment: &entity.Comment{ID: id}})
+}
+
+// CommentEdits satisfies the CommentEdits CommentRepository interface method
+func (es *EventSourcedRepository) CommentEdits(ctx context.Context, taskID, id uuid.UUID) ([]entity.CommentEdit, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	edits := make([]entity.CommentEdit, 0)
+	if _, _, err := es.comment(ctx, taskID, id); err != nil {
+		return edits, err
+	}
+	return append(edits, es.state.commentEdits[id]...), nil
+}
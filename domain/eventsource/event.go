@@ -0,0 +1,255 @@
+package eventsource
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// EventType is the kind of change recorded by an Event.
+type EventType string
+
+const (
+	// TaskCreated records a new Task, with its Description, Priority and
+	// calendar identity.
+	TaskCreated = EventType("TaskCreated")
+
+	// TaskDescribed records a new Description for a Task.
+	TaskDescribed = EventType("TaskDescribed")
+
+	// TaskPrioritized records a new Priority for a Task.
+	TaskPrioritized = EventType("TaskPrioritized")
+
+	// TaskCompleted records a Task being completed.
+	TaskCompleted = EventType("TaskCompleted")
+
+	// TaskReopened records a completed Task being reopened.
+	TaskReopened = EventType("TaskReopened")
+
+	// TaskScheduled records the new DueAt of a Task, or its removal.
+	TaskScheduled = EventType("TaskScheduled")
+
+	// TaskArchived records a Task being archived.
+	TaskArchived = EventType("TaskArchived")
+
+	// TaskUnarchived records a Task being brought back from the archive.
+	TaskUnarchived = EventType("TaskUnarchived")
+
+	// TaskDeleted records a Task being moved to the trash.
+	TaskDeleted = EventType("TaskDeleted")
+
+	// TaskRestored records a Task being restored from the trash.
+	TaskRestored = EventType("TaskRestored")
+
+	// TaskPurged records a Task being removed from the trash for good.
+	TaskPurged = EventType("TaskPurged")
+
+	// TaskShared records a Task being shared with a User, with its Role.
+	TaskShared = EventType("TaskShared")
+
+	// TaskUnshared records a User losing access to a Task.
+	TaskUnshared = EventType("TaskUnshared")
+
+	// TaskAssigned records a Task being assigned to a User.
+	TaskAssigned = EventType("TaskAssigned")
+
+	// TaskUnassigned records a Task being assigned to nobody.
+	TaskUnassigned = EventType("TaskUnassigned")
+
+	// CommentAdded records a new Comment about a Task.
+	CommentAdded = EventType("CommentAdded")
+
+	// CommentEdited records the new Body of a Comment.
+	CommentEdited = EventType("CommentEdited")
+
+	// CommentDeleted records the deletion of a Comment.
+	CommentDeleted = EventType("CommentDeleted")
+
+	// AttachmentAdded records a file being attached to a Task.
+	AttachmentAdded = EventType("AttachmentAdded")
+
+	// AttachmentDeleted records the deletion of an Attachment.
+	AttachmentDeleted = EventType("AttachmentDeleted")
+
+	// ChecklistItemAdded records a new item at the end of the checklist of a
+	// Task.
+	ChecklistItemAdded = EventType("ChecklistItemAdded")
+
+	// ChecklistItemEdited records the new Text of a checklist item.
+	ChecklistItemEdited = EventType("ChecklistItemEdited")
+
+	// ChecklistItemToggled records whether a checklist item is now Done.
+	ChecklistItemToggled = EventType("ChecklistItemToggled")
+
+	// ChecklistReordered records the new Order of the checklist of a Task.
+	ChecklistReordered = EventType("ChecklistReordered")
+
+	// ChecklistItemDeleted records the deletion of a checklist item.
+	ChecklistItemDeleted = EventType("ChecklistItemDeleted")
+
+	// UserRegistered records a new User, with its Username, PasswordHash,
+	// WorkspaceID and WorkspaceRole.
+	UserRegistered = EventType("UserRegistered")
+
+	// UserRemoved records the removal of a User from its workspace, along
+	// with its API tokens.
+	UserRemoved = EventType("UserRemoved")
+
+	// TokenCreated records a new API token of a User.
+	TokenCreated = EventType("TokenCreated")
+
+	// TokenRevoked records an API token being revoked.
+	TokenRevoked = EventType("TokenRevoked")
+
+	// TokenUsed records an API token being used.
+	TokenUsed = EventType("TokenUsed")
+
+	// WebhookCreated records a new webhook of a User.
+	WebhookCreated = EventType("WebhookCreated")
+
+	// WebhookDeleted records the deletion of a webhook, and of its
+	// deliveries.
+	WebhookDeleted = EventType("WebhookDeleted")
+
+	// DeliverySaved records a new webhook delivery, or the outcome of its
+	// latest attempt.
+	DeliverySaved = EventType("DeliverySaved")
+)
+
+// Event is an immutable fact about a Task. Events are numbered by Sequence in
+// the order they were appended to the log, and replaying them in that order
+// rebuilds every Task.
+type Event struct {
+	Sequence   uint64    `json:"sequence"`
+	Type       EventType `json:"type"`
+	TaskID     uuid.UUID `json:"task_id"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// Description is only set by TaskCreated and TaskDescribed events.
+	Description string `json:"description,omitempty"`
+	// Priority is only set by TaskCreated and TaskPrioritized events.
+	Priority entity.Priority `json:"priority,omitempty"`
+	// DueAt is only set by TaskScheduled events, unless the due date is
+	// removed.
+	DueAt *time.Time `json:"due_at,omitempty"`
+	// OwnerID is only set by TaskCreated events.
+	OwnerID uuid.UUID `json:"owner_id"`
+	// CalendarUID and CalendarName are only set by TaskCreated events, for
+	// the Tasks created by calendar applications.
+	CalendarUID  string `json:"calendar_uid,omitempty"`
+	CalendarName string `json:"calendar_name,omitempty"`
+	// WorkspaceID is only set by TaskCreated and UserRegistered events, and
+	// WorkspaceRole by UserRegistered events. The Users registered before
+	// there were roles have none.
+	WorkspaceID   uuid.UUID            `json:"workspace_id"`
+	WorkspaceRole entity.WorkspaceRole `json:"workspace_role,omitempty"`
+
+	// UserID is only set by UserRegistered, UserRemoved, TaskShared,
+	// TaskUnshared and TaskAssigned events, and Role by TaskShared events.
+	UserID uuid.UUID   `json:"user_id"`
+	Role   entity.Role `json:"role,omitempty"`
+
+	// Username and PasswordHash are only set by UserRegistered events.
+	Username     string `json:"username,omitempty"`
+	PasswordHash string `json:"password_hash,omitempty"`
+
+	// Comment is only set by the CommentAdded, CommentEdited and
+	// CommentDeleted events. Only its ID is set unless the Comment is added
+	// or its Body is edited.
+	Comment *entity.Comment `json:"comment,omitempty"`
+
+	// Attachment is only set by the AttachmentAdded and AttachmentDeleted
+	// events. Only its ID is set unless the Attachment is added.
+	Attachment *entity.Attachment `json:"attachment,omitempty"`
+
+	// ChecklistItem is only set by the events of the checklist items. Only
+	// its ID is set unless the item is added, edited or toggled. Order is only
+	// set by ChecklistReordered events.
+	ChecklistItem *entity.ChecklistItem `json:"checklist_item,omitempty"`
+	Order         []uuid.UUID           `json:"order,omitempty"`
+
+	// Token is only set by the TokenCreated, TokenRevoked and TokenUsed
+	// events. Only its ID is set unless the token is created.
+	Token *StoredToken `json:"token,omitempty"`
+
+	// Webhook is only set by the WebhookCreated and WebhookDeleted events.
+	// Only its ID is set unless the webhook is created. Delivery is only set
+	// by DeliverySaved events.
+	Webhook  *StoredWebhook          `json:"webhook,omitempty"`
+	Delivery *entity.WebhookDelivery `json:"delivery,omitempty"`
+}
+
+// action returns how the Event is recorded in the history of its Task.
+func (e Event) action() entity.HistoryAction {
+	switch e.Type {
+	case TaskCreated:
+		return entity.HistoryCreated
+	case TaskDeleted:
+		return entity.HistoryDeleted
+	case TaskRestored:
+		return entity.HistoryRestored
+	}
+	return entity.HistoryUpdated
+}
+
+// changes returns the Events turning the Task before into the Task after.
+// before is nil when the Task is new.
+func changes(before *entity.Task, after entity.Task) []Event {
+	var events []Event
+	event := func(eventType EventType) Event {
+		return Event{Type: eventType, TaskID: after.ID}
+	}
+
+	if before == nil {
+		created := event(TaskCreated)
+		created.Description = after.Description
+		created.Priority = after.Priority
+		created.OwnerID = after.OwnerID
+		created.WorkspaceID = after.WorkspaceID
+		created.CalendarUID = after.CalendarUID
+		created.CalendarName = after.CalendarName
+		events = append(events, created)
+		before = &entity.Task{Description: after.Description, Priority: after.Priority}
+	}
+
+	if after.Description != before.Description {
+		described := event(TaskDescribed)
+		described.Description = after.Description
+		events = append(events, described)
+	}
+	if after.Priority != before.Priority {
+		prioritized := event(TaskPrioritized)
+		prioritized.Priority = after.Priority
+		events = append(events, prioritized)
+	}
+	if after.Completed != before.Completed {
+		if after.Completed {
+			events = append(events, event(TaskCompleted))
+		} else {
+			events = append(events, event(TaskReopened))
+		}
+	}
+	if !sameTime(after.DueAt, before.DueAt) {
+		scheduled := event(TaskScheduled)
+		scheduled.DueAt = after.DueAt
+		events = append(events, scheduled)
+	}
+	if after.Archived != before.Archived {
+		if after.Archived {
+			events = append(events, event(TaskArchived))
+		} else {
+			events = append(events, event(TaskUnarchived))
+		}
+	}
+	return events
+}
+
+// sameTime reports whether two optional times are the same instant.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
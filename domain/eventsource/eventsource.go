@@ -0,0 +1,208 @@
+package eventsource
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// SnapshotInterval is the number of Events appended between two Snapshots.
+const SnapshotInterval = 100
+
+// EventSourcedRepository fulfills the TaskRepository interface. Its source of
+// truth is the log of Events kept by its Store, from which every Task is
+// projected in memory.
+type EventSourcedRepository struct {
+	sync.Mutex
+	store Store
+	state *projection
+
+	// pending counts the Events appended since the last Snapshot.
+	pending int
+
+	// past is the latest state replayed by AsOf, up to an Event which occurred
+	// at pastAt, from which the calls as of a later time resume.
+	past   *projection
+	pastAt time.Time
+}
+
+// NewEventSourcedRepository creates a datastore for Tasks on top of an event
+// Store. The Tasks are rebuilt from its latest Snapshot and the Events
+// appended since.
+func NewEventSourcedRepository(store Store) (*EventSourcedRepository, error) {
+	ctx := context.Background()
+
+	state := newProjection()
+	snapshot, err := store.Snapshot(ctx)
+	if err != nil {
+		log.Println("Failed to load the latest snapshot. \n", err)
+		return nil, err
+	}
+	if snapshot != nil {
+		state = fromSnapshot(snapshot)
+	}
+
+	events, err := store.Events(ctx, state.sequence)
+	if err != nil {
+		log.Println("Failed to replay the event log. \n", err)
+		return nil, err
+	}
+	for _, event := range events {
+		state.apply(event)
+	}
+
+	return &EventSourcedRepository{
+		store:   store,
+		state:   state,
+		pending: len(events),
+	}, nil
+}
+
+// emit numbers the Events, appends them to the log and applies them. The
+// caller must hold the lock.
+func (es *EventSourcedRepository) emit(ctx context.Context, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	actor := task.ActorFromContext(ctx)
+	for i := range events {
+		events[i].Sequence = es.state.sequence + uint64(i) + 1
+		events[i].Actor = actor
+		events[i].OccurredAt = now
+	}
+	return es.commit(ctx, events)
+}
+
+// commit appends numbered Events to the log and applies them. The caller must
+// hold the lock.
+func (es *EventSourcedRepository) commit(ctx context.Context, events []Event) error {
+	if err := es.store.Append(ctx, events); err != nil {
+		return err
+	}
+	for _, event := range events {
+		es.state.apply(event)
+	}
+
+	es.pending += len(events)
+	if es.pending >= SnapshotInterval {
+		// The Events are safe in the log, so a failed Snapshot only means a
+		// longer replay on the next startup.
+		if err := es.snapshot(ctx); err != nil {
+			log.Println("Failed to save a snapshot. \n", err)
+		}
+	}
+	return nil
+}
+
+// snapshot saves a Snapshot of the Tasks. The caller must hold the lock.
+func (es *EventSourcedRepository) snapshot(ctx context.Context) error {
+	snapshot := es.state.snapshot()
+	snapshot.TakenAt = time.Now()
+	if err := es.store.SaveSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	es.pending = 0
+	return nil
+}
+
+// Snapshot saves a Snapshot of the Tasks right away.
+func (es *EventSourcedRepository) Snapshot(ctx context.Context) error {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.snapshot(ctx)
+}
+
+// own sets the owner and workspace found in the context, if any, on a new
+// Task. New Tasks are unassigned, without a checklist.
+func own(ctx context.Context, t *entity.Task) {
+	t.AssigneeID, t.Checklist = nil, nil
+	if owner, ok := task.OwnerFromContext(ctx); ok {
+		t.OwnerID = owner
+	}
+	if workspace, ok := task.WorkspaceFromContext(ctx); ok {
+		t.WorkspaceID = workspace
+	}
+}
+
+// Get satifies the Get TaskRepository interface method
+func (es *EventSourcedRepository) Get(ctx context.Context, id uuid.UUID) (entity.Task, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	if task, ok := es.state.live(ctx, id); ok {
+		return task, nil
+	}
+	return entity.Task{}, entity.ErrTaskNotFound
+}
+
+// Post satifies the Post TaskRepository interface method
+func (es *EventSourcedRepository) Post(ctx context.Context, task *entity.Task) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if task.ID == uuid.Nil {
+		task.ID = uuid.New()
+	}
+	own(ctx, task)
+	if _, ok := es.state.tasks[task.ID]; ok {
+		return entity.ErrTaskUniqueConstraint
+	}
+
+	if err := es.emit(ctx, changes(nil, *task)...); err != nil {
+		return err
+	}
+	*task = es.state.tasks[task.ID]
+	return nil
+}
+
+// Delete satisfies the Delete TaskRepository interface method
+func (es *EventSourcedRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	t, ok := es.state.live(ctx, id)
+	if !ok {
+		return entity.ErrTaskNotFound
+	}
+	if !task.Owns(ctx, t) {
+		return entity.ErrForbidden
+	}
+	return es.emit(ctx, Event{Type: TaskDeleted, TaskID: id})
+}
+
+// All satisfies the All TaskRepository interface method
+func (es *EventSourcedRepository) All(ctx context.Context) ([]entity.Task, error) {
+	values, _, err := es.Find(ctx, task.Filter{})
+	return values, err
+}
+
+// Put satisfies the Put TaskRepository interface method. Only the fields which
+// changed are recorded, so a Put changing nothing appends no Event.
+func (es *EventSourcedRepository) Put(ctx context.Context, task *entity.Task) error {
+	es.Lock()
+	defer es.Unlock()
+
+	existing, ok := es.state.live(ctx, task.ID)
+	if !ok {
+		return entity.ErrTaskNotFound
+	}
+	if !es.state.can(ctx, existing, entity.RoleEditor) {
+		return entity.ErrForbidden
+	}
+	task.OwnerID, task.WorkspaceID = existing.OwnerID, existing.WorkspaceID
+	task.AssigneeID = existing.AssigneeID
+
+	if err := es.emit(ctx, changes(&existing, *task)...); err != nil {
+		return err
+	}
+	*task = es.state.tasks[task.ID]
+	return nil
+}
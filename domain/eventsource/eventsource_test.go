@@ -0,0 +1,636 @@
+package eventsource_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/eventsource"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepository(t *testing.T, store eventsource.Store) *eventsource.EventSourcedRepository {
+	repo, err := eventsource.NewEventSourcedRepository(store)
+	if err != nil {
+		t.Fatalf("failed to replay the event log: %v", err)
+	}
+	return repo
+}
+
+func eventTypes(t *testing.T, repo *eventsource.EventSourcedRepository) []eventsource.EventType {
+	events, err := repo.Events(context.Background(), 0)
+	assert.NoError(t, err)
+
+	types := make([]eventsource.EventType, 0, len(events))
+	for i, event := range events {
+		assert.Equal(t, uint64(i+1), event.Sequence)
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestEventSourcedRepository(t *testing.T) {
+	repo := newRepository(t, eventsource.NewMemoryStore())
+	ctx := context.Background()
+
+	task0 := entity.NewTask("task 0").WithCompleted(true)
+	assert.NoError(t, repo.Post(ctx, task0))
+	assert.NotNil(t, task0.CompletedAt)
+	assert.False(t, task0.CreatedAt.IsZero())
+	assert.ErrorIs(t, repo.Post(ctx, task0), entity.ErrTaskUniqueConstraint)
+
+	updated := *task0
+	updated.Description = "task 0, renamed"
+	updated.Priority = entity.PriorityHigh
+	updated.Completed = false
+	assert.NoError(t, repo.Put(ctx, &updated))
+	assert.Nil(t, updated.CompletedAt)
+	assert.Equal(t, task0.CreatedAt, updated.CreatedAt)
+
+	unchanged := updated
+	assert.NoError(t, repo.Put(ctx, &unchanged))
+
+	record, err := repo.Get(ctx, task0.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "task 0, renamed", record.Description)
+	assert.Equal(t, entity.PriorityHigh, record.Priority)
+
+	assert.NoError(t, repo.Delete(ctx, task0.ID))
+	_, err = repo.Get(ctx, task0.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, task0.ID), entity.ErrTaskNotFound)
+
+	assert.Equal(t, []eventsource.EventType{
+		eventsource.TaskCreated,
+		eventsource.TaskCompleted,
+		eventsource.TaskDescribed,
+		eventsource.TaskPrioritized,
+		eventsource.TaskReopened,
+		eventsource.TaskDeleted,
+	}, eventTypes(t, repo))
+}
+
+func TestEventSourcedRepositoryReplay(t *testing.T) {
+	store, err := eventsource.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	repo := newRepository(t, store)
+	ctx := task.WithActor(context.Background(), "alice")
+
+	task0 := entity.NewTask("task 0")
+	task1 := entity.NewTask("task 1")
+	assert.NoError(t, repo.PostMany(ctx, []*entity.Task{task0, task1}))
+	assert.NoError(t, repo.Snapshot(ctx))
+
+	done := *task1
+	done.Completed = true
+	assert.NoError(t, repo.Put(ctx, &done))
+	assert.NoError(t, repo.Delete(ctx, task0.ID))
+
+	want, err := repo.All(ctx)
+	assert.NoError(t, err)
+	wantTrash, err := repo.Trash(ctx)
+	assert.NoError(t, err)
+	wantHistory, err := repo.History(ctx, task1.ID)
+	assert.NoError(t, err)
+
+	// Rebuild the Tasks from the snapshot and the Events appended since.
+	replayed := newRepository(t, store)
+
+	got, err := replayed.All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.Equal(t, want[0].ID, got[0].ID)
+	assert.True(t, got[0].Completed)
+	assert.True(t, want[0].UpdatedAt.Equal(got[0].UpdatedAt))
+
+	gotTrash, err := replayed.Trash(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, gotTrash, len(wantTrash))
+
+	gotHistory, err := replayed.History(ctx, task1.ID)
+	assert.NoError(t, err)
+	assert.Len(t, gotHistory, 2)
+	for i := range wantHistory {
+		assert.Equal(t, wantHistory[i].ID, gotHistory[i].ID)
+		assert.Equal(t, wantHistory[i].Version, gotHistory[i].Version)
+		assert.Equal(t, "alice", gotHistory[i].Actor)
+	}
+
+	// Replaying without the snapshot yields the same Tasks.
+	events, err := store.Events(ctx, 0)
+	assert.NoError(t, err)
+	full := eventsource.NewMemoryStore()
+	assert.NoError(t, full.Append(ctx, events))
+
+	got, err = newRepository(t, full).All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.Equal(t, task1.ID, got[0].ID)
+	assert.True(t, got[0].Completed)
+}
+
+func TestFileStoreLock(t *testing.T) {
+	dir := t.TempDir()
+	store, err := eventsource.NewFileStore(dir)
+	assert.NoError(t, err)
+
+	_, err = eventsource.NewFileStore(dir)
+	assert.ErrorIs(t, err, eventsource.ErrStoreLocked)
+
+	assert.NoError(t, store.Close())
+	reopened, err := eventsource.NewFileStore(dir)
+	assert.NoError(t, err, "the lock is released when the store is closed")
+	assert.NoError(t, reopened.Close())
+}
+
+func TestFileStoreModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the permissions of the files are not Unix modes")
+	}
+	dir := filepath.Join(t.TempDir(), "events")
+	store, err := eventsource.NewFileStore(dir)
+	assert.NoError(t, err)
+	repo := newRepository(t, store)
+	ctx := context.Background()
+	assert.NoError(t, repo.Post(ctx, entity.NewTask("task 0")))
+	assert.NoError(t, repo.Snapshot(ctx))
+
+	info, err := os.Stat(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	for _, name := range []string{eventsource.EventsFile, eventsource.SnapshotFile, eventsource.LockFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), name)
+	}
+
+	// The files of the earlier versions are restricted when reopened.
+	assert.NoError(t, store.Close())
+	assert.NoError(t, os.Chmod(filepath.Join(dir, eventsource.EventsFile), 0o644))
+	reopened, err := eventsource.NewFileStore(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	info, err = os.Stat(filepath.Join(dir, eventsource.EventsFile))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestFileStoreTornLine(t *testing.T) {
+	dir := t.TempDir()
+	store, err := eventsource.NewFileStore(dir)
+	assert.NoError(t, err)
+	repo := newRepository(t, store)
+	ctx := context.Background()
+	assert.NoError(t, repo.Post(ctx, entity.NewTask("task 0")))
+	assert.NoError(t, store.Close())
+
+	// A crash in the middle of an Append leaves an incomplete last line.
+	log, err := os.OpenFile(filepath.Join(dir, eventsource.EventsFile), os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = log.WriteString(`{"sequence":2,"type":"task_cre`)
+	assert.NoError(t, err)
+	assert.NoError(t, log.Close())
+
+	store, err = eventsource.NewFileStore(dir)
+	assert.NoError(t, err, "the incomplete line does not fail the store")
+	defer store.Close()
+	repo = newRepository(t, store)
+	assert.NoError(t, repo.Post(ctx, entity.NewTask("task 1")))
+
+	tasks, err := newRepository(t, store).All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2, "the Events appended after the incomplete line are replayed")
+}
+
+func TestEventSourcedRepositorySnapshotInterval(t *testing.T) {
+	store := eventsource.NewMemoryStore()
+	repo := newRepository(t, store)
+
+	for i := 0; i < eventsource.SnapshotInterval; i++ {
+		snapshot, err := store.Snapshot(context.Background())
+		assert.NoError(t, err)
+		assert.Nil(t, snapshot)
+		assert.NoError(t, repo.Post(context.Background(), entity.NewTask("task")))
+	}
+
+	snapshot, err := store.Snapshot(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, snapshot) {
+		assert.Equal(t, uint64(eventsource.SnapshotInterval), snapshot.Sequence)
+		assert.Len(t, snapshot.Tasks, eventsource.SnapshotInterval)
+	}
+}
+
+func TestEventSourcedRepositoryUsersReplay(t *testing.T) {
+	store, err := eventsource.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	repo := newRepository(t, store)
+	ctx := context.Background()
+
+	user, err := entity.NewUser("alice", "correct horse")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateUser(ctx, user))
+	assert.ErrorIs(t, repo.CreateUser(ctx, user), entity.ErrUsernameTaken)
+
+	revoked, _, err := entity.NewAPIToken(user.ID, "old", entity.ScopeRead, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateToken(ctx, revoked))
+	assert.NoError(t, repo.Snapshot(ctx))
+
+	token, secret, err := entity.NewAPIToken(user.ID, "script", entity.ScopeWrite, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateToken(ctx, token))
+	assert.NoError(t, repo.RevokeToken(ctx, user.ID, revoked.ID))
+	assert.NoError(t, repo.TouchToken(ctx, token.ID, time.Now()))
+
+	// Users and their tokens, hashes included, survive a restart.
+	replayed := newRepository(t, store)
+
+	found, err := replayed.UserByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.True(t, found.CheckPassword("correct horse"))
+
+	used, err := replayed.TokenByHash(ctx, entity.HashAPIToken(secret))
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, used.ID)
+	assert.NotNil(t, used.LastUsedAt)
+
+	tokens, err := replayed.Tokens(ctx, user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 2) {
+		assert.NotNil(t, tokens[0].RevokedAt)
+		assert.Nil(t, tokens[1].RevokedAt)
+	}
+
+	// Removed Users lose their tokens.
+	assert.NoError(t, replayed.RemoveUser(ctx, user.ID))
+	assert.ErrorIs(t, replayed.RemoveUser(ctx, user.ID), entity.ErrUserNotFound)
+	replayed = newRepository(t, store)
+	_, err = replayed.UserByName(ctx, "alice")
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+	_, err = replayed.TokenByHash(ctx, entity.HashAPIToken(secret))
+	assert.ErrorIs(t, err, entity.ErrTokenNotFound)
+}
+
+func TestEventSourcedRepositoryFormerWorkspaceRoles(t *testing.T) {
+	store := eventsource.NewMemoryStore()
+	workspace := uuid.New()
+	founder, joiner := uuid.New(), uuid.New()
+	registered := func(sequence uint64, id uuid.UUID, username string) eventsource.Event {
+		return eventsource.Event{
+			Sequence:    sequence,
+			Type:        eventsource.UserRegistered,
+			OccurredAt:  time.Now(),
+			UserID:      id,
+			Username:    username,
+			WorkspaceID: workspace,
+		}
+	}
+	// The Users registered before there were workspace roles have none.
+	assert.NoError(t, store.Append(context.Background(), []eventsource.Event{
+		registered(1, founder, "founder"),
+		registered(2, joiner, "joiner"),
+	}))
+
+	repo := newRepository(t, store)
+	user, err := repo.GetUser(context.Background(), founder)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.WorkspaceAdmin, user.WorkspaceRole)
+	user, err = repo.GetUser(context.Background(), joiner)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.WorkspaceMember, user.WorkspaceRole)
+}
+
+func TestEventSourcedRepositorySharesReplay(t *testing.T) {
+	store, err := eventsource.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	repo := newRepository(t, store)
+	owner := task.WithOwner(context.Background(), uuid.New())
+	editorID, viewerID := uuid.New(), uuid.New()
+	editor := task.WithOwner(context.Background(), editorID)
+	viewer := task.WithOwner(context.Background(), viewerID)
+
+	shared := entity.NewTask("Shared")
+	assert.NoError(t, repo.Post(owner, shared))
+	assert.NoError(t, repo.Share(owner, shared.ID, editorID, entity.RoleEditor))
+	assert.NoError(t, repo.Snapshot(owner))
+	assert.NoError(t, repo.Share(owner, shared.ID, viewerID, entity.RoleViewer))
+
+	// Shares survive a restart, whether they were in the snapshot or not.
+	replayed := newRepository(t, store)
+
+	tasks, _, err := replayed.Find(viewer, task.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	update := *shared
+	update.Description = "Edited"
+	assert.ErrorIs(t, replayed.Put(viewer, &update), entity.ErrForbidden)
+	assert.NoError(t, replayed.Put(editor, &update))
+	assert.ErrorIs(t, replayed.Delete(editor, shared.ID), entity.ErrForbidden)
+
+	assert.NoError(t, replayed.Unshare(owner, shared.ID, viewerID))
+	_, err = replayed.Get(viewer, shared.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+
+	// Purging a Task drops its shares.
+	assert.NoError(t, replayed.Delete(owner, shared.ID))
+	assert.NoError(t, replayed.Purge(owner, shared.ID))
+	_, err = replayed.Shares(owner, shared.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+}
+
+func TestEventSourcedRepositoryWorkspaces(t *testing.T) {
+	store := eventsource.NewMemoryStore()
+	repo := newRepository(t, store)
+
+	owner := uuid.New()
+	ours := task.WithWorkspace(task.WithOwner(context.Background(), owner), uuid.New())
+	theirs := task.WithWorkspace(task.WithOwner(context.Background(), owner), uuid.New())
+
+	secret := entity.NewTask("Secret")
+	assert.NoError(t, repo.Post(ours, secret))
+
+	// Tasks stay in their workspace after a restart.
+	replayed := newRepository(t, store)
+
+	tasks, _, err := replayed.Find(ours, task.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	tasks, _, err = replayed.Find(theirs, task.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	_, err = replayed.Get(theirs, secret.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	assert.ErrorIs(t, replayed.Delete(theirs, secret.ID), entity.ErrTaskNotFound)
+}
+
+func TestEventSourcedRepositoryAssigneesReplay(t *testing.T) {
+	store := eventsource.NewMemoryStore()
+	repo := newRepository(t, store)
+
+	owner, _ := entity.NewUser("assigner", "correct horse")
+	assignee, _ := entity.NewUser("assignee", "correct horse")
+	assignee.WorkspaceID = owner.WorkspaceID
+	outsider, _ := entity.NewUser("outsider", "correct horse")
+	for _, user := range []*entity.User{owner, assignee, outsider} {
+		assert.NoError(t, repo.CreateUser(context.Background(), user))
+	}
+	ctx := task.WithWorkspace(task.WithOwner(context.Background(), owner.ID), owner.WorkspaceID)
+
+	assigned := entity.NewTask("Assigned")
+	assert.NoError(t, repo.Post(ctx, assigned))
+	assert.ErrorIs(t, repo.Assign(ctx, assigned.ID, outsider.ID), entity.ErrInvalidAssignee)
+	assert.NoError(t, repo.Assign(ctx, assigned.ID, assignee.ID))
+
+	replayed := newRepository(t, store)
+
+	tasks, _, err := replayed.Find(ctx, task.Filter{AssignedTo: assignee.ID})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	assert.NoError(t, replayed.Unassign(ctx, assigned.ID))
+	tasks, _, err = replayed.Find(ctx, task.Filter{Unassigned: true})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Contains(t, eventTypes(t, replayed), eventsource.TaskUnassigned)
+}
+
+func TestEventSourcedRepositoryCommentsReplay(t *testing.T) {
+	store, err := eventsource.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	repo := newRepository(t, store)
+	owner := task.WithOwner(context.Background(), uuid.New())
+	viewerID := uuid.New()
+	viewer := task.WithOwner(context.Background(), viewerID)
+
+	discussed := entity.NewTask("Discussed")
+	assert.NoError(t, repo.Post(owner, discussed))
+	assert.NoError(t, repo.Share(owner, discussed.ID, viewerID, entity.RoleViewer))
+
+	question, _ := entity.NewComment(discussed.ID, "Why?")
+	assert.NoError(t, repo.AddComment(viewer, question))
+	answer, _ := entity.NewComment(discussed.ID, "Because.")
+	assert.NoError(t, repo.AddComment(owner, answer))
+	assert.NoError(t, repo.Snapshot(owner))
+	_, err = repo.EditComment(viewer, discussed.ID, question.ID, "Why now?")
+	assert.NoError(t, err)
+
+	// Comments and their edits survive a restart.
+	replayed := newRepository(t, store)
+
+	comments, err := replayed.Comments(viewer, discussed.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.Equal(t, "Why now?", comments[0].Body)
+		assert.NotNil(t, comments[0].EditedAt)
+		assert.Equal(t, viewerID, comments[0].AuthorID)
+	}
+	edits, err := replayed.CommentEdits(viewer, discussed.ID, question.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, edits, 1) {
+		assert.Equal(t, "Why?", edits[0].Body)
+	}
+
+	_, err = replayed.EditComment(owner, discussed.ID, question.ID, "Hijacked")
+	assert.ErrorIs(t, err, entity.ErrForbidden)
+	assert.NoError(t, replayed.DeleteComment(owner, discussed.ID, question.ID))
+	_, err = replayed.GetComment(viewer, discussed.ID, question.ID)
+	assert.ErrorIs(t, err, entity.ErrCommentNotFound)
+
+	// Purging a Task drops its comments.
+	assert.NoError(t, replayed.Delete(owner, discussed.ID))
+	assert.NoError(t, replayed.Purge(owner, discussed.ID))
+	_, err = replayed.Comments(owner, discussed.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+}
+
+func TestEventSourcedRepositoryAttachmentsReplay(t *testing.T) {
+	store := eventsource.NewMemoryStore()
+	repo := newRepository(t, store)
+
+	owner := task.WithOwner(context.Background(), uuid.New())
+	viewerID := uuid.New()
+	viewer := task.WithOwner(context.Background(), viewerID)
+
+	documented := entity.NewTask("Documented")
+	assert.NoError(t, repo.Post(owner, documented))
+	assert.NoError(t, repo.Share(owner, documented.ID, viewerID, entity.RoleViewer))
+
+	screenshot, _ := entity.NewAttachment(documented.ID, "screenshot.png", "image/png", 2048)
+	assert.ErrorIs(t, repo.AddAttachment(viewer, screenshot), entity.ErrForbidden)
+	assert.NoError(t, repo.AddAttachment(owner, screenshot))
+	assert.NoError(t, repo.Snapshot(owner))
+	notes, _ := entity.NewAttachment(documented.ID, "notes.txt", "text/plain; charset=utf-8", 12)
+	assert.NoError(t, repo.AddAttachment(owner, notes))
+
+	// Attachments survive a restart, whether they were in the snapshot or not.
+	replayed := newRepository(t, store)
+
+	attachments, err := replayed.Attachments(viewer, documented.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, attachments, 2) {
+		assert.Equal(t, *screenshot, attachments[0])
+		assert.Equal(t, "notes.txt", attachments[1].Filename)
+	}
+
+	assert.ErrorIs(t, replayed.DeleteAttachment(viewer, documented.ID, notes.ID), entity.ErrForbidden)
+	assert.NoError(t, replayed.DeleteAttachment(owner, documented.ID, notes.ID))
+	_, err = replayed.GetAttachment(owner, documented.ID, notes.ID)
+	assert.ErrorIs(t, err, entity.ErrAttachmentNotFound)
+
+	// Purging a Task drops its attachments, which are kept apart until their
+	// contents are deleted.
+	assert.NoError(t, replayed.Delete(owner, documented.ID))
+	assert.NoError(t, replayed.Purge(owner, documented.ID))
+	_, err = replayed.Attachments(owner, documented.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	assert.NoError(t, replayed.Snapshot(owner))
+
+	replayed = newRepository(t, store)
+	purged, err := replayed.PurgedAttachments(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, purged, 1) {
+		assert.Equal(t, screenshot.ID, purged[0].ID)
+		assert.NotNil(t, purged[0].PurgedAt)
+	}
+	assert.NoError(t, replayed.ForgetAttachment(context.Background(), screenshot.ID))
+	assert.ErrorIs(t, replayed.ForgetAttachment(context.Background(), screenshot.ID), entity.ErrAttachmentNotFound)
+
+	replayed = newRepository(t, store)
+	purged, err = replayed.PurgedAttachments(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, purged)
+}
+
+func TestEventSourcedRepositoryChecklistReplay(t *testing.T) {
+	store := eventsource.NewMemoryStore()
+	repo := newRepository(t, store)
+	owner := task.WithOwner(context.Background(), uuid.New())
+
+	packing := entity.NewTask("Pack for the trip")
+	assert.NoError(t, repo.Post(owner, packing))
+
+	var items []*entity.ChecklistItem
+	for _, text := range []string{"Passport", "Charger", "Toothbrush"} {
+		item, _ := entity.NewChecklistItem(packing.ID, text)
+		assert.NoError(t, repo.AddChecklistItem(owner, item))
+		items = append(items, item)
+	}
+	assert.NoError(t, repo.Snapshot(owner))
+	_, err := repo.ToggleChecklistItem(owner, packing.ID, items[1].ID)
+	assert.NoError(t, err)
+	_, err = repo.EditChecklistItem(owner, packing.ID, items[2].ID, "Toothpaste")
+	assert.NoError(t, err)
+	_, err = repo.ReorderChecklist(owner, packing.ID, []uuid.UUID{items[2].ID, items[1].ID, items[0].ID})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.DeleteChecklistItem(owner, packing.ID, items[0].ID))
+
+	// The checklist survives a restart, whether it was in the snapshot or not.
+	replayed := newRepository(t, store)
+
+	found, err := replayed.Get(owner, packing.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, found.Checklist, 2) {
+		assert.Equal(t, "Toothpaste", found.Checklist[0].Text)
+		assert.Equal(t, "Charger", found.Checklist[1].Text)
+		assert.Equal(t, 1, found.Checklist[1].Position)
+	}
+	assert.Equal(t, 50, found.ChecklistCompletion())
+
+	// The checklist is not part of the history of the Task.
+	history, err := replayed.History(owner, packing.ID)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestEventSourcedRepositoryDueDatesReplay(t *testing.T) {
+	store := eventsource.NewMemoryStore()
+	repo := newRepository(t, store)
+	owner := task.WithOwner(context.Background(), uuid.New())
+
+	due := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	taxes := entity.NewTask("File taxes")
+	taxes.DueAt = &due
+	taxes.CalendarUID, taxes.CalendarName = "taxes@example.com", "taxes"
+	assert.NoError(t, repo.Post(owner, taxes))
+
+	later := due.AddDate(0, 0, 14)
+	taxes.DueAt = &later
+	assert.NoError(t, repo.Put(owner, taxes))
+
+	unscheduled := entity.NewTask("Someday")
+	unscheduled.DueAt = &due
+	assert.NoError(t, repo.Post(owner, unscheduled))
+	unscheduled.DueAt = nil
+	assert.NoError(t, repo.Put(owner, unscheduled))
+
+	replayed := newRepository(t, store)
+	found, err := replayed.Get(owner, taxes.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found.DueAt) {
+		assert.True(t, later.Equal(*found.DueAt))
+	}
+	assert.Equal(t, "taxes@example.com", found.CalendarUID, "the calendar identity is kept")
+	assert.Equal(t, "taxes", found.CalendarName)
+	found, err = replayed.Get(owner, unscheduled.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, found.DueAt)
+
+	history, err := replayed.History(owner, taxes.ID)
+	assert.NoError(t, err)
+	assert.Len(t, history, 3, "scheduling and rescheduling are recorded")
+}
+
+func TestEventSourcedRepositoryWebhooksReplay(t *testing.T) {
+	store, err := eventsource.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	repo := newRepository(t, store)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	kept, err := entity.NewWebhook(userID, "https://ci.example.com/hooks", nil, "whsec_kept")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateWebhook(ctx, kept))
+	dropped, err := entity.NewWebhook(userID, "https://old.example.com/hooks", nil, "")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateWebhook(ctx, dropped))
+
+	delivery := entity.NewWebhookDelivery(*kept, "created", `{"event":"created"}`)
+	assert.NoError(t, repo.SaveDelivery(ctx, delivery))
+	assert.NoError(t, repo.Snapshot(ctx))
+
+	delivery.Status, delivery.Attempts, delivery.NextAttemptAt = entity.DeliveryDead, 8, nil
+	assert.NoError(t, repo.SaveDelivery(ctx, delivery))
+	assert.NoError(t, repo.DeleteWebhook(ctx, userID, dropped.ID))
+
+	// The webhooks, their secrets and their deliveries survive a restart.
+	replayed := newRepository(t, store)
+
+	webhooks, err := replayed.Webhooks(ctx, userID)
+	assert.NoError(t, err)
+	if assert.Len(t, webhooks, 1) {
+		assert.Equal(t, "whsec_kept", webhooks[0].Secret)
+	}
+
+	dead, err := replayed.DeadDeliveries(ctx, userID)
+	assert.NoError(t, err)
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, delivery.ID, dead[0].ID)
+		assert.Equal(t, 8, dead[0].Attempts)
+		assert.False(t, dead[0].CreatedAt.IsZero())
+	}
+}
@@ -0,0 +1,76 @@
+package eventsource
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// History satisfies the History TaskRepository interface method. Every Event
+// is one version of its Task.
+func (es *EventSourcedRepository) History(ctx context.Context, id uuid.UUID) ([]entity.HistoryEntry, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	// Once purged, a Task has no owner to show its history to.
+	if _, scoped := task.OwnerFromContext(ctx); scoped {
+		if t, ok := es.state.tasks[id]; !ok || !es.state.can(ctx, t, entity.RoleViewer) {
+			return make([]entity.HistoryEntry, 0), nil
+		}
+	}
+
+	entries := make([]entity.HistoryEntry, len(es.state.history[id]))
+	copy(entries, es.state.history[id])
+	return entries, nil
+}
+
+// Events returns the Events of the log numbered after the given sequence, in
+// order.
+func (es *EventSourcedRepository) Events(ctx context.Context, after uint64) ([]Event, error) {
+	return es.store.Events(ctx, after)
+}
+
+// AsOf satisfies the AsOf HistoricalRepository interface method. The log is
+// replayed up to the given time into a detached, in-memory repository. The
+// replay starts from the state of the previous call, or else from the latest
+// Snapshot, when they are not later than that time.
+func (es *EventSourcedRepository) AsOf(ctx context.Context, at time.Time) (task.TaskRepository, error) {
+	es.Lock()
+	past, pastAt := es.past, es.pastAt
+	es.Unlock()
+
+	if past == nil || pastAt.After(at) {
+		past, pastAt = newProjection(), time.Time{}
+		snapshot, err := es.store.Snapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Every Event of a Snapshot occurred before it was taken.
+		if snapshot != nil && !snapshot.TakenAt.After(at) {
+			past, pastAt = fromSnapshot(snapshot), snapshot.TakenAt
+		}
+	}
+
+	events, err := es.store.Events(ctx, past.sequence)
+	if err != nil {
+		return nil, err
+	}
+	state := past.clone()
+	for _, event := range events {
+		if event.OccurredAt.After(at) {
+			break
+		}
+		state.apply(event)
+		pastAt = event.OccurredAt
+	}
+
+	// The state is kept for the next calls as of the same or a later time,
+	// which only replay the Events which occurred since.
+	es.Lock()
+	es.past, es.pastAt = state.clone(), pastAt
+	es.Unlock()
+	return &EventSourcedRepository{store: NewMemoryStore(), state: state}, nil
+}
@@ -0,0 +1,149 @@
+package eventsource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omaciel/GoDoIt/domain/eventsource"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourcedRepositoryAsOf(t *testing.T) {
+	repo := newRepository(t, eventsource.NewMemoryStore())
+	ctx := context.Background()
+
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, repo.Post(ctx, task0))
+	time.Sleep(time.Millisecond)
+	beforeUpdate := time.Now()
+	time.Sleep(time.Millisecond)
+
+	renamed := *task0
+	renamed.Description = "task 0, renamed"
+	assert.NoError(t, repo.Put(ctx, &renamed))
+	assert.NoError(t, repo.Post(ctx, entity.NewTask("task 1")))
+	assert.NoError(t, repo.Delete(ctx, task0.ID))
+
+	past, err := repo.AsOf(ctx, beforeUpdate)
+	assert.NoError(t, err)
+	tasks, err := past.All(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, "task 0", tasks[0].Description)
+	}
+
+	results, err := past.Search(ctx, entity.SearchQuery{Terms: []entity.SearchTerm{{Words: []string{"renamed"}}}})
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Changing the past does not change the present.
+	assert.NoError(t, past.Delete(ctx, task0.ID))
+	present, err := repo.All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, present, 1)
+	assert.Equal(t, "task 1", present[0].Description)
+
+	beginning, err := repo.AsOf(ctx, task0.CreatedAt.Add(-time.Second))
+	assert.NoError(t, err)
+	tasks, err = beginning.All(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestEventSourcedRepositoryAsOfResumes(t *testing.T) {
+	repo := newRepository(t, eventsource.NewMemoryStore())
+	ctx := context.Background()
+
+	// count returns how many Tasks there were at the given time.
+	count := func(at time.Time) int {
+		past, err := repo.AsOf(ctx, at)
+		assert.NoError(t, err)
+		tasks, err := past.All(ctx)
+		assert.NoError(t, err)
+		return len(tasks)
+	}
+	// post creates a Task, and returns a time after it.
+	post := func(description string) time.Time {
+		task := entity.NewTask(description)
+		assert.NoError(t, repo.Post(ctx, task))
+		time.Sleep(time.Millisecond)
+		after := time.Now()
+		time.Sleep(time.Millisecond)
+		return after
+	}
+
+	first := post("task 0")
+	assert.Equal(t, 1, count(first))
+	second := post("task 1")
+	assert.Equal(t, 2, count(second), "the Events since the previous call are replayed")
+	assert.Equal(t, 1, count(first), "the calls as of an earlier time replay the log again")
+
+	// Changing the past does not change the state the next calls resume from.
+	past, err := repo.AsOf(ctx, second)
+	assert.NoError(t, err)
+	tasks, err := past.All(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, past.Delete(ctx, tasks[0].ID))
+	assert.Equal(t, 2, count(second))
+
+	assert.NoError(t, repo.Snapshot(ctx))
+	third := post("task 2")
+	assert.Equal(t, 3, count(third))
+	assert.Equal(t, 1, count(first))
+	assert.Equal(t, 3, count(third), "the replay starts from the Snapshot")
+}
+
+func TestEventSourcedRepositoryHistory(t *testing.T) {
+	repo := newRepository(t, eventsource.NewMemoryStore())
+	ctx := task.WithActor(context.Background(), "bob")
+
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, repo.Post(ctx, task0))
+
+	err := repo.WithTx(ctx, func(tx task.TaskRepository) error {
+		done := *task0
+		done.Completed = true
+		if err := tx.Put(ctx, &done); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	assert.Error(t, err)
+
+	archived := *task0
+	archived.Completed = true
+	archived.Archived = true
+	assert.NoError(t, repo.Put(ctx, &archived))
+	assert.NoError(t, repo.Delete(ctx, task0.ID))
+	assert.NoError(t, repo.Restore(ctx, task0.ID))
+
+	entries, err := repo.History(ctx, task0.ID)
+	assert.NoError(t, err)
+
+	actions := []entity.HistoryAction{
+		entity.HistoryCreated,
+		entity.HistoryUpdated,
+		entity.HistoryUpdated,
+		entity.HistoryDeleted,
+		entity.HistoryRestored,
+	}
+	if assert.Len(t, entries, len(actions)) {
+		for i, action := range actions {
+			assert.Equal(t, i+1, entries[i].Version)
+			assert.Equal(t, action, entries[i].Action)
+			assert.Equal(t, "bob", entries[i].Actor)
+		}
+		assert.Contains(t, entries[1].Changes, "completed")
+		assert.Contains(t, entries[2].Changes, "archived")
+		assert.Nil(t, entries[3].After)
+	}
+
+	reverted, err := task.Revert(ctx, repo, task0.ID, 1)
+	assert.NoError(t, err)
+	assert.False(t, reverted.Completed)
+	assert.False(t, reverted.Archived)
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd || windows)
+
+package eventsource
+
+import "os"
+
+// lockFile does nothing on the platforms without file locks, where nothing
+// keeps two processes from sharing a directory.
+func lockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,539 @@
+package eventsource
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// projection is the state of every Task after applying the Events of the log
+// up to, and including, sequence.
+type projection struct {
+	sequence uint64
+	tasks    map[uuid.UUID]entity.Task
+	history  map[uuid.UUID][]entity.HistoryEntry
+	users    map[uuid.UUID]entity.User
+	tokens   map[uuid.UUID]entity.APIToken
+
+	// shares gives Users access to the Tasks of others, by Task then User.
+	shares map[uuid.UUID]map[uuid.UUID]entity.Share
+
+	// comments are kept by Task, oldest first, and their edits by Comment.
+	comments     map[uuid.UUID][]entity.Comment
+	commentEdits map[uuid.UUID][]entity.CommentEdit
+
+	// attachments are kept by Task, oldest first. The attachments of the
+	// purged Tasks are kept apart until their contents are deleted.
+	attachments       map[uuid.UUID][]entity.Attachment
+	purgedAttachments []entity.Attachment
+
+	// webhooks receive the changes of their User, and deliveries record what
+	// was sent to them.
+	webhooks   map[uuid.UUID]entity.Webhook
+	deliveries map[uuid.UUID]entity.WebhookDelivery
+
+	// view answers the queries which need every Task. It is built the first
+	// time it is needed after a change.
+	view *memory.MemoryRepository
+}
+
+func newProjection() *projection {
+	return &projection{
+		tasks:   make(map[uuid.UUID]entity.Task),
+		history: make(map[uuid.UUID][]entity.HistoryEntry),
+		users:   make(map[uuid.UUID]entity.User),
+		tokens:  make(map[uuid.UUID]entity.APIToken),
+		shares:  make(map[uuid.UUID]map[uuid.UUID]entity.Share),
+
+		comments:     make(map[uuid.UUID][]entity.Comment),
+		commentEdits: make(map[uuid.UUID][]entity.CommentEdit),
+		attachments:  make(map[uuid.UUID][]entity.Attachment),
+
+		webhooks:   make(map[uuid.UUID]entity.Webhook),
+		deliveries: make(map[uuid.UUID]entity.WebhookDelivery),
+	}
+}
+
+// fromSnapshot returns the projection saved by a Snapshot.
+func fromSnapshot(snapshot *Snapshot) *projection {
+	p := newProjection()
+	p.sequence = snapshot.Sequence
+	for _, task := range snapshot.Tasks {
+		p.tasks[task.ID] = task
+	}
+	for _, entry := range snapshot.History {
+		p.history[entry.TaskID] = append(p.history[entry.TaskID], entry)
+	}
+	for _, user := range snapshot.Users {
+		p.users[user.ID] = user.User()
+	}
+	for id, user := range p.users {
+		if user.WorkspaceRole == "" {
+			user.WorkspaceRole = p.formerRole(user)
+			p.users[id] = user
+		}
+	}
+	for _, token := range snapshot.Tokens {
+		p.tokens[token.ID] = token.APIToken()
+	}
+	for _, share := range snapshot.Shares {
+		p.share(share)
+	}
+	for _, comment := range snapshot.Comments {
+		p.comments[comment.TaskID] = append(p.comments[comment.TaskID], comment)
+	}
+	for _, edit := range snapshot.CommentEdits {
+		p.commentEdits[edit.CommentID] = append(p.commentEdits[edit.CommentID], edit)
+	}
+	for _, attachment := range snapshot.Attachments {
+		if attachment.PurgedAt != nil {
+			p.purgedAttachments = append(p.purgedAttachments, attachment)
+			continue
+		}
+		p.attachments[attachment.TaskID] = append(p.attachments[attachment.TaskID], attachment)
+	}
+	for _, webhook := range snapshot.Webhooks {
+		p.webhooks[webhook.ID] = webhook.Webhook()
+	}
+	for _, delivery := range snapshot.Deliveries {
+		p.deliveries[delivery.ID] = delivery
+	}
+	return p
+}
+
+// snapshot returns a Snapshot of the projection.
+func (p *projection) snapshot() Snapshot {
+	snapshot := Snapshot{
+		Sequence: p.sequence,
+		Tasks:    make([]entity.Task, 0, len(p.tasks)),
+	}
+	for _, task := range p.tasks {
+		snapshot.Tasks = append(snapshot.Tasks, task)
+	}
+	for _, entries := range p.history {
+		snapshot.History = append(snapshot.History, entries...)
+	}
+	for _, user := range p.users {
+		snapshot.Users = append(snapshot.Users, newStoredUser(user))
+	}
+	for _, token := range p.tokens {
+		snapshot.Tokens = append(snapshot.Tokens, newStoredToken(token))
+	}
+	for _, shares := range p.shares {
+		for _, share := range shares {
+			snapshot.Shares = append(snapshot.Shares, share)
+		}
+	}
+	for _, comments := range p.comments {
+		snapshot.Comments = append(snapshot.Comments, comments...)
+	}
+	for _, edits := range p.commentEdits {
+		snapshot.CommentEdits = append(snapshot.CommentEdits, edits...)
+	}
+	for _, attachments := range p.attachments {
+		snapshot.Attachments = append(snapshot.Attachments, attachments...)
+	}
+	snapshot.Attachments = append(snapshot.Attachments, p.purgedAttachments...)
+	for _, webhook := range p.webhooks {
+		snapshot.Webhooks = append(snapshot.Webhooks, newStoredWebhook(webhook))
+	}
+	for _, delivery := range p.deliveries {
+		snapshot.Deliveries = append(snapshot.Deliveries, delivery)
+	}
+	return snapshot
+}
+
+// clone returns a copy of the projection which can be changed independently.
+func (p *projection) clone() *projection {
+	c := newProjection()
+	c.sequence = p.sequence
+	for id, task := range p.tasks {
+		c.tasks[id] = task
+	}
+	for id, entries := range p.history {
+		// Limit the capacity so that appending never writes to p.history.
+		c.history[id] = entries[:len(entries):len(entries)]
+	}
+	for id, user := range p.users {
+		c.users[id] = user
+	}
+	for id, token := range p.tokens {
+		c.tokens[id] = token
+	}
+	for _, shares := range p.shares {
+		for _, share := range shares {
+			c.share(share)
+		}
+	}
+	for id, comments := range p.comments {
+		// Comments are edited in place, so they are copied.
+		c.comments[id] = append([]entity.Comment(nil), comments...)
+	}
+	for id, edits := range p.commentEdits {
+		c.commentEdits[id] = edits[:len(edits):len(edits)]
+	}
+	for id, attachments := range p.attachments {
+		c.attachments[id] = attachments[:len(attachments):len(attachments)]
+	}
+	c.purgedAttachments = p.purgedAttachments[:len(p.purgedAttachments):len(p.purgedAttachments)]
+	for id, webhook := range p.webhooks {
+		c.webhooks[id] = webhook
+	}
+	for id, delivery := range p.deliveries {
+		c.deliveries[id] = delivery
+	}
+	return c
+}
+
+// live returns the Task with the given ID unless it does not exist, is in the
+// trash or cannot be seen with the context.
+func (p *projection) live(ctx context.Context, id uuid.UUID) (entity.Task, bool) {
+	t, ok := p.tasks[id]
+	if !ok || t.IsDeleted() || !p.can(ctx, t, entity.RoleViewer) {
+		return entity.Task{}, false
+	}
+	return t, true
+}
+
+// can reports whether the context has the required Role on the Task, whose
+// assignee is one of its viewers.
+func (p *projection) can(ctx context.Context, t entity.Task, required entity.Role) bool {
+	if !task.InWorkspace(ctx, t) {
+		return false
+	}
+	if task.Owns(ctx, t) {
+		return true
+	}
+	if task.Assigned(ctx, t) && entity.RoleViewer.Allows(required) {
+		return true
+	}
+	user, _ := task.OwnerFromContext(ctx)
+	share, ok := p.shares[t.ID][user]
+	return ok && share.Role.Allows(required)
+}
+
+// share gives a User access to a Task.
+func (p *projection) share(share entity.Share) {
+	if p.shares[share.TaskID] == nil {
+		p.shares[share.TaskID] = make(map[uuid.UUID]entity.Share)
+	}
+	p.shares[share.TaskID][share.UserID] = share
+}
+
+// trashed returns the Task with the given ID if it is in the trash and can be
+// seen with the context.
+func (p *projection) trashed(ctx context.Context, id uuid.UUID) (entity.Task, bool) {
+	t, ok := p.tasks[id]
+	if !ok || !t.IsDeleted() || !task.InWorkspace(ctx, t) || !task.Owns(ctx, t) {
+		return entity.Task{}, false
+	}
+	return t, true
+}
+
+// apply changes the projection with the next Event of the log.
+func (p *projection) apply(event Event) {
+	p.sequence = event.Sequence
+	switch event.Type {
+	case UserRegistered, UserRemoved, TokenCreated, TokenRevoked, TokenUsed:
+		p.applyUserEvent(event)
+		return
+	case WebhookCreated, WebhookDeleted, DeliverySaved:
+		p.applyWebhookEvent(event)
+		return
+	}
+	p.view = nil
+
+	switch event.Type {
+	case TaskShared:
+		share, ok := p.shares[event.TaskID][event.UserID]
+		if !ok {
+			share = entity.Share{TaskID: event.TaskID, UserID: event.UserID, CreatedAt: event.OccurredAt}
+		}
+		share.Role = event.Role
+		p.share(share)
+		return
+	case TaskUnshared:
+		delete(p.shares[event.TaskID], event.UserID)
+		return
+	case CommentAdded, CommentEdited, CommentDeleted:
+		p.applyComment(event)
+		return
+	case ChecklistItemAdded, ChecklistItemEdited, ChecklistItemToggled, ChecklistReordered, ChecklistItemDeleted:
+		p.applyChecklist(event)
+		return
+	case AttachmentAdded:
+		attachment := *event.Attachment
+		attachment.TaskID, attachment.CreatedAt = event.TaskID, event.OccurredAt
+		p.attachments[event.TaskID] = append(p.attachments[event.TaskID], attachment)
+		return
+	case AttachmentDeleted:
+		attachments := p.attachments[event.TaskID]
+		kept := make([]entity.Attachment, 0, len(attachments))
+		for _, attachment := range attachments {
+			if attachment.ID != event.Attachment.ID {
+				kept = append(kept, attachment)
+			}
+		}
+		if len(kept) > 0 {
+			p.attachments[event.TaskID] = kept
+		} else {
+			delete(p.attachments, event.TaskID)
+		}
+		purged := make([]entity.Attachment, 0, len(p.purgedAttachments))
+		for _, attachment := range p.purgedAttachments {
+			if attachment.ID != event.Attachment.ID {
+				purged = append(purged, attachment)
+			}
+		}
+		p.purgedAttachments = purged
+		return
+	}
+
+	before, exists := p.tasks[event.TaskID]
+	if event.Type == TaskPurged {
+		delete(p.tasks, event.TaskID)
+		delete(p.shares, event.TaskID)
+		for _, comment := range p.comments[event.TaskID] {
+			delete(p.commentEdits, comment.ID)
+		}
+		delete(p.comments, event.TaskID)
+		for _, attachment := range p.attachments[event.TaskID] {
+			attachment.PurgedAt = &event.OccurredAt
+			p.purgedAttachments = append(p.purgedAttachments, attachment)
+		}
+		delete(p.attachments, event.TaskID)
+		return
+	}
+
+	at := event.OccurredAt
+	after := before
+	switch event.Type {
+	case TaskCreated:
+		after = entity.Task{
+			ID:          event.TaskID,
+			Description: event.Description,
+			Priority:    event.Priority,
+			OwnerID:     event.OwnerID,
+			WorkspaceID: event.WorkspaceID,
+			CreatedAt:   at,
+
+			CalendarUID:  event.CalendarUID,
+			CalendarName: event.CalendarName,
+		}
+	case TaskDescribed:
+		after.Description = event.Description
+	case TaskPrioritized:
+		after.Priority = event.Priority
+	case TaskScheduled:
+		after.DueAt = event.DueAt
+	case TaskAssigned:
+		assignee := event.UserID
+		after.AssigneeID = &assignee
+	case TaskUnassigned:
+		after.AssigneeID = nil
+	case TaskCompleted:
+		after.Completed = true
+	case TaskReopened:
+		after.Completed = false
+	case TaskArchived:
+		after.Archived = true
+	case TaskUnarchived:
+		after.Archived = false
+	case TaskDeleted:
+		after.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+	case TaskRestored:
+		after.DeletedAt = gorm.DeletedAt{}
+	}
+	after.UpdatedAt = at
+	after.Stamp(at)
+	p.tasks[event.TaskID] = after
+
+	var previous *entity.Task
+	if exists && event.Type != TaskCreated {
+		previous = &before
+	}
+	current := &after
+	if event.Type == TaskDeleted {
+		current = nil
+	}
+
+	entries := p.history[event.TaskID]
+	entry := entity.NewHistoryEntry(event.action(), event.Actor, len(entries)+1, previous, current)
+	// The entry is rebuilt on every replay, so its ID derives from the Event.
+	entry.ID = uuid.NewSHA1(event.TaskID, []byte(strconv.FormatUint(event.Sequence, 10)))
+	entry.CreatedAt = at
+	p.history[event.TaskID] = append(entries, entry)
+}
+
+// applyComment changes the comments of a Task with an Event.
+func (p *projection) applyComment(event Event) {
+	comments := p.comments[event.TaskID]
+	switch event.Type {
+	case CommentAdded:
+		comment := *event.Comment
+		comment.TaskID, comment.CreatedAt = event.TaskID, event.OccurredAt
+		p.comments[event.TaskID] = append(comments, comment)
+	case CommentEdited:
+		for i, comment := range comments {
+			if comment.ID != event.Comment.ID {
+				continue
+			}
+			at := event.OccurredAt
+			p.commentEdits[comment.ID] = append(p.commentEdits[comment.ID], entity.CommentEdit{
+				// The edit is rebuilt on every replay, so its ID derives from
+				// the Event.
+				ID:        uuid.NewSHA1(comment.ID, []byte(strconv.FormatUint(event.Sequence, 10))),
+				CommentID: comment.ID,
+				Body:      comment.Body,
+				EditedAt:  at,
+			})
+			comments[i].Body, comments[i].EditedAt = event.Comment.Body, &at
+		}
+	case CommentDeleted:
+		kept := make([]entity.Comment, 0, len(comments))
+		for _, comment := range comments {
+			if comment.ID != event.Comment.ID {
+				kept = append(kept, comment)
+			}
+		}
+		p.comments[event.TaskID] = kept
+		delete(p.commentEdits, event.Comment.ID)
+	}
+}
+
+// applyChecklist changes the checklist of a Task with an Event. The checklist
+// is copied, as the history keeps the previous one.
+func (p *projection) applyChecklist(event Event) {
+	t, ok := p.tasks[event.TaskID]
+	if !ok {
+		return
+	}
+
+	checklist := make([]entity.ChecklistItem, 0, len(t.Checklist)+1)
+	for _, item := range t.Checklist {
+		switch {
+		case event.Type == ChecklistItemDeleted && item.ID == event.ChecklistItem.ID:
+			continue
+		case event.Type == ChecklistItemEdited && item.ID == event.ChecklistItem.ID:
+			item.Text = event.ChecklistItem.Text
+		case event.Type == ChecklistItemToggled && item.ID == event.ChecklistItem.ID:
+			item.Done = event.ChecklistItem.Done
+		}
+		item.Position = len(checklist)
+		checklist = append(checklist, item)
+	}
+
+	switch event.Type {
+	case ChecklistItemAdded:
+		item := *event.ChecklistItem
+		item.TaskID, item.Position, item.CreatedAt = event.TaskID, len(checklist), event.OccurredAt
+		checklist = append(checklist, item)
+	case ChecklistReordered:
+		if reordered, err := entity.Reorder(checklist, event.Order); err == nil {
+			checklist = reordered
+		}
+	}
+	t.Checklist = checklist
+	p.tasks[event.TaskID] = t
+}
+
+// applyUserEvent changes the Users and their API tokens with an Event.
+func (p *projection) applyUserEvent(event Event) {
+	at := event.OccurredAt
+
+	switch event.Type {
+	case UserRegistered:
+		user := entity.User{
+			ID:            event.UserID,
+			Username:      event.Username,
+			PasswordHash:  event.PasswordHash,
+			CreatedAt:     at,
+			WorkspaceID:   event.WorkspaceID,
+			WorkspaceRole: event.WorkspaceRole,
+		}
+		if user.WorkspaceRole == "" {
+			user.WorkspaceRole = p.formerRole(user)
+		}
+		p.users[user.ID] = user
+	case UserRemoved:
+		delete(p.users, event.UserID)
+		for id, token := range p.tokens {
+			if token.UserID == event.UserID {
+				delete(p.tokens, id)
+			}
+		}
+	case TokenCreated:
+		token := event.Token.APIToken()
+		token.CreatedAt = at
+		p.tokens[token.ID] = token
+	case TokenRevoked:
+		if token, ok := p.tokens[event.Token.ID]; ok {
+			token.RevokedAt = &at
+			p.tokens[token.ID] = token
+		}
+	case TokenUsed:
+		if token, ok := p.tokens[event.Token.ID]; ok {
+			token.LastUsedAt = &at
+			p.tokens[token.ID] = token
+		}
+	}
+}
+
+// formerRole returns the WorkspaceRole of a User registered before there were
+// any: the first User of a workspace registered it, and is its admin.
+func (p *projection) formerRole(user entity.User) entity.WorkspaceRole {
+	for _, other := range p.users {
+		if other.ID != user.ID && other.WorkspaceID == user.WorkspaceID && other.CreatedAt.Before(user.CreatedAt) {
+			return entity.WorkspaceMember
+		}
+	}
+	return entity.WorkspaceAdmin
+}
+
+// applyWebhookEvent changes the webhooks and their deliveries with an Event.
+func (p *projection) applyWebhookEvent(event Event) {
+	switch event.Type {
+	case WebhookCreated:
+		webhook := event.Webhook.Webhook()
+		webhook.CreatedAt = event.OccurredAt
+		p.webhooks[webhook.ID] = webhook
+	case WebhookDeleted:
+		delete(p.webhooks, event.Webhook.ID)
+		for id, delivery := range p.deliveries {
+			if delivery.WebhookID == event.Webhook.ID {
+				delete(p.deliveries, id)
+			}
+		}
+	case DeliverySaved:
+		delivery := *event.Delivery
+		if previous, ok := p.deliveries[delivery.ID]; ok {
+			delivery.CreatedAt = previous.CreatedAt
+		} else {
+			delivery.CreatedAt = event.OccurredAt
+		}
+		delivery.UpdatedAt = event.OccurredAt
+		p.deliveries[delivery.ID] = delivery
+	}
+}
+
+// records returns the view of the projection, building it if needed.
+func (p *projection) records() *memory.MemoryRepository {
+	if p.view == nil {
+		p.view = memory.NewMemoryRepository()
+		for _, task := range p.tasks {
+			p.view.Load(task)
+		}
+		for id, shares := range p.shares {
+			for userID, share := range shares {
+				// Without an owner, the context may share any Task.
+				_ = p.view.Share(context.Background(), id, userID, share.Role)
+			}
+		}
+	}
+	return p.view
+}
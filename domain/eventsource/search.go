@@ -0,0 +1,15 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Search satisfies the Search TaskRepository interface method
+func (es *EventSourcedRepository) Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.state.records().Search(ctx, query)
+}
@@ -0,0 +1,74 @@
+package eventsource
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// owned returns the Task with the given ID if the context owns it, whether it
+// is in the trash or not. The caller must hold the lock.
+func (es *EventSourcedRepository) owned(ctx context.Context, id uuid.UUID) (entity.Task, error) {
+	t, ok := es.state.tasks[id]
+	if !ok || !es.state.can(ctx, t, entity.RoleViewer) {
+		return entity.Task{}, entity.ErrTaskNotFound
+	}
+	if !task.Owns(ctx, t) {
+		return entity.Task{}, entity.ErrForbidden
+	}
+	return t, nil
+}
+
+// Share satisfies the Share TaskRepository interface method
+func (es *EventSourcedRepository) Share(ctx context.Context, id uuid.UUID, userID uuid.UUID, role entity.Role) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if err := role.Validate(); err != nil {
+		return err
+	}
+	t, err := es.owned(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.OwnerID == userID {
+		return entity.ErrInvalidShare
+	}
+	return es.emit(ctx, Event{Type: TaskShared, TaskID: id, UserID: userID, Role: role})
+}
+
+// Unshare satisfies the Unshare TaskRepository interface method
+func (es *EventSourcedRepository) Unshare(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, err := es.owned(ctx, id); err != nil {
+		return err
+	}
+	if _, ok := es.state.shares[id][userID]; !ok {
+		return entity.ErrShareNotFound
+	}
+	return es.emit(ctx, Event{Type: TaskUnshared, TaskID: id, UserID: userID})
+}
+
+// Shares satisfies the Shares TaskRepository interface method
+func (es *EventSourcedRepository) Shares(ctx context.Context, id uuid.UUID) ([]entity.Share, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	shares := make([]entity.Share, 0)
+	if t, ok := es.state.tasks[id]; !ok || !es.state.can(ctx, t, entity.RoleViewer) {
+		return shares, entity.ErrTaskNotFound
+	}
+
+	for _, share := range es.state.shares[id] {
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].CreatedAt.Before(shares[j].CreatedAt)
+	})
+	return shares, nil
+}
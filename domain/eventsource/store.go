@@ -0,0 +1,434 @@
+package eventsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+const (
+	// EventsFile is the name of the event log kept by a FileStore.
+	EventsFile = "events.jsonl"
+
+	// SnapshotFile is the name of the latest snapshot kept by a FileStore.
+	SnapshotFile = "snapshot.json"
+
+	// LockFile is the name of the file a FileStore locks, so that only one
+	// process uses the directory at a time.
+	LockFile = "lock"
+)
+
+// The log and the snapshots hold the hashes of the passwords and of the API
+// tokens, and the secrets of the webhooks, so only their owner may read them.
+const (
+	dirMode  os.FileMode = 0o700
+	fileMode os.FileMode = 0o600
+)
+
+// ErrStoreLocked is returned when the directory of a FileStore is used by
+// another process, or another FileStore.
+var ErrStoreLocked = errors.New("the event store is used by another process")
+
+// Snapshot is the state of every Task after applying the Events of the log up
+// to, and including, Sequence. It spares replaying the whole log on startup.
+type Snapshot struct {
+	Sequence uint64                `json:"sequence"`
+	TakenAt  time.Time             `json:"taken_at"`
+	Tasks    []entity.Task         `json:"tasks"`
+	History  []entity.HistoryEntry `json:"history"`
+	Users    []StoredUser          `json:"users"`
+	Tokens   []StoredToken         `json:"tokens"`
+	Shares   []entity.Share        `json:"shares"`
+
+	Comments     []entity.Comment     `json:"comments"`
+	CommentEdits []entity.CommentEdit `json:"comment_edits"`
+	Attachments  []entity.Attachment  `json:"attachments"`
+
+	Webhooks   []StoredWebhook          `json:"webhooks"`
+	Deliveries []entity.WebhookDelivery `json:"deliveries"`
+}
+
+// StoredUser is a User along with its PasswordHash, which is otherwise
+// never serialized.
+type StoredUser struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+	WorkspaceID  uuid.UUID `json:"workspace_id"`
+
+	WorkspaceRole entity.WorkspaceRole `json:"workspace_role,omitempty"`
+}
+
+func newStoredUser(user entity.User) StoredUser {
+	return StoredUser{
+		ID:           user.ID,
+		Username:     user.Username,
+		PasswordHash: user.PasswordHash,
+		CreatedAt:    user.CreatedAt,
+		WorkspaceID:  user.WorkspaceID,
+
+		WorkspaceRole: user.WorkspaceRole,
+	}
+}
+
+// User returns the stored User.
+func (su StoredUser) User() entity.User {
+	return entity.User{
+		ID:           su.ID,
+		Username:     su.Username,
+		PasswordHash: su.PasswordHash,
+		CreatedAt:    su.CreatedAt,
+		WorkspaceID:  su.WorkspaceID,
+
+		WorkspaceRole: su.WorkspaceRole,
+	}
+}
+
+// StoredWebhook is a Webhook along with its Secret, which is otherwise never
+// serialized.
+type StoredWebhook struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newStoredWebhook(webhook entity.Webhook) StoredWebhook {
+	return StoredWebhook{
+		ID:        webhook.ID,
+		UserID:    webhook.UserID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		Secret:    webhook.Secret,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+// Webhook returns the stored Webhook.
+func (sw StoredWebhook) Webhook() entity.Webhook {
+	return entity.Webhook{
+		ID:        sw.ID,
+		UserID:    sw.UserID,
+		URL:       sw.URL,
+		Events:    sw.Events,
+		Secret:    sw.Secret,
+		CreatedAt: sw.CreatedAt,
+	}
+}
+
+// StoredToken is an APIToken along with its Hash, which is otherwise never
+// serialized.
+type StoredToken struct {
+	ID         uuid.UUID         `json:"id"`
+	UserID     uuid.UUID         `json:"user_id"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"`
+	Hash       string            `json:"hash"`
+	Scope      entity.TokenScope `json:"scope"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ExpiresAt  *time.Time        `json:"expires_at"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	RevokedAt  *time.Time        `json:"revoked_at"`
+}
+
+func newStoredToken(token entity.APIToken) StoredToken {
+	return StoredToken{
+		ID:         token.ID,
+		UserID:     token.UserID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Hash:       token.Hash,
+		Scope:      token.Scope,
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		RevokedAt:  token.RevokedAt,
+	}
+}
+
+// APIToken returns the stored APIToken.
+func (st StoredToken) APIToken() entity.APIToken {
+	return entity.APIToken{
+		ID:         st.ID,
+		UserID:     st.UserID,
+		Name:       st.Name,
+		Prefix:     st.Prefix,
+		Hash:       st.Hash,
+		Scope:      st.Scope,
+		CreatedAt:  st.CreatedAt,
+		ExpiresAt:  st.ExpiresAt,
+		LastUsedAt: st.LastUsedAt,
+		RevokedAt:  st.RevokedAt,
+	}
+}
+
+// Store keeps the append-only log of Events along with its latest Snapshot.
+type Store interface {
+	// Append adds Events to the end of the log, all of them or none.
+	Append(ctx context.Context, events []Event) error
+	// Events returns the Events of the log numbered after the given sequence,
+	// in order.
+	Events(ctx context.Context, after uint64) ([]Event, error)
+
+	// Snapshot returns the latest Snapshot saved, or nil if there is none.
+	Snapshot(ctx context.Context) (*Snapshot, error)
+	SaveSnapshot(ctx context.Context, snapshot Snapshot) error
+}
+
+// MemoryStore is a Store which lives as long as the process.
+type MemoryStore struct {
+	sync.Mutex
+	events   []Event
+	snapshot *Snapshot
+}
+
+// NewMemoryStore creates an empty in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append satisfies the Append Store interface method
+func (ms *MemoryStore) Append(ctx context.Context, events []Event) error {
+	ms.Lock()
+	defer ms.Unlock()
+
+	ms.events = append(ms.events, events...)
+	return nil
+}
+
+// Events satisfies the Events Store interface method
+func (ms *MemoryStore) Events(ctx context.Context, after uint64) ([]Event, error) {
+	ms.Lock()
+	defer ms.Unlock()
+
+	events := make([]Event, 0)
+	for _, event := range ms.events {
+		if event.Sequence > after {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Snapshot satisfies the Snapshot Store interface method
+func (ms *MemoryStore) Snapshot(ctx context.Context) (*Snapshot, error) {
+	ms.Lock()
+	defer ms.Unlock()
+
+	return ms.snapshot, nil
+}
+
+// SaveSnapshot satisfies the SaveSnapshot Store interface method
+func (ms *MemoryStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	ms.Lock()
+	defer ms.Unlock()
+
+	ms.snapshot = &snapshot
+	return nil
+}
+
+// FileStore is a Store keeping the log as one JSON Event per line, next to
+// the latest Snapshot, in a directory.
+type FileStore struct {
+	sync.Mutex
+	Dir string
+
+	lock *os.File
+}
+
+// NewFileStore creates a Store in the given directory, creating it if needed.
+// The directory is locked until the FileStore is closed, and NewFileStore
+// fails with ErrStoreLocked when it already is. An incomplete last line, left
+// by a crash in the middle of an Append, is cut from the log, and the files
+// left readable by others are restricted to their owner.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return nil, err
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, LockFile), os.O_CREATE|os.O_RDWR, fileMode)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	for _, name := range []string{EventsFile, SnapshotFile} {
+		if err := os.Chmod(filepath.Join(dir, name), fileMode); err != nil && !errors.Is(err, os.ErrNotExist) {
+			lock.Close()
+			return nil, err
+		}
+	}
+	if err := truncateTorn(filepath.Join(dir, EventsFile)); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return &FileStore{Dir: dir, lock: lock}, nil
+}
+
+// truncateTorn truncates the log after its last newline, so that an Event
+// whose line was only partly written is dropped rather than failing the
+// replay, and the next Events start on a line of their own.
+func truncateTorn(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	end := info.Size()
+	buf := make([]byte, 4096)
+	for end > 0 {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			end = start + int64(i) + 1
+			break
+		}
+		end = start
+	}
+	if end == info.Size() {
+		return nil
+	}
+
+	log.Printf("Truncating the incomplete last line of %s, %d bytes long.\n", path, info.Size()-end)
+	if err := file.Truncate(end); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Close releases the lock of the directory.
+func (fs *FileStore) Close() error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	return fs.lock.Close()
+}
+
+// Append satisfies the Append Store interface method. The Events are written
+// at once and synced before returning.
+func (fs *FileStore) Append(ctx context.Context, events []Event) error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(filepath.Join(fs.Dir, EventsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Events satisfies the Events Store interface method
+func (fs *FileStore) Events(ctx context.Context, after uint64) ([]Event, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	events := make([]Event, 0)
+	file, err := os.Open(filepath.Join(fs.Dir, EventsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return events, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", EventsFile, line, err)
+		}
+		if event.Sequence > after {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// Snapshot satisfies the Snapshot Store interface method
+func (fs *FileStore) Snapshot(ctx context.Context) (*Snapshot, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(fs.Dir, SnapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("%s: %w", SnapshotFile, err)
+	}
+	return &snapshot, nil
+}
+
+// SaveSnapshot satisfies the SaveSnapshot Store interface method. The
+// Snapshot replaces the previous one atomically.
+func (fs *FileStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	// A temporary file left by a failed save would keep its mode.
+	tmp := filepath.Join(fs.Dir, SnapshotFile+".tmp")
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(tmp, data, fileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(fs.Dir, SnapshotFile))
+}
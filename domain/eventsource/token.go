@@ -0,0 +1,81 @@
+package eventsource
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CreateToken satisfies the CreateToken UserRepository interface method
+func (es *EventSourcedRepository) CreateToken(ctx context.Context, token *entity.APIToken) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	stored := newStoredToken(*token)
+	if err := es.emit(ctx, Event{Type: TokenCreated, Token: &stored}); err != nil {
+		return err
+	}
+	*token = es.state.tokens[token.ID]
+	return nil
+}
+
+// Tokens satisfies the Tokens UserRepository interface method
+func (es *EventSourcedRepository) Tokens(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	tokens := make([]entity.APIToken, 0)
+	for _, token := range es.state.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// TokenByHash satisfies the TokenByHash UserRepository interface method
+func (es *EventSourcedRepository) TokenByHash(ctx context.Context, hash string) (entity.APIToken, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	for _, token := range es.state.tokens {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return entity.APIToken{}, entity.ErrTokenNotFound
+}
+
+// RevokeToken satisfies the RevokeToken UserRepository interface method
+func (es *EventSourcedRepository) RevokeToken(ctx context.Context, userID, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	token, ok := es.state.tokens[id]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return entity.ErrTokenNotFound
+	}
+	return es.emit(ctx, Event{Type: TokenRevoked, Token: &StoredToken{ID: id}})
+}
+
+// TouchToken satisfies the TouchToken UserRepository interface method. The
+// time of the use is the time the Event is appended.
+func (es *EventSourcedRepository) TouchToken(ctx context.Context, id uuid.UUID, at time.Time) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, ok := es.state.tokens[id]; !ok {
+		return entity.ErrTokenNotFound
+	}
+	return es.emit(ctx, Event{Type: TokenUsed, Token: &StoredToken{ID: id}})
+}
@@ -0,0 +1,58 @@
+package eventsource
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Trash satisfies the Trash TaskRepository interface method
+func (es *EventSourcedRepository) Trash(ctx context.Context) ([]entity.Task, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.state.records().Trash(ctx)
+}
+
+// Restore satisfies the Restore TaskRepository interface method
+func (es *EventSourcedRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, ok := es.state.trashed(ctx, id); !ok {
+		return entity.ErrTaskNotFound
+	}
+	return es.emit(ctx, Event{Type: TaskRestored, TaskID: id})
+}
+
+// Purge satisfies the Purge TaskRepository interface method. The Task is gone
+// from the projection, but the Events which built it stay in the log.
+func (es *EventSourcedRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, ok := es.state.trashed(ctx, id); !ok {
+		return entity.ErrTaskNotFound
+	}
+	return es.emit(ctx, Event{Type: TaskPurged, TaskID: id})
+}
+
+// PurgeDeletedBefore satisfies the PurgeDeletedBefore TaskRepository interface method
+func (es *EventSourcedRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	var events []Event
+	for id, value := range es.state.tasks {
+		if value.IsDeleted() && value.DeletedAt.Time.Before(before) && task.InWorkspace(ctx, value) && task.Owns(ctx, value) {
+			events = append(events, Event{Type: TaskPurged, TaskID: id})
+		}
+	}
+	if err := es.emit(ctx, events...); err != nil {
+		return 0, err
+	}
+	return int64(len(events)), nil
+}
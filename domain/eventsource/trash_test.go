@@ -0,0 +1,47 @@
+package eventsource_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/eventsource"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourcedRepositoryTrash(t *testing.T) {
+	repo := newRepository(t, eventsource.NewMemoryStore())
+	ctx := context.Background()
+
+	task0 := entity.NewTask("task 0")
+	task1 := entity.NewTask("task 1")
+	task2 := entity.NewTask("task 2")
+	assert.NoError(t, repo.PostMany(ctx, []*entity.Task{task0, task1, task2}))
+	assert.ErrorIs(t, repo.DeleteMany(ctx, []uuid.UUID{task0.ID, uuid.New()}), entity.ErrTaskNotFound)
+	assert.NoError(t, repo.DeleteMany(ctx, []uuid.UUID{task0.ID, task1.ID}))
+
+	trash, err := repo.Trash(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, trash, 2)
+
+	assert.ErrorIs(t, repo.Restore(ctx, task2.ID), entity.ErrTaskNotFound)
+	assert.NoError(t, repo.Restore(ctx, task0.ID))
+	_, err = repo.Get(ctx, task0.ID)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, repo.Purge(ctx, task2.ID), entity.ErrTaskNotFound)
+	purged, err := repo.PurgeDeletedBefore(ctx, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	trash, err = repo.Trash(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+
+	// A purged Task is gone for good, but the Events which built it remain.
+	assert.ErrorIs(t, repo.Restore(ctx, task1.ID), entity.ErrTaskNotFound)
+	types := eventTypes(t, repo)
+	assert.Equal(t, eventsource.TaskPurged, types[len(types)-1])
+}
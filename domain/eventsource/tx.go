@@ -0,0 +1,27 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+)
+
+// WithTx satisfies the WithTx TaskRepository interface method. fn works on a
+// copy of the Tasks and its Events are only appended to the log when it
+// succeeds. The repository stays locked until fn returns, so fn must only use
+// the repository it is given.
+func (es *EventSourcedRepository) WithTx(ctx context.Context, fn func(repo task.TaskRepository) error) error {
+	es.Lock()
+	defer es.Unlock()
+
+	draft := NewMemoryStore()
+	tx := &EventSourcedRepository{store: draft, state: es.state.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if len(draft.events) == 0 {
+		return nil
+	}
+	return es.commit(ctx, draft.events)
+}
@@ -0,0 +1,73 @@
+package eventsource
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CreateUser satisfies the CreateUser UserRepository interface method
+func (es *EventSourcedRepository) CreateUser(ctx context.Context, user *entity.User) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	for _, existing := range es.state.users {
+		if existing.ID == user.ID || existing.Username == user.Username {
+			return entity.ErrUsernameTaken
+		}
+	}
+
+	err := es.emit(ctx, Event{
+		Type:         UserRegistered,
+		UserID:       user.ID,
+		Username:     user.Username,
+		PasswordHash: user.PasswordHash,
+		WorkspaceID:  user.WorkspaceID,
+
+		WorkspaceRole: user.WorkspaceRole,
+	})
+	if err != nil {
+		return err
+	}
+	*user = es.state.users[user.ID]
+	return nil
+}
+
+// GetUser satisfies the GetUser UserRepository interface method
+func (es *EventSourcedRepository) GetUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	if user, ok := es.state.users[id]; ok {
+		return user, nil
+	}
+	return entity.User{}, entity.ErrUserNotFound
+}
+
+// UserByName satisfies the UserByName UserRepository interface method
+func (es *EventSourcedRepository) UserByName(ctx context.Context, username string) (entity.User, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	for _, user := range es.state.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return entity.User{}, entity.ErrUserNotFound
+}
+
+// RemoveUser satisfies the RemoveUser UserRepository interface method
+func (es *EventSourcedRepository) RemoveUser(ctx context.Context, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, ok := es.state.users[id]; !ok {
+		return entity.ErrUserNotFound
+	}
+	return es.emit(ctx, Event{Type: UserRemoved, UserID: id})
+}
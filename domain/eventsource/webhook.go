@@ -0,0 +1,154 @@
+package eventsource
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CreateWebhook satisfies the CreateWebhook WebhookRepository interface method
+func (es *EventSourcedRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+	stored := newStoredWebhook(*webhook)
+	if err := es.emit(ctx, Event{Type: WebhookCreated, Webhook: &stored}); err != nil {
+		return err
+	}
+	*webhook = es.state.webhooks[webhook.ID]
+	return nil
+}
+
+// Webhooks satisfies the Webhooks WebhookRepository interface method
+func (es *EventSourcedRepository) Webhooks(ctx context.Context, userID uuid.UUID) ([]entity.Webhook, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	webhooks := make([]entity.Webhook, 0)
+	for _, webhook := range es.state.webhooks {
+		if webhook.UserID == userID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+// GetWebhook satisfies the GetWebhook WebhookRepository interface method
+func (es *EventSourcedRepository) GetWebhook(ctx context.Context, userID, id uuid.UUID) (entity.Webhook, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	webhook, ok := es.state.webhooks[id]
+	if !ok || webhook.UserID != userID {
+		return entity.Webhook{}, entity.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook satisfies the DeleteWebhook WebhookRepository interface method
+func (es *EventSourcedRepository) DeleteWebhook(ctx context.Context, userID, id uuid.UUID) error {
+	es.Lock()
+	defer es.Unlock()
+
+	webhook, ok := es.state.webhooks[id]
+	if !ok || webhook.UserID != userID {
+		return entity.ErrWebhookNotFound
+	}
+	return es.emit(ctx, Event{Type: WebhookDeleted, Webhook: &StoredWebhook{ID: id}})
+}
+
+// SaveDelivery satisfies the SaveDelivery WebhookRepository interface method.
+// The times of the delivery are the times its Events are appended.
+func (es *EventSourcedRepository) SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, ok := es.state.webhooks[delivery.WebhookID]; !ok {
+		return entity.ErrWebhookNotFound
+	}
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+	saved := *delivery
+	if err := es.emit(ctx, Event{Type: DeliverySaved, Delivery: &saved}); err != nil {
+		return err
+	}
+	*delivery = es.state.deliveries[delivery.ID]
+	return nil
+}
+
+// GetDelivery satisfies the GetDelivery WebhookRepository interface method
+func (es *EventSourcedRepository) GetDelivery(ctx context.Context, webhookID, id uuid.UUID) (entity.WebhookDelivery, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	delivery, ok := es.state.deliveries[id]
+	if !ok || delivery.WebhookID != webhookID {
+		return entity.WebhookDelivery{}, entity.ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// deliveriesWhere returns the deliveries selected by keep, in the given order.
+// The caller must hold the lock.
+func (es *EventSourcedRepository) deliveriesWhere(keep func(entity.WebhookDelivery) bool, less func(a, b entity.WebhookDelivery) bool) []entity.WebhookDelivery {
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for _, delivery := range es.state.deliveries {
+		if keep(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return less(deliveries[i], deliveries[j])
+	})
+	return deliveries
+}
+
+// newestFirst orders the deliveries from the latest created.
+func newestFirst(a, b entity.WebhookDelivery) bool {
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// Deliveries satisfies the Deliveries WebhookRepository interface method
+func (es *EventSourcedRepository) Deliveries(ctx context.Context, webhookID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.deliveriesWhere(func(delivery entity.WebhookDelivery) bool {
+		return delivery.WebhookID == webhookID
+	}, newestFirst), nil
+}
+
+// DueDeliveries satisfies the DueDeliveries WebhookRepository interface method
+func (es *EventSourcedRepository) DueDeliveries(ctx context.Context, at time.Time) ([]entity.WebhookDelivery, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.deliveriesWhere(func(delivery entity.WebhookDelivery) bool {
+		return delivery.Status == entity.DeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(at)
+	}, func(a, b entity.WebhookDelivery) bool {
+		return a.NextAttemptAt.Before(*b.NextAttemptAt)
+	}), nil
+}
+
+// DeadDeliveries satisfies the DeadDeliveries WebhookRepository interface
+// method
+func (es *EventSourcedRepository) DeadDeliveries(ctx context.Context, userID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	es.Lock()
+	defer es.Unlock()
+
+	return es.deliveriesWhere(func(delivery entity.WebhookDelivery) bool {
+		return delivery.UserID == userID && delivery.Status == entity.DeliveryDead
+	}, newestFirst), nil
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Find satisfies the Find TaskRepository interface method
+func (mr *MemoryRepository) Find(ctx context.Context, filter task.Filter) ([]entity.Task, int64, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	values := make([]entity.Task, 0)
+	mr.each(ctx, func(value entity.Task) {
+		if value.IsDeleted() || value.Archived != filter.Archived || !filter.MatchesAssignee(value) ||
+			!mr.can(ctx, value, entity.RoleViewer) {
+			return
+		}
+		values = append(values, value)
+	})
+
+	sort.Slice(values, func(i, j int) bool {
+		a, b := values[i], values[j]
+		if filter.Archived && a.ArchivedAt != nil && b.ArchivedAt != nil && !a.ArchivedAt.Equal(*b.ArchivedAt) {
+			return a.ArchivedAt.After(*b.ArchivedAt)
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	total := int64(len(values))
+	if filter.Offset >= len(values) {
+		return values[:0], total, nil
+	}
+	values = values[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(values) {
+		values = values[:filter.Limit]
+	}
+	return values, total, nil
+}
+
+// ArchiveCompleted satisfies the ArchiveCompleted TaskRepository interface method
+func (mr *MemoryRepository) ArchiveCompleted(ctx context.Context, before time.Time) (int64, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	var completed []entity.Task
+	mr.each(ctx, func(value entity.Task) {
+		if value.IsDeleted() || value.Archived || !value.Completed || !task.Owns(ctx, value) {
+			return
+		}
+		if value.CompletedAt != nil && !value.CompletedAt.Before(before) {
+			return
+		}
+		completed = append(completed, value)
+	})
+
+	now := time.Now()
+	for _, value := range completed {
+		before := value
+		value.Archived = true
+		touch(&value, now)
+		mr.store(value)
+		mr.record(ctx, entity.HistoryUpdated, &before, &value)
+	}
+	return int64(len(completed)), nil
+}
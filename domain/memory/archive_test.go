@@ -0,0 +1,113 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryStampsTasks(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, mr.Post(context.Background(), task0))
+	assert.False(t, task0.CreatedAt.IsZero())
+	assert.Nil(t, task0.CompletedAt)
+
+	created := task0.CreatedAt
+	task0.CreatedAt = time.Time{}
+	task0.Completed = true
+	assert.NoError(t, mr.Put(context.Background(), task0))
+	assert.Equal(t, created, task0.CreatedAt, "the creation time should not change")
+	assert.NotNil(t, task0.CompletedAt)
+
+	task0.Completed = false
+	assert.NoError(t, mr.Put(context.Background(), task0))
+	assert.Nil(t, task0.CompletedAt)
+}
+
+func TestMemoryRepositoryFind(t *testing.T) {
+	now := time.Now()
+	records := make(map[uuid.UUID]entity.Task)
+	var active, archived []uuid.UUID
+	for i := 0; i < 5; i++ {
+		task := *entity.NewTask("active")
+		task.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		records[task.ID] = task
+		active = append(active, task.ID)
+
+		task = *entity.NewTask("archived")
+		task.Archived = true
+		archivedAt := now.Add(-time.Duration(i) * time.Minute)
+		task.ArchivedAt = &archivedAt
+		records[task.ID] = task
+		archived = append(archived, task.ID)
+	}
+	deleted := *entity.NewTask("deleted")
+	deleted.DeletedAt.Time, deleted.DeletedAt.Valid = now, true
+	records[deleted.ID] = deleted
+
+	mr := memory.MemoryRepository{Records: records}
+
+	ids := func(tasks []entity.Task) []uuid.UUID {
+		found := make([]uuid.UUID, 0, len(tasks))
+		for _, task := range tasks {
+			found = append(found, task.ID)
+		}
+		return found
+	}
+
+	tests := []struct {
+		name     string
+		filter   task.Filter
+		expected []uuid.UUID
+	}{
+		{"Active tasks, oldest first", task.Filter{}, active},
+		{"Archived tasks, most recently archived first", task.Filter{Archived: true}, archived},
+		{"First page", task.Filter{Limit: 2}, active[:2]},
+		{"Last page", task.Filter{Archived: true, Limit: 2, Offset: 4}, archived[4:]},
+		{"Past the last page", task.Filter{Limit: 2, Offset: 10}, []uuid.UUID{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, total, err := mr.Find(context.Background(), tt.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(5), total)
+			assert.Equal(t, tt.expected, ids(tasks))
+		})
+	}
+}
+
+func TestMemoryRepositoryArchiveCompleted(t *testing.T) {
+	now := time.Now()
+	longAgo := now.Add(-48 * time.Hour)
+
+	old := *entity.NewTask("completed long ago").WithCompleted(true)
+	old.CompletedAt = &longAgo
+	recent := *entity.NewTask("completed recently").WithCompleted(true)
+	recent.CompletedAt = &now
+	open := *entity.NewTask("not completed")
+
+	mr := memory.MemoryRepository{
+		Records: map[uuid.UUID]entity.Task{
+			old.ID:    old,
+			recent.ID: recent,
+			open.ID:   open,
+		},
+	}
+
+	archived, err := mr.ArchiveCompleted(context.Background(), now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+	assert.True(t, mr.Records[old.ID].Archived)
+	assert.NotNil(t, mr.Records[old.ID].ArchivedAt)
+	assert.True(t, mr.Records[old.ID].Completed, "archiving should not change completion")
+
+	tasks, _ := mr.All(context.Background())
+	assert.Len(t, tasks, 2)
+}
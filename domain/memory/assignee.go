@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// assign sets the assignee of a Task which the context may edit. The caller
+// must hold the lock.
+func (mr *MemoryRepository) assign(ctx context.Context, id uuid.UUID, assignee *uuid.UUID) error {
+	t, ok := mr.live(ctx, id)
+	if !ok {
+		return entity.ErrTaskNotFound
+	}
+	if !mr.can(ctx, t, entity.RoleEditor) {
+		return entity.ErrForbidden
+	}
+	if assignee != nil {
+		if user, ok := mr.users[*assignee]; !ok || user.WorkspaceID != t.WorkspaceID {
+			return entity.ErrInvalidAssignee
+		}
+	}
+
+	before := t
+	t.AssigneeID = assignee
+	touch(&t, time.Now())
+	mr.store(t)
+	mr.record(ctx, entity.HistoryUpdated, &before, &t)
+	return nil
+}
+
+// Assign satisfies the Assign TaskRepository interface method
+func (mr *MemoryRepository) Assign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	return mr.assign(ctx, id, &userID)
+}
+
+// Unassign satisfies the Unassign TaskRepository interface method
+func (mr *MemoryRepository) Unassign(ctx context.Context, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	return mr.assign(ctx, id, nil)
+}
@@ -0,0 +1,65 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryAssignees(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	owner, _ := entity.NewUser("assigner", "correct horse")
+	assignee, _ := entity.NewUser("assignee", "correct horse")
+	assignee.WorkspaceID = owner.WorkspaceID
+	outsider, _ := entity.NewUser("outsider", "correct horse")
+	for _, user := range []*entity.User{owner, assignee, outsider} {
+		assert.NoError(t, mr.CreateUser(context.Background(), user))
+	}
+	ctx := task.WithWorkspace(task.WithOwner(context.Background(), owner.ID), owner.WorkspaceID)
+
+	assigned, unassigned := entity.NewTask("Assigned"), entity.NewTask("Unassigned")
+	assert.NoError(t, mr.Post(ctx, assigned))
+	assert.NoError(t, mr.Post(ctx, unassigned))
+
+	assert.ErrorIs(t, mr.Assign(ctx, assigned.ID, outsider.ID), entity.ErrInvalidAssignee)
+	assert.ErrorIs(t, mr.Assign(ctx, assigned.ID, uuid.New()), entity.ErrInvalidAssignee)
+	assert.ErrorIs(t, mr.Assign(ctx, uuid.New(), assignee.ID), entity.ErrTaskNotFound)
+	assert.NoError(t, mr.Assign(ctx, assigned.ID, assignee.ID))
+
+	tasks, _, err := mr.Find(ctx, task.Filter{AssignedTo: assignee.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, assigned.ID, tasks[0].ID)
+	}
+
+	tasks, _, err = mr.Find(ctx, task.Filter{Unassigned: true})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, unassigned.ID, tasks[0].ID)
+	}
+
+	// Put leaves the assignee alone.
+	update := *assigned
+	update.Description = "Still assigned"
+	assert.NoError(t, mr.Put(ctx, &update))
+	found, err := mr.Get(ctx, assigned.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found.AssigneeID) {
+		assert.Equal(t, assignee.ID, *found.AssigneeID)
+	}
+
+	assert.NoError(t, mr.Unassign(ctx, assigned.ID))
+	tasks, _, err = mr.Find(ctx, task.Filter{Unassigned: true})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	entries, err := mr.History(ctx, assigned.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// attachment returns the position of an Attachment to a Task which the
+// context may see. The caller must hold the lock.
+func (mr *MemoryRepository) attachment(ctx context.Context, taskID, id uuid.UUID) (int, error) {
+	if _, ok := mr.live(ctx, taskID); !ok {
+		return 0, entity.ErrTaskNotFound
+	}
+	for i, attachment := range mr.attachments.value(taskID) {
+		if attachment.ID == id {
+			return i, nil
+		}
+	}
+	return 0, entity.ErrAttachmentNotFound
+}
+
+// AddAttachment satisfies the AddAttachment AttachmentRepository interface method
+func (mr *MemoryRepository) AddAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if err := attachment.Validate(); err != nil {
+		return err
+	}
+	t, ok := mr.live(ctx, attachment.TaskID)
+	if !ok {
+		return entity.ErrTaskNotFound
+	}
+	if !mr.can(ctx, t, entity.RoleEditor) {
+		return entity.ErrForbidden
+	}
+
+	if attachment.ID == uuid.Nil {
+		attachment.ID = uuid.New()
+	}
+	attachment.UploaderID, _ = task.OwnerFromContext(ctx)
+	attachment.CreatedAt = time.Now()
+
+	attachments := mr.attachments.value(attachment.TaskID)
+	mr.attachments.set(attachment.TaskID, append(attachments[:len(attachments):len(attachments)], *attachment))
+	return nil
+}
+
+// GetAttachment satisfies the GetAttachment AttachmentRepository interface method
+func (mr *MemoryRepository) GetAttachment(ctx context.Context, taskID, id uuid.UUID) (entity.Attachment, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	i, err := mr.attachment(ctx, taskID, id)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+	return mr.attachments.value(taskID)[i], nil
+}
+
+// Attachments satisfies the Attachments AttachmentRepository interface method
+func (mr *MemoryRepository) Attachments(ctx context.Context, taskID uuid.UUID) ([]entity.Attachment, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	attachments := make([]entity.Attachment, 0)
+	if _, ok := mr.live(ctx, taskID); !ok {
+		return attachments, entity.ErrTaskNotFound
+	}
+	return append(attachments, mr.attachments.value(taskID)...), nil
+}
+
+// DeleteAttachment satisfies the DeleteAttachment AttachmentRepository interface method
+func (mr *MemoryRepository) DeleteAttachment(ctx context.Context, taskID, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	i, err := mr.attachment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	if t, _ := mr.live(ctx, taskID); !mr.can(ctx, t, entity.RoleEditor) {
+		return entity.ErrForbidden
+	}
+
+	attachments := mr.attachments.value(taskID)
+	mr.attachments.set(taskID, append(attachments[:i:i], attachments[i+1:]...))
+	return nil
+}
+
+// PurgedAttachments satisfies the PurgedAttachments AttachmentRepository
+// interface method
+func (mr *MemoryRepository) PurgedAttachments(ctx context.Context) ([]entity.Attachment, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	attachments := make([]entity.Attachment, 0)
+	return append(attachments, mr.purgedAttachments...), nil
+}
+
+// ForgetAttachment satisfies the ForgetAttachment AttachmentRepository
+// interface method
+func (mr *MemoryRepository) ForgetAttachment(ctx context.Context, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	for i, attachment := range mr.purgedAttachments {
+		if attachment.ID == id {
+			mr.purgedAttachments = append(mr.purgedAttachments[:i:i], mr.purgedAttachments[i+1:]...)
+			return nil
+		}
+	}
+	return entity.ErrAttachmentNotFound
+}
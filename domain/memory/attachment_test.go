@@ -0,0 +1,74 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryAttachments(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	editorID, viewerID := uuid.New(), uuid.New()
+	owner := task.WithOwner(context.Background(), uuid.New())
+	editor := task.WithOwner(context.Background(), editorID)
+	viewer := task.WithOwner(context.Background(), viewerID)
+
+	documented := entity.NewTask("Documented")
+	assert.NoError(t, mr.Post(owner, documented))
+	assert.NoError(t, mr.Share(owner, documented.ID, editorID, entity.RoleEditor))
+	assert.NoError(t, mr.Share(owner, documented.ID, viewerID, entity.RoleViewer))
+
+	screenshot, err := entity.NewAttachment(documented.ID, "C:\\Users\\me\\screenshot.png", "image/png", 2048)
+	assert.NoError(t, err)
+	assert.Equal(t, "screenshot.png", screenshot.Filename, "only the base name is kept")
+
+	assert.ErrorIs(t, mr.AddAttachment(viewer, screenshot), entity.ErrForbidden)
+	assert.NoError(t, mr.AddAttachment(editor, screenshot))
+	assert.Equal(t, editorID, screenshot.UploaderID)
+	assert.ErrorIs(t, mr.AddAttachment(owner, &entity.Attachment{TaskID: documented.ID, Filename: "huge.iso", Size: entity.MaxAttachmentSize + 1}), entity.ErrAttachmentTooLarge)
+
+	attachments, err := mr.Attachments(viewer, documented.ID)
+	assert.NoError(t, err)
+	assert.Len(t, attachments, 1)
+
+	found, err := mr.GetAttachment(viewer, documented.ID, screenshot.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", found.ContentType)
+	_, err = mr.GetAttachment(viewer, documented.ID, uuid.New())
+	assert.ErrorIs(t, err, entity.ErrAttachmentNotFound)
+
+	assert.ErrorIs(t, mr.DeleteAttachment(viewer, documented.ID, screenshot.ID), entity.ErrForbidden)
+	assert.NoError(t, mr.DeleteAttachment(editor, documented.ID, screenshot.ID))
+	_, err = mr.GetAttachment(owner, documented.ID, screenshot.ID)
+	assert.ErrorIs(t, err, entity.ErrAttachmentNotFound)
+
+	// Purging the Task hides its attachments until their contents are deleted.
+	notes, _ := entity.NewAttachment(documented.ID, "notes.txt", "text/plain; charset=utf-8", 12)
+	assert.NoError(t, mr.AddAttachment(owner, notes))
+	assert.NoError(t, mr.Delete(owner, documented.ID))
+	assert.NoError(t, mr.Purge(owner, documented.ID))
+	assert.NoError(t, mr.Post(owner, documented))
+	attachments, err = mr.Attachments(owner, documented.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, attachments)
+	_, err = mr.GetAttachment(owner, documented.ID, notes.ID)
+	assert.ErrorIs(t, err, entity.ErrAttachmentNotFound)
+
+	purged, err := mr.PurgedAttachments(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, purged, 1) {
+		assert.Equal(t, notes.ID, purged[0].ID)
+		assert.NotNil(t, purged[0].PurgedAt)
+	}
+	assert.NoError(t, mr.ForgetAttachment(context.Background(), notes.ID))
+	assert.ErrorIs(t, mr.ForgetAttachment(context.Background(), notes.ID), entity.ErrAttachmentNotFound)
+	purged, err = mr.PurgedAttachments(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, purged)
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// PostMany satisfies the PostMany TaskRepository interface method. No Task is
+// added unless all of them can be.
+func (mr *MemoryRepository) PostMany(ctx context.Context, tasks []*entity.Task) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	seen := make(map[uuid.UUID]bool, len(tasks))
+	for _, t := range tasks {
+		if _, ok := mr.lookup(context.Background(), t.ID); ok || seen[t.ID] {
+			return entity.ErrTaskUniqueConstraint
+		}
+		seen[t.ID] = true
+	}
+
+	now := time.Now()
+	for _, t := range tasks {
+		own(ctx, t)
+		touch(t, now)
+		mr.store(*t)
+		mr.record(ctx, entity.HistoryCreated, nil, t)
+		if mr.index != nil {
+			mr.index.add(*t)
+		}
+	}
+	return nil
+}
+
+// DeleteMany satisfies the DeleteMany TaskRepository interface method. No Task
+// is moved to the trash unless all of them exist, and the duplicate IDs are
+// moved once.
+func (mr *MemoryRepository) DeleteMany(ctx context.Context, ids []uuid.UUID) error {
+	ids = task.Distinct(ids)
+	mr.Lock()
+	defer mr.Unlock()
+
+	for _, id := range ids {
+		task, ok := mr.live(ctx, id)
+		if !ok {
+			return entity.ErrTaskNotFound
+		}
+		if !mr.can(ctx, task, entity.RoleOwner) {
+			return entity.ErrForbidden
+		}
+	}
+
+	for _, id := range ids {
+		before, _ := mr.lookup(ctx, id)
+		mr.store(moveToTrash(before))
+		mr.record(ctx, entity.HistoryDeleted, &before, nil)
+		if mr.index != nil {
+			mr.index.remove(id)
+		}
+	}
+	return nil
+}
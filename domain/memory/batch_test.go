@@ -0,0 +1,101 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryPostMany(t *testing.T) {
+	task0 := entity.NewTask("task 0")
+
+	tests := []struct {
+		name         string
+		tasks        []*entity.Task
+		wantErr      error
+		expectedSize int
+	}{
+		{"Add new tasks", []*entity.Task{entity.NewTask("task 1"), entity.NewTask("task 2")}, nil, 3},
+		{"Add an existing task", []*entity.Task{entity.NewTask("task 1"), task0}, entity.ErrTaskUniqueConstraint, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := memory.NewMemoryRepository()
+			assert.NoError(t, mr.Post(context.Background(), task0))
+
+			err := mr.PostMany(context.Background(), tt.tasks)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Len(t, mr.Records, tt.expectedSize)
+		})
+	}
+
+	t.Run("Add duplicated tasks", func(t *testing.T) {
+		mr := memory.NewMemoryRepository()
+		err := mr.PostMany(context.Background(), []*entity.Task{task0, task0})
+		assert.ErrorIs(t, err, entity.ErrTaskUniqueConstraint)
+		assert.Len(t, mr.Records, 0)
+	})
+}
+
+func TestMemoryRepositoryDeleteMany(t *testing.T) {
+	task0 := entity.NewTask("task 0")
+	task1 := entity.NewTask("task 1")
+
+	tests := []struct {
+		name         string
+		ids          []uuid.UUID
+		wantErr      error
+		expectedSize int
+	}{
+		{"Delete existing tasks", []uuid.UUID{task0.ID, task1.ID}, nil, 0},
+		{"Delete a missing task", []uuid.UUID{task0.ID, uuid.New()}, entity.ErrTaskNotFound, 2},
+		{"Delete duplicate tasks", []uuid.UUID{task0.ID, task1.ID, task0.ID}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := memory.NewMemoryRepository()
+			assert.NoError(t, mr.PostMany(context.Background(), []*entity.Task{task0, task1}))
+
+			err := mr.DeleteMany(context.Background(), tt.ids)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			tasks, _ := mr.All(context.Background())
+			assert.Len(t, tasks, tt.expectedSize)
+		})
+	}
+}
+
+func TestMemoryRepositoryBatch(t *testing.T) {
+	task0 := entity.NewTask("task 0")
+	task1 := entity.NewTask("task 1")
+	mr := memory.NewMemoryRepository()
+	assert.NoError(t, mr.Post(context.Background(), task0))
+
+	updated := *task0
+	updated.Description = "task 0, updated"
+
+	err := task.Batch(context.Background(), mr, []task.Operation{
+		{Action: task.ActionCreate, Task: task1},
+		{Action: task.ActionUpdate, Task: &updated},
+		{Action: task.ActionDelete, ID: uuid.New()},
+	})
+	var opErr *task.OperationError
+	assert.ErrorAs(t, err, &opErr)
+	assert.Equal(t, 2, opErr.Index)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	assert.Len(t, mr.Records, 1)
+	assert.Equal(t, "task 0", mr.Records[task0.ID].Description)
+
+	err = task.Batch(context.Background(), mr, []task.Operation{
+		{Action: task.ActionCreate, Task: task1},
+		{Action: task.ActionUpdate, Task: &updated},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, mr.Records, 2)
+	assert.Equal(t, "task 0, updated", mr.Records[task0.ID].Description)
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// editable returns the Task with the given ID if the context may edit it. The
+// caller must hold the lock.
+func (mr *MemoryRepository) editable(ctx context.Context, id uuid.UUID) (entity.Task, error) {
+	t, ok := mr.live(ctx, id)
+	if !ok {
+		return entity.Task{}, entity.ErrTaskNotFound
+	}
+	if !mr.can(ctx, t, entity.RoleEditor) {
+		return entity.Task{}, entity.ErrForbidden
+	}
+	return t, nil
+}
+
+// changeChecklistItem changes an item of the checklist of a Task which the
+// context may edit. The checklist is copied, as the history keeps the previous
+// one. The caller must hold the lock.
+func (mr *MemoryRepository) changeChecklistItem(ctx context.Context, taskID, id uuid.UUID, change func(item *entity.ChecklistItem)) (entity.ChecklistItem, error) {
+	t, err := mr.editable(ctx, taskID)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+	i, err := t.ChecklistItem(id)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	t.Checklist = append([]entity.ChecklistItem(nil), t.Checklist...)
+	change(&t.Checklist[i])
+	mr.store(t)
+	return t.Checklist[i], nil
+}
+
+// AddChecklistItem satisfies the AddChecklistItem TaskRepository interface method
+func (mr *MemoryRepository) AddChecklistItem(ctx context.Context, item *entity.ChecklistItem) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if err := entity.ValidateChecklistText(item.Text); err != nil {
+		return err
+	}
+	t, err := mr.editable(ctx, item.TaskID)
+	if err != nil {
+		return err
+	}
+
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	item.Position = len(t.Checklist)
+	item.CreatedAt = time.Now()
+
+	t.Checklist = append(t.Checklist[:len(t.Checklist):len(t.Checklist)], *item)
+	mr.store(t)
+	return nil
+}
+
+// EditChecklistItem satisfies the EditChecklistItem TaskRepository interface method
+func (mr *MemoryRepository) EditChecklistItem(ctx context.Context, taskID, id uuid.UUID, text string) (entity.ChecklistItem, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if err := entity.ValidateChecklistText(text); err != nil {
+		return entity.ChecklistItem{}, err
+	}
+	return mr.changeChecklistItem(ctx, taskID, id, func(item *entity.ChecklistItem) {
+		item.Text = text
+	})
+}
+
+// ToggleChecklistItem satisfies the ToggleChecklistItem TaskRepository interface method
+func (mr *MemoryRepository) ToggleChecklistItem(ctx context.Context, taskID, id uuid.UUID) (entity.ChecklistItem, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	return mr.changeChecklistItem(ctx, taskID, id, func(item *entity.ChecklistItem) {
+		item.Done = !item.Done
+	})
+}
+
+// ReorderChecklist satisfies the ReorderChecklist TaskRepository interface method
+func (mr *MemoryRepository) ReorderChecklist(ctx context.Context, taskID uuid.UUID, order []uuid.UUID) ([]entity.ChecklistItem, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	t, err := mr.editable(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Checklist, err = entity.Reorder(t.Checklist, order); err != nil {
+		return nil, err
+	}
+	mr.store(t)
+	return append([]entity.ChecklistItem{}, t.Checklist...), nil
+}
+
+// DeleteChecklistItem satisfies the DeleteChecklistItem TaskRepository interface method
+func (mr *MemoryRepository) DeleteChecklistItem(ctx context.Context, taskID, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	t, err := mr.editable(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if _, err := t.ChecklistItem(id); err != nil {
+		return err
+	}
+
+	checklist := make([]entity.ChecklistItem, 0, len(t.Checklist)-1)
+	for _, item := range t.Checklist {
+		if item.ID != id {
+			item.Position = len(checklist)
+			checklist = append(checklist, item)
+		}
+	}
+	t.Checklist = checklist
+	mr.store(t)
+	return nil
+}
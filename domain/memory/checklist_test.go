@@ -0,0 +1,86 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryChecklist(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	viewerID := uuid.New()
+	owner := task.WithOwner(context.Background(), uuid.New())
+	viewer := task.WithOwner(context.Background(), viewerID)
+
+	packing := entity.NewTask("Pack for the trip")
+	assert.NoError(t, mr.Post(owner, packing))
+	assert.NoError(t, mr.Share(owner, packing.ID, viewerID, entity.RoleViewer))
+
+	var items []*entity.ChecklistItem
+	for _, text := range []string{"Passport", "Charger", "Toothbrush"} {
+		item, err := entity.NewChecklistItem(packing.ID, text)
+		assert.NoError(t, err)
+		assert.NoError(t, mr.AddChecklistItem(owner, item))
+		items = append(items, item)
+	}
+	assert.Equal(t, 2, items[2].Position)
+	blank := &entity.ChecklistItem{TaskID: packing.ID, Text: " "}
+	assert.ErrorIs(t, mr.AddChecklistItem(owner, blank), entity.ErrInvalidChecklistItem)
+	assert.ErrorIs(t, mr.AddChecklistItem(viewer, &entity.ChecklistItem{TaskID: packing.ID, Text: "Snacks"}), entity.ErrForbidden)
+
+	toggled, err := mr.ToggleChecklistItem(owner, packing.ID, items[0].ID)
+	assert.NoError(t, err)
+	assert.True(t, toggled.Done)
+	_, err = mr.ToggleChecklistItem(viewer, packing.ID, items[1].ID)
+	assert.ErrorIs(t, err, entity.ErrForbidden)
+	_, err = mr.ToggleChecklistItem(owner, packing.ID, uuid.New())
+	assert.ErrorIs(t, err, entity.ErrChecklistItemNotFound)
+
+	edited, err := mr.EditChecklistItem(owner, packing.ID, items[1].ID, "Phone charger")
+	assert.NoError(t, err)
+	assert.Equal(t, "Phone charger", edited.Text)
+
+	found, err := mr.Get(viewer, packing.ID)
+	assert.NoError(t, err)
+	assert.Len(t, found.Checklist, 3)
+	assert.Equal(t, 33, found.ChecklistCompletion())
+
+	_, err = mr.ReorderChecklist(owner, packing.ID, []uuid.UUID{items[2].ID, items[0].ID})
+	assert.ErrorIs(t, err, entity.ErrInvalidChecklistOrder, "every item must be listed")
+	_, err = mr.ReorderChecklist(owner, packing.ID, []uuid.UUID{items[2].ID, items[0].ID, items[0].ID})
+	assert.ErrorIs(t, err, entity.ErrInvalidChecklistOrder, "items must be listed once")
+	checklist, err := mr.ReorderChecklist(owner, packing.ID, []uuid.UUID{items[2].ID, items[0].ID, items[1].ID})
+	assert.NoError(t, err)
+	if assert.Len(t, checklist, 3) {
+		assert.Equal(t, "Toothbrush", checklist[0].Text)
+		assert.Equal(t, 2, checklist[2].Position)
+	}
+
+	// Put leaves the checklist unchanged.
+	update := *packing
+	update.Description, update.Checklist = "Pack for the holidays", nil
+	assert.NoError(t, mr.Put(owner, &update))
+	found, _ = mr.Get(owner, packing.ID)
+	assert.Equal(t, "Pack for the holidays", found.Description)
+	assert.Len(t, found.Checklist, 3)
+
+	assert.NoError(t, mr.DeleteChecklistItem(owner, packing.ID, items[2].ID))
+	found, _ = mr.Get(owner, packing.ID)
+	if assert.Len(t, found.Checklist, 2) {
+		assert.Equal(t, "Passport", found.Checklist[0].Text)
+		assert.Equal(t, 0, found.Checklist[0].Position, "the items after a deleted one move up")
+		assert.Equal(t, 50, found.ChecklistCompletion())
+	}
+
+	tasks, _, err := mr.Find(owner, task.Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Len(t, tasks[0].Checklist, 2)
+	}
+}
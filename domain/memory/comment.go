@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// comment returns the position of a Comment about a Task which the context
+// may see. The caller must hold the lock.
+func (mr *MemoryRepository) comment(ctx context.Context, taskID, id uuid.UUID) (int, error) {
+	if _, ok := mr.live(ctx, taskID); !ok {
+		return 0, entity.ErrTaskNotFound
+	}
+	for i, comment := range mr.comments.value(taskID) {
+		if comment.ID == id {
+			return i, nil
+		}
+	}
+	return 0, entity.ErrCommentNotFound
+}
+
+// AddComment satisfies the AddComment CommentRepository interface method
+func (mr *MemoryRepository) AddComment(ctx context.Context, comment *entity.Comment) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if err := entity.ValidateCommentBody(comment.Body); err != nil {
+		return err
+	}
+	if _, ok := mr.live(ctx, comment.TaskID); !ok {
+		return entity.ErrTaskNotFound
+	}
+
+	if comment.ID == uuid.Nil {
+		comment.ID = uuid.New()
+	}
+	comment.AuthorID, _ = task.OwnerFromContext(ctx)
+	comment.CreatedAt = time.Now()
+	comment.EditedAt = nil
+
+	comments := mr.comments.value(comment.TaskID)
+	mr.comments.set(comment.TaskID, append(comments[:len(comments):len(comments)], *comment))
+	return nil
+}
+
+// GetComment satisfies the GetComment CommentRepository interface method
+func (mr *MemoryRepository) GetComment(ctx context.Context, taskID, id uuid.UUID) (entity.Comment, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	i, err := mr.comment(ctx, taskID, id)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+	return mr.comments.value(taskID)[i], nil
+}
+
+// Comments satisfies the Comments CommentRepository interface method
+func (mr *MemoryRepository) Comments(ctx context.Context, taskID uuid.UUID) ([]entity.Comment, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	comments := make([]entity.Comment, 0)
+	if _, ok := mr.live(ctx, taskID); !ok {
+		return comments, entity.ErrTaskNotFound
+	}
+	return append(comments, mr.comments.value(taskID)...), nil
+}
+
+// EditComment satisfies the EditComment CommentRepository interface method
+func (mr *MemoryRepository) EditComment(ctx context.Context, taskID, id uuid.UUID, body string) (entity.Comment, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if err := entity.ValidateCommentBody(body); err != nil {
+		return entity.Comment{}, err
+	}
+	i, err := mr.comment(ctx, taskID, id)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+	comments := mr.comments.value(taskID)
+	comment := comments[i]
+	if author, scoped := task.OwnerFromContext(ctx); scoped && comment.AuthorID != author {
+		return entity.Comment{}, entity.ErrForbidden
+	}
+
+	now := time.Now()
+	edits := mr.commentEdits.value(id)
+	mr.commentEdits.set(id, append(edits[:len(edits):len(edits)], entity.CommentEdit{
+		ID:        uuid.New(),
+		CommentID: id,
+		Body:      comment.Body,
+		EditedAt:  now,
+	}))
+
+	comment.Body, comment.EditedAt = body, &now
+	comments = append([]entity.Comment(nil), comments...)
+	comments[i] = comment
+	mr.comments.set(taskID, comments)
+	return comment, nil
+}
+
+// DeleteComment satisfies the DeleteComment CommentRepository interface method
+func (mr *MemoryRepository) DeleteComment(ctx context.Context, taskID, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	i, err := mr.comment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	t, _ := mr.live(ctx, taskID)
+	comments := mr.comments.value(taskID)
+	if author, scoped := task.OwnerFromContext(ctx); scoped && comments[i].AuthorID != author && !task.Owns(ctx, t) {
+		return entity.ErrForbidden
+	}
+
+	mr.comments.set(taskID, append(comments[:i:i], comments[i+1:]...))
+	mr.commentEdits.delete(id)
+	return nil
+}
+
+// CommentEdits satisfies the CommentEdits CommentRepository interface method
+func (mr *MemoryRepository) CommentEdits(ctx context.Context, taskID, id uuid.UUID) ([]entity.CommentEdit, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	edits := make([]entity.CommentEdit, 0)
+	if _, err := mr.comment(ctx, taskID, id); err != nil {
+		return edits, err
+	}
+	return append(edits, mr.commentEdits.value(id)...), nil
+}
@@ -0,0 +1,77 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryComments(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	ownerID, viewerID := uuid.New(), uuid.New()
+	owner := task.WithOwner(context.Background(), ownerID)
+	viewer := task.WithOwner(context.Background(), viewerID)
+	stranger := task.WithOwner(context.Background(), uuid.New())
+
+	discussed := entity.NewTask("Discussed")
+	assert.NoError(t, mr.Post(owner, discussed))
+	assert.NoError(t, mr.Share(owner, discussed.ID, viewerID, entity.RoleViewer))
+
+	question, err := entity.NewComment(discussed.ID, "Should we *really* do this?")
+	assert.NoError(t, err)
+	assert.NoError(t, mr.AddComment(viewer, question))
+	assert.Equal(t, viewerID, question.AuthorID)
+
+	answer, _ := entity.NewComment(discussed.ID, "Yes.")
+	assert.NoError(t, mr.AddComment(owner, answer))
+	lost, _ := entity.NewComment(discussed.ID, "Let me in")
+	assert.ErrorIs(t, mr.AddComment(stranger, lost), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, mr.AddComment(owner, &entity.Comment{TaskID: discussed.ID, Body: " "}), entity.ErrInvalidComment)
+
+	comments, err := mr.Comments(viewer, discussed.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.Equal(t, question.ID, comments[0].ID)
+		assert.Equal(t, answer.ID, comments[1].ID)
+	}
+
+	_, err = mr.EditComment(owner, discussed.ID, question.ID, "Hijacked")
+	assert.ErrorIs(t, err, entity.ErrForbidden, "only the author edits a comment")
+	time.Sleep(time.Millisecond)
+	edited, err := mr.EditComment(viewer, discussed.ID, question.ID, "Should we do this?")
+	assert.NoError(t, err)
+	assert.NotNil(t, edited.EditedAt)
+	_, err = mr.EditComment(viewer, discussed.ID, question.ID, "Should we do this now?")
+	assert.NoError(t, err)
+
+	edits, err := mr.CommentEdits(viewer, discussed.ID, question.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, edits, 2) {
+		assert.Equal(t, "Should we *really* do this?", edits[0].Body)
+		assert.Equal(t, "Should we do this?", edits[1].Body)
+	}
+
+	found, err := mr.GetComment(viewer, discussed.ID, question.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Should we do this now?", found.Body)
+
+	assert.ErrorIs(t, mr.DeleteComment(viewer, discussed.ID, answer.ID), entity.ErrForbidden)
+	assert.NoError(t, mr.DeleteComment(owner, discussed.ID, answer.ID), "the owner of the Task moderates it")
+	_, err = mr.GetComment(owner, discussed.ID, answer.ID)
+	assert.ErrorIs(t, err, entity.ErrCommentNotFound)
+
+	// Purging the Task deletes its comments.
+	assert.NoError(t, mr.Delete(owner, discussed.ID))
+	assert.NoError(t, mr.Purge(owner, discussed.ID))
+	assert.NoError(t, mr.Post(owner, discussed))
+	comments, err = mr.Comments(owner, discussed.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+}
@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// record appends a HistoryEntry for the change of a Task from before to
+// after. The caller must hold the lock.
+func (mr *MemoryRepository) record(ctx context.Context, action entity.HistoryAction, before, after *entity.Task) {
+	id := after
+	if id == nil {
+		id = before
+	}
+	entries := mr.history.value(id.ID)
+	entry := entity.NewHistoryEntry(action, task.ActorFromContext(ctx), len(entries)+1, before, after)
+	mr.history.set(id.ID, append(entries[:len(entries):len(entries)], entry))
+}
+
+// History satisfies the History TaskRepository interface method
+func (mr *MemoryRepository) History(ctx context.Context, id uuid.UUID) ([]entity.HistoryEntry, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	// Once purged, a Task has no owner to show its history to.
+	_, scoped := task.OwnerFromContext(ctx)
+	_, tenanted := task.WorkspaceFromContext(ctx)
+	if scoped || tenanted {
+		if t, ok := mr.lookup(ctx, id); !ok || !mr.can(ctx, t, entity.RoleViewer) {
+			return make([]entity.HistoryEntry, 0), nil
+		}
+	}
+
+	entries := make([]entity.HistoryEntry, 0)
+	return append(entries, mr.history.value(id)...), nil
+}
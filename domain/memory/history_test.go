@@ -0,0 +1,107 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryHistory(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	ctx := task.WithActor(context.Background(), "alice")
+
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, mr.Post(ctx, task0))
+
+	updated := *task0
+	updated.Priority = entity.PriorityHigh
+	assert.NoError(t, mr.Put(task.WithActor(context.Background(), "bob"), &updated))
+	assert.NoError(t, mr.Delete(context.Background(), task0.ID))
+	assert.NoError(t, mr.Restore(ctx, task0.ID))
+
+	entries, err := mr.History(context.Background(), task0.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	expected := []struct {
+		action entity.HistoryAction
+		actor  string
+	}{
+		{entity.HistoryCreated, "alice"},
+		{entity.HistoryUpdated, "bob"},
+		{entity.HistoryDeleted, task.AnonymousActor},
+		{entity.HistoryRestored, "alice"},
+	}
+	for i, want := range expected {
+		assert.Equal(t, i+1, entries[i].Version)
+		assert.Equal(t, task0.ID, entries[i].TaskID)
+		assert.Equal(t, want.action, entries[i].Action)
+		assert.Equal(t, want.actor, entries[i].Actor)
+	}
+
+	assert.Nil(t, entries[0].Before)
+	assert.Equal(t, task0.Description, entries[0].After.Description)
+
+	assert.Equal(t, entity.PriorityLow, entries[1].Before.Priority)
+	assert.Equal(t, entity.PriorityHigh, entries[1].After.Priority)
+	assert.Equal(t, map[string]entity.Change{
+		"priority": {From: float64(entity.PriorityLow), To: float64(entity.PriorityHigh)},
+	}, entries[1].Changes)
+
+	assert.NotNil(t, entries[2].Before)
+	assert.Nil(t, entries[2].After)
+}
+
+func TestMemoryRepositoryHistoryRolledBack(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, mr.Post(context.Background(), task0))
+
+	err := mr.WithTx(context.Background(), func(repo task.TaskRepository) error {
+		updated := *task0
+		updated.Completed = true
+		if err := repo.Put(context.Background(), &updated); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	assert.Error(t, err)
+
+	entries, err := mr.History(context.Background(), task0.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "the history of a rolled back change should be discarded")
+}
+
+func TestRevert(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, mr.Post(context.Background(), task0))
+
+	updated := *task0
+	updated.Description = "task 0, renamed"
+	updated.Priority = entity.PriorityHigh
+	assert.NoError(t, mr.Put(context.Background(), &updated))
+
+	reverted, err := task.Revert(context.Background(), mr, task0.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "task 0", reverted.Description)
+	assert.Equal(t, entity.PriorityLow, reverted.Priority)
+
+	record, _ := mr.Get(context.Background(), task0.ID)
+	assert.Equal(t, "task 0", record.Description)
+
+	entries, _ := mr.History(context.Background(), task0.ID)
+	assert.Len(t, entries, 3, "the revert should be recorded too")
+
+	_, err = task.Revert(context.Background(), mr, task0.ID, 42)
+	assert.ErrorIs(t, err, entity.ErrVersionNotFound)
+
+	assert.NoError(t, mr.Delete(context.Background(), task0.ID))
+	_, err = task.Revert(context.Background(), mr, task0.ID, 1)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound, "tasks in the trash must be restored first")
+}
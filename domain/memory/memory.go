@@ -3,15 +3,55 @@ package memory
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
 	"github.com/omaciel/GoDoIt/entity"
 )
 
 // MemoryRepository fulfills the TaskRepository interface
 type MemoryRepository struct {
+	// Records holds the Tasks outside of any workspace.
 	Records map[uuid.UUID]entity.Task
 	sync.Mutex
+
+	// workspaces holds the Tasks of every workspace, in a map of their own so
+	// that a workspace never reaches the Tasks of another.
+	workspaces map[uuid.UUID]map[uuid.UUID]entity.Task
+
+	// index is built the first time the repository is searched.
+	index *searchIndex
+
+	// history records every change made to every Task.
+	history table[uuid.UUID, []entity.HistoryEntry]
+
+	// users holds the accounts owning the Tasks, and tokens their API tokens.
+	users  map[uuid.UUID]entity.User
+	tokens map[uuid.UUID]entity.APIToken
+
+	// shares gives Users access to the Tasks of others, by Task then User.
+	shares table[uuid.UUID, map[uuid.UUID]entity.Share]
+
+	// comments are kept by Task, oldest first, and their edits by Comment.
+	comments     table[uuid.UUID, []entity.Comment]
+	commentEdits table[uuid.UUID, []entity.CommentEdit]
+
+	// attachments are kept by Task, oldest first.
+	attachments table[uuid.UUID, []entity.Attachment]
+	// purgedAttachments are the attachments of the purged Tasks, until their
+	// contents are deleted.
+	purgedAttachments []entity.Attachment
+
+	// webhooks receive the changes of their User, and deliveries record what
+	// was sent to them.
+	webhooks   map[uuid.UUID]entity.Webhook
+	deliveries map[uuid.UUID]entity.WebhookDelivery
+
+	// base is the repository overlaid by a transaction, whose Tasks are read
+	// unless the transaction stored or removed them.
+	base    *MemoryRepository
+	removed map[uuid.UUID]struct{}
 }
 
 // NewMemoryRepository creates an in-memory datastore for Tasks
@@ -21,9 +61,148 @@ func NewMemoryRepository() *MemoryRepository {
 	}
 }
 
+// touch sets the timestamps which GORM manages for the SQL repositories.
+func touch(task *entity.Task, now time.Time) {
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now
+	}
+	task.UpdatedAt = now
+	task.Stamp(now)
+}
+
+// partition returns the Tasks of a workspace. The caller must hold the lock.
+func (mr *MemoryRepository) partition(workspace uuid.UUID) map[uuid.UUID]entity.Task {
+	if workspace == uuid.Nil {
+		if mr.Records == nil {
+			mr.Records = make(map[uuid.UUID]entity.Task)
+		}
+		return mr.Records
+	}
+
+	if mr.workspaces == nil {
+		mr.workspaces = make(map[uuid.UUID]map[uuid.UUID]entity.Task)
+	}
+	if mr.workspaces[workspace] == nil {
+		mr.workspaces[workspace] = make(map[uuid.UUID]entity.Task)
+	}
+	return mr.workspaces[workspace]
+}
+
+// partitions returns the Tasks of the workspace of the context, or of every
+// workspace for a context without one. The caller must hold the lock.
+func (mr *MemoryRepository) partitions(ctx context.Context) []map[uuid.UUID]entity.Task {
+	if workspace, ok := task.WorkspaceFromContext(ctx); ok {
+		return []map[uuid.UUID]entity.Task{mr.partition(workspace)}
+	}
+
+	partitions := []map[uuid.UUID]entity.Task{mr.partition(uuid.Nil)}
+	for _, records := range mr.workspaces {
+		partitions = append(partitions, records)
+	}
+	return partitions
+}
+
+// lookup returns the Task with the given ID if it is in the workspace of the
+// context. The caller must hold the lock.
+func (mr *MemoryRepository) lookup(ctx context.Context, id uuid.UUID) (entity.Task, bool) {
+	for _, records := range mr.partitions(ctx) {
+		if t, ok := records[id]; ok {
+			return t, true
+		}
+	}
+	if _, ok := mr.removed[id]; ok || mr.base == nil {
+		return entity.Task{}, false
+	}
+	return mr.base.lookup(ctx, id)
+}
+
+// each calls fn with every Task in the workspace of the context. fn must not
+// store or remove Tasks. The caller must hold the lock.
+func (mr *MemoryRepository) each(ctx context.Context, fn func(t entity.Task)) {
+	for _, records := range mr.partitions(ctx) {
+		for _, t := range records {
+			fn(t)
+		}
+	}
+	if mr.base == nil {
+		return
+	}
+	mr.base.each(ctx, func(t entity.Task) {
+		if _, ok := mr.removed[t.ID]; ok {
+			return
+		}
+		if _, ok := mr.partition(t.WorkspaceID)[t.ID]; !ok {
+			fn(t)
+		}
+	})
+}
+
+// store saves the Task in the partition of its workspace. The caller must
+// hold the lock.
+func (mr *MemoryRepository) store(t entity.Task) {
+	mr.partition(t.WorkspaceID)[t.ID] = t
+	delete(mr.removed, t.ID)
+}
+
+// remove deletes the Task from the partition of its workspace, and hides it
+// in the base of a transaction. The caller must hold the lock.
+func (mr *MemoryRepository) remove(t entity.Task) {
+	delete(mr.partition(t.WorkspaceID), t.ID)
+	if mr.base != nil {
+		mr.removed[t.ID] = struct{}{}
+	}
+}
+
+// Load adds Tasks as they are, without recording their history, to the
+// partition of their workspace.
+func (mr *MemoryRepository) Load(tasks ...entity.Task) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	for _, t := range tasks {
+		mr.store(t)
+	}
+	mr.index = nil
+}
+
+// live returns the Task with the given ID unless it does not exist, is in the
+// trash or cannot be seen with the context. The caller must hold the lock.
+func (mr *MemoryRepository) live(ctx context.Context, id uuid.UUID) (entity.Task, bool) {
+	t, ok := mr.lookup(ctx, id)
+	if !ok || t.IsDeleted() || !mr.can(ctx, t, entity.RoleViewer) {
+		return entity.Task{}, false
+	}
+	return t, true
+}
+
+// trashed returns the Task with the given ID if it is in the trash and can be
+// seen with the context. The caller must hold the lock.
+func (mr *MemoryRepository) trashed(ctx context.Context, id uuid.UUID) (entity.Task, bool) {
+	t, ok := mr.lookup(ctx, id)
+	if !ok || !t.IsDeleted() || !task.Owns(ctx, t) {
+		return entity.Task{}, false
+	}
+	return t, true
+}
+
+// own sets the owner and workspace found in the context, if any, on a new
+// Task. New Tasks are unassigned, without a checklist.
+func own(ctx context.Context, t *entity.Task) {
+	t.AssigneeID, t.Checklist = nil, nil
+	if owner, ok := task.OwnerFromContext(ctx); ok {
+		t.OwnerID = owner
+	}
+	if workspace, ok := task.WorkspaceFromContext(ctx); ok {
+		t.WorkspaceID = workspace
+	}
+}
+
 // Get satifies the Get TaskRepository interface method
 func (mr *MemoryRepository) Get(ctx context.Context, id uuid.UUID) (entity.Task, error) {
-	if task, ok := mr.Records[id]; ok {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if task, ok := mr.live(ctx, id); ok {
 		return task, nil
 	}
 	return entity.Task{}, entity.ErrTaskNotFound
@@ -31,33 +210,45 @@ func (mr *MemoryRepository) Get(ctx context.Context, id uuid.UUID) (entity.Task,
 
 // Post satifies the Post TaskRepository interface method
 func (mr *MemoryRepository) Post(ctx context.Context, task *entity.Task) error {
-	if mr.Records == nil {
-		mr.Lock()
-		mr.Records = make(map[uuid.UUID]entity.Task)
-		mr.Unlock()
-	}
+	mr.Lock()
+	defer mr.Unlock()
 
-	// Does the Task already exist?
-	if _, ok := mr.Records[task.ID]; ok {
+	// Does the Task already exist, in any workspace?
+	if _, ok := mr.lookup(context.Background(), task.ID); ok {
 		return entity.ErrTaskUniqueConstraint
 	}
-	mr.Lock()
-	mr.Records[task.ID] = *task
-	mr.Unlock()
+	own(ctx, task)
+	touch(task, time.Now())
+	mr.store(*task)
+	mr.record(ctx, entity.HistoryCreated, nil, task)
+	if mr.index != nil {
+		mr.index.add(*task)
+	}
 	return nil
 }
 
 // Delete satisfies the Delete TaskRepository interface method
 func (mr *MemoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
 	// Check if Task exists first.
-	if _, ok := mr.Records[id]; !ok {
+	task, ok := mr.live(ctx, id)
+	if !ok {
 		return entity.ErrTaskNotFound
 	}
+	if !mr.can(ctx, task, entity.RoleOwner) {
+		return entity.ErrForbidden
+	}
 
-	// Delete the Task.
-	delete(mr.Records, id)
+	// Move the Task to the trash.
+	mr.store(moveToTrash(task))
+	mr.record(ctx, entity.HistoryDeleted, &task, nil)
+	if mr.index != nil {
+		mr.index.remove(id)
+	}
 	// Assure that Task could not be found.
-	if _, ok := mr.Records[id]; ok {
+	if _, ok := mr.live(ctx, id); ok {
 		return entity.ErrCouldNotDeleteTask
 	}
 	return nil
@@ -65,27 +256,33 @@ func (mr *MemoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 // All satisfies the All TaskRepository interface method
 func (mr *MemoryRepository) All(ctx context.Context) ([]entity.Task, error) {
-	if mr.Records == nil {
-		mr.Lock()
-		mr.Records = make(map[uuid.UUID]entity.Task)
-		mr.Unlock()
-	}
-
-	values := make([]entity.Task, 0)
-
-	for _, value := range mr.Records {
-		values = append(values, value)
-	}
-
-	return values, nil
+	values, _, err := mr.Find(ctx, task.Filter{})
+	return values, err
 }
 
 // Put satisfies the Put TaskRepository interface method method
 func (mr *MemoryRepository) Put(ctx context.Context, task *entity.Task) error {
-	if _, ok := mr.Records[task.ID]; !ok {
+	mr.Lock()
+	defer mr.Unlock()
+
+	existing, ok := mr.live(ctx, task.ID)
+	if !ok {
 		return entity.ErrTaskNotFound
 	}
+	if !mr.can(ctx, existing, entity.RoleEditor) {
+		return entity.ErrForbidden
+	}
 
-	mr.Records[task.ID] = *task
+	task.OwnerID, task.WorkspaceID = existing.OwnerID, existing.WorkspaceID
+	task.AssigneeID, task.Checklist = existing.AssigneeID, existing.Checklist
+	task.CreatedAt, task.DeletedAt = existing.CreatedAt, existing.DeletedAt
+	task.CalendarUID, task.CalendarName = existing.CalendarUID, existing.CalendarName
+	touch(task, time.Now())
+	mr.store(*task)
+	mr.record(ctx, entity.HistoryUpdated, &existing, task)
+	if mr.index != nil {
+		mr.index.remove(task.ID)
+		mr.index.add(*task)
+	}
 	return nil
 }
@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// searchIndex is an inverted index over the words of every Task description.
+type searchIndex struct {
+	// postings maps a word to the Tasks containing it.
+	postings map[string]map[uuid.UUID]struct{}
+	// words keeps the tokenized description of every Task, in order, so that
+	// phrases can be matched and matches highlighted.
+	words map[uuid.UUID][]string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string]map[uuid.UUID]struct{}),
+		words:    make(map[uuid.UUID][]string),
+	}
+}
+
+func (si *searchIndex) add(task entity.Task) {
+	words := entity.Tokenize(task.Description)
+	si.words[task.ID] = words
+	for _, word := range words {
+		if si.postings[word] == nil {
+			si.postings[word] = make(map[uuid.UUID]struct{})
+		}
+		si.postings[word][task.ID] = struct{}{}
+	}
+}
+
+func (si *searchIndex) remove(id uuid.UUID) {
+	for _, word := range si.words[id] {
+		delete(si.postings[word], id)
+		if len(si.postings[word]) == 0 {
+			delete(si.postings, word)
+		}
+	}
+	delete(si.words, id)
+}
+
+// candidates returns the Tasks containing the first word of the term.
+func (si *searchIndex) candidates(term entity.SearchTerm) map[uuid.UUID]struct{} {
+	first := term.Words[0]
+	if term.IsPhrase() || !term.Prefix {
+		return si.postings[first]
+	}
+
+	found := make(map[uuid.UUID]struct{})
+	for word, ids := range si.postings {
+		if strings.HasPrefix(word, first) {
+			for id := range ids {
+				found[id] = struct{}{}
+			}
+		}
+	}
+	return found
+}
+
+// match returns, for every Task matching the term, the positions of the
+// matching words in its description.
+func (si *searchIndex) match(term entity.SearchTerm) map[uuid.UUID][]int {
+	matches := make(map[uuid.UUID][]int)
+	last := len(term.Words) - 1
+
+	for id := range si.candidates(term) {
+		words := si.words[id]
+		for i := 0; i+last < len(words); i++ {
+			ok := true
+			for j, want := range term.Words {
+				got := words[i+j]
+				if j == last && term.Prefix {
+					ok = strings.HasPrefix(got, want)
+				} else {
+					ok = got == want
+				}
+				if !ok {
+					break
+				}
+			}
+			if ok {
+				for j := 0; j <= last; j++ {
+					matches[id] = append(matches[id], i+j)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// Search satisfies the Search TaskRepository interface method
+func (mr *MemoryRepository) Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if len(query.Terms) == 0 {
+		return nil, entity.ErrInvalidSearchQuery
+	}
+	if mr.index == nil {
+		mr.index = newSearchIndex()
+		mr.each(context.Background(), func(t entity.Task) {
+			if !t.IsDeleted() {
+				mr.index.add(t)
+			}
+		})
+	}
+
+	// The index spans every workspace, but Tasks are only ranked against
+	// the ones of the workspace of the context.
+	reachable := make(map[uuid.UUID]entity.Task)
+	mr.each(ctx, func(record entity.Task) {
+		if _, ok := mr.index.words[record.ID]; ok {
+			reachable[record.ID] = record
+		}
+	})
+
+	var hits map[uuid.UUID]map[int]bool
+	scores := make(map[uuid.UUID]float64)
+	total := float64(len(reachable))
+
+	for i, term := range query.Terms {
+		matches := mr.index.match(term)
+		for id := range matches {
+			if _, ok := reachable[id]; !ok {
+				delete(matches, id)
+			}
+		}
+		idf := math.Log(1 + total/float64(len(matches)+1))
+
+		next := make(map[uuid.UUID]map[int]bool)
+		for id, positions := range matches {
+			if i > 0 && hits[id] == nil {
+				continue
+			}
+			next[id] = hits[id]
+			if next[id] == nil {
+				next[id] = make(map[int]bool)
+			}
+			for _, p := range positions {
+				next[id][p] = true
+			}
+			occurrences := float64(len(positions) / len(term.Words))
+			scores[id] += occurrences * idf
+		}
+		hits = next
+	}
+
+	results := make([]entity.SearchResult, 0, len(hits))
+	for id, positions := range hits {
+		record := reachable[id]
+		if !mr.can(ctx, record, entity.RoleViewer) {
+			continue
+		}
+		results = append(results, entity.SearchResult{
+			Task:    record,
+			Rank:    scores[id] / math.Sqrt(float64(len(mr.index.words[id]))),
+			Snippet: snippet(record.Description, positions),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Task.Description < results[j].Task.Description
+	})
+	return results, nil
+}
+
+// snippet returns an HTML-escaped excerpt of text around its first highlighted
+// word. Words are counted the same way entity.Tokenize splits them.
+func snippet(text string, highlight map[int]bool) string {
+	type span struct{ start, end int }
+
+	var spans []span
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsNumber(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+
+	first := len(spans)
+	for p := range highlight {
+		if p < first {
+			first = p
+		}
+	}
+
+	from, to := 0, len(spans)
+	if to > entity.SnippetWords {
+		from = first - 2
+		if from < 0 {
+			from = 0
+		}
+		to = from + entity.SnippetWords
+		if to > len(spans) {
+			to = len(spans)
+			from = to - entity.SnippetWords
+		}
+	}
+
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString(entity.SnippetEllipsis)
+	}
+	cursor := 0
+	if from > 0 {
+		cursor = spans[from].start
+	}
+	for i := from; i < to; i++ {
+		sb.WriteString(html.EscapeString(text[cursor:spans[i].start]))
+		word := text[spans[i].start:spans[i].end]
+		if highlight[i] {
+			sb.WriteString(entity.SnippetStart + word + entity.SnippetEnd)
+		} else {
+			sb.WriteString(word)
+		}
+		cursor = spans[i].end
+	}
+	if to < len(spans) {
+		sb.WriteString(entity.SnippetEllipsis)
+	} else {
+		sb.WriteString(html.EscapeString(text[cursor:]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositorySearch(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	for _, description := range []string{
+		"Write the weekly report",
+		"Report the broken printer to IT",
+		"Buy groceries: milk, eggs and bread",
+		"Read a report about weekly groceries, then write a summary of the report",
+	} {
+		err := mr.Post(context.Background(), entity.NewTask(description))
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			"Single word",
+			"printer",
+			[]string{"Report the broken printer to IT"},
+		},
+		{
+			"All words must match",
+			"weekly write",
+			[]string{"Write the weekly report", "Read a report about weekly groceries, then write a summary of the report"},
+		},
+		{
+			"Phrase",
+			`"weekly report"`,
+			[]string{"Write the weekly report"},
+		},
+		{
+			"Prefix",
+			"grocer*",
+			[]string{"Buy groceries: milk, eggs and bread", "Read a report about weekly groceries, then write a summary of the report"},
+		},
+		{
+			"Prefix phrase",
+			`"broken print"*`,
+			[]string{"Report the broken printer to IT"},
+		},
+		{
+			"No match",
+			"vacation",
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := entity.ParseSearchQuery(tt.query)
+			assert.NoError(t, err)
+
+			results, err := mr.Search(context.Background(), query)
+			assert.NoError(t, err)
+
+			found := make([]string, 0, len(results))
+			for _, result := range results {
+				found = append(found, result.Task.Description)
+			}
+			assert.ElementsMatch(t, tt.expected, found)
+		})
+	}
+}
+
+func TestMemoryRepositorySearchRanking(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	once := entity.NewTask("Read the report before the meeting with the whole team")
+	twice := entity.NewTask("Report the report")
+	assert.NoError(t, mr.Post(context.Background(), once))
+	assert.NoError(t, mr.Post(context.Background(), twice))
+
+	query, err := entity.ParseSearchQuery("report")
+	assert.NoError(t, err)
+
+	results, err := mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, twice.ID, results[0].Task.ID)
+	assert.Greater(t, results[0].Rank, results[1].Rank)
+	assert.Equal(t, "<mark>Report</mark> the <mark>report</mark>", results[0].Snippet)
+	assert.Equal(t, "Read the <mark>report</mark> before the meeting with the whole team", results[1].Snippet)
+}
+
+func TestMemoryRepositorySearchKeepsIndexUpToDate(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task := entity.NewTask("Call the plumber")
+	assert.NoError(t, mr.Post(context.Background(), task))
+
+	query, err := entity.ParseSearchQuery("plumber")
+	assert.NoError(t, err)
+	results, err := mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	task.Description = "Call the electrician"
+	assert.NoError(t, mr.Put(context.Background(), task))
+	results, err = mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 0)
+
+	query, err = entity.ParseSearchQuery("electrician")
+	assert.NoError(t, err)
+	assert.NoError(t, mr.Delete(context.Background(), task.ID))
+	results, err = mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 0)
+}
+
+func TestMemoryRepositorySearchSnippet(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task := entity.NewTask("One two three four five six seven eight nine ten eleven twelve thirteen fourteen")
+	assert.NoError(t, mr.Post(context.Background(), task))
+
+	query, err := entity.ParseSearchQuery("seven")
+	assert.NoError(t, err)
+	results, err := mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "…five six <mark>seven</mark> eight nine ten eleven twelve thirteen fourteen", results[0].Snippet)
+}
+
+func TestMemoryRepositorySearchSnippetEscapesHTML(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task := entity.NewTask(`Fix the <script>alert("report")</script> & rest`)
+	assert.NoError(t, mr.Post(context.Background(), task))
+
+	query, err := entity.ParseSearchQuery("script")
+	assert.NoError(t, err)
+	results, err := mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Fix the &lt;<mark>script</mark>&gt;alert(&#34;report&#34;)&lt;/<mark>script</mark>&gt; &amp; rest", results[0].Snippet)
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// can reports whether the context has the required Role on the Task, whose
+// assignee is one of its viewers. The caller must hold the lock.
+func (mr *MemoryRepository) can(ctx context.Context, t entity.Task, required entity.Role) bool {
+	if task.Owns(ctx, t) {
+		return true
+	}
+	if task.Assigned(ctx, t) && entity.RoleViewer.Allows(required) {
+		return true
+	}
+	user, _ := task.OwnerFromContext(ctx)
+	share, ok := mr.shares.value(t.ID)[user]
+	return ok && share.Role.Allows(required)
+}
+
+// owned returns the Task with the given ID if the context owns it, whether it
+// is in the trash or not. The caller must hold the lock.
+func (mr *MemoryRepository) owned(ctx context.Context, id uuid.UUID) (entity.Task, error) {
+	t, ok := mr.lookup(ctx, id)
+	if !ok || !mr.can(ctx, t, entity.RoleViewer) {
+		return entity.Task{}, entity.ErrTaskNotFound
+	}
+	if !task.Owns(ctx, t) {
+		return entity.Task{}, entity.ErrForbidden
+	}
+	return t, nil
+}
+
+// Share satisfies the Share TaskRepository interface method
+func (mr *MemoryRepository) Share(ctx context.Context, id uuid.UUID, userID uuid.UUID, role entity.Role) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if err := role.Validate(); err != nil {
+		return err
+	}
+	t, err := mr.owned(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.OwnerID == userID {
+		return entity.ErrInvalidShare
+	}
+
+	shares := maps.Clone(mr.shares.value(id))
+	if shares == nil {
+		shares = make(map[uuid.UUID]entity.Share)
+	}
+
+	share, ok := shares[userID]
+	if !ok {
+		share = entity.Share{TaskID: id, UserID: userID, CreatedAt: time.Now()}
+	}
+	share.Role = role
+	shares[userID] = share
+	mr.shares.set(id, shares)
+	return nil
+}
+
+// Unshare satisfies the Unshare TaskRepository interface method
+func (mr *MemoryRepository) Unshare(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if _, err := mr.owned(ctx, id); err != nil {
+		return err
+	}
+	shares := mr.shares.value(id)
+	if _, ok := shares[userID]; !ok {
+		return entity.ErrShareNotFound
+	}
+
+	shares = maps.Clone(shares)
+	delete(shares, userID)
+	mr.shares.set(id, shares)
+	return nil
+}
+
+// Shares satisfies the Shares TaskRepository interface method
+func (mr *MemoryRepository) Shares(ctx context.Context, id uuid.UUID) ([]entity.Share, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	shares := make([]entity.Share, 0)
+	if t, ok := mr.lookup(ctx, id); !ok || !mr.can(ctx, t, entity.RoleViewer) {
+		return shares, entity.ErrTaskNotFound
+	}
+
+	for _, share := range mr.shares.value(id) {
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].CreatedAt.Before(shares[j].CreatedAt)
+	})
+	return shares, nil
+}
@@ -0,0 +1,66 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestMemoryRepositoryShares(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	owner := task.WithOwner(context.Background(), uuid.New())
+	editorID, viewerID := uuid.New(), uuid.New()
+	editor := task.WithOwner(context.Background(), editorID)
+	viewer := task.WithOwner(context.Background(), viewerID)
+	stranger := task.WithOwner(context.Background(), uuid.New())
+
+	shared := entity.NewTask("Shared")
+	assert.NoError(t, mr.Post(owner, shared))
+
+	assert.ErrorIs(t, mr.Share(owner, shared.ID, editorID, entity.RoleOwner), entity.ErrInvalidRole)
+	assert.ErrorIs(t, mr.Share(viewer, shared.ID, viewerID, entity.RoleEditor), entity.ErrTaskNotFound)
+	assert.NoError(t, mr.Share(owner, shared.ID, editorID, entity.RoleEditor))
+	assert.NoError(t, mr.Share(owner, shared.ID, viewerID, entity.RoleViewer))
+	assert.ErrorIs(t, mr.Share(editor, shared.ID, uuid.New(), entity.RoleViewer), entity.ErrForbidden,
+		"only the owner may share a Task")
+
+	_, err := mr.Get(viewer, shared.ID)
+	assert.NoError(t, err)
+	_, err = mr.Get(stranger, shared.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+
+	tasks, _, err := mr.Find(viewer, task.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	update := *shared
+	update.Description = "Edited"
+	assert.ErrorIs(t, mr.Put(viewer, &update), entity.ErrForbidden)
+	assert.NoError(t, mr.Put(editor, &update))
+	assert.ErrorIs(t, mr.Delete(editor, shared.ID), entity.ErrForbidden)
+	trashed := update
+	trashed.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	assert.NoError(t, mr.Put(editor, &trashed))
+	assert.False(t, trashed.IsDeleted(), "the editors cannot move a Task to the trash")
+
+	edited, err := mr.Get(owner, shared.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Edited", edited.Description)
+
+	shares, err := mr.Shares(viewer, shared.ID)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 2)
+
+	assert.NoError(t, mr.Unshare(owner, shared.ID, viewerID))
+	assert.ErrorIs(t, mr.Unshare(owner, shared.ID, viewerID), entity.ErrShareNotFound)
+	_, err = mr.Get(viewer, shared.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+}
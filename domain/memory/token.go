@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CreateToken satisfies the CreateToken UserRepository interface method
+func (mr *MemoryRepository) CreateToken(ctx context.Context, token *entity.APIToken) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if mr.tokens == nil {
+		mr.tokens = make(map[uuid.UUID]entity.APIToken)
+	}
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	mr.tokens[token.ID] = *token
+	return nil
+}
+
+// Tokens satisfies the Tokens UserRepository interface method
+func (mr *MemoryRepository) Tokens(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	tokens := make([]entity.APIToken, 0)
+	for _, token := range mr.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// TokenByHash satisfies the TokenByHash UserRepository interface method
+func (mr *MemoryRepository) TokenByHash(ctx context.Context, hash string) (entity.APIToken, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	for _, token := range mr.tokens {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return entity.APIToken{}, entity.ErrTokenNotFound
+}
+
+// RevokeToken satisfies the RevokeToken UserRepository interface method
+func (mr *MemoryRepository) RevokeToken(ctx context.Context, userID, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	token, ok := mr.tokens[id]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return entity.ErrTokenNotFound
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+	mr.tokens[id] = token
+	return nil
+}
+
+// TouchToken satisfies the TouchToken UserRepository interface method
+func (mr *MemoryRepository) TouchToken(ctx context.Context, id uuid.UUID, at time.Time) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	token, ok := mr.tokens[id]
+	if !ok {
+		return entity.ErrTokenNotFound
+	}
+
+	token.LastUsedAt = &at
+	mr.tokens[id] = token
+	return nil
+}
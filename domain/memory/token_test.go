@@ -0,0 +1,44 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryTokens(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	userID := uuid.New()
+
+	token, secret, err := entity.NewAPIToken(userID, "script", entity.ScopeRead, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, mr.CreateToken(context.Background(), token))
+
+	found, err := mr.TokenByHash(context.Background(), entity.HashAPIToken(secret))
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.True(t, found.Active(time.Now()))
+
+	used := time.Now()
+	assert.NoError(t, mr.TouchToken(context.Background(), token.ID, used))
+
+	assert.ErrorIs(t, mr.RevokeToken(context.Background(), uuid.New(), token.ID), entity.ErrTokenNotFound,
+		"only the owner of a token can revoke it")
+	assert.NoError(t, mr.RevokeToken(context.Background(), userID, token.ID))
+	assert.ErrorIs(t, mr.RevokeToken(context.Background(), userID, token.ID), entity.ErrTokenNotFound)
+
+	tokens, err := mr.Tokens(context.Background(), userID)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.Equal(t, used, *tokens[0].LastUsedAt)
+		assert.False(t, tokens[0].Active(time.Now()))
+	}
+
+	_, err = mr.TokenByHash(context.Background(), entity.HashAPIToken("gdi_unknown"))
+	assert.ErrorIs(t, err, entity.ErrTokenNotFound)
+}
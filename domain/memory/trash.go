@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// moveToTrash returns a copy of the Task marked as deleted now.
+func moveToTrash(task entity.Task) entity.Task {
+	task.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return task
+}
+
+// purgeRelated deletes the shares and comments of a purged Task, and keeps its
+// attachments apart until their contents are deleted. The caller must hold the
+// lock.
+func (mr *MemoryRepository) purgeRelated(id uuid.UUID) {
+	mr.shares.delete(id)
+	for _, comment := range mr.comments.value(id) {
+		mr.commentEdits.delete(comment.ID)
+	}
+	mr.comments.delete(id)
+
+	now := time.Now()
+	for _, attachment := range mr.attachments.value(id) {
+		attachment.PurgedAt = &now
+		mr.purgedAttachments = append(mr.purgedAttachments, attachment)
+	}
+	mr.attachments.delete(id)
+}
+
+// Trash satisfies the Trash TaskRepository interface method
+func (mr *MemoryRepository) Trash(ctx context.Context) ([]entity.Task, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	values := make([]entity.Task, 0)
+	mr.each(ctx, func(value entity.Task) {
+		if value.IsDeleted() && task.Owns(ctx, value) {
+			values = append(values, value)
+		}
+	})
+
+	// Most recently deleted first.
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].DeletedAt.Time.After(values[j].DeletedAt.Time)
+	})
+	return values, nil
+}
+
+// Restore satisfies the Restore TaskRepository interface method
+func (mr *MemoryRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	task, ok := mr.trashed(ctx, id)
+	if !ok {
+		return entity.ErrTaskNotFound
+	}
+
+	before := task
+	task.DeletedAt = gorm.DeletedAt{}
+	mr.store(task)
+	mr.record(ctx, entity.HistoryRestored, &before, &task)
+	if mr.index != nil {
+		mr.index.add(task)
+	}
+	return nil
+}
+
+// Purge satisfies the Purge TaskRepository interface method
+func (mr *MemoryRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	task, ok := mr.trashed(ctx, id)
+	if !ok {
+		return entity.ErrTaskNotFound
+	}
+
+	mr.remove(task)
+	mr.purgeRelated(id)
+	return nil
+}
+
+// PurgeDeletedBefore satisfies the PurgeDeletedBefore TaskRepository interface method
+func (mr *MemoryRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	var expired []entity.Task
+	mr.each(ctx, func(value entity.Task) {
+		if value.IsDeleted() && value.DeletedAt.Time.Before(before) && task.Owns(ctx, value) {
+			expired = append(expired, value)
+		}
+	})
+	for _, value := range expired {
+		mr.remove(value)
+		mr.purgeRelated(value.ID)
+	}
+	return int64(len(expired)), nil
+}
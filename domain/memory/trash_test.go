@@ -0,0 +1,130 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryDeleteMovesToTrash(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, mr.Post(context.Background(), task0))
+	assert.NoError(t, mr.Delete(context.Background(), task0.ID))
+
+	_, err := mr.Get(context.Background(), task0.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+
+	tasks, _ := mr.All(context.Background())
+	assert.Len(t, tasks, 0)
+
+	trash, err := mr.Trash(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, trash, 1)
+	assert.Equal(t, task0.ID, trash[0].ID)
+	assert.True(t, trash[0].IsDeleted())
+
+	err = mr.Delete(context.Background(), task0.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound, "a task cannot be deleted twice")
+}
+
+func TestMemoryRepositoryRestore(t *testing.T) {
+	task0 := entity.NewTask("task 0")
+	task1 := entity.NewTask("task 1")
+
+	tests := []struct {
+		name    string
+		id      uuid.UUID
+		wantErr error
+	}{
+		{"Restore a deleted task", task0.ID, nil},
+		{"Restore a task which is not deleted", task1.ID, entity.ErrTaskNotFound},
+		{"Restore a missing task", uuid.New(), entity.ErrTaskNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := memory.NewMemoryRepository()
+			assert.NoError(t, mr.PostMany(context.Background(), []*entity.Task{task0, task1}))
+			assert.NoError(t, mr.Delete(context.Background(), task0.ID))
+
+			err := mr.Restore(context.Background(), tt.id)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				record, err := mr.Get(context.Background(), tt.id)
+				assert.NoError(t, err)
+				assert.False(t, record.IsDeleted())
+			}
+		})
+	}
+}
+
+func TestMemoryRepositoryPurge(t *testing.T) {
+	task0 := entity.NewTask("task 0")
+	task1 := entity.NewTask("task 1")
+
+	tests := []struct {
+		name         string
+		id           uuid.UUID
+		wantErr      error
+		expectedSize int
+	}{
+		{"Purge a deleted task", task0.ID, nil, 1},
+		{"Purge a task which is not deleted", task1.ID, entity.ErrTaskNotFound, 2},
+		{"Purge a missing task", uuid.New(), entity.ErrTaskNotFound, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := memory.NewMemoryRepository()
+			assert.NoError(t, mr.PostMany(context.Background(), []*entity.Task{task0, task1}))
+			assert.NoError(t, mr.Delete(context.Background(), task0.ID))
+
+			err := mr.Purge(context.Background(), tt.id)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Len(t, mr.Records, tt.expectedSize)
+		})
+	}
+}
+
+func TestMemoryRepositoryPurgeDeletedBefore(t *testing.T) {
+	old := *entity.NewTask("deleted long ago")
+	old.DeletedAt.Time, old.DeletedAt.Valid = time.Now().Add(-48*time.Hour), true
+	recent := *entity.NewTask("deleted recently")
+	recent.DeletedAt.Time, recent.DeletedAt.Valid = time.Now().Add(-time.Hour), true
+	live := *entity.NewTask("not deleted")
+
+	mr := memory.MemoryRepository{
+		Records: map[uuid.UUID]entity.Task{
+			old.ID:    old,
+			recent.ID: recent,
+			live.ID:   live,
+		},
+	}
+
+	purged, err := mr.PurgeDeletedBefore(context.Background(), time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Len(t, mr.Records, 2)
+	assert.NotContains(t, mr.Records, old.ID)
+}
+
+func TestMemoryRepositorySearchSkipsTrash(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	task0 := entity.NewTask("Call the plumber")
+	assert.NoError(t, mr.Post(context.Background(), task0))
+	assert.NoError(t, mr.Delete(context.Background(), task0.ID))
+
+	query, _ := entity.ParseSearchQuery("plumber")
+	results, err := mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 0)
+
+	assert.NoError(t, mr.Restore(context.Background(), task0.ID))
+	results, err = mr.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
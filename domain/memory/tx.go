@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// table is a map which a transaction can overlay. An overlay records the
+// values it sets and the keys it deletes, reads the other keys through to its
+// base, and merges its changes into the base on commit. The values are shared
+// with the base, so they are replaced rather than changed in place.
+type table[K comparable, V any] struct {
+	base    *table[K, V]
+	values  map[K]V
+	deleted map[K]struct{}
+}
+
+// get returns the value of a key, and whether there is one.
+func (t *table[K, V]) get(key K) (V, bool) {
+	if value, ok := t.values[key]; ok {
+		return value, true
+	}
+	if _, ok := t.deleted[key]; ok || t.base == nil {
+		var zero V
+		return zero, false
+	}
+	return t.base.get(key)
+}
+
+// value returns the value of a key, or the zero value if there is none.
+func (t *table[K, V]) value(key K) V {
+	value, _ := t.get(key)
+	return value
+}
+
+// set sets the value of a key.
+func (t *table[K, V]) set(key K, value V) {
+	if t.values == nil {
+		t.values = make(map[K]V)
+	}
+	t.values[key] = value
+	delete(t.deleted, key)
+}
+
+// delete deletes a key, hiding its value in the base of an overlay.
+func (t *table[K, V]) delete(key K) {
+	delete(t.values, key)
+	if t.base == nil {
+		return
+	}
+	if t.deleted == nil {
+		t.deleted = make(map[K]struct{})
+	}
+	t.deleted[key] = struct{}{}
+}
+
+// overlay returns an empty overlay of the table.
+func (t *table[K, V]) overlay() table[K, V] {
+	return table[K, V]{base: t}
+}
+
+// commit merges the changes of an overlay into its base.
+func (t *table[K, V]) commit() {
+	for key := range t.deleted {
+		t.base.delete(key)
+	}
+	for key, value := range t.values {
+		t.base.set(key, value)
+	}
+}
+
+// WithTx satisfies the WithTx TaskRepository interface method. fn works on an
+// overlay of the repository, which only holds what fn changes and is merged
+// into the repository when fn succeeds. The repository stays locked until fn
+// returns, so fn must only use the repository it is given.
+func (mr *MemoryRepository) WithTx(ctx context.Context, fn func(repo task.TaskRepository) error) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	tx := &MemoryRepository{
+		Records: make(map[uuid.UUID]entity.Task),
+		base:    mr,
+		removed: make(map[uuid.UUID]struct{}),
+
+		history:      mr.history.overlay(),
+		shares:       mr.shares.overlay(),
+		comments:     mr.comments.overlay(),
+		commentEdits: mr.commentEdits.overlay(),
+		attachments:  mr.attachments.overlay(),
+		// Limit the capacity so that appending never writes to
+		// mr.purgedAttachments.
+		purgedAttachments: mr.purgedAttachments[:len(mr.purgedAttachments):len(mr.purgedAttachments)],
+
+		// Users are only read through a TaskRepository, so they are shared.
+		users: mr.users,
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	for id := range tx.removed {
+		if t, ok := mr.lookup(context.Background(), id); ok {
+			mr.remove(t)
+		}
+		if mr.index != nil {
+			mr.index.remove(id)
+		}
+	}
+	for _, records := range tx.partitions(context.Background()) {
+		for _, t := range records {
+			mr.store(t)
+			if mr.index != nil {
+				mr.index.remove(t.ID)
+				if !t.IsDeleted() {
+					mr.index.add(t)
+				}
+			}
+		}
+	}
+	tx.history.commit()
+	tx.shares.commit()
+	tx.comments.commit()
+	tx.commentEdits.commit()
+	tx.attachments.commit()
+	mr.purgedAttachments = tx.purgedAttachments
+	return nil
+}
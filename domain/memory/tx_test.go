@@ -0,0 +1,160 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryWithTx(t *testing.T) {
+	errAbort := errors.New("abort")
+	task0 := entity.NewTask("task 0")
+
+	tests := []struct {
+		name          string
+		fn            func(repo task.TaskRepository) error
+		wantErr       error
+		wantCompleted bool
+		expectedSize  int
+	}{
+		{
+			"Commit when fn succeeds",
+			func(repo task.TaskRepository) error {
+				done := *task0
+				done.Completed = true
+				if err := repo.Put(context.Background(), &done); err != nil {
+					return err
+				}
+				return repo.Post(context.Background(), entity.NewTask("task 1"))
+			},
+			nil,
+			true,
+			2,
+		},
+		{
+			"Roll back when fn fails",
+			func(repo task.TaskRepository) error {
+				done := *task0
+				done.Completed = true
+				if err := repo.Put(context.Background(), &done); err != nil {
+					return err
+				}
+				if err := repo.Post(context.Background(), entity.NewTask("task 1")); err != nil {
+					return err
+				}
+				return errAbort
+			},
+			errAbort,
+			false,
+			1,
+		},
+		{
+			"Roll back a failed nested unit of work only",
+			func(repo task.TaskRepository) error {
+				if err := repo.Post(context.Background(), entity.NewTask("task 1")); err != nil {
+					return err
+				}
+				err := repo.WithTx(context.Background(), func(nested task.TaskRepository) error {
+					if err := nested.Delete(context.Background(), task0.ID); err != nil {
+						return err
+					}
+					return errAbort
+				})
+				assert.ErrorIs(t, err, errAbort)
+				return nil
+			},
+			nil,
+			false,
+			2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := memory.NewMemoryRepository()
+			assert.NoError(t, mr.Post(context.Background(), task0))
+
+			err := mr.WithTx(context.Background(), tt.fn)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Len(t, mr.Records, tt.expectedSize)
+			assert.Equal(t, tt.wantCompleted, mr.Records[task0.ID].Completed)
+		})
+	}
+}
+
+func TestMemoryRepositoryWithTxIsolation(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	err := mr.WithTx(context.Background(), func(repo task.TaskRepository) error {
+		if err := repo.Post(context.Background(), entity.NewTask("task 0")); err != nil {
+			return err
+		}
+		assert.Len(t, mr.Records, 0, "changes should not be visible before the commit")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, mr.Records, 1)
+}
+
+func TestMemoryRepositoryWithTxOverlay(t *testing.T) {
+	for _, fail := range []bool{false, true} {
+		mr := memory.NewMemoryRepository()
+		kept, purged := entity.NewTask("kept"), entity.NewTask("purged")
+		assert.NoError(t, mr.Post(context.Background(), kept))
+		assert.NoError(t, mr.Post(context.Background(), purged))
+		assert.NoError(t, mr.Delete(context.Background(), purged.ID))
+		comment := &entity.Comment{TaskID: kept.ID, Body: "before"}
+		assert.NoError(t, mr.AddComment(context.Background(), comment))
+		user := uuid.New()
+		query, err := entity.ParseSearchQuery("kept")
+		assert.NoError(t, err)
+		results, err := mr.Search(context.Background(), query)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+
+		err = mr.WithTx(context.Background(), func(repo task.TaskRepository) error {
+			tx := repo.(*memory.MemoryRepository)
+			got, err := tx.Get(context.Background(), kept.ID)
+			assert.NoError(t, err, "tasks should be read through to the repository")
+			assert.Equal(t, kept.Description, got.Description)
+
+			got.Description = "changed"
+			assert.NoError(t, tx.Put(context.Background(), &got))
+			assert.NoError(t, tx.Purge(context.Background(), purged.ID))
+			assert.NoError(t, tx.Share(context.Background(), kept.ID, user, entity.RoleViewer))
+			_, err = tx.EditComment(context.Background(), kept.ID, comment.ID, "after")
+			assert.NoError(t, err)
+			if fail {
+				return errors.New("abort")
+			}
+			return nil
+		})
+		assert.Equal(t, fail, err != nil)
+
+		want, wantBody, wantShares, wantTrash, wantResults := "changed", "after", 1, 0, 0
+		if fail {
+			want, wantBody, wantShares, wantTrash, wantResults = "kept", "before", 0, 1, 1
+		}
+		got, err := mr.Get(context.Background(), kept.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got.Description)
+		comments, err := mr.Comments(context.Background(), kept.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, wantBody, comments[0].Body)
+		shares, err := mr.Shares(context.Background(), kept.ID)
+		assert.NoError(t, err)
+		assert.Len(t, shares, wantShares)
+		trash, err := mr.Trash(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, trash, wantTrash)
+		results, err = mr.Search(context.Background(), query)
+		assert.NoError(t, err)
+		assert.Len(t, results, wantResults, "the search index should follow the commit")
+	}
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CreateUser satisfies the CreateUser UserRepository interface method
+func (mr *MemoryRepository) CreateUser(ctx context.Context, user *entity.User) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if mr.users == nil {
+		mr.users = make(map[uuid.UUID]entity.User)
+	}
+	for _, existing := range mr.users {
+		if existing.ID == user.ID || existing.Username == user.Username {
+			return entity.ErrUsernameTaken
+		}
+	}
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	mr.users[user.ID] = *user
+	return nil
+}
+
+// GetUser satisfies the GetUser UserRepository interface method
+func (mr *MemoryRepository) GetUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if user, ok := mr.users[id]; ok {
+		return user, nil
+	}
+	return entity.User{}, entity.ErrUserNotFound
+}
+
+// UserByName satisfies the UserByName UserRepository interface method
+func (mr *MemoryRepository) UserByName(ctx context.Context, username string) (entity.User, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	for _, user := range mr.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return entity.User{}, entity.ErrUserNotFound
+}
+
+// RemoveUser satisfies the RemoveUser UserRepository interface method
+func (mr *MemoryRepository) RemoveUser(ctx context.Context, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if _, ok := mr.users[id]; !ok {
+		return entity.ErrUserNotFound
+	}
+	delete(mr.users, id)
+	for tokenID, token := range mr.tokens {
+		if token.UserID == id {
+			delete(mr.tokens, tokenID)
+		}
+	}
+	return nil
+}
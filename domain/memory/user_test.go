@@ -0,0 +1,84 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryUsers(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	alice, err := entity.NewUser("alice", "correct horse")
+	assert.NoError(t, err)
+	assert.NoError(t, mr.CreateUser(context.Background(), alice))
+	assert.False(t, alice.CreatedAt.IsZero())
+
+	taken, _ := entity.NewUser("alice", "another horse")
+	assert.ErrorIs(t, mr.CreateUser(context.Background(), taken), entity.ErrUsernameTaken)
+
+	found, err := mr.UserByName(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID, found.ID)
+	assert.True(t, found.CheckPassword("correct horse"))
+	assert.False(t, found.CheckPassword("wrong horse"))
+
+	_, err = mr.GetUser(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+
+	token, _, err := entity.NewAPIToken(alice.ID, "script", entity.ScopeRead, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, mr.CreateToken(context.Background(), token))
+	assert.NoError(t, mr.RemoveUser(context.Background(), alice.ID))
+	_, err = mr.UserByName(context.Background(), "alice")
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+	tokens, err := mr.Tokens(context.Background(), alice.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, tokens, "the API tokens of a removed User are deleted")
+	assert.ErrorIs(t, mr.RemoveUser(context.Background(), alice.ID), entity.ErrUserNotFound)
+}
+
+func TestMemoryRepositoryOwnership(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	alice := task.WithOwner(context.Background(), uuid.New())
+	bob := task.WithOwner(context.Background(), uuid.New())
+
+	task0 := entity.NewTask("task 0")
+	assert.NoError(t, mr.Post(alice, task0))
+	assert.NoError(t, mr.Post(bob, entity.NewTask("task 1")))
+
+	owner, _ := task.OwnerFromContext(alice)
+	assert.Equal(t, owner, task0.OwnerID)
+
+	_, err := mr.Get(bob, task0.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+
+	stolen := *task0
+	stolen.Description = "stolen"
+	assert.ErrorIs(t, mr.Put(bob, &stolen), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, mr.Delete(bob, task0.ID), entity.ErrTaskNotFound)
+
+	tasks, _ := mr.All(bob)
+	assert.Len(t, tasks, 1)
+	tasks, _ = mr.All(context.Background())
+	assert.Len(t, tasks, 2, "a context without an owner sees every Task")
+
+	query, _ := entity.ParseSearchQuery("task")
+	results, err := mr.Search(bob, query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	entries, _ := mr.History(bob, task0.ID)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, mr.Delete(alice, task0.ID))
+	trash, _ := mr.Trash(bob)
+	assert.Empty(t, trash)
+	assert.ErrorIs(t, mr.Restore(bob, task0.ID), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, mr.Purge(bob, task0.ID), entity.ErrTaskNotFound)
+}
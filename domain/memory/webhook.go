@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CreateWebhook satisfies the CreateWebhook WebhookRepository interface method
+func (mr *MemoryRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if mr.webhooks == nil {
+		mr.webhooks = make(map[uuid.UUID]entity.Webhook)
+	}
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+	if webhook.CreatedAt.IsZero() {
+		webhook.CreatedAt = time.Now()
+	}
+	mr.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+// Webhooks satisfies the Webhooks WebhookRepository interface method
+func (mr *MemoryRepository) Webhooks(ctx context.Context, userID uuid.UUID) ([]entity.Webhook, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	webhooks := make([]entity.Webhook, 0)
+	for _, webhook := range mr.webhooks {
+		if webhook.UserID == userID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+// GetWebhook satisfies the GetWebhook WebhookRepository interface method
+func (mr *MemoryRepository) GetWebhook(ctx context.Context, userID, id uuid.UUID) (entity.Webhook, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	webhook, ok := mr.webhooks[id]
+	if !ok || webhook.UserID != userID {
+		return entity.Webhook{}, entity.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook satisfies the DeleteWebhook WebhookRepository interface method
+func (mr *MemoryRepository) DeleteWebhook(ctx context.Context, userID, id uuid.UUID) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	webhook, ok := mr.webhooks[id]
+	if !ok || webhook.UserID != userID {
+		return entity.ErrWebhookNotFound
+	}
+
+	delete(mr.webhooks, id)
+	for deliveryID, delivery := range mr.deliveries {
+		if delivery.WebhookID == id {
+			delete(mr.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// SaveDelivery satisfies the SaveDelivery WebhookRepository interface method
+func (mr *MemoryRepository) SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	if _, ok := mr.webhooks[delivery.WebhookID]; !ok {
+		return entity.ErrWebhookNotFound
+	}
+	if mr.deliveries == nil {
+		mr.deliveries = make(map[uuid.UUID]entity.WebhookDelivery)
+	}
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+
+	now := time.Now()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now
+	}
+	delivery.UpdatedAt = now
+	mr.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+// GetDelivery satisfies the GetDelivery WebhookRepository interface method
+func (mr *MemoryRepository) GetDelivery(ctx context.Context, webhookID, id uuid.UUID) (entity.WebhookDelivery, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	delivery, ok := mr.deliveries[id]
+	if !ok || delivery.WebhookID != webhookID {
+		return entity.WebhookDelivery{}, entity.ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// deliveriesWhere returns the deliveries selected by keep, in the given order.
+// The caller must hold the lock.
+func (mr *MemoryRepository) deliveriesWhere(keep func(entity.WebhookDelivery) bool, less func(a, b entity.WebhookDelivery) bool) []entity.WebhookDelivery {
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for _, delivery := range mr.deliveries {
+		if keep(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return less(deliveries[i], deliveries[j])
+	})
+	return deliveries
+}
+
+// newestFirst orders the deliveries from the latest created.
+func newestFirst(a, b entity.WebhookDelivery) bool {
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// Deliveries satisfies the Deliveries WebhookRepository interface method
+func (mr *MemoryRepository) Deliveries(ctx context.Context, webhookID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	return mr.deliveriesWhere(func(delivery entity.WebhookDelivery) bool {
+		return delivery.WebhookID == webhookID
+	}, newestFirst), nil
+}
+
+// DueDeliveries satisfies the DueDeliveries WebhookRepository interface method
+func (mr *MemoryRepository) DueDeliveries(ctx context.Context, at time.Time) ([]entity.WebhookDelivery, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	return mr.deliveriesWhere(func(delivery entity.WebhookDelivery) bool {
+		return delivery.Status == entity.DeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(at)
+	}, func(a, b entity.WebhookDelivery) bool {
+		return a.NextAttemptAt.Before(*b.NextAttemptAt)
+	}), nil
+}
+
+// DeadDeliveries satisfies the DeadDeliveries WebhookRepository interface
+// method
+func (mr *MemoryRepository) DeadDeliveries(ctx context.Context, userID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	return mr.deliveriesWhere(func(delivery entity.WebhookDelivery) bool {
+		return delivery.UserID == userID && delivery.Status == entity.DeliveryDead
+	}, newestFirst), nil
+}
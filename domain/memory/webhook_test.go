@@ -0,0 +1,66 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryWebhooks(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	hook, err := entity.NewWebhook(userID, "https://ci.example.com/hooks", []string{"created"}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, mr.CreateWebhook(ctx, hook))
+
+	found, err := mr.GetWebhook(ctx, userID, hook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, hook.Secret, found.Secret)
+	assert.Equal(t, []string{"created"}, found.Events)
+	_, err = mr.GetWebhook(ctx, uuid.New(), hook.ID)
+	assert.ErrorIs(t, err, entity.ErrWebhookNotFound, "only the owner of a webhook can see it")
+
+	webhooks, err := mr.Webhooks(ctx, userID)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+
+	first := entity.NewWebhookDelivery(found, "created", `{"event":"created"}`)
+	assert.NoError(t, mr.SaveDelivery(ctx, first))
+	second := entity.NewWebhookDelivery(found, "created", `{"event":"created"}`)
+	later := time.Now().Add(time.Hour)
+	second.NextAttemptAt = &later
+	assert.NoError(t, mr.SaveDelivery(ctx, second))
+
+	due, err := mr.DueDeliveries(ctx, time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, first.ID, due[0].ID)
+	}
+
+	second.Status, second.Attempts, second.NextAttemptAt = entity.DeliveryDead, 3, nil
+	assert.NoError(t, mr.SaveDelivery(ctx, second))
+	dead, err := mr.DeadDeliveries(ctx, userID)
+	assert.NoError(t, err)
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, 3, dead[0].Attempts)
+	}
+
+	deliveries, err := mr.Deliveries(ctx, hook.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, second.ID, deliveries[0].ID, "the latest delivery comes first")
+	}
+
+	assert.ErrorIs(t, mr.DeleteWebhook(ctx, uuid.New(), hook.ID), entity.ErrWebhookNotFound)
+	assert.NoError(t, mr.DeleteWebhook(ctx, userID, hook.ID))
+	_, err = mr.GetDelivery(ctx, hook.ID, first.ID)
+	assert.ErrorIs(t, err, entity.ErrDeliveryNotFound, "the deliveries are deleted with their webhook")
+	assert.ErrorIs(t, mr.SaveDelivery(ctx, first), entity.ErrWebhookNotFound)
+}
@@ -0,0 +1,62 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryWorkspaces(t *testing.T) {
+	mr := memory.NewMemoryRepository()
+
+	// The same User in two workspaces, so that only the workspace tells the
+	// Tasks apart.
+	owner := uuid.New()
+	ours := task.WithWorkspace(task.WithOwner(context.Background(), owner), uuid.New())
+	theirs := task.WithWorkspace(task.WithOwner(context.Background(), owner), uuid.New())
+
+	secret := entity.NewTask("Secret wombat plans")
+	assert.NoError(t, mr.Post(ours, secret))
+	assert.Empty(t, mr.Records, "the Tasks of a workspace are kept apart")
+	assert.ErrorIs(t, mr.Post(theirs, secret), entity.ErrTaskUniqueConstraint)
+
+	_, err := mr.Get(theirs, secret.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+
+	tasks, _, err := mr.Find(theirs, task.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	query, _ := entity.ParseSearchQuery("wombat")
+	results, err := mr.Search(theirs, query)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	entries, err := mr.History(theirs, secret.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	update := *secret
+	update.Description = "Stolen"
+	assert.ErrorIs(t, mr.Put(theirs, &update), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, mr.Delete(theirs, secret.ID), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, mr.Share(theirs, secret.ID, uuid.New(), entity.RoleViewer), entity.ErrTaskNotFound)
+
+	assert.NoError(t, mr.Delete(ours, secret.ID))
+	trash, err := mr.Trash(theirs)
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+	assert.ErrorIs(t, mr.Restore(theirs, secret.ID), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, mr.Purge(theirs, secret.ID), entity.ErrTaskNotFound)
+
+	// Background jobs see every workspace.
+	purged, err := mr.PurgeDeletedBefore(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// PostMany satisfies the PostMany TaskRepository interface method. The Tasks
+// are inserted in a single transaction.
+func (pr *PostgresRepository) PostMany(ctx context.Context, tasks []*entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	for _, t := range tasks {
+		if t.ID == uuid.Nil {
+			t.ID = uuid.New()
+		}
+		own(ctx, t)
+	}
+
+	err := pr.Db.Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(tasks); result.Error != nil {
+			return result.Error
+		}
+		for _, t := range tasks {
+			if err := record(ctx, tx, entity.HistoryCreated, nil, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return entity.ErrTaskUniqueConstraint
+	}
+	return err
+}
+
+// DeleteMany satisfies the DeleteMany TaskRepository interface method. No Task
+// is deleted unless all of them exist, and the duplicate IDs are deleted once.
+func (pr *PostgresRepository) DeleteMany(ctx context.Context, ids []uuid.UUID) error {
+	ids = task.Distinct(ids)
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		var before []entity.Task
+		if result := tx.Scopes(readable(ctx)).Where("id IN ?", ids).Find(&before); result.Error != nil {
+			return result.Error
+		}
+		for _, t := range before {
+			if !task.Owns(ctx, t) {
+				return entity.ErrForbidden
+			}
+		}
+
+		result := tx.Scopes(owned(ctx)).Where("id IN ?", ids).Delete(&entity.Task{})
+		if result.Error != nil {
+			return entity.ErrCouldNotDeleteTask
+		}
+		if result.RowsAffected != int64(len(ids)) {
+			return entity.ErrTaskNotFound
+		}
+
+		for i := range before {
+			if err := record(ctx, tx, entity.HistoryDeleted, &before[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// visibleTask returns the Task with the given ID if the context may see it.
+func visibleTask(ctx context.Context, tx *gorm.DB, id uuid.UUID) (entity.Task, error) {
+	var t entity.Task
+	if result := tx.Scopes(readable(ctx)).Where("id = ?", id).First(&t); result.Error != nil {
+		return t, entity.ErrTaskNotFound
+	}
+	return t, nil
+}
+
+// findComment returns a Comment about a Task which the context may see, along
+// with the Task.
+func findComment(ctx context.Context, tx *gorm.DB, taskID, id uuid.UUID) (entity.Task, entity.Comment, error) {
+	var comment entity.Comment
+	t, err := visibleTask(ctx, tx, taskID)
+	if err != nil {
+		return t, comment, err
+	}
+
+	result := tx.Where("id = ? AND task_id = ?", id, taskID).First(&comment)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return t, comment, entity.ErrCommentNotFound
+	}
+	return t, comment, result.Error
+}
+
+// AddComment satisfies the AddComment CommentRepository interface method
+func (pr *PostgresRepository) AddComment(ctx context.Context, comment *entity.Comment) error {
+	if err := entity.ValidateCommentBody(comment.Body); err != nil {
+		return err
+	}
+
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		if _, err := visibleTask(ctx, tx, comment.TaskID); err != nil {
+			return err
+		}
+
+		if comment.ID == uuid.Nil {
+			comment.ID = uuid.New()
+		}
+		comment.AuthorID, _ = task.OwnerFromContext(ctx)
+		comment.EditedAt = nil
+		return tx.Create(comment).Error
+	})
+}
+
+// GetComment satisfies the GetComment CommentRepository interface method
+func (pr *PostgresRepository) GetComment(ctx context.Context, taskID, id uuid.UUID) (entity.Comment, error) {
+	_, comment, err := findComment(ctx, pr.Db, taskID, id)
+	return comment, err
+}
+
+// Comments satisfies the Comments CommentRepository interface method
+func (pr *PostgresRepository) Comments(ctx context.Context, taskID uuid.UUID) ([]entity.Comment, error) {
+	comments := make([]entity.Comment, 0)
+	if _, err := visibleTask(ctx, pr.Db, taskID); err != nil {
+		return comments, err
+	}
+
+	result := pr.Db.Where("task_id = ?", taskID).Order("created_at").Order("id").Find(&comments)
+	return comments, result.Error
+}
+
+// EditComment satisfies the EditComment CommentRepository interface method
+func (pr *PostgresRepository) EditComment(ctx context.Context, taskID, id uuid.UUID, body string) (entity.Comment, error) {
+	if err := entity.ValidateCommentBody(body); err != nil {
+		return entity.Comment{}, err
+	}
+
+	var comment entity.Comment
+	err := pr.Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if _, comment, err = findComment(ctx, tx, taskID, id); err != nil {
+			return err
+		}
+		if author, scoped := task.OwnerFromContext(ctx); scoped && comment.AuthorID != author {
+			return entity.ErrForbidden
+		}
+
+		now := time.Now()
+		edit := entity.CommentEdit{ID: uuid.New(), CommentID: id, Body: comment.Body, EditedAt: now}
+		if result := tx.Create(&edit); result.Error != nil {
+			return result.Error
+		}
+
+		comment.Body, comment.EditedAt = body, &now
+		return tx.Model(&comment).Select("body", "edited_at").Updates(&comment).Error
+	})
+	return comment, err
+}
+
+// DeleteComment satisfies the DeleteComment CommentRepository interface method
+func (pr *PostgresRepository) DeleteComment(ctx context.Context, taskID, id uuid.UUID) error {
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		t, comment, err := findComment(ctx, tx, taskID, id)
+		if err != nil {
+			return err
+		}
+		if author, scoped := task.OwnerFromContext(ctx); scoped && comment.AuthorID != author && !task.Owns(ctx, t) {
+			return entity.ErrForbidden
+		}
+
+		if result := tx.Where("comment_id = ?", id).Delete(&entity.CommentEdit{}); result.Error != nil {
+			return result.Error
+		}
+		return tx.Delete(&comment).Error
+	})
+}
+
+// CommentEdits satisfies the CommentEdits CommentRepository interface method
+func (pr *PostgresRepository) CommentEdits(ctx context.Context, taskID, id uuid.UUID) ([]entity.CommentEdit, error) {
+	edits := make([]entity.CommentEdit, 0)
+	if _, _, err := findComment(ctx, pr.Db, taskID, id); err != nil {
+		return edits, err
+	}
+
+	result := pr.Db.Where("comment_id = ?", id).Order("edited_at").Order("id").Find(&edits)
+	return edits, result.Error
+}
@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
 	"github.com/omaciel/GoDoIt/entity"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -28,7 +30,8 @@ func NewPostgresRepository() (*PostgresRepository, error) {
 	)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Error),
+		Logger:         logger.Default.LogMode(logger.Error),
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -40,12 +43,24 @@ func NewPostgresRepository() (*PostgresRepository, error) {
 	db.Logger = logger.Default.LogMode(logger.Info)
 
 	log.Println("Running database migrations.")
-	err = db.AutoMigrate(&entity.Task{})
+	err = db.AutoMigrate(&entity.Task{}, &entity.HistoryEntry{}, &entity.User{}, &entity.APIToken{}, &entity.Share{},
+		&entity.Comment{}, &entity.CommentEdit{}, &entity.Attachment{}, &entity.ChecklistItem{}, &entity.Webhook{}, &entity.WebhookDelivery{})
 	if err != nil {
 		log.Fatal("Failed to migrate the database schema. \n", err)
 		return nil, err
 	}
 
+	err = migrateSearch(db)
+	if err != nil {
+		log.Fatal("Failed to create the search index. \n", err)
+		return nil, err
+	}
+
+	if err := migrateWorkspaceRoles(db); err != nil {
+		log.Fatal("Failed to migrate the workspace roles. \n", err)
+		return nil, err
+	}
+
 	return &PostgresRepository{
 		Db: db,
 	}, nil
@@ -55,7 +70,7 @@ func NewPostgresRepository() (*PostgresRepository, error) {
 func (pr *PostgresRepository) Get(ctx context.Context, id uuid.UUID) (entity.Task, error) {
 	var task entity.Task
 
-	result := pr.Db.Where("id = ?", id).First(&task)
+	result := pr.Db.Scopes(readable(ctx), withChecklist).Where("id = ?", id).First(&task)
 	if result.Error != nil {
 		return task, result.Error
 	}
@@ -67,30 +82,67 @@ func (pr *PostgresRepository) Post(ctx context.Context, task *entity.Task) error
 	if task.ID == uuid.Nil {
 		task.ID = uuid.New()
 	}
-	result := pr.Db.Create(&task)
-	return result.Error
+	own(ctx, task)
+
+	err := pr.Db.Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(&task); result.Error != nil {
+			return result.Error
+		}
+		return record(ctx, tx, entity.HistoryCreated, nil, task)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return entity.ErrTaskUniqueConstraint
+	}
+	return err
 }
 
 // Delete satisfies the Delete TaskRepository interface method
 func (pr *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := pr.Db.Where("id = ?", id).Delete(entity.Task{})
-	if result.Error != nil {
-		return entity.ErrCouldNotDeleteTask
-	}
-	return nil
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		var before entity.Task
+		if result := tx.Scopes(readable(ctx)).Where("id = ?", id).First(&before); result.Error != nil {
+			return entity.ErrTaskNotFound
+		}
+		if !task.Owns(ctx, before) {
+			return entity.ErrForbidden
+		}
+
+		result := tx.Where("id = ?", id).Delete(&entity.Task{})
+		if result.Error != nil {
+			return entity.ErrCouldNotDeleteTask
+		}
+		return record(ctx, tx, entity.HistoryDeleted, &before, nil)
+	})
 }
 
 // All satisfies the All TaskRepository interface
 func (pr *PostgresRepository) All(ctx context.Context) ([]entity.Task, error) {
-	var tasks []entity.Task = make([]entity.Task, 0)
-	pr.Db.Find(&tasks)
-	return tasks, nil
+	tasks, _, err := pr.Find(ctx, task.Filter{})
+	return tasks, err
 }
 
 // Put satisfies the Put TaskRepository interface method
 func (pr *PostgresRepository) Put(ctx context.Context, task *entity.Task) error {
-	if result := pr.Db.Save(&task); result.Error != nil {
-		return result.Error
-	}
-	return nil
-}
\ No newline at end of file
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		var before entity.Task
+		if result := tx.Scopes(readable(ctx), withChecklist).Where("id = ?", task.ID).First(&before); result.Error != nil {
+			return entity.ErrTaskNotFound
+		}
+		if ok, err := can(ctx, tx, before, entity.RoleEditor); err != nil || !ok {
+			if err == nil {
+				err = entity.ErrForbidden
+			}
+			return err
+		}
+		task.OwnerID, task.WorkspaceID = before.OwnerID, before.WorkspaceID
+		task.AssigneeID, task.Checklist = before.AssigneeID, before.Checklist
+		task.DeletedAt = before.DeletedAt
+		task.CalendarUID, task.CalendarName = before.CalendarUID, before.CalendarName
+
+		if result := tx.Omit("created_at", "deleted_at", "Checklist").Save(&task); result.Error != nil {
+			return result.Error
+		}
+		task.CreatedAt = before.CreatedAt
+		return record(ctx, tx, entity.HistoryUpdated, &before, task)
+	})
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// searchConfig is the PostgreSQL text search configuration used to parse and
+// stem Task descriptions.
+const searchConfig = "english"
+
+// migrateSearch adds a generated tsvector column over Task descriptions and
+// the GIN index used to search it.
+func migrateSearch(db *gorm.DB) error {
+	statements := []string{
+		fmt.Sprintf(`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('%s', coalesce(description, ''))) STORED`, searchConfig),
+		`CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tsquery renders a SearchQuery using the to_tsquery syntax. Words only ever
+// contain letters and digits, so they need no escaping.
+func tsquery(query entity.SearchQuery) string {
+	clauses := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		clause := strings.Join(term.Words, " <-> ")
+		if term.Prefix {
+			clause += ":*"
+		}
+		clauses = append(clauses, "("+clause+")")
+	}
+	return strings.Join(clauses, " & ")
+}
+
+type searchRow struct {
+	entity.Task
+	SearchRank float64
+	Snippet    string
+}
+
+// Search satisfies the Search TaskRepository interface method
+func (pr *PostgresRepository) Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error) {
+	if len(query.Terms) == 0 {
+		return nil, entity.ErrInvalidSearchQuery
+	}
+
+	options := fmt.Sprintf(
+		"StartSel=%s, StopSel=%s, FragmentDelimiter=%s, MaxWords=%d, MinWords=%d, MaxFragments=1",
+		entity.SnippetStartMarker, entity.SnippetEndMarker, entity.SnippetEllipsis, entity.SnippetWords, entity.SnippetWords/2,
+	)
+
+	owner, scoped := task.OwnerFromContext(ctx)
+	workspace, tenanted := task.WorkspaceFromContext(ctx)
+
+	var rows []searchRow
+	result := pr.Db.Raw(`SELECT tasks.*, ts_rank(tasks.search_vector, query) AS search_rank,
+			ts_headline(?, tasks.description, query, ?) AS snippet
+		FROM tasks, to_tsquery(?, ?) query
+		WHERE tasks.search_vector @@ query AND tasks.deleted_at IS NULL
+			AND (? OR tasks.owner_id = ? OR tasks.assignee_id = ? OR tasks.id IN (SELECT task_id FROM shares WHERE user_id = ?))
+			AND (? OR tasks.workspace_id = ?)
+		ORDER BY search_rank DESC, tasks.description`,
+		searchConfig, options, searchConfig, tsquery(query), !scoped, owner, owner, owner, !tenanted, workspace,
+	).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	results := make([]entity.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, entity.SearchResult{
+			Task:    row.Task,
+			Rank:    row.SearchRank,
+			Snippet: entity.HighlightSnippet(row.Snippet),
+		})
+	}
+	return results, loadChecklists(pr.Db, results)
+}
@@ -0,0 +1,89 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/postgres"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+	driver "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// statements is a gorm logger recording the SQL of every statement.
+type statements []string
+
+func (s *statements) LogMode(logger.LogLevel) logger.Interface { return s }
+
+func (s *statements) Info(context.Context, string, ...interface{}) {}
+
+func (s *statements) Warn(context.Context, string, ...interface{}) {}
+
+func (s *statements) Error(context.Context, string, ...interface{}) {}
+
+func (s *statements) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	*s = append(*s, sql)
+}
+
+// newDryRunRepository returns a PostgresRepository which never connects to a
+// database, and the SQL of the statements it would have run.
+func newDryRunRepository(t *testing.T) (*postgres.PostgresRepository, *statements) {
+	var logged statements
+	db, err := gorm.Open(driver.Open("host=localhost sslmode=disable"), &gorm.Config{
+		Logger:               &logged,
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open the Postgres dialector: %v", err)
+	}
+	return &postgres.PostgresRepository{Db: db}, &logged
+}
+
+func TestPostgresRepositorySearch(t *testing.T) {
+	repo, logged := newDryRunRepository(t)
+
+	query, err := entity.ParseSearchQuery(`"weekly report" grocer*`)
+	assert.NoError(t, err)
+	_, err = repo.Search(context.Background(), query)
+	assert.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported)
+
+	assert.Len(t, *logged, 1)
+	sql := (*logged)[0]
+	assert.Contains(t, sql, `to_tsquery('english', '(weekly <-> report) & (grocer:*)')`)
+	// The highlights are marked with control characters, which are replaced
+	// once the snippet is escaped.
+	assert.Contains(t, sql, "StartSel=\x02, StopSel=\x03, FragmentDelimiter=…, MaxWords=10, MinWords=5")
+	assert.Contains(t, sql, "AND (true OR tasks.owner_id = '00000000-0000-0000-0000-000000000000'")
+}
+
+func TestPostgresRepositorySearchScope(t *testing.T) {
+	repo, logged := newDryRunRepository(t)
+
+	owner, workspace := uuid.New(), uuid.New()
+	ctx := task.WithWorkspace(task.WithOwner(context.Background(), owner), workspace)
+	query, err := entity.ParseSearchQuery("report")
+	assert.NoError(t, err)
+	_, err = repo.Search(ctx, query)
+	assert.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported)
+
+	assert.Len(t, *logged, 1)
+	sql := (*logged)[0]
+	assert.Contains(t, sql, `to_tsquery('english', '(report)')`)
+	assert.Contains(t, sql, "AND (false OR tasks.owner_id = '"+owner.String()+"'")
+	assert.Contains(t, sql, "AND (false OR tasks.workspace_id = '"+workspace.String()+"')")
+}
+
+func TestPostgresRepositorySearchEmptyQuery(t *testing.T) {
+	repo, logged := newDryRunRepository(t)
+
+	_, err := repo.Search(context.Background(), entity.SearchQuery{})
+	assert.ErrorIs(t, err, entity.ErrInvalidSearchQuery)
+	assert.Empty(t, *logged)
+}
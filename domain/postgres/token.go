@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// CreateToken satisfies the CreateToken UserRepository interface method
+func (pr *PostgresRepository) CreateToken(ctx context.Context, token *entity.APIToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	return pr.Db.Create(token).Error
+}
+
+// Tokens satisfies the Tokens UserRepository interface method
+func (pr *PostgresRepository) Tokens(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error) {
+	var tokens []entity.APIToken = make([]entity.APIToken, 0)
+	result := pr.Db.Where("user_id = ?", userID).Order("created_at").Find(&tokens)
+	return tokens, result.Error
+}
+
+// TokenByHash satisfies the TokenByHash UserRepository interface method
+func (pr *PostgresRepository) TokenByHash(ctx context.Context, hash string) (entity.APIToken, error) {
+	var token entity.APIToken
+
+	result := pr.Db.Where("hash = ?", hash).First(&token)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return token, entity.ErrTokenNotFound
+	}
+	return token, result.Error
+}
+
+// RevokeToken satisfies the RevokeToken UserRepository interface method
+func (pr *PostgresRepository) RevokeToken(ctx context.Context, userID, id uuid.UUID) error {
+	result := pr.Db.Model(&entity.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrTokenNotFound
+	}
+	return nil
+}
+
+// TouchToken satisfies the TouchToken UserRepository interface method
+func (pr *PostgresRepository) TouchToken(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result := pr.Db.Model(&entity.APIToken{}).Where("id = ?", id).Update("last_used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrTokenNotFound
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// Trash satisfies the Trash TaskRepository interface method
+func (pr *PostgresRepository) Trash(ctx context.Context) ([]entity.Task, error) {
+	var tasks []entity.Task = make([]entity.Task, 0)
+	result := pr.Db.Unscoped().Scopes(owned(ctx), withChecklist).Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&tasks)
+	return tasks, result.Error
+}
+
+// Restore satisfies the Restore TaskRepository interface method
+func (pr *PostgresRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		var before entity.Task
+		result := tx.Unscoped().Scopes(owned(ctx)).Where("id = ? AND deleted_at IS NOT NULL", id).First(&before)
+		if result.Error != nil {
+			return entity.ErrTaskNotFound
+		}
+
+		result = tx.Unscoped().Model(&entity.Task{}).Where("id = ?", id).Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		after := before
+		after.DeletedAt = gorm.DeletedAt{}
+		return record(ctx, tx, entity.HistoryRestored, &before, &after)
+	})
+}
+
+// purgeRelated deletes the shares, comments and checklist items of the purged
+// Tasks selected by the condition on their task_id, and marks their
+// attachments as purged until their contents are deleted.
+func purgeRelated(tx *gorm.DB, condition string, args ...interface{}) error {
+	if result := tx.Where(condition, args...).Delete(&entity.Share{}); result.Error != nil {
+		return result.Error
+	}
+	comments := tx.Model(&entity.Comment{}).Select("id").Where(condition, args...)
+	if result := tx.Where("comment_id IN (?)", comments).Delete(&entity.CommentEdit{}); result.Error != nil {
+		return result.Error
+	}
+	if result := tx.Where(condition, args...).Delete(&entity.Comment{}); result.Error != nil {
+		return result.Error
+	}
+	result := tx.Model(&entity.Attachment{}).Where(condition, args...).Where("purged_at IS NULL").Update("purged_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	return tx.Where(condition, args...).Delete(&entity.ChecklistItem{}).Error
+}
+
+// Purge satisfies the Purge TaskRepository interface method
+func (pr *PostgresRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Scopes(owned(ctx)).Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&entity.Task{})
+		if result.Error != nil {
+			return entity.ErrCouldNotDeleteTask
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrTaskNotFound
+		}
+		return purgeRelated(tx, "task_id = ?", id)
+	})
+}
+
+// PurgeDeletedBefore satisfies the PurgeDeletedBefore TaskRepository interface method
+func (pr *PostgresRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+
+	err := pr.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Scopes(owned(ctx)).Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&entity.Task{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = result.RowsAffected
+		return purgeRelated(tx, "task_id NOT IN (SELECT id FROM tasks)")
+	})
+	return purged, err
+}
@@ -0,0 +1,17 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"gorm.io/gorm"
+)
+
+// WithTx satisfies the WithTx TaskRepository interface method. fn runs inside
+// a database transaction which is rolled back if it returns an error. Nested
+// calls use savepoints.
+func (pr *PostgresRepository) WithTx(ctx context.Context, fn func(repo task.TaskRepository) error) error {
+	return pr.Db.Transaction(func(tx *gorm.DB) error {
+		return fn(&PostgresRepository{Db: tx})
+	})
+}
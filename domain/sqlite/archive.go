@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// Find satisfies the Find TaskRepository interface method
+func (repo *SqliteDBRepository) Find(ctx context.Context, filter task.Filter) ([]entity.Task, int64, error) {
+	var tasks []entity.Task = make([]entity.Task, 0)
+	var total int64
+
+	query := repo.Db.Model(&entity.Task{}).Scopes(readable(ctx)).Where("archived = ?", filter.Archived)
+	if filter.Unassigned {
+		query = query.Where("assignee_id IS NULL")
+	} else if filter.AssignedTo != uuid.Nil {
+		query = query.Where("assignee_id = ?", filter.AssignedTo)
+	}
+	if result := query.Count(&total); result.Error != nil {
+		return tasks, 0, result.Error
+	}
+
+	if filter.Archived {
+		query = query.Order("archived_at DESC")
+	}
+	query = query.Scopes(withChecklist).Order("created_at").Order("id").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	result := query.Find(&tasks)
+	return tasks, total, result.Error
+}
+
+// ArchiveCompleted satisfies the ArchiveCompleted TaskRepository interface method
+func (repo *SqliteDBRepository) ArchiveCompleted(ctx context.Context, before time.Time) (int64, error) {
+	var archived int64
+
+	err := repo.Db.Transaction(func(tx *gorm.DB) error {
+		var tasks []entity.Task
+		result := tx.Scopes(owned(ctx)).Where("completed = ? AND archived = ?", true, false).
+			Where("completed_at IS NULL OR completed_at < ?", before).
+			Find(&tasks)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		now := time.Now()
+		for i := range tasks {
+			before := tasks[i]
+			after := &tasks[i]
+			after.Archived, after.ArchivedAt = true, &now
+
+			result := tx.Model(after).Select("archived", "archived_at").Updates(after)
+			if result.Error != nil {
+				return result.Error
+			}
+			if err := record(ctx, tx, entity.HistoryUpdated, &before, after); err != nil {
+				return err
+			}
+			archived++
+		}
+		return nil
+	})
+	return archived, err
+}
@@ -0,0 +1,62 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryArchive(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	// Archive whatever previous tests left completed.
+	_, err = repo.ArchiveCompleted(context.Background(), time.Now())
+	assert.NoError(t, err)
+	_, archivedBefore, err := repo.Find(context.Background(), task.Filter{Archived: true})
+	assert.NoError(t, err)
+
+	done := entity.NewTask("Archive task 0").WithCompleted(true)
+	open := entity.NewTask("Archive task 1")
+	assert.NoError(t, repo.PostMany(context.Background(), []*entity.Task{done, open}))
+	assert.NotNil(t, done.CompletedAt)
+	assert.Nil(t, open.CompletedAt)
+
+	archived, err := repo.ArchiveCompleted(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), archived, "the task was completed too recently")
+
+	archived, err = repo.ArchiveCompleted(context.Background(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+
+	record, err := repo.Get(context.Background(), done.ID)
+	assert.NoError(t, err)
+	assert.True(t, record.Archived)
+	assert.True(t, record.Completed)
+	assert.NotNil(t, record.ArchivedAt)
+
+	tasks, err := repo.All(context.Background())
+	assert.NoError(t, err)
+	for _, task := range tasks {
+		assert.NotEqual(t, done.ID, task.ID, "archived tasks should not be listed")
+	}
+
+	page, total, err := repo.Find(context.Background(), task.Filter{Archived: true, Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, archivedBefore+1, total)
+	assert.Len(t, page, 1)
+	assert.Equal(t, done.ID, page[0].ID, "the most recently archived task should come first")
+}
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// assign sets the assignee of a Task which the context may edit.
+func (repo *SqliteDBRepository) assign(ctx context.Context, id uuid.UUID, assignee *uuid.UUID) error {
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		var before entity.Task
+		if result := tx.Scopes(readable(ctx)).Where("id = ?", id).First(&before); result.Error != nil {
+			return entity.ErrTaskNotFound
+		}
+		if ok, err := can(ctx, tx, before, entity.RoleEditor); err != nil || !ok {
+			if err == nil {
+				err = entity.ErrForbidden
+			}
+			return err
+		}
+		if assignee != nil {
+			var user entity.User
+			if result := tx.Where("id = ?", *assignee).First(&user); result.Error != nil || user.WorkspaceID != before.WorkspaceID {
+				return entity.ErrInvalidAssignee
+			}
+		}
+
+		after := before
+		after.AssigneeID = assignee
+		if result := tx.Model(&after).Select("assignee_id").Updates(&after); result.Error != nil {
+			return result.Error
+		}
+		return record(ctx, tx, entity.HistoryUpdated, &before, &after)
+	})
+}
+
+// Assign satisfies the Assign TaskRepository interface method
+func (repo *SqliteDBRepository) Assign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return repo.assign(ctx, id, &userID)
+}
+
+// Unassign satisfies the Unassign TaskRepository interface method
+func (repo *SqliteDBRepository) Unassign(ctx context.Context, id uuid.UUID) error {
+	return repo.assign(ctx, id, nil)
+}
@@ -0,0 +1,88 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryAssignees(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	owner, _ := entity.NewUser("sqlite-assigner", "correct horse")
+	assignee, _ := entity.NewUser("sqlite-assignee", "correct horse")
+	assignee.WorkspaceID = owner.WorkspaceID
+	outsider, _ := entity.NewUser("sqlite-outsider", "correct horse")
+	for _, user := range []*entity.User{owner, assignee, outsider} {
+		assert.NoError(t, repo.CreateUser(context.Background(), user))
+	}
+	ctx := task.WithWorkspace(task.WithOwner(context.Background(), owner.ID), owner.WorkspaceID)
+
+	assigned, unassigned := entity.NewTask("Assigned"), entity.NewTask("Unassigned")
+	assert.NoError(t, repo.Post(ctx, assigned))
+	assert.NoError(t, repo.Post(ctx, unassigned))
+
+	assert.ErrorIs(t, repo.Assign(ctx, assigned.ID, outsider.ID), entity.ErrInvalidAssignee)
+	assert.ErrorIs(t, repo.Assign(ctx, assigned.ID, uuid.New()), entity.ErrInvalidAssignee)
+	assert.ErrorIs(t, repo.Assign(ctx, uuid.New(), assignee.ID), entity.ErrTaskNotFound)
+	assert.NoError(t, repo.Assign(ctx, assigned.ID, assignee.ID))
+
+	tasks, _, err := repo.Find(ctx, task.Filter{AssignedTo: assignee.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, assigned.ID, tasks[0].ID)
+	}
+
+	tasks, _, err = repo.Find(ctx, task.Filter{Unassigned: true})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, unassigned.ID, tasks[0].ID)
+	}
+
+	// The assignee sees the Task, but cannot change it.
+	theirs := task.WithWorkspace(task.WithOwner(context.Background(), assignee.ID), owner.WorkspaceID)
+	tasks, _, err = repo.Find(theirs, task.Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, assigned.ID, tasks[0].ID)
+	}
+	query, _ := entity.ParseSearchQuery("assigned")
+	results, err := repo.Search(theirs, query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	taken := *assigned
+	taken.Description = "Taken over"
+	assert.ErrorIs(t, repo.Put(theirs, &taken), entity.ErrForbidden)
+
+	// Put leaves the assignee alone.
+	update := *assigned
+	update.Description = "Still assigned"
+	assert.NoError(t, repo.Put(ctx, &update))
+	found, err := repo.Get(ctx, assigned.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found.AssigneeID) {
+		assert.Equal(t, assignee.ID, *found.AssigneeID)
+	}
+
+	assert.NoError(t, repo.Unassign(ctx, assigned.ID))
+	tasks, _, err = repo.Find(ctx, task.Filter{Unassigned: true})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	entries, err := repo.History(ctx, assigned.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+}
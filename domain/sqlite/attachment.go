@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// editableTask returns the Task with the given ID if the context may edit it.
+func editableTask(ctx context.Context, tx *gorm.DB, id uuid.UUID) (entity.Task, error) {
+	t, err := visibleTask(ctx, tx, id)
+	if err != nil {
+		return t, err
+	}
+	if ok, err := can(ctx, tx, t, entity.RoleEditor); err != nil || !ok {
+		if err == nil {
+			err = entity.ErrForbidden
+		}
+		return t, err
+	}
+	return t, nil
+}
+
+// findAttachment returns an Attachment to a Task which the context may see.
+func findAttachment(ctx context.Context, tx *gorm.DB, taskID, id uuid.UUID) (entity.Attachment, error) {
+	var attachment entity.Attachment
+	if _, err := visibleTask(ctx, tx, taskID); err != nil {
+		return attachment, err
+	}
+
+	result := tx.Where("id = ? AND task_id = ? AND purged_at IS NULL", id, taskID).First(&attachment)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return attachment, entity.ErrAttachmentNotFound
+	}
+	return attachment, result.Error
+}
+
+// AddAttachment satisfies the AddAttachment AttachmentRepository interface method
+func (repo *SqliteDBRepository) AddAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	if err := attachment.Validate(); err != nil {
+		return err
+	}
+
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		if _, err := editableTask(ctx, tx, attachment.TaskID); err != nil {
+			return err
+		}
+
+		if attachment.ID == uuid.Nil {
+			attachment.ID = uuid.New()
+		}
+		attachment.UploaderID, _ = task.OwnerFromContext(ctx)
+		return tx.Create(attachment).Error
+	})
+}
+
+// GetAttachment satisfies the GetAttachment AttachmentRepository interface method
+func (repo *SqliteDBRepository) GetAttachment(ctx context.Context, taskID, id uuid.UUID) (entity.Attachment, error) {
+	return findAttachment(ctx, repo.Db, taskID, id)
+}
+
+// Attachments satisfies the Attachments AttachmentRepository interface method
+func (repo *SqliteDBRepository) Attachments(ctx context.Context, taskID uuid.UUID) ([]entity.Attachment, error) {
+	attachments := make([]entity.Attachment, 0)
+	if _, err := visibleTask(ctx, repo.Db, taskID); err != nil {
+		return attachments, err
+	}
+
+	result := repo.Db.Where("task_id = ? AND purged_at IS NULL", taskID).Order("created_at").Order("id").Find(&attachments)
+	return attachments, result.Error
+}
+
+// DeleteAttachment satisfies the DeleteAttachment AttachmentRepository interface method
+func (repo *SqliteDBRepository) DeleteAttachment(ctx context.Context, taskID, id uuid.UUID) error {
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		attachment, err := findAttachment(ctx, tx, taskID, id)
+		if err != nil {
+			return err
+		}
+		if _, err := editableTask(ctx, tx, taskID); err != nil {
+			return err
+		}
+		return tx.Delete(&attachment).Error
+	})
+}
+
+// PurgedAttachments satisfies the PurgedAttachments AttachmentRepository
+// interface method
+func (repo *SqliteDBRepository) PurgedAttachments(ctx context.Context) ([]entity.Attachment, error) {
+	attachments := make([]entity.Attachment, 0)
+	result := repo.Db.Where("purged_at IS NOT NULL").Order("purged_at").Order("id").Find(&attachments)
+	return attachments, result.Error
+}
+
+// ForgetAttachment satisfies the ForgetAttachment AttachmentRepository
+// interface method
+func (repo *SqliteDBRepository) ForgetAttachment(ctx context.Context, id uuid.UUID) error {
+	result := repo.Db.Where("id = ? AND purged_at IS NOT NULL", id).Delete(&entity.Attachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrAttachmentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryAttachments(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	editorID, viewerID := uuid.New(), uuid.New()
+	owner := task.WithOwner(context.Background(), uuid.New())
+	editor := task.WithOwner(context.Background(), editorID)
+	viewer := task.WithOwner(context.Background(), viewerID)
+
+	documented := entity.NewTask("Documented")
+	assert.NoError(t, repo.Post(owner, documented))
+	assert.NoError(t, repo.Share(owner, documented.ID, editorID, entity.RoleEditor))
+	assert.NoError(t, repo.Share(owner, documented.ID, viewerID, entity.RoleViewer))
+
+	screenshot, err := entity.NewAttachment(documented.ID, "C:\\Users\\me\\screenshot.png", "image/png", 2048)
+	assert.NoError(t, err)
+	assert.Equal(t, "screenshot.png", screenshot.Filename, "only the base name is kept")
+
+	assert.ErrorIs(t, repo.AddAttachment(viewer, screenshot), entity.ErrForbidden)
+	assert.NoError(t, repo.AddAttachment(editor, screenshot))
+	assert.Equal(t, editorID, screenshot.UploaderID)
+	assert.ErrorIs(t, repo.AddAttachment(owner, &entity.Attachment{TaskID: documented.ID, Filename: "huge.iso", Size: entity.MaxAttachmentSize + 1}), entity.ErrAttachmentTooLarge)
+
+	attachments, err := repo.Attachments(viewer, documented.ID)
+	assert.NoError(t, err)
+	assert.Len(t, attachments, 1)
+
+	found, err := repo.GetAttachment(viewer, documented.ID, screenshot.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", found.ContentType)
+	_, err = repo.GetAttachment(viewer, documented.ID, uuid.New())
+	assert.ErrorIs(t, err, entity.ErrAttachmentNotFound)
+
+	assert.ErrorIs(t, repo.DeleteAttachment(viewer, documented.ID, screenshot.ID), entity.ErrForbidden)
+	assert.NoError(t, repo.DeleteAttachment(editor, documented.ID, screenshot.ID))
+	_, err = repo.GetAttachment(owner, documented.ID, screenshot.ID)
+	assert.ErrorIs(t, err, entity.ErrAttachmentNotFound)
+
+	// Purging the Task hides its attachments until their contents are deleted.
+	notes, _ := entity.NewAttachment(documented.ID, "notes.txt", "text/plain; charset=utf-8", 12)
+	assert.NoError(t, repo.AddAttachment(owner, notes))
+	assert.NoError(t, repo.Delete(owner, documented.ID))
+	assert.NoError(t, repo.Purge(owner, documented.ID))
+	assert.NoError(t, repo.Post(owner, documented))
+	attachments, err = repo.Attachments(owner, documented.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, attachments)
+	_, err = repo.GetAttachment(owner, documented.ID, notes.ID)
+	assert.ErrorIs(t, err, entity.ErrAttachmentNotFound)
+
+	purged, err := repo.PurgedAttachments(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, purged, 1) {
+		assert.Equal(t, notes.ID, purged[0].ID)
+		assert.NotNil(t, purged[0].PurgedAt)
+	}
+	assert.NoError(t, repo.ForgetAttachment(context.Background(), notes.ID))
+	assert.ErrorIs(t, repo.ForgetAttachment(context.Background(), notes.ID), entity.ErrAttachmentNotFound)
+	purged, err = repo.PurgedAttachments(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, purged)
+}
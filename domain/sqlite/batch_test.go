@@ -0,0 +1,59 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryBatch(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	task0 := entity.NewTask("Batch task 0")
+	task1 := entity.NewTask("Batch task 1")
+	task2 := entity.NewTask("Batch task 2")
+
+	err = repo.PostMany(context.Background(), []*entity.Task{task0, task1})
+	assert.NoError(t, err)
+
+	err = repo.PostMany(context.Background(), []*entity.Task{task2, task0})
+	assert.ErrorIs(t, err, entity.ErrTaskUniqueConstraint)
+	_, err = repo.Get(context.Background(), task2.ID)
+	assert.Error(t, err, "the whole PostMany should have been rolled back")
+
+	updated := *task0
+	updated.Completed = true
+	err = task.Batch(context.Background(), repo, []task.Operation{
+		{Action: task.ActionCreate, Task: task2},
+		{Action: task.ActionUpdate, Task: &updated},
+		{Action: task.ActionDelete, ID: uuid.New()},
+	})
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	_, err = repo.Get(context.Background(), task2.ID)
+	assert.Error(t, err, "the whole Batch should have been rolled back")
+	record, _ := repo.Get(context.Background(), task0.ID)
+	assert.False(t, record.Completed)
+
+	err = repo.DeleteMany(context.Background(), []uuid.UUID{task0.ID, uuid.New()})
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+	_, err = repo.Get(context.Background(), task0.ID)
+	assert.NoError(t, err, "the whole DeleteMany should have been rolled back")
+
+	err = repo.DeleteMany(context.Background(), []uuid.UUID{task0.ID, task1.ID, task0.ID})
+	assert.NoError(t, err, "the duplicate IDs are deleted once")
+	_, err = repo.Get(context.Background(), task1.ID)
+	assert.Error(t, err)
+}
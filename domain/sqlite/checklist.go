@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// withChecklist loads the checklist of the Tasks, in order.
+func withChecklist(db *gorm.DB) *gorm.DB {
+	return db.Preload("Checklist", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	})
+}
+
+// loadChecklists loads the checklists of the Tasks found by a search, which
+// cannot be preloaded as the search query is raw SQL.
+func loadChecklists(db *gorm.DB, results []entity.SearchResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Task.ID)
+	}
+
+	var items []entity.ChecklistItem
+	if result := db.Where("task_id IN ?", ids).Order("position").Find(&items); result.Error != nil {
+		return result.Error
+	}
+	checklists := make(map[uuid.UUID][]entity.ChecklistItem)
+	for _, item := range items {
+		checklists[item.TaskID] = append(checklists[item.TaskID], item)
+	}
+	for i := range results {
+		results[i].Task.Checklist = checklists[results[i].Task.ID]
+	}
+	return nil
+}
+
+// findChecklistItem returns an item of the checklist of a Task which the
+// context may edit.
+func findChecklistItem(ctx context.Context, tx *gorm.DB, taskID, id uuid.UUID) (entity.ChecklistItem, error) {
+	var item entity.ChecklistItem
+	if _, err := editableTask(ctx, tx, taskID); err != nil {
+		return item, err
+	}
+
+	result := tx.Where("id = ? AND task_id = ?", id, taskID).First(&item)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return item, entity.ErrChecklistItemNotFound
+	}
+	return item, result.Error
+}
+
+// AddChecklistItem satisfies the AddChecklistItem TaskRepository interface method
+func (repo *SqliteDBRepository) AddChecklistItem(ctx context.Context, item *entity.ChecklistItem) error {
+	if err := entity.ValidateChecklistText(item.Text); err != nil {
+		return err
+	}
+
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		if _, err := editableTask(ctx, tx, item.TaskID); err != nil {
+			return err
+		}
+
+		var count int64
+		if result := tx.Model(&entity.ChecklistItem{}).Where("task_id = ?", item.TaskID).Count(&count); result.Error != nil {
+			return result.Error
+		}
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+		item.Position = int(count)
+		return tx.Create(item).Error
+	})
+}
+
+// EditChecklistItem satisfies the EditChecklistItem TaskRepository interface method
+func (repo *SqliteDBRepository) EditChecklistItem(ctx context.Context, taskID, id uuid.UUID, text string) (entity.ChecklistItem, error) {
+	if err := entity.ValidateChecklistText(text); err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	var item entity.ChecklistItem
+	err := repo.Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if item, err = findChecklistItem(ctx, tx, taskID, id); err != nil {
+			return err
+		}
+		item.Text = text
+		return tx.Model(&item).Select("text").Updates(&item).Error
+	})
+	return item, err
+}
+
+// ToggleChecklistItem satisfies the ToggleChecklistItem TaskRepository interface method
+func (repo *SqliteDBRepository) ToggleChecklistItem(ctx context.Context, taskID, id uuid.UUID) (entity.ChecklistItem, error) {
+	var item entity.ChecklistItem
+	err := repo.Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if item, err = findChecklistItem(ctx, tx, taskID, id); err != nil {
+			return err
+		}
+		item.Done = !item.Done
+		return tx.Model(&item).Select("done").Updates(&item).Error
+	})
+	return item, err
+}
+
+// ReorderChecklist satisfies the ReorderChecklist TaskRepository interface method
+func (repo *SqliteDBRepository) ReorderChecklist(ctx context.Context, taskID uuid.UUID, order []uuid.UUID) ([]entity.ChecklistItem, error) {
+	var checklist []entity.ChecklistItem
+	err := repo.Db.Transaction(func(tx *gorm.DB) error {
+		if _, err := editableTask(ctx, tx, taskID); err != nil {
+			return err
+		}
+		if result := tx.Where("task_id = ?", taskID).Order("position").Find(&checklist); result.Error != nil {
+			return result.Error
+		}
+
+		var err error
+		if checklist, err = entity.Reorder(checklist, order); err != nil {
+			return err
+		}
+		for i := range checklist {
+			if result := tx.Model(&checklist[i]).Select("position").Updates(&checklist[i]); result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+	return checklist, err
+}
+
+// DeleteChecklistItem satisfies the DeleteChecklistItem TaskRepository interface method
+func (repo *SqliteDBRepository) DeleteChecklistItem(ctx context.Context, taskID, id uuid.UUID) error {
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		item, err := findChecklistItem(ctx, tx, taskID, id)
+		if err != nil {
+			return err
+		}
+		if result := tx.Delete(&item); result.Error != nil {
+			return result.Error
+		}
+
+		// The items after it move up, so that positions stay contiguous.
+		return tx.Model(&entity.ChecklistItem{}).Where("task_id = ? AND position > ?", taskID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
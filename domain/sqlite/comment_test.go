@@ -0,0 +1,85 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryComments(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	ownerID, viewerID := uuid.New(), uuid.New()
+	owner := task.WithOwner(context.Background(), ownerID)
+	viewer := task.WithOwner(context.Background(), viewerID)
+	stranger := task.WithOwner(context.Background(), uuid.New())
+
+	discussed := entity.NewTask("Discussed")
+	assert.NoError(t, repo.Post(owner, discussed))
+	assert.NoError(t, repo.Share(owner, discussed.ID, viewerID, entity.RoleViewer))
+
+	question, err := entity.NewComment(discussed.ID, "Should we *really* do this?")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.AddComment(viewer, question))
+	assert.Equal(t, viewerID, question.AuthorID)
+
+	answer, _ := entity.NewComment(discussed.ID, "Yes.")
+	assert.NoError(t, repo.AddComment(owner, answer))
+	lost, _ := entity.NewComment(discussed.ID, "Let me in")
+	assert.ErrorIs(t, repo.AddComment(stranger, lost), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.AddComment(owner, &entity.Comment{TaskID: discussed.ID, Body: " "}), entity.ErrInvalidComment)
+
+	comments, err := repo.Comments(viewer, discussed.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.Equal(t, question.ID, comments[0].ID)
+		assert.Equal(t, answer.ID, comments[1].ID)
+	}
+
+	_, err = repo.EditComment(owner, discussed.ID, question.ID, "Hijacked")
+	assert.ErrorIs(t, err, entity.ErrForbidden, "only the author edits a comment")
+	time.Sleep(time.Millisecond)
+	edited, err := repo.EditComment(viewer, discussed.ID, question.ID, "Should we do this?")
+	assert.NoError(t, err)
+	assert.NotNil(t, edited.EditedAt)
+	_, err = repo.EditComment(viewer, discussed.ID, question.ID, "Should we do this now?")
+	assert.NoError(t, err)
+
+	edits, err := repo.CommentEdits(viewer, discussed.ID, question.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, edits, 2) {
+		assert.Equal(t, "Should we *really* do this?", edits[0].Body)
+		assert.Equal(t, "Should we do this?", edits[1].Body)
+	}
+
+	found, err := repo.GetComment(viewer, discussed.ID, question.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Should we do this now?", found.Body)
+
+	assert.ErrorIs(t, repo.DeleteComment(viewer, discussed.ID, answer.ID), entity.ErrForbidden)
+	assert.NoError(t, repo.DeleteComment(owner, discussed.ID, answer.ID), "the owner of the Task moderates it")
+	_, err = repo.GetComment(owner, discussed.ID, answer.ID)
+	assert.ErrorIs(t, err, entity.ErrCommentNotFound)
+
+	// Purging the Task deletes its comments.
+	assert.NoError(t, repo.Delete(owner, discussed.ID))
+	assert.NoError(t, repo.Purge(owner, discussed.ID))
+	assert.NoError(t, repo.Post(owner, discussed))
+	comments, err = repo.Comments(owner, discussed.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+}
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// record stores a HistoryEntry for the change of a Task from before to after,
+// within the transaction making the change.
+func record(ctx context.Context, tx *gorm.DB, action entity.HistoryAction, before, after *entity.Task) error {
+	changed := after
+	if changed == nil {
+		changed = before
+	}
+
+	var version int
+	result := tx.Model(&entity.HistoryEntry{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("task_id = ?", changed.ID).
+		Scan(&version)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	entry := entity.NewHistoryEntry(action, task.ActorFromContext(ctx), version+1, before, after)
+	return tx.Create(&entry).Error
+}
+
+// History satisfies the History TaskRepository interface method
+func (repo *SqliteDBRepository) History(ctx context.Context, id uuid.UUID) ([]entity.HistoryEntry, error) {
+	var entries []entity.HistoryEntry = make([]entity.HistoryEntry, 0)
+
+	// Once purged, a Task has no owner to show its history to.
+	if _, scoped := task.OwnerFromContext(ctx); scoped {
+		var count int64
+		result := repo.Db.Model(&entity.Task{}).Unscoped().Scopes(readable(ctx)).Where("id = ?", id).Count(&count)
+		if result.Error != nil || count == 0 {
+			return entries, result.Error
+		}
+	}
+
+	result := repo.Db.Where("task_id = ?", id).Order("version").Find(&entries)
+	return entries, result.Error
+}
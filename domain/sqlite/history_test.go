@@ -0,0 +1,64 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryHistory(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	ctx := task.WithActor(context.Background(), "alice")
+
+	task0 := entity.NewTask("History task 0")
+	assert.NoError(t, repo.Post(ctx, task0))
+
+	updated := *task0
+	updated.Completed = true
+	assert.NoError(t, repo.Put(ctx, &updated))
+	assert.NoError(t, repo.Delete(ctx, task0.ID))
+	assert.NoError(t, repo.Restore(ctx, task0.ID))
+
+	entries, err := repo.History(context.Background(), task0.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	actions := []entity.HistoryAction{
+		entity.HistoryCreated,
+		entity.HistoryUpdated,
+		entity.HistoryDeleted,
+		entity.HistoryRestored,
+	}
+	for i, action := range actions {
+		assert.Equal(t, i+1, entries[i].Version)
+		assert.Equal(t, action, entries[i].Action)
+		assert.Equal(t, "alice", entries[i].Actor)
+	}
+
+	assert.False(t, entries[1].Before.Completed)
+	assert.True(t, entries[1].After.Completed)
+	assert.Contains(t, entries[1].Changes, "completed")
+	assert.Contains(t, entries[1].Changes, "completed_at")
+
+	reverted, err := task.Revert(ctx, repo, task0.ID, 1)
+	assert.NoError(t, err)
+	assert.False(t, reverted.Completed)
+
+	record, err := repo.Get(context.Background(), task0.ID)
+	assert.NoError(t, err)
+	assert.False(t, record.Completed)
+	assert.Nil(t, record.CompletedAt)
+}
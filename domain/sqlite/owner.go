@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// tenant scopes a query to the Tasks of the workspace of the context.
+func tenant(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if workspace, ok := task.WorkspaceFromContext(ctx); ok {
+			return db.Where("tasks.workspace_id = ?", workspace)
+		}
+		return db
+	}
+}
+
+// owned scopes a query to the Tasks owned by the User of the context.
+func owned(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = tenant(ctx)(db)
+		if owner, ok := task.OwnerFromContext(ctx); ok {
+			return db.Where("tasks.owner_id = ?", owner)
+		}
+		return db
+	}
+}
+
+// readable scopes a query to the Tasks which the context owns, or which are
+// assigned to or shared with its User.
+func readable(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = tenant(ctx)(db)
+		if owner, ok := task.OwnerFromContext(ctx); ok {
+			return db.Where("(tasks.owner_id = ? OR tasks.assignee_id = ? OR tasks.id IN (SELECT task_id FROM shares WHERE user_id = ?))", owner, owner, owner)
+		}
+		return db
+	}
+}
+
+// can reports whether the context has the required Role on the Task. The
+// assignee of a Task is one of its viewers.
+func can(ctx context.Context, tx *gorm.DB, t entity.Task, required entity.Role) (bool, error) {
+	if task.Owns(ctx, t) {
+		return true, nil
+	}
+
+	owner, _ := task.OwnerFromContext(ctx)
+	if task.Assigned(ctx, t) && entity.RoleViewer.Allows(required) {
+		return true, nil
+	}
+	var shares []entity.Share
+	result := tx.Where("task_id = ? AND user_id = ?", t.ID, owner).Limit(1).Find(&shares)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return len(shares) > 0 && shares[0].Role.Allows(required), nil
+}
+
+// own sets the owner and workspace found in the context, if any, on a new
+// Task. New Tasks are unassigned, without a checklist.
+func own(ctx context.Context, t *entity.Task) {
+	t.AssigneeID, t.Checklist = nil, nil
+	if owner, ok := task.OwnerFromContext(ctx); ok {
+		t.OwnerID = owner
+	}
+	if workspace, ok := task.WorkspaceFromContext(ctx); ok {
+		t.WorkspaceID = workspace
+	}
+}
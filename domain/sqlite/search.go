@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	fts5 = "fts5"
+	fts4 = "fts4"
+)
+
+// migrateSearch creates the full-text index over Task descriptions and the
+// triggers keeping it up to date. The index uses searchModule, which depends on
+// the build tags, so an index left by a build using the other module is rebuilt.
+func migrateSearch(db *gorm.DB) error {
+	var existing string
+	err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'task_search'").Scan(&existing).Error
+	if err != nil {
+		return err
+	}
+	if existing != "" && !strings.Contains(strings.ToLower(existing), "using "+searchModule) {
+		log.Println("Rebuilding the search index with", strings.ToUpper(searchModule))
+		if err := db.Exec("DROP TABLE task_search").Error; err != nil {
+			return fmt.Errorf("could not drop the search index of another full-text search module: %w", err)
+		}
+	}
+
+	create := "CREATE VIRTUAL TABLE IF NOT EXISTS task_search USING fts5(id UNINDEXED, description)"
+	if searchModule == fts4 {
+		create = "CREATE VIRTUAL TABLE IF NOT EXISTS task_search USING fts4(id, description, notindexed=id)"
+	}
+	if err := db.Exec(create).Error; err != nil {
+		return err
+	}
+	log.Println("Searching the Tasks with", strings.ToUpper(searchModule))
+
+	statements := []string{
+		`CREATE TRIGGER IF NOT EXISTS task_search_insert AFTER INSERT ON tasks BEGIN
+			INSERT INTO task_search(id, description) VALUES (new.id, new.description);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS task_search_update AFTER UPDATE OF description ON tasks BEGIN
+			UPDATE task_search SET description = new.description WHERE id = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS task_search_delete AFTER DELETE ON tasks BEGIN
+			DELETE FROM task_search WHERE id = old.id;
+		END`,
+		// Index the Tasks created before the index existed.
+		`INSERT INTO task_search(id, description)
+			SELECT id, description FROM tasks WHERE id NOT IN (SELECT id FROM task_search)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchExpression renders a SearchQuery using the FTS query syntax. Words only
+// ever contain letters and digits, so they need no escaping.
+func matchExpression(query entity.SearchQuery) string {
+	clauses := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		clause := `"` + strings.Join(term.Words, " ")
+		switch {
+		case term.Prefix && searchModule == fts5:
+			clause += `"*`
+		case term.Prefix:
+			clause += `*"`
+		default:
+			clause += `"`
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " ")
+}
+
+type searchRow struct {
+	entity.Task
+	SearchRank float64
+	Snippet    string
+	Offsets    string
+}
+
+// Search satisfies the Search TaskRepository interface method
+func (repo *SqliteDBRepository) Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error) {
+	if len(query.Terms) == 0 {
+		return nil, entity.ErrInvalidSearchQuery
+	}
+
+	var sql string
+	if searchModule == fts5 {
+		sql = `SELECT tasks.*, -bm25(task_search) AS search_rank,
+				snippet(task_search, 1, ?, ?, ?, ?) AS snippet
+			FROM task_search JOIN tasks ON tasks.id = task_search.id
+			WHERE task_search MATCH ? AND tasks.deleted_at IS NULL
+				AND (? OR tasks.owner_id = ? OR tasks.assignee_id = ? OR tasks.id IN (SELECT task_id FROM shares WHERE user_id = ?))
+				AND (? OR tasks.workspace_id = ?)
+			ORDER BY search_rank DESC, tasks.description`
+	} else {
+		// FTS4 has no ranking function, so Tasks are ranked by how many
+		// times the query matched, using the offsets of every match.
+		sql = `SELECT tasks.*, offsets(task_search) AS offsets,
+				snippet(task_search, ?, ?, ?, 1, ?) AS snippet
+			FROM task_search JOIN tasks ON tasks.id = task_search.id
+			WHERE task_search MATCH ? AND tasks.deleted_at IS NULL
+				AND (? OR tasks.owner_id = ? OR tasks.assignee_id = ? OR tasks.id IN (SELECT task_id FROM shares WHERE user_id = ?))
+				AND (? OR tasks.workspace_id = ?)
+			ORDER BY tasks.description`
+	}
+
+	owner, scoped := task.OwnerFromContext(ctx)
+	workspace, tenanted := task.WorkspaceFromContext(ctx)
+
+	var rows []searchRow
+	result := repo.Db.Raw(sql,
+		entity.SnippetStartMarker, entity.SnippetEndMarker, entity.SnippetEllipsis, entity.SnippetWords,
+		matchExpression(query), !scoped, owner, owner, owner, !tenanted, workspace,
+	).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	results := make([]entity.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		rank := row.SearchRank
+		if searchModule == fts4 {
+			// Every match is described by four integers.
+			matches := float64(len(strings.Fields(row.Offsets)) / 4)
+			rank = matches / math.Sqrt(float64(len(entity.Tokenize(row.Description))))
+		}
+		results = append(results, entity.SearchResult{
+			Task:    row.Task,
+			Rank:    rank,
+			Snippet: entity.HighlightSnippet(row.Snippet),
+		})
+	}
+
+	if searchModule == fts4 {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Rank > results[j].Rank
+		})
+	}
+	return results, loadChecklists(repo.Db, results)
+}
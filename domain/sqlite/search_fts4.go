@@ -0,0 +1,8 @@
+//go:build !sqlite_fts5
+
+package sqlite
+
+// searchModule is the SQLite full-text search module indexing Tasks. Without
+// the sqlite_fts5 build tag the SQLite driver has no FTS5, so Tasks are indexed
+// with FTS4, which ranks and highlights the matches less precisely.
+const searchModule = fts4
@@ -0,0 +1,7 @@
+//go:build sqlite_fts5
+
+package sqlite
+
+// searchModule is the SQLite full-text search module indexing Tasks. FTS5 is
+// only compiled into the SQLite driver with the sqlite_fts5 build tag.
+const searchModule = fts5
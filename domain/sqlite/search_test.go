@@ -0,0 +1,107 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositorySearch(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	weekly := entity.NewTask("Write the zanzibar weekly report")
+	printer := entity.NewTask("Report the zanzibar printer, the printer is broken")
+	for _, task := range []*entity.Task{weekly, printer} {
+		if err := repo.Post(context.Background(), task); err != nil {
+			t.Fatalf("failed to create a task in the Sqlite database: %v", err)
+		}
+	}
+
+	search := func(q string) []entity.SearchResult {
+		query, err := entity.ParseSearchQuery(q)
+		assert.NoError(t, err)
+		results, err := repo.Search(context.Background(), query)
+		assert.NoError(t, err)
+		return results
+	}
+
+	results := search(`zanzibar "weekly report"`)
+	assert.Len(t, results, 1)
+	assert.Equal(t, weekly.ID, results[0].Task.ID)
+	// FTS5 highlights a phrase as a whole, FTS4 highlights each of its words.
+	assert.Regexp(t, "<mark>weekly(</mark> <mark>| )report</mark>", results[0].Snippet)
+
+	results = search("zanzibar print*")
+	assert.Len(t, results, 1)
+	assert.Equal(t, printer.ID, results[0].Task.ID)
+
+	results = search("zanzibar")
+	assert.Len(t, results, 2)
+	assert.GreaterOrEqual(t, results[0].Rank, results[1].Rank)
+
+	weekly.Description = "Write the zanzibar monthly report"
+	assert.NoError(t, repo.Put(context.Background(), weekly))
+	assert.Len(t, search(`zanzibar "weekly report"`), 0)
+	assert.Len(t, search(`zanzibar monthly`), 1)
+
+	assert.NoError(t, repo.Delete(context.Background(), weekly.ID))
+	assert.Len(t, search(`zanzibar monthly`), 0)
+
+	html := entity.NewTask("Fix the <b>zanzibar</b> & printer\x03 markup")
+	assert.NoError(t, repo.Post(context.Background(), html))
+	results = search("zanzibar markup")
+	assert.Len(t, results, 1)
+	assert.Equal(t, html.ID, results[0].Task.ID)
+	assert.Equal(t, "Fix the &lt;b&gt;<mark>zanzibar</mark>&lt;/b&gt; &amp; printer <mark>markup</mark>", results[0].Snippet)
+}
+
+func TestSqliteDbRepositorySearchRebuildsIndex(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	task := entity.NewTask("Water the quokka plants")
+	assert.NoError(t, repo.Post(context.Background(), task))
+
+	// An index left by a build using another full-text search module.
+	assert.NoError(t, repo.Db.Exec("DROP TABLE task_search").Error)
+	assert.NoError(t, repo.Db.Exec("CREATE VIRTUAL TABLE task_search USING fts3(id, description)").Error)
+
+	// The in-memory database is shared while a connection to it is open.
+	rebuilt, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+	defer func() {
+		sqlDB, _ := rebuilt.Db.DB()
+		sqlDB.Close()
+	}()
+
+	var schema string
+	assert.NoError(t, rebuilt.Db.Raw("SELECT sql FROM sqlite_master WHERE name = 'task_search'").Scan(&schema).Error)
+	assert.NotContains(t, schema, "fts3")
+
+	query, err := entity.ParseSearchQuery("quokka")
+	assert.NoError(t, err)
+	results, err := rebuilt.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, task.ID, results[0].Task.ID)
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ownedTask returns the Task with the given ID if the context owns it,
+// whether it is in the trash or not.
+func ownedTask(ctx context.Context, tx *gorm.DB, id uuid.UUID) (entity.Task, error) {
+	var t entity.Task
+	if result := tx.Unscoped().Scopes(readable(ctx)).Where("id = ?", id).First(&t); result.Error != nil {
+		return t, entity.ErrTaskNotFound
+	}
+	if !task.Owns(ctx, t) {
+		return t, entity.ErrForbidden
+	}
+	return t, nil
+}
+
+// Share satisfies the Share TaskRepository interface method
+func (repo *SqliteDBRepository) Share(ctx context.Context, id uuid.UUID, userID uuid.UUID, role entity.Role) error {
+	if err := role.Validate(); err != nil {
+		return err
+	}
+
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		t, err := ownedTask(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if t.OwnerID == userID {
+			return entity.ErrInvalidShare
+		}
+
+		share := entity.Share{TaskID: id, UserID: userID, Role: role, CreatedAt: time.Now()}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).Create(&share).Error
+	})
+}
+
+// Unshare satisfies the Unshare TaskRepository interface method
+func (repo *SqliteDBRepository) Unshare(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		if _, err := ownedTask(ctx, tx, id); err != nil {
+			return err
+		}
+
+		result := tx.Where("task_id = ? AND user_id = ?", id, userID).Delete(&entity.Share{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrShareNotFound
+		}
+		return nil
+	})
+}
+
+// Shares satisfies the Shares TaskRepository interface method
+func (repo *SqliteDBRepository) Shares(ctx context.Context, id uuid.UUID) ([]entity.Share, error) {
+	var shares []entity.Share = make([]entity.Share, 0)
+
+	var count int64
+	result := repo.Db.Model(&entity.Task{}).Unscoped().Scopes(readable(ctx)).Where("id = ?", id).Count(&count)
+	if result.Error != nil {
+		return shares, result.Error
+	}
+	if count == 0 {
+		return shares, entity.ErrTaskNotFound
+	}
+
+	result = repo.Db.Where("task_id = ?", id).Order("created_at").Find(&shares)
+	return shares, result.Error
+}
@@ -0,0 +1,68 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryShares(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	owner := task.WithOwner(context.Background(), uuid.New())
+	editorID, viewerID := uuid.New(), uuid.New()
+	editor := task.WithOwner(context.Background(), editorID)
+	viewer := task.WithOwner(context.Background(), viewerID)
+	stranger := task.WithOwner(context.Background(), uuid.New())
+
+	shared := entity.NewTask("Shared")
+	assert.NoError(t, repo.Post(owner, shared))
+
+	assert.ErrorIs(t, repo.Share(owner, shared.ID, editorID, entity.RoleOwner), entity.ErrInvalidRole)
+	assert.ErrorIs(t, repo.Share(viewer, shared.ID, viewerID, entity.RoleEditor), entity.ErrTaskNotFound)
+	assert.NoError(t, repo.Share(owner, shared.ID, editorID, entity.RoleEditor))
+	assert.NoError(t, repo.Share(owner, shared.ID, viewerID, entity.RoleViewer))
+	assert.ErrorIs(t, repo.Share(editor, shared.ID, uuid.New(), entity.RoleViewer), entity.ErrForbidden,
+		"only the owner may share a Task")
+
+	_, err = repo.Get(viewer, shared.ID)
+	assert.NoError(t, err)
+	_, err = repo.Get(stranger, shared.ID)
+	assert.Error(t, err)
+
+	tasks, _, err := repo.Find(viewer, task.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	update := *shared
+	update.Description = "Edited"
+	assert.ErrorIs(t, repo.Put(viewer, &update), entity.ErrForbidden)
+	assert.NoError(t, repo.Put(editor, &update))
+	assert.ErrorIs(t, repo.Delete(editor, shared.ID), entity.ErrForbidden)
+
+	edited, err := repo.Get(owner, shared.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Edited", edited.Description)
+
+	shares, err := repo.Shares(viewer, shared.ID)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 2)
+
+	assert.NoError(t, repo.Unshare(owner, shared.ID, viewerID))
+	assert.ErrorIs(t, repo.Unshare(owner, shared.ID, viewerID), entity.ErrShareNotFound)
+	_, err = repo.Get(viewer, shared.ID)
+	assert.Error(t, err)
+}
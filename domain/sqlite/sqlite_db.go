@@ -2,9 +2,11 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/task"
 	"github.com/omaciel/GoDoIt/entity"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -21,19 +23,31 @@ func NewSqliteDBRepository() (*SqliteDBRepository, error) {
 	db, err := gorm.Open(
 		sqlite.Open("file::memory:?cache=shared"),
 		&gorm.Config{
-			Logger: logger.Default.LogMode(logger.Info),
+			Logger:         logger.Default.LogMode(logger.Info),
+			TranslateError: true,
 		})
 	if err != nil {
 		log.Fatal("Failed to connect to the database. \n", err)
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&entity.Task{})
+	err = db.AutoMigrate(&entity.Task{}, &entity.HistoryEntry{}, &entity.User{}, &entity.APIToken{}, &entity.Share{},
+		&entity.Comment{}, &entity.CommentEdit{}, &entity.Attachment{}, &entity.ChecklistItem{}, &entity.Webhook{}, &entity.WebhookDelivery{})
 	if err != nil {
 		log.Fatal("Failed to migrate the database schema. \n", err)
 		return nil, err
 	}
 
+	if err := migrateSearch(db); err != nil {
+		log.Fatal("Failed to create the search index. \n", err)
+		return nil, err
+	}
+
+	if err := migrateWorkspaceRoles(db); err != nil {
+		log.Fatal("Failed to migrate the workspace roles. \n", err)
+		return nil, err
+	}
+
 	return &SqliteDBRepository{
 		Db: db,
 	}, nil
@@ -43,7 +57,7 @@ func NewSqliteDBRepository() (*SqliteDBRepository, error) {
 func (repo *SqliteDBRepository) Get(ctx context.Context, id uuid.UUID) (entity.Task, error) {
 	var task entity.Task
 
-	result := repo.Db.Where("id = ?", id).First(&task)
+	result := repo.Db.Scopes(readable(ctx), withChecklist).Where("id = ?", id).First(&task)
 	if result.Error != nil {
 		return task, result.Error
 	}
@@ -55,30 +69,67 @@ func (repo *SqliteDBRepository) Post(ctx context.Context, task *entity.Task) err
 	if task.ID == uuid.Nil {
 		task.ID = uuid.New()
 	}
-	result := repo.Db.Create(&task)
-	return result.Error
+	own(ctx, task)
+
+	err := repo.Db.Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(&task); result.Error != nil {
+			return result.Error
+		}
+		return record(ctx, tx, entity.HistoryCreated, nil, task)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return entity.ErrTaskUniqueConstraint
+	}
+	return err
 }
 
 // Delete satisfies the Delete TaskRepository interface method
 func (repo *SqliteDBRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := repo.Db.Where("id = ?", id).Delete(entity.Task{})
-	if result.Error != nil {
-		return entity.ErrCouldNotDeleteTask
-	}
-	return nil
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		var before entity.Task
+		if result := tx.Scopes(readable(ctx)).Where("id = ?", id).First(&before); result.Error != nil {
+			return entity.ErrTaskNotFound
+		}
+		if !task.Owns(ctx, before) {
+			return entity.ErrForbidden
+		}
+
+		result := tx.Where("id = ?", id).Delete(&entity.Task{})
+		if result.Error != nil {
+			return entity.ErrCouldNotDeleteTask
+		}
+		return record(ctx, tx, entity.HistoryDeleted, &before, nil)
+	})
 }
 
 // All satisfies the All TaskRepository interface
 func (repo *SqliteDBRepository) All(ctx context.Context) ([]entity.Task, error) {
-	var tasks []entity.Task = make([]entity.Task, 0)
-	repo.Db.Find(&tasks)
-	return tasks, nil
+	tasks, _, err := repo.Find(ctx, task.Filter{})
+	return tasks, err
 }
 
 // Put satisfies the Put TaskRepository interface method
 func (repo *SqliteDBRepository) Put(ctx context.Context, task *entity.Task) error {
-	if result := repo.Db.Save(&task); result.Error != nil {
-		return result.Error
-	}
-	return nil
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		var before entity.Task
+		if result := tx.Scopes(readable(ctx), withChecklist).Where("id = ?", task.ID).First(&before); result.Error != nil {
+			return entity.ErrTaskNotFound
+		}
+		if ok, err := can(ctx, tx, before, entity.RoleEditor); err != nil || !ok {
+			if err == nil {
+				err = entity.ErrForbidden
+			}
+			return err
+		}
+		task.OwnerID, task.WorkspaceID = before.OwnerID, before.WorkspaceID
+		task.AssigneeID, task.Checklist = before.AssigneeID, before.Checklist
+		task.DeletedAt = before.DeletedAt
+		task.CalendarUID, task.CalendarName = before.CalendarUID, before.CalendarName
+
+		if result := tx.Omit("created_at", "deleted_at", "Checklist").Save(&task); result.Error != nil {
+			return result.Error
+		}
+		task.CreatedAt = before.CreatedAt
+		return record(ctx, tx, entity.HistoryUpdated, &before, task)
+	})
 }
@@ -0,0 +1,48 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryTokens(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	userID := uuid.New()
+	expires := time.Now().Add(time.Hour)
+	token, secret, err := entity.NewAPIToken(userID, "script", entity.ScopeWrite, &expires)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateToken(context.Background(), token))
+
+	found, err := repo.TokenByHash(context.Background(), entity.HashAPIToken(secret))
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.Equal(t, entity.ScopeWrite, found.Scope)
+	assert.True(t, found.Active(time.Now()))
+	assert.False(t, found.Active(expires))
+
+	assert.NoError(t, repo.TouchToken(context.Background(), token.ID, time.Now()))
+	assert.ErrorIs(t, repo.RevokeToken(context.Background(), uuid.New(), token.ID), entity.ErrTokenNotFound)
+	assert.NoError(t, repo.RevokeToken(context.Background(), userID, token.ID))
+
+	tokens, err := repo.Tokens(context.Background(), userID)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.NotNil(t, tokens[0].LastUsedAt)
+		assert.NotNil(t, tokens[0].RevokedAt)
+	}
+}
@@ -0,0 +1,76 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestSqliteDbRepositoryTrash(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	task0 := entity.NewTask("Trash quokka task 0")
+	task1 := entity.NewTask("Trash quokka task 1")
+	assert.NoError(t, repo.PostMany(context.Background(), []*entity.Task{task0, task1}))
+
+	inTrash := func(task *entity.Task) bool {
+		trash, err := repo.Trash(context.Background())
+		assert.NoError(t, err)
+		for _, trashed := range trash {
+			if trashed.ID == task.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.NoError(t, repo.Delete(context.Background(), task0.ID))
+	_, err = repo.Get(context.Background(), task0.ID)
+	assert.Error(t, err)
+	assert.True(t, inTrash(task0))
+
+	query, _ := entity.ParseSearchQuery("quokka")
+	results, err := repo.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1, "deleted tasks should not be searchable")
+
+	assert.ErrorIs(t, repo.Restore(context.Background(), task1.ID), entity.ErrTaskNotFound)
+	assert.NoError(t, repo.Restore(context.Background(), task0.ID))
+	assert.False(t, inTrash(task0))
+	_, err = repo.Get(context.Background(), task0.ID)
+	assert.NoError(t, err)
+
+	sent := *task0
+	sent.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	assert.NoError(t, repo.Put(context.Background(), &sent))
+	assert.False(t, inTrash(task0), "the Tasks are not moved to the trash by Put")
+
+	assert.ErrorIs(t, repo.Purge(context.Background(), task0.ID), entity.ErrTaskNotFound)
+	assert.NoError(t, repo.DeleteMany(context.Background(), []uuid.UUID{task0.ID, task1.ID}))
+	assert.NoError(t, repo.Purge(context.Background(), task0.ID))
+	assert.False(t, inTrash(task0))
+
+	purged, err := repo.PurgeDeletedBefore(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+	assert.True(t, inTrash(task1))
+
+	purged, err = repo.PurgeDeletedBefore(context.Background(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(1))
+	assert.False(t, inTrash(task1))
+}
@@ -0,0 +1,17 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"gorm.io/gorm"
+)
+
+// WithTx satisfies the WithTx TaskRepository interface method. fn runs inside
+// a database transaction which is rolled back if it returns an error. Nested
+// calls use savepoints.
+func (repo *SqliteDBRepository) WithTx(ctx context.Context, fn func(repo task.TaskRepository) error) error {
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		return fn(&SqliteDBRepository{Db: tx})
+	})
+}
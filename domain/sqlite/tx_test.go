@@ -0,0 +1,63 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryWithTx(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	errAbort := errors.New("abort")
+	task0 := entity.NewTask("Transaction task 0")
+	task1 := entity.NewTask("Transaction task 1")
+	assert.NoError(t, repo.Post(context.Background(), task0))
+
+	err = repo.WithTx(context.Background(), func(tx task.TaskRepository) error {
+		done := *task0
+		done.Completed = true
+		if err := tx.Put(context.Background(), &done); err != nil {
+			return err
+		}
+		if err := tx.Post(context.Background(), task1); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	record, err := repo.Get(context.Background(), task0.ID)
+	assert.NoError(t, err)
+	assert.False(t, record.Completed, "the update should have been rolled back")
+	_, err = repo.Get(context.Background(), task1.ID)
+	assert.Error(t, err, "the insert should have been rolled back")
+
+	err = repo.WithTx(context.Background(), func(tx task.TaskRepository) error {
+		done := *task0
+		done.Completed = true
+		if err := tx.Put(context.Background(), &done); err != nil {
+			return err
+		}
+		return tx.Post(context.Background(), task1)
+	})
+	assert.NoError(t, err)
+
+	record, _ = repo.Get(context.Background(), task0.ID)
+	assert.True(t, record.Completed)
+	_, err = repo.Get(context.Background(), task1.ID)
+	assert.NoError(t, err)
+}
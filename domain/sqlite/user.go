@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// CreateUser satisfies the CreateUser UserRepository interface method
+func (repo *SqliteDBRepository) CreateUser(ctx context.Context, user *entity.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+
+	result := repo.Db.Create(user)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return entity.ErrUsernameTaken
+	}
+	return result.Error
+}
+
+// GetUser satisfies the GetUser UserRepository interface method
+func (repo *SqliteDBRepository) GetUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	var user entity.User
+
+	result := repo.Db.Where("id = ?", id).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return user, entity.ErrUserNotFound
+	}
+	return user, result.Error
+}
+
+// UserByName satisfies the UserByName UserRepository interface method
+func (repo *SqliteDBRepository) UserByName(ctx context.Context, username string) (entity.User, error) {
+	var user entity.User
+
+	result := repo.Db.Where("username = ?", username).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return user, entity.ErrUserNotFound
+	}
+	return user, result.Error
+}
+
+// RemoveUser satisfies the RemoveUser UserRepository interface method
+func (repo *SqliteDBRepository) RemoveUser(ctx context.Context, id uuid.UUID) error {
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&entity.APIToken{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// migrateWorkspaceRoles gives a WorkspaceRole to the Users created before
+// there were any: the first User of each workspace registered it, and is its
+// admin.
+func migrateWorkspaceRoles(db *gorm.DB) error {
+	statements := []string{
+		`UPDATE users SET workspace_role = 'admin'
+			WHERE COALESCE(workspace_role, '') = ''
+			AND NOT EXISTS (SELECT 1 FROM users AS earlier
+				WHERE earlier.workspace_id = users.workspace_id AND earlier.created_at < users.created_at)`,
+		`UPDATE users SET workspace_role = 'member' WHERE COALESCE(workspace_role, '') = ''`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryUsers(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	username := "user-" + uuid.NewString()[:8]
+	user, err := entity.NewUser(username, "correct horse")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateUser(context.Background(), user))
+
+	taken, _ := entity.NewUser(username, "another horse")
+	assert.ErrorIs(t, repo.CreateUser(context.Background(), taken), entity.ErrUsernameTaken)
+
+	found, err := repo.UserByName(context.Background(), username)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+	assert.True(t, found.CheckPassword("correct horse"))
+
+	_, err = repo.GetUser(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+
+	token, _, err := entity.NewAPIToken(user.ID, "script", entity.ScopeRead, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateToken(context.Background(), token))
+	assert.NoError(t, repo.RemoveUser(context.Background(), user.ID))
+	_, err = repo.UserByName(context.Background(), username)
+	assert.ErrorIs(t, err, entity.ErrUserNotFound)
+	tokens, err := repo.Tokens(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, tokens, "the API tokens of a removed User are deleted")
+	assert.ErrorIs(t, repo.RemoveUser(context.Background(), user.ID), entity.ErrUserNotFound)
+}
+
+func TestSqliteDbRepositoryWorkspaceRolesMigration(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	// Users created before there were workspace roles have none.
+	founder, _ := entity.NewUser("founder-"+uuid.NewString()[:8], "correct horse")
+	joiner, _ := entity.NewUser("joiner-"+uuid.NewString()[:8], "correct horse")
+	joiner.WorkspaceID = founder.WorkspaceID
+	assert.NoError(t, repo.CreateUser(context.Background(), founder))
+	assert.NoError(t, repo.CreateUser(context.Background(), joiner))
+	assert.NoError(t, repo.Db.Exec("UPDATE users SET workspace_role = NULL WHERE id IN (?, ?)", founder.ID, joiner.ID).Error)
+
+	// The in-memory database is shared while a connection to it is open.
+	migrated, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+	defer func() {
+		sqlDB, _ := migrated.Db.DB()
+		sqlDB.Close()
+	}()
+
+	found, err := migrated.GetUser(context.Background(), founder.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.WorkspaceAdmin, found.WorkspaceRole)
+	found, err = migrated.GetUser(context.Background(), joiner.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.WorkspaceMember, found.WorkspaceRole)
+}
+
+func TestSqliteDbRepositoryOwnership(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	alice := task.WithOwner(context.Background(), uuid.New())
+	bob := task.WithOwner(context.Background(), uuid.New())
+
+	task0 := entity.NewTask("Ownership wombat task 0")
+	assert.NoError(t, repo.Post(alice, task0))
+	assert.NoError(t, repo.Post(bob, entity.NewTask("Ownership wombat task 1")))
+
+	_, err = repo.Get(bob, task0.ID)
+	assert.Error(t, err)
+
+	stolen := *task0
+	stolen.Description = "stolen"
+	assert.ErrorIs(t, repo.Put(bob, &stolen), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Delete(bob, task0.ID), entity.ErrTaskNotFound)
+
+	tasks, _ := repo.All(bob)
+	assert.Len(t, tasks, 1)
+
+	query, _ := entity.ParseSearchQuery("wombat")
+	results, err := repo.Search(bob, query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	results, err = repo.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2, "a context without an owner sees every Task")
+
+	entries, _ := repo.History(bob, task0.ID)
+	assert.Empty(t, entries)
+	entries, _ = repo.History(alice, task0.ID)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, repo.Delete(alice, task0.ID))
+	assert.ErrorIs(t, repo.Restore(bob, task0.ID), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Purge(bob, task0.ID), entity.ErrTaskNotFound)
+	assert.NoError(t, repo.Restore(alice, task0.ID))
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// CreateWebhook satisfies the CreateWebhook WebhookRepository interface method
+func (repo *SqliteDBRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+	return repo.Db.Create(webhook).Error
+}
+
+// Webhooks satisfies the Webhooks WebhookRepository interface method
+func (repo *SqliteDBRepository) Webhooks(ctx context.Context, userID uuid.UUID) ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook = make([]entity.Webhook, 0)
+	result := repo.Db.Where("user_id = ?", userID).Order("created_at").Find(&webhooks)
+	return webhooks, result.Error
+}
+
+// GetWebhook satisfies the GetWebhook WebhookRepository interface method
+func (repo *SqliteDBRepository) GetWebhook(ctx context.Context, userID, id uuid.UUID) (entity.Webhook, error) {
+	var webhook entity.Webhook
+
+	result := repo.Db.Where("id = ? AND user_id = ?", id, userID).First(&webhook)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return webhook, entity.ErrWebhookNotFound
+	}
+	return webhook, result.Error
+}
+
+// DeleteWebhook satisfies the DeleteWebhook WebhookRepository interface method
+func (repo *SqliteDBRepository) DeleteWebhook(ctx context.Context, userID, id uuid.UUID) error {
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&entity.Webhook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrWebhookNotFound
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&entity.WebhookDelivery{}).Error
+	})
+}
+
+// SaveDelivery satisfies the SaveDelivery WebhookRepository interface method
+func (repo *SqliteDBRepository) SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+
+	return repo.Db.Transaction(func(tx *gorm.DB) error {
+		var webhooks int64
+		if err := tx.Model(&entity.Webhook{}).Where("id = ?", delivery.WebhookID).Count(&webhooks).Error; err != nil {
+			return err
+		}
+		if webhooks == 0 {
+			return entity.ErrWebhookNotFound
+		}
+		return tx.Save(delivery).Error
+	})
+}
+
+// GetDelivery satisfies the GetDelivery WebhookRepository interface method
+func (repo *SqliteDBRepository) GetDelivery(ctx context.Context, webhookID, id uuid.UUID) (entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+
+	result := repo.Db.Where("id = ? AND webhook_id = ?", id, webhookID).First(&delivery)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return delivery, entity.ErrDeliveryNotFound
+	}
+	return delivery, result.Error
+}
+
+// Deliveries satisfies the Deliveries WebhookRepository interface method
+func (repo *SqliteDBRepository) Deliveries(ctx context.Context, webhookID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery = make([]entity.WebhookDelivery, 0)
+	result := repo.Db.Where("webhook_id = ?", webhookID).Order("created_at DESC").Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// DueDeliveries satisfies the DueDeliveries WebhookRepository interface method
+func (repo *SqliteDBRepository) DueDeliveries(ctx context.Context, at time.Time) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery = make([]entity.WebhookDelivery, 0)
+	result := repo.Db.Where("status = ? AND next_attempt_at <= ?", entity.DeliveryPending, at).
+		Order("next_attempt_at").Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// DeadDeliveries satisfies the DeadDeliveries WebhookRepository interface
+// method
+func (repo *SqliteDBRepository) DeadDeliveries(ctx context.Context, userID uuid.UUID) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery = make([]entity.WebhookDelivery, 0)
+	result := repo.Db.Where("user_id = ? AND status = ?", userID, entity.DeliveryDead).
+		Order("created_at DESC").Find(&deliveries)
+	return deliveries, result.Error
+}
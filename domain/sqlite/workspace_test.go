@@ -0,0 +1,65 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/sqlite"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteDbRepositoryWorkspaces(t *testing.T) {
+	repo, err := sqlite.NewSqliteDBRepository()
+	if err != nil {
+		t.Fatalf("failed to start Sqlite database: %v", err)
+	}
+
+	defer func() {
+		sqlDB, _ := repo.Db.DB()
+		sqlDB.Close()
+	}()
+
+	// The same User in two workspaces, so that only the workspace tells the
+	// Tasks apart.
+	owner := uuid.New()
+	ours := task.WithWorkspace(task.WithOwner(context.Background(), owner), uuid.New())
+	theirs := task.WithWorkspace(task.WithOwner(context.Background(), owner), uuid.New())
+
+	secret := entity.NewTask("Secret wombat plans")
+	assert.NoError(t, repo.Post(ours, secret))
+	assert.ErrorIs(t, repo.Post(theirs, secret), entity.ErrTaskUniqueConstraint)
+
+	_, err = repo.Get(theirs, secret.ID)
+	assert.Error(t, err)
+
+	tasks, _, err := repo.Find(theirs, task.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	query, _ := entity.ParseSearchQuery("wombat")
+	results, err := repo.Search(theirs, query)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	entries, err := repo.History(theirs, secret.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	update := *secret
+	update.Description = "Stolen"
+	assert.ErrorIs(t, repo.Put(theirs, &update), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Delete(theirs, secret.ID), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Share(theirs, secret.ID, uuid.New(), entity.RoleViewer), entity.ErrTaskNotFound)
+
+	assert.NoError(t, repo.Delete(ours, secret.ID))
+	trash, err := repo.Trash(theirs)
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+	assert.ErrorIs(t, repo.Restore(theirs, secret.ID), entity.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Purge(theirs, secret.ID), entity.ErrTaskNotFound)
+
+	assert.NoError(t, repo.Purge(ours, secret.ID))
+}
@@ -0,0 +1,127 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Action is the kind of change applied by an Operation.
+type Action string
+
+const (
+	// ActionCreate adds a new Task.
+	ActionCreate = Action("create")
+
+	// ActionUpdate replaces an existing Task.
+	ActionUpdate = Action("update")
+
+	// ActionDelete removes an existing Task.
+	ActionDelete = Action("delete")
+)
+
+// Operation is a single change in a batch of changes to the repository.
+type Operation struct {
+	Action Action       `json:"op"`
+	Task   *entity.Task `json:"task,omitempty"`
+	ID     uuid.UUID    `json:"id,omitempty"`
+}
+
+// TaskID returns the ID of the Task the Operation applies to.
+func (op Operation) TaskID() uuid.UUID {
+	if op.Task != nil {
+		return op.Task.ID
+	}
+	return op.ID
+}
+
+// Validate checks that the Operation carries everything its Action needs.
+func (op Operation) Validate() error {
+	switch op.Action {
+	case ActionCreate, ActionUpdate:
+		if op.Task == nil {
+			return fmt.Errorf("%w: %s requires a task", entity.ErrInvalidOperation, op.Action)
+		}
+		if op.Action == ActionUpdate && op.Task.ID == uuid.Nil {
+			return fmt.Errorf("%w: update requires a task id", entity.ErrInvalidOperation)
+		}
+		return op.Task.Validate()
+	case ActionDelete:
+		if op.ID == uuid.Nil {
+			return fmt.Errorf("%w: delete requires an id", entity.ErrInvalidOperation)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unknown op %q", entity.ErrInvalidOperation, op.Action)
+}
+
+// OperationError reports which Operation of a batch failed.
+type OperationError struct {
+	Index int
+	Err   error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Apply runs a single Operation against the repository. Updates and deletes
+// fail with entity.ErrTaskNotFound when the Task does not exist.
+func Apply(ctx context.Context, repo TaskRepository, op Operation) error {
+	if err := op.Validate(); err != nil {
+		return err
+	}
+
+	switch op.Action {
+	case ActionCreate:
+		return repo.Post(ctx, op.Task)
+	case ActionUpdate:
+		if _, err := repo.Get(ctx, op.Task.ID); err != nil {
+			return entity.ErrTaskNotFound
+		}
+		return repo.Put(ctx, op.Task)
+	default:
+		if _, err := repo.Get(ctx, op.ID); err != nil {
+			return entity.ErrTaskNotFound
+		}
+		return repo.Delete(ctx, op.ID)
+	}
+}
+
+// ApplyAll runs every Operation in order, stopping at the first one failing
+// with an *OperationError.
+func ApplyAll(ctx context.Context, repo TaskRepository, ops []Operation) error {
+	for i, op := range ops {
+		if err := Apply(ctx, repo, op); err != nil {
+			return &OperationError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+// Batch runs every Operation in a single unit of work, so that either all of
+// them are applied or none is.
+func Batch(ctx context.Context, repo TaskRepository, ops []Operation) error {
+	return repo.WithTx(ctx, func(tx TaskRepository) error {
+		return ApplyAll(ctx, tx, ops)
+	})
+}
+
+// Distinct returns the IDs in order, without their duplicates.
+func Distinct(ids []uuid.UUID) []uuid.UUID {
+	distinct := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,37 @@
+package task
+
+import (
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Filter narrows down and paginates the Tasks returned by Find. Active Tasks
+// are sorted by creation time and archived Tasks by most recently archived.
+type Filter struct {
+	// Archived selects archived Tasks instead of active ones.
+	Archived bool
+
+	// Limit is the maximum number of Tasks returned, zero meaning no limit.
+	Limit int
+
+	// Offset is the number of Tasks skipped.
+	Offset int
+
+	// AssignedTo selects the Tasks assigned to a User, unless it is uuid.Nil.
+	AssignedTo uuid.UUID
+
+	// Unassigned selects the Tasks which are not assigned to anybody.
+	Unassigned bool
+}
+
+// MatchesAssignee reports whether the Task is selected by the assignee
+// filters.
+func (f Filter) MatchesAssignee(t entity.Task) bool {
+	switch {
+	case f.Unassigned:
+		return t.AssigneeID == nil
+	case f.AssignedTo != uuid.Nil:
+		return t.AssigneeID != nil && *t.AssigneeID == f.AssignedTo
+	}
+	return true
+}
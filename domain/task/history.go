@@ -0,0 +1,58 @@
+package task
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"gorm.io/gorm"
+)
+
+// AnonymousActor is recorded as the author of changes made without an actor.
+const AnonymousActor = "anonymous"
+
+type actorKey struct{}
+
+// WithActor returns a context recording who makes the changes done with it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns who makes the changes done with the context.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// Revert replaces a Task with the snapshot recorded by a version of its
+// history. The revert itself is recorded as a new version.
+func Revert(ctx context.Context, repo TaskRepository, id uuid.UUID, version int) (entity.Task, error) {
+	var reverted entity.Task
+
+	err := repo.WithTx(ctx, func(tx TaskRepository) error {
+		current, err := tx.Get(ctx, id)
+		if err != nil {
+			return entity.ErrTaskNotFound
+		}
+
+		entries, err := tx.History(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.Version != version || entry.After == nil {
+				continue
+			}
+
+			reverted = *entry.After
+			reverted.CreatedAt = current.CreatedAt
+			reverted.DeletedAt = gorm.DeletedAt{}
+			return tx.Put(ctx, &reverted)
+		}
+		return entity.ErrVersionNotFound
+	})
+	return reverted, err
+}
@@ -0,0 +1,38 @@
+package task
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+type ownerKey struct{}
+
+// WithOwner returns a context restricting the repositories to the Tasks of
+// the given User. New Tasks are owned by that User.
+func WithOwner(ctx context.Context, owner uuid.UUID) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// OwnerFromContext returns the User whose Tasks the context is restricted to,
+// if any. Contexts without an owner, such as the ones of background jobs, see
+// every Task.
+func OwnerFromContext(ctx context.Context) (uuid.UUID, bool) {
+	owner, ok := ctx.Value(ownerKey{}).(uuid.UUID)
+	return owner, ok
+}
+
+// Owns reports whether the context is not restricted to the Tasks of another
+// User than the owner of the Task.
+func Owns(ctx context.Context, t entity.Task) bool {
+	owner, ok := OwnerFromContext(ctx)
+	return !ok || t.OwnerID == owner
+}
+
+// Assigned reports whether the Task is assigned to the User the context is
+// restricted to.
+func Assigned(ctx context.Context, t entity.Task) bool {
+	owner, ok := OwnerFromContext(ctx)
+	return ok && t.AssigneeID != nil && *t.AssigneeID == owner
+}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/omaciel/GoDoIt/entity"
@@ -20,4 +21,52 @@ type TaskRepository interface {
 	Put(ctx context.Context, task *entity.Task) error
 	All(ctx context.Context) ([]entity.Task, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error)
+	PostMany(ctx context.Context, tasks []*entity.Task) error
+	DeleteMany(ctx context.Context, ids []uuid.UUID) error
+	WithTx(ctx context.Context, fn func(repo TaskRepository) error) error
+
+	// Deleted Tasks stay in the trash until they are restored or purged.
+	Trash(ctx context.Context) ([]entity.Task, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	Purge(ctx context.Context, id uuid.UUID) error
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// Find returns a page of the Tasks matching the filter, along with the
+	// total number of matching Tasks. All returns every active Task.
+	Find(ctx context.Context, filter Filter) ([]entity.Task, int64, error)
+	ArchiveCompleted(ctx context.Context, before time.Time) (int64, error)
+
+	// Every change to a Task is recorded, oldest first, along with the actor
+	// found in the context of the write.
+	History(ctx context.Context, id uuid.UUID) ([]entity.HistoryEntry, error)
+
+	// Owners share their Tasks with other Users, who may then see them, and
+	// change them if they are editors. Sharing a Task again changes the Role.
+	Share(ctx context.Context, id uuid.UUID, userID uuid.UUID, role entity.Role) error
+	Unshare(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	Shares(ctx context.Context, id uuid.UUID) ([]entity.Share, error)
+
+	// Editors assign Tasks to a User of their workspace. Tasks are created
+	// unassigned, and Put leaves their assignee unchanged.
+	Assign(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	Unassign(ctx context.Context, id uuid.UUID) error
+
+	// Editors keep an ordered checklist in their Tasks. New items are added at
+	// the end, and ReorderChecklist takes the IDs of every item in their new
+	// order. Tasks are created without a checklist, and Put leaves it
+	// unchanged.
+	AddChecklistItem(ctx context.Context, item *entity.ChecklistItem) error
+	EditChecklistItem(ctx context.Context, taskID, id uuid.UUID, text string) (entity.ChecklistItem, error)
+	ToggleChecklistItem(ctx context.Context, taskID, id uuid.UUID) (entity.ChecklistItem, error)
+	ReorderChecklist(ctx context.Context, taskID uuid.UUID, order []uuid.UUID) ([]entity.ChecklistItem, error)
+	DeleteChecklistItem(ctx context.Context, taskID, id uuid.UUID) error
+}
+
+// HistoricalRepository is implemented by the repositories which can tell what
+// the Tasks looked like at any point in the past.
+type HistoricalRepository interface {
+	// AsOf returns a repository holding the Tasks as they were at the given
+	// time. Changes made to it are not kept.
+	AsOf(ctx context.Context, at time.Time) (TaskRepository, error)
 }
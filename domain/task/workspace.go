@@ -0,0 +1,31 @@
+package task
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a context restricting the repositories to the Tasks
+// of the given workspace. New Tasks belong to that workspace.
+func WithWorkspace(ctx context.Context, workspace uuid.UUID) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// WorkspaceFromContext returns the workspace whose Tasks the context is
+// restricted to, if any. Contexts without a workspace, such as the ones of
+// background jobs, see the Tasks of every workspace.
+func WorkspaceFromContext(ctx context.Context) (uuid.UUID, bool) {
+	workspace, ok := ctx.Value(workspaceKey{}).(uuid.UUID)
+	return workspace, ok
+}
+
+// InWorkspace reports whether the context is not restricted to another
+// workspace than the one of the Task.
+func InWorkspace(ctx context.Context, t entity.Task) bool {
+	workspace, ok := WorkspaceFromContext(ctx)
+	return !ok || t.WorkspaceID == workspace
+}
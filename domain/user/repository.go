@@ -0,0 +1,27 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+type UserRepository interface {
+	// CreateUser fails with entity.ErrUsernameTaken if the username is in use.
+	CreateUser(ctx context.Context, user *entity.User) error
+	GetUser(ctx context.Context, id uuid.UUID) (entity.User, error)
+	UserByName(ctx context.Context, username string) (entity.User, error)
+	// RemoveUser deletes a User along with its API tokens, and fails with
+	// entity.ErrUserNotFound if there is no such User.
+	RemoveUser(ctx context.Context, id uuid.UUID) error
+
+	// API tokens are looked up by their hash. Revoked tokens are kept, and
+	// listed, so that their last use stays known.
+	CreateToken(ctx context.Context, token *entity.APIToken) error
+	Tokens(ctx context.Context, userID uuid.UUID) ([]entity.APIToken, error)
+	TokenByHash(ctx context.Context, hash string) (entity.APIToken, error)
+	RevokeToken(ctx context.Context, userID, id uuid.UUID) error
+	TouchToken(ctx context.Context, id uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// WebhookRepository records the webhooks of the Users, and the deliveries of
+// the changes to them. Webhooks are only seen by their User, and deleting one
+// deletes its deliveries.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) error
+	Webhooks(ctx context.Context, userID uuid.UUID) ([]entity.Webhook, error)
+	GetWebhook(ctx context.Context, userID, id uuid.UUID) (entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, id uuid.UUID) error
+
+	// SaveDelivery creates the WebhookDelivery, or records the outcome of its
+	// latest attempt. Deliveries are listed newest first.
+	SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	GetDelivery(ctx context.Context, webhookID, id uuid.UUID) (entity.WebhookDelivery, error)
+	Deliveries(ctx context.Context, webhookID uuid.UUID) ([]entity.WebhookDelivery, error)
+
+	// DueDeliveries returns the pending deliveries whose next attempt is due
+	// at the given time, the earliest first. DeadDeliveries returns the
+	// deliveries of the User which gave up.
+	DueDeliveries(ctx context.Context, at time.Time) ([]entity.WebhookDelivery, error)
+	DeadDeliveries(ctx context.Context, userID uuid.UUID) ([]entity.WebhookDelivery, error)
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("the attachment was not found in the repository")
+	ErrAttachmentTooLarge = errors.New("the attachment is larger than 10 MiB")
+	ErrInvalidAttachment  = errors.New("the attachment must be a named, non-empty file")
+)
+
+// MaxAttachmentSize is the number of bytes an Attachment has at most.
+const MaxAttachmentSize = 10 << 20
+
+// Attachment is a file attached to a Task. The repository only records it;
+// its contents are kept in a blob store, under its Key.
+type Attachment struct {
+	ID         uuid.UUID `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	TaskID     uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index"`
+	UploaderID uuid.UUID `json:"uploader_id" gorm:"type:uuid"`
+	Filename   string    `json:"filename" gorm:"not null"`
+
+	// ContentType is sniffed from the contents rather than trusted from the
+	// upload.
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// PurgedAt is set once the Task is purged, until the contents of the
+	// Attachment are deleted. The purged Attachments cannot be seen.
+	PurgedAt *time.Time `json:"purged_at,omitempty" gorm:"index"`
+}
+
+// Key is where the contents of the Attachment are kept in the blob store.
+func (a *Attachment) Key() string {
+	return "tasks/" + a.TaskID.String() + "/" + a.ID.String()
+}
+
+func (a *Attachment) Validate() error {
+	if a.Filename == "" || a.Size <= 0 {
+		return ErrInvalidAttachment
+	}
+	if a.Size > MaxAttachmentSize {
+		return ErrAttachmentTooLarge
+	}
+	return nil
+}
+
+// NewAttachment creates a new Attachment to a Task. Only the base name of the
+// uploaded file is kept.
+func NewAttachment(taskID uuid.UUID, filename string, contentType string, size int64) (*Attachment, error) {
+	filename = strings.TrimSpace(path.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if filename == "." || filename == "/" {
+		filename = ""
+	}
+
+	attachment := &Attachment{
+		ID:          uuid.New(),
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+	}
+	if err := attachment.Validate(); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrChecklistItemNotFound = errors.New("the checklist item was not found")
+	ErrInvalidChecklistItem  = errors.New("the checklist item must have 1 to 500 characters")
+	ErrInvalidChecklistOrder = errors.New("the checklist order must list every item of the checklist once")
+)
+
+// MaxChecklistItemLength is the number of characters a ChecklistItem has at
+// most.
+const MaxChecklistItemLength = 500
+
+// ChecklistItem is a lightweight step of a Task. The items of a checklist are
+// ordered by Position, from 0.
+type ChecklistItem struct {
+	ID        uuid.UUID `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index"`
+	Position  int       `json:"position" gorm:"not null"`
+	Text      string    `json:"text" gorm:"not null"`
+	Done      bool      `json:"done" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidateChecklistText accepts the texts which are neither blank nor longer
+// than MaxChecklistItemLength.
+func ValidateChecklistText(text string) error {
+	if strings.TrimSpace(text) == "" || utf8.RuneCountInString(text) > MaxChecklistItemLength {
+		return ErrInvalidChecklistItem
+	}
+	return nil
+}
+
+// NewChecklistItem creates a new ChecklistItem of a Task, which is added at
+// the end of its checklist.
+func NewChecklistItem(taskID uuid.UUID, text string) (*ChecklistItem, error) {
+	if err := ValidateChecklistText(text); err != nil {
+		return nil, err
+	}
+	return &ChecklistItem{
+		ID:     uuid.New(),
+		TaskID: taskID,
+		Text:   text,
+	}, nil
+}
+
+// ChecklistCompletion is the percentage of the items of the checklist which
+// are done, rounded down. It is 0 without items.
+func (t *Task) ChecklistCompletion() int {
+	if len(t.Checklist) == 0 {
+		return 0
+	}
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return done * 100 / len(t.Checklist)
+}
+
+// ChecklistItem returns the position of an item in the checklist of the Task.
+func (t *Task) ChecklistItem(id uuid.UUID) (int, error) {
+	for i, item := range t.Checklist {
+		if item.ID == id {
+			return i, nil
+		}
+	}
+	return 0, ErrChecklistItemNotFound
+}
+
+// Reorder returns the items of a checklist in the given order, renumbering
+// their Position. The order must list the ID of every item once.
+func Reorder(checklist []ChecklistItem, order []uuid.UUID) ([]ChecklistItem, error) {
+	if len(order) != len(checklist) {
+		return nil, ErrInvalidChecklistOrder
+	}
+	items := make(map[uuid.UUID]ChecklistItem, len(checklist))
+	for _, item := range checklist {
+		items[item.ID] = item
+	}
+
+	reordered := make([]ChecklistItem, 0, len(order))
+	for position, id := range order {
+		item, ok := items[id]
+		if !ok {
+			return nil, ErrInvalidChecklistOrder
+		}
+		delete(items, id)
+		item.Position = position
+		reordered = append(reordered, item)
+	}
+	return reordered, nil
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCommentNotFound = errors.New("the comment was not found in the repository")
+	ErrInvalidComment  = errors.New("the comment must have 1 to 10000 characters")
+)
+
+// MaxCommentLength is the number of characters a Comment has at most.
+const MaxCommentLength = 10000
+
+// Comment is a message about a Task. Its Body is Markdown, which is kept as
+// is for clients to render.
+type Comment struct {
+	ID        uuid.UUID `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index"`
+	AuthorID  uuid.UUID `json:"author_id" gorm:"type:uuid"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// EditedAt is the last time the Body was changed, if ever. The previous
+	// bodies are kept as CommentEdits.
+	EditedAt *time.Time `json:"edited_at"`
+}
+
+// CommentEdit keeps the Body a Comment had before it was edited.
+type CommentEdit struct {
+	ID        uuid.UUID `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	CommentID uuid.UUID `json:"comment_id" gorm:"type:uuid;not null;index"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	EditedAt  time.Time `json:"edited_at"`
+}
+
+// ValidateCommentBody accepts the bodies which are neither blank nor longer
+// than MaxCommentLength.
+func ValidateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" || utf8.RuneCountInString(body) > MaxCommentLength {
+		return ErrInvalidComment
+	}
+	return nil
+}
+
+// NewComment creates a new Comment about a Task.
+func NewComment(taskID uuid.UUID, body string) (*Comment, error) {
+	if err := ValidateCommentBody(body); err != nil {
+		return nil, err
+	}
+	return &Comment{
+		ID:     uuid.New(),
+		TaskID: taskID,
+		Body:   body,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package entity
+
+import "errors"
+
+// errorCodes are the stable codes the API reports its errors with, next to
+// their messages, so that clients can tell them apart without parsing text.
+// The codes must never change once released.
+var errorCodes = []struct {
+	code string
+	err  error
+}{
+	{"task_not_found", ErrTaskNotFound},
+	{"version_not_found", ErrVersionNotFound},
+	{"user_not_found", ErrUserNotFound},
+	{"token_not_found", ErrTokenNotFound},
+	{"share_not_found", ErrShareNotFound},
+	{"comment_not_found", ErrCommentNotFound},
+	{"attachment_not_found", ErrAttachmentNotFound},
+	{"checklist_item_not_found", ErrChecklistItemNotFound},
+	{"webhook_not_found", ErrWebhookNotFound},
+	{"delivery_not_found", ErrDeliveryNotFound},
+	{"task_unique_constraint", ErrTaskUniqueConstraint},
+	{"username_taken", ErrUsernameTaken},
+	{"delivery_not_dead", ErrDeliveryNotDead},
+	{"could_not_delete_task", ErrCouldNotDeleteTask},
+	{"invalid_operation", ErrInvalidOperation},
+	{"invalid_task_description", ErrInvalidTaskDescription},
+	{"invalid_priority_level", ErrInvalidPriorityLevel},
+	{"invalid_search_query", ErrInvalidSearchQuery},
+	{"invalid_username", ErrInvalidUsername},
+	{"invalid_password", ErrInvalidPassword},
+	{"invalid_token_name", ErrInvalidTokenName},
+	{"invalid_token_scope", ErrInvalidTokenScope},
+	{"invalid_token_expiry", ErrInvalidTokenExpiry},
+	{"invalid_role", ErrInvalidRole},
+	{"invalid_share", ErrInvalidShare},
+	{"invalid_assignee", ErrInvalidAssignee},
+	{"invalid_comment", ErrInvalidComment},
+	{"invalid_attachment", ErrInvalidAttachment},
+	{"invalid_checklist_item", ErrInvalidChecklistItem},
+	{"invalid_checklist_order", ErrInvalidChecklistOrder},
+	{"invalid_webhook_url", ErrInvalidWebhookURL},
+	{"invalid_webhook_events", ErrInvalidWebhookEvents},
+	{"invalid_workspace_role", ErrInvalidWorkspaceRole},
+	{"cannot_remove_self", ErrCannotRemoveSelf},
+	{"attachment_too_large", ErrAttachmentTooLarge},
+	{"invalid_credentials", ErrInvalidCredentials},
+	{"invalid_token", ErrInvalidToken},
+	{"insufficient_token_scope", ErrInsufficientTokenScope},
+	{"session_required", ErrSessionRequired},
+	{"admin_required", ErrAdminRequired},
+	{"forbidden", ErrForbidden},
+}
+
+// ErrorCode returns the code of an error, or of the error it wraps, or an
+// empty string when it has none.
+func ErrorCode(err error) string {
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.code
+		}
+	}
+	return ""
+}
+
+// ErrorForCode returns the error with the given code, or nil when there is
+// none, such as for a code added by a newer server.
+func ErrorForCode(code string) error {
+	for _, known := range errorCodes {
+		if code == known.code {
+			return known.err
+		}
+	}
+	return nil
+}
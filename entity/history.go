@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrVersionNotFound = errors.New("the task version was not found")
+)
+
+// HistoryAction is the kind of change recorded by a HistoryEntry.
+type HistoryAction string
+
+const (
+	// HistoryCreated records the creation of a Task.
+	HistoryCreated = HistoryAction("created")
+
+	// HistoryUpdated records a change to an existing Task.
+	HistoryUpdated = HistoryAction("updated")
+
+	// HistoryDeleted records a Task being moved to the trash.
+	HistoryDeleted = HistoryAction("deleted")
+
+	// HistoryRestored records a Task being restored from the trash.
+	HistoryRestored = HistoryAction("restored")
+)
+
+// Change is the value of a Task field before and after a HistoryEntry.
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// HistoryEntry is an immutable record of a change made to a Task. Before is
+// nil when the Task was created, and After is nil when it was deleted.
+type HistoryEntry struct {
+	ID        uuid.UUID         `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	TaskID    uuid.UUID         `json:"task_id" gorm:"type:uuid;not null;uniqueIndex:idx_history_task_version"`
+	Version   int               `json:"version" gorm:"not null;uniqueIndex:idx_history_task_version"`
+	Action    HistoryAction     `json:"action" gorm:"not null"`
+	Actor     string            `json:"actor"`
+	Before    *Task             `json:"before" gorm:"serializer:json"`
+	After     *Task             `json:"after" gorm:"serializer:json"`
+	Changes   map[string]Change `json:"changes" gorm:"serializer:json"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// NewHistoryEntry records the change of a Task from before to after.
+func NewHistoryEntry(action HistoryAction, actor string, version int, before, after *Task) HistoryEntry {
+	entry := HistoryEntry{
+		ID:        uuid.New(),
+		Version:   version,
+		Action:    action,
+		Actor:     actor,
+		Changes:   Diff(before, after),
+		CreatedAt: time.Now(),
+	}
+	if before != nil {
+		snapshot := *before
+		entry.Before, entry.TaskID = &snapshot, before.ID
+	}
+	if after != nil {
+		snapshot := *after
+		entry.After, entry.TaskID = &snapshot, after.ID
+	}
+	return entry
+}
+
+// Diff returns the fields of a Task which differ between before and after,
+// keyed by their JSON name. Either side may be nil.
+func Diff(before, after *Task) map[string]Change {
+	from, to := fields(before), fields(after)
+
+	changes := make(map[string]Change)
+	for name, value := range to {
+		if !reflect.DeepEqual(from[name], value) {
+			changes[name] = Change{From: from[name], To: value}
+		}
+	}
+	for name, value := range from {
+		if _, ok := to[name]; !ok {
+			changes[name] = Change{From: value}
+		}
+	}
+	return changes
+}
+
+// fields returns the JSON representation of a Task, without the fields which
+// change on every write.
+func fields(t *Task) map[string]interface{} {
+	values := make(map[string]interface{})
+	if t == nil {
+		return values
+	}
+
+	data, _ := json.Marshal(t)
+	_ = json.Unmarshal(data, &values)
+	delete(values, "id")
+	delete(values, "updated_at")
+	return values
+}
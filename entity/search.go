@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidSearchQuery = errors.New("the search query cannot be empty")
+)
+
+// SearchTerm is a single clause of a SearchQuery. A term with more than one
+// word is a phrase and only matches when the words appear next to each other,
+// in order. A Prefix term matches any word starting with its last word.
+type SearchTerm struct {
+	Words  []string
+	Prefix bool
+}
+
+// IsPhrase reports whether the term must match consecutive words.
+func (st SearchTerm) IsPhrase() bool {
+	return len(st.Words) > 1
+}
+
+// SearchQuery is a parsed full-text query. All of its terms must match.
+type SearchQuery struct {
+	Terms []SearchTerm
+}
+
+// SearchResult is a Task matching a SearchQuery. A higher Rank means a better
+// match, and Snippet is an HTML-escaped excerpt of the description with the
+// matching words wrapped in SnippetStart and SnippetEnd.
+type SearchResult struct {
+	Task    Task    `json:"task"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+const (
+	// SnippetStart marks the beginning of a highlighted word in a snippet.
+	SnippetStart = "<mark>"
+
+	// SnippetEnd marks the end of a highlighted word in a snippet.
+	SnippetEnd = "</mark>"
+
+	// SnippetEllipsis replaces the text left out of a snippet.
+	SnippetEllipsis = "…"
+
+	// SnippetWords is the maximum number of words shown in a snippet.
+	SnippetWords = 10
+
+	// SnippetStartMarker and SnippetEndMarker are the control characters a
+	// database wraps around the highlighted words of a snippet, which
+	// HighlightSnippet then replaces with SnippetStart and SnippetEnd.
+	SnippetStartMarker = "\x02"
+	SnippetEndMarker   = "\x03"
+)
+
+// HighlightSnippet HTML-escapes a snippet and replaces the markers around its
+// highlighted words with SnippetStart and SnippetEnd. Markers which do not
+// open or close a highlight, such as the ones of a description containing
+// them, are dropped so that the highlights are always balanced.
+func HighlightSnippet(snippet string) string {
+	var sb strings.Builder
+	highlighted := false
+	for len(snippet) > 0 {
+		i := strings.IndexAny(snippet, SnippetStartMarker+SnippetEndMarker)
+		if i < 0 {
+			sb.WriteString(html.EscapeString(snippet))
+			break
+		}
+		sb.WriteString(html.EscapeString(snippet[:i]))
+		switch marker := snippet[i : i+1]; {
+		case marker == SnippetStartMarker && !highlighted:
+			sb.WriteString(SnippetStart)
+			highlighted = true
+		case marker == SnippetEndMarker && highlighted:
+			sb.WriteString(SnippetEnd)
+			highlighted = false
+		}
+		snippet = snippet[i+1:]
+	}
+	if highlighted {
+		sb.WriteString(SnippetEnd)
+	}
+	return sb.String()
+}
+
+// Tokenize splits text into lower case words, dropping punctuation.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// ParseSearchQuery turns user input into a SearchQuery. Words between double
+// quotes form a phrase and a trailing * turns a word or phrase into a prefix
+// match, e.g. `"weekly report" grocer*`.
+func ParseSearchQuery(q string) (SearchQuery, error) {
+	var query SearchQuery
+
+	for len(q) > 0 {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+
+		var chunk string
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			if end < 0 {
+				// An unbalanced quote runs until the end of the query.
+				chunk, q = q[1:], ""
+			} else {
+				chunk, q = q[1:end+1], q[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(q, unicode.IsSpace)
+			if end < 0 {
+				end = len(q)
+			}
+			chunk, q = q[:end], q[end:]
+		}
+
+		prefix := false
+		if strings.HasPrefix(q, "*") {
+			prefix, q = true, q[1:]
+		}
+		if strings.HasSuffix(chunk, "*") {
+			prefix = true
+		}
+
+		words := Tokenize(chunk)
+		if len(words) == 0 {
+			continue
+		}
+		query.Terms = append(query.Terms, SearchTerm{Words: words, Prefix: prefix})
+	}
+
+	if len(query.Terms) == 0 {
+		return query, ErrInvalidSearchQuery
+	}
+	return query, nil
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrForbidden     = errors.New("you are not allowed to do this with the task")
+	ErrInvalidRole   = errors.New("the role must be editor or viewer")
+	ErrInvalidShare  = errors.New("a task cannot be shared with its owner")
+	ErrShareNotFound = errors.New("the task is not shared with this user")
+)
+
+// Role is what a User may do with a Task.
+type Role string
+
+const (
+	// RoleOwner may do anything with the Task, including sharing it. Only
+	// the User who created a Task is its owner.
+	RoleOwner = Role("owner")
+
+	// RoleEditor may read and change the Task.
+	RoleEditor = Role("editor")
+
+	// RoleViewer may only read the Task.
+	RoleViewer = Role("viewer")
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// Validate accepts the Roles which a Task can be shared with.
+func (r Role) Validate() error {
+	switch r {
+	case RoleEditor, RoleViewer:
+		return nil
+	}
+	return ErrInvalidRole
+}
+
+// Allows reports whether the Role may do what the required Role may.
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// Share gives a User other than its owner access to a Task.
+type Share struct {
+	TaskID    uuid.UUID `json:"task_id" gorm:"primaryKey;type:uuid"`
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;type:uuid;index"`
+	Role      Role      `json:"role" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
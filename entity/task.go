@@ -1,10 +1,15 @@
 package entity
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 var (
@@ -13,6 +18,8 @@ var (
 	ErrTaskUniqueConstraint   = errors.New("unique constraint or index violation")
 	ErrTaskNotFound           = errors.New("the task was not found in the repository")
 	ErrCouldNotDeleteTask     = errors.New("could not delete the task")
+	ErrInvalidOperation       = errors.New("invalid batch operation")
+	ErrInvalidAssignee        = errors.New("the assignee must be a member of the workspace of the task")
 )
 
 // Priority represents how important a Task is for the user.
@@ -26,6 +33,30 @@ func (p Priority) Validate() error {
 	return ErrInvalidPriorityLevel
 }
 
+// String returns the name of the Priority, as used by the exports.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityMedium:
+		return "medium"
+	case PriorityHigh:
+		return "high"
+	}
+	return fmt.Sprintf("Priority(%d)", uint(p))
+}
+
+// ParsePriority returns the Priority with the given name or level, as written
+// by the exports.
+func ParsePriority(s string) (Priority, error) {
+	for _, p := range []Priority{PriorityLow, PriorityMedium, PriorityHigh} {
+		if strings.EqualFold(s, p.String()) || s == strconv.Itoa(int(p)) {
+			return p, nil
+		}
+	}
+	return 0, ErrInvalidPriorityLevel
+}
+
 const (
 	// PriorityLow represents a non-urgent task.
 	PriorityLow = Priority(iota + 1)
@@ -42,6 +73,82 @@ type Task struct {
 	Description string    `json:"description" gorm:"text;not null;default:null"`
 	Priority    Priority  `json:"priority" gorm:"default:3"`
 	Completed   bool      `json:"completed" gorm:"default:false"`
+
+	// OwnerID is the User who created the Task, and the only one who can see it
+	// unless it is shared.
+	OwnerID uuid.UUID `json:"owner_id" gorm:"type:uuid;index"`
+
+	// WorkspaceID is the workspace of the owner. Tasks are never seen outside
+	// of their workspace.
+	WorkspaceID uuid.UUID `json:"workspace_id" gorm:"type:uuid;index"`
+
+	// AssigneeID is the User of the workspace who should do the Task, if any.
+	AssigneeID *uuid.UUID `json:"assignee_id" gorm:"type:uuid;index"`
+
+	// Checklist holds the steps of the Task, ordered by Position.
+	Checklist []ChecklistItem `json:"checklist" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE"`
+
+	// Archived Tasks are hidden from the default listings, independently of
+	// whether they are completed.
+	Archived bool `json:"archived" gorm:"default:false;index"`
+
+	// DueAt is when the Task should be completed by, if ever.
+	DueAt *time.Time `json:"due_at"`
+
+	// CalendarUID and CalendarName are the iCalendar UID and the name of the
+	// calendar object given by the application which created the Task, when
+	// they are not its ID. They never change.
+	CalendarUID  string `json:"calendar_uid,omitempty"`
+	CalendarName string `json:"calendar_name,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+
+	// DeletedAt is set when the Task is moved to the trash.
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// MarshalJSON adds the completion of the checklist to the JSON of the Task.
+func (t Task) MarshalJSON() ([]byte, error) {
+	// task has the fields of Task without its methods, so that it is encoded
+	// as usual.
+	type task Task
+	if t.Checklist == nil {
+		t.Checklist = []ChecklistItem{}
+	}
+	return json.Marshal(struct {
+		task
+		ChecklistCompletion int `json:"checklist_completion"`
+	}{task(t), t.ChecklistCompletion()})
+}
+
+// IsDeleted reports whether the Task is in the trash.
+func (t *Task) IsDeleted() bool {
+	return t.DeletedAt.Valid
+}
+
+// Stamp keeps CompletedAt and ArchivedAt consistent with the Completed and
+// Archived flags, recording now as the time of any new state.
+func (t *Task) Stamp(now time.Time) {
+	if !t.Completed {
+		t.CompletedAt = nil
+	} else if t.CompletedAt == nil {
+		t.CompletedAt = &now
+	}
+
+	if !t.Archived {
+		t.ArchivedAt = nil
+	} else if t.ArchivedAt == nil {
+		t.ArchivedAt = &now
+	}
+}
+
+// BeforeSave is a GORM hook stamping the Task before it is written.
+func (t *Task) BeforeSave(tx *gorm.DB) error {
+	t.Stamp(time.Now())
+	return nil
 }
 
 // NewTask creates a new Task with sane default values
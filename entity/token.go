@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTokenNotFound          = errors.New("the API token was not found in the repository")
+	ErrInvalidToken           = errors.New("the token is invalid or has expired")
+	ErrInvalidTokenScope      = errors.New("the scope must be read or write")
+	ErrInvalidTokenName       = errors.New("the token name cannot be empty")
+	ErrInvalidTokenExpiry     = errors.New("the token cannot expire in the past")
+	ErrInsufficientTokenScope = errors.New("the API token does not allow this request")
+	ErrSessionRequired        = errors.New("the API tokens and the workspace members can only be managed with a session")
+)
+
+// APITokenPrefix starts every API token, telling them apart from sessions.
+const APITokenPrefix = "gdi_"
+
+// TokenScope is what an API token allows its bearer to do.
+type TokenScope string
+
+const (
+	// ScopeRead only allows reading Tasks.
+	ScopeRead = TokenScope("read")
+
+	// ScopeWrite allows reading and changing Tasks.
+	ScopeWrite = TokenScope("write")
+)
+
+func (s TokenScope) Validate() error {
+	switch s {
+	case ScopeRead, ScopeWrite:
+		return nil
+	}
+	return ErrInvalidTokenScope
+}
+
+// The WebDAV methods which only read, which the calendar clients send.
+const (
+	MethodPropfind = "PROPFIND"
+	MethodReport   = "REPORT"
+)
+
+// Allows reports whether a request with the given HTTP method is allowed. The
+// WebDAV methods which only read, PROPFIND and REPORT, are allowed too.
+func (s TokenScope) Allows(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, MethodPropfind, MethodReport:
+		return true
+	}
+	return s == ScopeWrite
+}
+
+// APIToken gives scripts access to the Tasks of a User. Only a hash of the
+// token is kept, along with its first characters to recognize it by.
+type APIToken struct {
+	ID         uuid.UUID  `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-" gorm:"not null;uniqueIndex"`
+	Scope      TokenScope `json:"scope" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// NewAPIToken creates an APIToken for the User, returning it along with the
+// token itself, which cannot be recovered afterwards. A nil expiry never
+// expires.
+func NewAPIToken(userID uuid.UUID, name string, scope TokenScope, expiresAt *time.Time) (*APIToken, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", ErrInvalidTokenName
+	}
+	if err := scope.Validate(); err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidTokenExpiry
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := APITokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	return &APIToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:len(APITokenPrefix)+6],
+		Hash:      HashAPIToken(secret),
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	}, secret, nil
+}
+
+// IsAPIToken reports whether a bearer token is an API token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// HashAPIToken returns the hash under which an API token is kept. The tokens
+// are random enough for a fast hash to be safe.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Active reports whether the APIToken is neither revoked nor expired at now.
+func (t *APIToken) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidUsername    = errors.New("the username must have 3 to 32 letters, digits, dots, dashes or underscores")
+	ErrInvalidPassword    = errors.New("the password must have 8 to 72 bytes")
+	ErrUsernameTaken      = errors.New("the username is already taken")
+	ErrUserNotFound       = errors.New("the user was not found in the repository")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	ErrInvalidWorkspaceRole = errors.New("the workspace role must be admin or member")
+	ErrAdminRequired        = errors.New("only the admins of the workspace can manage its members")
+	ErrCannotRemoveSelf     = errors.New("the admins cannot remove themselves from their workspace")
+)
+
+// WorkspaceRole is what a User may do in its workspace. Admins manage the
+// members of the workspace, on top of what members do.
+type WorkspaceRole string
+
+const (
+	WorkspaceAdmin  WorkspaceRole = "admin"
+	WorkspaceMember WorkspaceRole = "member"
+)
+
+// Validate returns ErrInvalidWorkspaceRole unless the WorkspaceRole is known.
+func (r WorkspaceRole) Validate() error {
+	switch r {
+	case WorkspaceAdmin, WorkspaceMember:
+		return nil
+	}
+	return ErrInvalidWorkspaceRole
+}
+
+const (
+	// MinPasswordLength is the number of bytes a password has at least.
+	MinPasswordLength = 8
+
+	// MaxPasswordLength is the number of bytes a password has at most, as
+	// bcrypt ignores anything longer.
+	MaxPasswordLength = 72
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{3,32}$`)
+
+// User is an account owning Tasks. Only a hash of its password is kept.
+type User struct {
+	ID           uuid.UUID `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	Username     string    `json:"username" gorm:"not null;uniqueIndex"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// WorkspaceID is the workspace, or tenant, the User belongs to. Users only
+	// ever see the Tasks of their workspace.
+	WorkspaceID uuid.UUID `json:"workspace_id" gorm:"type:uuid;index"`
+	// WorkspaceRole is the role of the User in its workspace. Users who
+	// register are the admins of their new workspace.
+	WorkspaceRole WorkspaceRole `json:"workspace_role"`
+}
+
+// NewUser creates a new User with a hash of the given password, as the admin
+// of a new workspace of its own.
+func NewUser(username, password string) (*User, error) {
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return nil, ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return &User{
+		ID:            uuid.New(),
+		Username:      username,
+		PasswordHash:  string(hash),
+		WorkspaceID:   uuid.New(),
+		WorkspaceRole: WorkspaceAdmin,
+	}, nil
+}
+
+// CheckPassword reports whether password is the password of the User.
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
@@ -0,0 +1,136 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebhookNotFound      = errors.New("the webhook was not found in the repository")
+	ErrDeliveryNotFound     = errors.New("the webhook delivery was not found in the repository")
+	ErrInvalidWebhookURL    = errors.New("the webhook URL must be an absolute http or https URL")
+	ErrInvalidWebhookEvents = errors.New("the webhook events must be created, updated or deleted")
+	ErrDeliveryNotDead      = errors.New("only the deliveries which gave up can be redelivered")
+)
+
+// WebhookSecretPrefix starts the secrets generated for the webhooks.
+const WebhookSecretPrefix = "whsec_"
+
+// WebhookEvents are the types of the changes to the Tasks which are delivered
+// to the webhooks.
+var WebhookEvents = []string{"created", "updated", "deleted"}
+
+// Webhook receives the changes to the Tasks its User can see, signed with its
+// Secret, which is only shown when the Webhook is created.
+type Webhook struct {
+	ID     uuid.UUID `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	URL    string    `json:"url" gorm:"not null"`
+
+	// Events are the types of the changes which are delivered, every type
+	// when empty.
+	Events    []string  `json:"events" gorm:"serializer:json"`
+	Secret    string    `json:"-" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewWebhook creates a Webhook for the User. A secret is generated unless one
+// is given.
+func NewWebhook(userID uuid.UUID, rawURL string, events []string, secret string) (*Webhook, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	for _, event := range events {
+		if !contains(WebhookEvents, event) {
+			return nil, ErrInvalidWebhookEvents
+		}
+	}
+
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		secret = WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(random)
+	}
+
+	return &Webhook{
+		ID:     uuid.New(),
+		UserID: userID,
+		URL:    rawURL,
+		Events: events,
+		Secret: secret,
+	}, nil
+}
+
+// Wants reports whether the changes of the given type are delivered to the
+// Webhook.
+func (w *Webhook) Wants(event string) bool {
+	return len(w.Events) == 0 || contains(w.Events, event)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus tells where a WebhookDelivery stands.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is waiting for its next attempt.
+	DeliveryPending = DeliveryStatus("pending")
+
+	// DeliverySucceeded was acknowledged by the receiver.
+	DeliverySucceeded = DeliveryStatus("succeeded")
+
+	// DeliveryDead gave up after its last attempt failed, and is kept in the
+	// dead-letter list until it is redelivered.
+	DeliveryDead = DeliveryStatus("dead")
+)
+
+// WebhookDelivery is a change sent to a Webhook, along with the outcome of
+// its last attempt.
+type WebhookDelivery struct {
+	ID        uuid.UUID      `json:"id" gorm:"primary_key;unique;type:uuid;column:id"`
+	WebhookID uuid.UUID      `json:"webhook_id" gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	Event     string         `json:"event" gorm:"not null"`
+	Payload   string         `json:"payload" gorm:"not null"`
+	Status    DeliveryStatus `json:"status" gorm:"not null;index"`
+	Attempts  int            `json:"attempts"`
+
+	// ResponseStatus is the HTTP status of the last response, if any, and
+	// LastError why the last attempt failed.
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewWebhookDelivery creates a pending WebhookDelivery of a payload, to be
+// attempted right away.
+func NewWebhookDelivery(webhook Webhook, event string, payload string) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     webhook.ID,
+		UserID:        webhook.UserID,
+		Event:         event,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: &now,
+	}
+}
@@ -0,0 +1,141 @@
+package formats
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+var errNoDescriptionColumn = errors.New("the CSV header must name a description column")
+
+// CSVHeader names the columns of the CSV format.
+var CSVHeader = []string{"id", "description", "priority", "completed", "archived", "created_at", "completed_at", "assignee_id"}
+
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvEncoder) begin() error {
+	return e.writer.Write(CSVHeader)
+}
+
+func (e *csvEncoder) task(t entity.Task) error {
+	var completed, assignee string
+	if t.CompletedAt != nil {
+		completed = t.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	if t.AssigneeID != nil {
+		assignee = t.AssigneeID.String()
+	}
+
+	return e.writer.Write([]string{
+		t.ID.String(),
+		t.Description,
+		t.Priority.String(),
+		strconv.FormatBool(t.Completed),
+		strconv.FormatBool(t.Archived),
+		t.CreatedAt.UTC().Format(time.RFC3339),
+		completed,
+		assignee,
+	})
+}
+
+func (e *csvEncoder) end() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// decodeCSV reads the rows of a CSV file under a header naming their columns,
+// as CSVHeader does. Only the description column is required, and the columns
+// which are not in CSVHeader are ignored, as is the assignee.
+func decodeCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errNoDescriptionColumn
+	} else if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheets often begin their files with a byte order mark.
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["description"]; !ok {
+		return nil, errNoDescriptionColumn
+	}
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			entries = append(entries, Entry{Line: parseErr.StartLine, Err: err})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		t, err := csvTask(record, columns)
+		entries = append(entries, Entry{Line: line, Task: t, Err: err})
+	}
+}
+
+// csvTask returns the Task of a CSV row.
+func csvTask(record []string, columns map[string]int) (*entity.Task, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	t := entity.NewTask(field("description"))
+	var err error
+	if id := field("id"); id != "" {
+		if t.ID, err = uuid.Parse(id); err != nil {
+			return nil, fmt.Errorf("id: %w", err)
+		}
+	}
+	if priority := field("priority"); priority != "" {
+		if t.Priority, err = entity.ParsePriority(priority); err != nil {
+			return nil, err
+		}
+	}
+	if completed := field("completed"); completed != "" {
+		if t.Completed, err = strconv.ParseBool(completed); err != nil {
+			return nil, fmt.Errorf("completed: %w", err)
+		}
+	}
+	if archived := field("archived"); archived != "" {
+		if t.Archived, err = strconv.ParseBool(archived); err != nil {
+			return nil, fmt.Errorf("archived: %w", err)
+		}
+	}
+	if created := field("created_at"); created != "" {
+		if t.CreatedAt, err = time.Parse(time.RFC3339, created); err != nil {
+			return nil, fmt.Errorf("created_at: %w", err)
+		}
+	}
+	if completed := field("completed_at"); completed != "" {
+		at, err := time.Parse(time.RFC3339, completed)
+		if err != nil {
+			return nil, fmt.Errorf("completed_at: %w", err)
+		}
+		t.CompletedAt = &at
+	}
+	return t, nil
+}
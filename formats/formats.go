@@ -0,0 +1,204 @@
+package formats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+var (
+	ErrUnknownFormat = errors.New("the format must be csv, json, md, todotxt or ics")
+	ErrNotImportable = errors.New("tasks can only be imported from csv, json, todotxt or ics")
+)
+
+// Format is a file format in which Tasks are handed to other tools.
+type Format string
+
+const (
+	// CSV has a row of columns per Task, after a header row.
+	CSV = Format("csv")
+
+	// JSON is an array of Tasks, as returned by the API.
+	JSON = Format("json")
+
+	// Markdown is a task list, with the checklist of every Task nested in it.
+	Markdown = Format("md")
+
+	// TodoTxt follows the todo.txt conventions, with a line per Task.
+	TodoTxt = Format("todotxt")
+
+	// ICalendar is a calendar with a VTODO component per Task.
+	ICalendar = Format("ics")
+)
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case CSV, JSON, Markdown, TodoTxt, ICalendar:
+		return format, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// ContentType is the media type of the files in the Format.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case JSON:
+		return "application/json"
+	case Markdown:
+		return "text/markdown; charset=utf-8"
+	case ICalendar:
+		return "text/calendar; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Extension is the usual file name extension of the Format.
+func (f Format) Extension() string {
+	switch f {
+	case TodoTxt:
+		return ".txt"
+	}
+	return "." + string(f)
+}
+
+// encoder writes the parts of a file in a Format.
+type encoder interface {
+	begin() error
+	task(t entity.Task) error
+	end() error
+}
+
+// Encoder writes Tasks in a Format one at a time, so that they can be
+// streamed.
+type Encoder struct {
+	w       *bufio.Writer
+	encoder encoder
+	begun   bool
+}
+
+// NewEncoder creates an Encoder writing to w in the given Format.
+func NewEncoder(w io.Writer, format Format) (*Encoder, error) {
+	buffered := bufio.NewWriter(w)
+
+	var encoder encoder
+	switch format {
+	case CSV:
+		encoder = &csvEncoder{writer: csv.NewWriter(buffered)}
+	case JSON:
+		encoder = &jsonEncoder{w: buffered}
+	case Markdown:
+		encoder = &markdownEncoder{w: buffered}
+	case TodoTxt:
+		encoder = &todoTxtEncoder{w: buffered}
+	case ICalendar:
+		encoder = &icalEncoder{icalWriter{w: buffered}}
+	default:
+		return nil, ErrUnknownFormat
+	}
+	return &Encoder{w: buffered, encoder: encoder}, nil
+}
+
+// Encode writes a Task, after the beginning of the file if it is the first.
+func (e *Encoder) Encode(t entity.Task) error {
+	if !e.begun {
+		e.begun = true
+		if err := e.encoder.begin(); err != nil {
+			return err
+		}
+	}
+	return e.encoder.task(t)
+}
+
+// Close writes the end of the file. It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	if !e.begun {
+		e.begun = true
+		if err := e.encoder.begin(); err != nil {
+			return err
+		}
+	}
+	if err := e.encoder.end(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// Encode writes the Tasks in the Format.
+func Encode(w io.Writer, format Format, tasks []entity.Task) error {
+	encoder, err := NewEncoder(w, format)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if err := encoder.Encode(t); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// singleLine replaces the line breaks of a description, for the formats
+// which have a line per Task.
+func singleLine(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
+
+// completedAt is when a completed Task was completed, as far as is known.
+func completedAt(t entity.Task) time.Time {
+	if t.CompletedAt != nil {
+		return *t.CompletedAt
+	}
+	return t.UpdatedAt
+}
+
+// Entry is a Task read from a file, or the reason why the Task at its line is
+// invalid.
+type Entry struct {
+	Line int
+	Task *entity.Task
+	Err  error
+}
+
+// Decode reads the Tasks of a file in the Format. Every Task is validated, and
+// the lines which do not hold a valid Task are returned with their error, so
+// that the rest of the file can still be read. Only errors making the whole
+// file unreadable are returned as such.
+func Decode(r io.Reader, format Format) ([]Entry, error) {
+	var entries []Entry
+	var err error
+	switch format {
+	case CSV:
+		entries, err = decodeCSV(r)
+	case JSON:
+		entries, err = decodeJSON(r)
+	case TodoTxt:
+		entries, err = decodeTodoTxt(r)
+	case ICalendar:
+		entries, err = decodeICalendar(r)
+	case Markdown:
+		return nil, ErrNotImportable
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		if entries[i].Err == nil {
+			entries[i].Err = entries[i].Task.Validate()
+		}
+		if entries[i].Err != nil {
+			entries[i].Task = nil
+		}
+	}
+	return entries, nil
+}
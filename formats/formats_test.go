@@ -0,0 +1,113 @@
+package formats_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/formats"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	created   = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	completed = time.Date(2024, 3, 5, 17, 0, 0, 0, time.UTC)
+)
+
+// exported returns an open Task with a checklist and a completed one.
+func exported() []entity.Task {
+	open := entity.NewTask("Pack, then *leave*")
+	open.ID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	open.Priority = entity.PriorityHigh
+	open.CreatedAt, open.UpdatedAt = created, created
+	open.Checklist = []entity.ChecklistItem{{Text: "Passport", Done: true}, {Text: "Charger"}}
+
+	done := entity.NewTask("File taxes")
+	done.ID = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	done.Priority = entity.PriorityMedium
+	done.Completed, done.CompletedAt = true, &completed
+	done.CreatedAt, done.UpdatedAt = created, completed
+
+	return []entity.Task{*open, *done}
+}
+
+func encode(t *testing.T, format formats.Format, tasks []entity.Task) string {
+	var out bytes.Buffer
+	assert.NoError(t, formats.Encode(&out, format, tasks))
+	return out.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := formats.ParseFormat("ICS")
+	assert.NoError(t, err)
+	assert.Equal(t, formats.ICalendar, format)
+	assert.Equal(t, ".ics", format.Extension())
+	assert.Equal(t, ".txt", formats.TodoTxt.Extension())
+
+	_, err = formats.ParseFormat("xlsx")
+	assert.ErrorIs(t, err, formats.ErrUnknownFormat)
+}
+
+func TestEncodeCSV(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(encode(t, formats.CSV, exported()))).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, formats.CSVHeader, records[0])
+		assert.Equal(t, []string{"11111111-1111-1111-1111-111111111111", "Pack, then *leave*", "high", "false", "false", "2024-03-01T09:30:00Z", "", ""}, records[1])
+		assert.Equal(t, "2024-03-05T17:00:00Z", records[2][6])
+	}
+
+	records, _ = csv.NewReader(strings.NewReader(encode(t, formats.CSV, nil))).ReadAll()
+	assert.Len(t, records, 1, "the header is written without Tasks")
+}
+
+func TestEncodeMarkdown(t *testing.T) {
+	assert.Equal(t, "# Tasks\n\n"+
+		"- [ ] Pack, then \\*leave\\* *(high)*\n"+
+		"  - [x] Passport\n"+
+		"  - [ ] Charger\n"+
+		"- [x] File taxes *(medium)*\n",
+		encode(t, formats.Markdown, exported()))
+}
+
+func TestEncodeTodoTxt(t *testing.T) {
+	assert.Equal(t,
+		"(A) 2024-03-01 Pack, then *leave* id:11111111-1111-1111-1111-111111111111\n"+
+			"x 2024-03-05 2024-03-01 File taxes pri:B id:22222222-2222-2222-2222-222222222222\n",
+		encode(t, formats.TodoTxt, exported()))
+
+	multiline := exported()[:1]
+	multiline[0].Description = "Pack\nthen leave"
+	assert.Contains(t, encode(t, formats.TodoTxt, multiline), " Pack then leave ")
+}
+
+func TestEncodeICalendar(t *testing.T) {
+	calendar := encode(t, formats.ICalendar, exported())
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(calendar, "END:VTODO\r\nEND:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(calendar, "BEGIN:VTODO\r\n"))
+
+	for _, line := range []string{
+		"UID:11111111-1111-1111-1111-111111111111",
+		`SUMMARY:Pack\, then *leave*`,
+		"PRIORITY:1",
+		"STATUS:NEEDS-ACTION",
+		"PERCENT-COMPLETE:50",
+		"PRIORITY:5",
+		"STATUS:COMPLETED",
+		"COMPLETED:20240305T170000Z",
+		"DTSTAMP:20240305T170000Z",
+	} {
+		assert.Contains(t, calendar, "\r\n"+line+"\r\n")
+	}
+
+	long := exported()[:1]
+	long[0].Description = strings.Repeat("é", 100)
+	for _, line := range strings.Split(encode(t, formats.ICalendar, long), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "lines are folded at 75 octets")
+	}
+}
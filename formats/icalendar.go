@@ -0,0 +1,361 @@
+package formats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+var (
+	errInvalidICalendar = errors.New("the file must hold a VCALENDAR object")
+)
+
+// icalTime is the layout of the UTC date-times of iCalendar.
+const icalTime = "20060102T150405Z"
+
+// icalPriority maps the priorities of the Tasks onto the PRIORITY property of
+// RFC 5545, where 1 is the highest and 9 the lowest.
+var icalPriority = map[entity.Priority]int{
+	entity.PriorityHigh:   1,
+	entity.PriorityMedium: 5,
+	entity.PriorityLow:    9,
+}
+
+// icalEscaper escapes the TEXT values of iCalendar, and icalUnescaper
+// reverses it.
+var (
+	icalEscaper   = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`)
+	icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+)
+
+// icalWriter writes the content lines of an iCalendar object, folding them at
+// 75 octets.
+type icalWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *icalWriter) line(name string, value string) {
+	if iw.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	var folded strings.Builder
+	// Continuation lines start with a space, which counts in their 75 octets.
+	for limit := 75; len(line) > limit; limit = 74 {
+		// Lines are never folded in the middle of a character.
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	folded.WriteString(line + "\r\n")
+	_, iw.err = io.WriteString(iw.w, folded.String())
+}
+
+func (iw *icalWriter) time(name string, at time.Time) {
+	iw.line(name, at.UTC().Format(icalTime))
+}
+
+// vtodo writes the VTODO component of a Task.
+func (iw *icalWriter) vtodo(t entity.Task) {
+	stamp := t.UpdatedAt
+	if stamp.IsZero() {
+		stamp = t.CreatedAt
+	}
+
+	iw.line("BEGIN", "VTODO")
+	iw.line("UID", UID(t))
+	iw.time("DTSTAMP", stamp)
+	if !t.CreatedAt.IsZero() {
+		iw.time("CREATED", t.CreatedAt)
+	}
+	if !t.UpdatedAt.IsZero() {
+		iw.time("LAST-MODIFIED", t.UpdatedAt)
+	}
+	iw.line("SUMMARY", icalEscaper.Replace(t.Description))
+	if priority, ok := icalPriority[t.Priority]; ok {
+		iw.line("PRIORITY", strconv.Itoa(priority))
+	}
+	if t.DueAt != nil {
+		iw.time("DUE", *t.DueAt)
+	}
+	if t.Completed {
+		iw.line("STATUS", "COMPLETED")
+		iw.time("COMPLETED", completedAt(t))
+		iw.line("PERCENT-COMPLETE", "100")
+	} else {
+		iw.line("STATUS", "NEEDS-ACTION")
+		if len(t.Checklist) > 0 {
+			iw.line("PERCENT-COMPLETE", strconv.Itoa(t.ChecklistCompletion()))
+		}
+	}
+	iw.line("END", "VTODO")
+}
+
+// icalEncoder writes a VCALENDAR object with a VTODO component per Task.
+type icalEncoder struct {
+	icalWriter
+}
+
+func (e *icalEncoder) begin() error {
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", "-//GoDoIt//GoDoIt//EN")
+	return e.err
+}
+
+func (e *icalEncoder) task(t entity.Task) error {
+	e.vtodo(t)
+	return e.err
+}
+
+func (e *icalEncoder) end() error {
+	e.line("END", "VCALENDAR")
+	return e.err
+}
+
+// Property is a content line of an iCalendar object.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Text returns the value of a TEXT property, unescaped.
+func (p Property) Text() string {
+	return icalUnescaper.Replace(p.Value)
+}
+
+// Component is a component of an iCalendar object, such as a VCALENDAR or
+// the VTODO components in it. Line is where it begins.
+type Component struct {
+	Name       string
+	Line       int
+	Properties []Property
+	Components []Component
+}
+
+// Property returns the first property of the Component with the given name.
+func (c Component) Property(name string) (Property, bool) {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// icalLine is an unfolded content line, along with the line where it begins.
+type icalLine struct {
+	number int
+	text   string
+}
+
+// icalLines reads the content lines of an iCalendar object, unfolding them.
+func icalLines(r io.Reader) ([]icalLine, error) {
+	var lines []icalLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			if len(lines) > 0 {
+				lines[len(lines)-1].text += text[1:]
+			}
+			continue
+		}
+		if text != "" {
+			lines = append(lines, icalLine{number: number, text: text})
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty parses a content line. The values of the parameters may be
+// quoted, and then hold colons and semicolons.
+func parseProperty(text string) (Property, error) {
+	property := Property{Params: map[string]string{}}
+	quoted, start := false, 0
+	var name string
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' || c == ':':
+			part := text[start:i]
+			if name == "" {
+				name = part
+			} else if key, value, ok := strings.Cut(part, "="); ok {
+				property.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			start = i + 1
+			if c == ':' {
+				property.Name = strings.ToUpper(name)
+				property.Value = text[i+1:]
+				return property, nil
+			}
+		}
+	}
+	return property, fmt.Errorf("%w: %q is not a content line", errInvalidICalendar, text)
+}
+
+// ParseICalendar parses an iCalendar object, and returns its outermost
+// component.
+func ParseICalendar(r io.Reader) (Component, error) {
+	lines, err := icalLines(r)
+	if err != nil {
+		return Component{}, err
+	}
+
+	// open holds the components which are not ended yet, the outermost first.
+	var open []Component
+	for _, line := range lines {
+		property, err := parseProperty(line.text)
+		if err != nil {
+			return Component{}, fmt.Errorf("line %d: %w", line.number, err)
+		}
+
+		switch property.Name {
+		case "BEGIN":
+			open = append(open, Component{Name: strings.ToUpper(property.Value), Line: line.number})
+		case "END":
+			if len(open) == 0 || open[len(open)-1].Name != strings.ToUpper(property.Value) {
+				return Component{}, fmt.Errorf("line %d: %w: unexpected END:%s", line.number, errInvalidICalendar, property.Value)
+			}
+			ended := open[len(open)-1]
+			if open = open[:len(open)-1]; len(open) == 0 {
+				return ended, nil
+			}
+			parent := &open[len(open)-1]
+			parent.Components = append(parent.Components, ended)
+		default:
+			if len(open) == 0 {
+				return Component{}, errInvalidICalendar
+			}
+			current := &open[len(open)-1]
+			current.Properties = append(current.Properties, property)
+		}
+	}
+	return Component{}, errInvalidICalendar
+}
+
+// TaskID returns the ID of the Task with an iCalendar UID. UIDs which are not
+// UUIDs, as written by other applications, always give the same ID.
+func TaskID(uid string) uuid.UUID {
+	if id, err := uuid.Parse(uid); err == nil {
+		return id
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(uid))
+}
+
+// UID returns the iCalendar UID of a Task, which is its ID unless the
+// application which created it gave another.
+func UID(t entity.Task) string {
+	if t.CalendarUID != "" {
+		return t.CalendarUID
+	}
+	return t.ID.String()
+}
+
+// icalDateTime parses the value of a DATE or DATE-TIME property. Floating
+// times, and times in an unknown time zone, are taken as UTC.
+func icalDateTime(p Property) (time.Time, error) {
+	location := time.UTC
+	if tzid, ok := p.Params["TZID"]; ok {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			location = zone
+		}
+	}
+
+	value := strings.TrimSuffix(p.Value, "Z")
+	if len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, location)
+	}
+	if strings.HasSuffix(p.Value, "Z") {
+		location = time.UTC
+	}
+	return time.ParseInLocation("20060102T150405", value, location)
+}
+
+// vtodoTask returns the Task of a VTODO component. PRIORITY follows the
+// ranges of RFC 5545, where 1 to 4 are high and 6 to 9 are low.
+func vtodoTask(c Component) (*entity.Task, error) {
+	t := entity.NewTask("")
+	for _, p := range c.Properties {
+		var err error
+		switch p.Name {
+		case "UID":
+			t.ID, t.CalendarUID = TaskID(p.Value), ""
+			if t.ID.String() != p.Value {
+				t.CalendarUID = p.Value
+			}
+		case "SUMMARY":
+			t.Description = p.Text()
+		case "PRIORITY":
+			var level int
+			if level, err = strconv.Atoi(p.Value); err != nil || level < 0 || level > 9 {
+				return nil, entity.ErrInvalidPriorityLevel
+			}
+			switch {
+			case level >= 1 && level <= 4:
+				t.Priority = entity.PriorityHigh
+			case level == 5:
+				t.Priority = entity.PriorityMedium
+			default:
+				t.Priority = entity.PriorityLow
+			}
+		case "STATUS":
+			t.Completed = t.Completed || strings.EqualFold(p.Value, "COMPLETED")
+		case "COMPLETED":
+			var at time.Time
+			if at, err = icalDateTime(p); err == nil {
+				t.Completed, t.CompletedAt = true, &at
+			}
+		case "DUE":
+			var at time.Time
+			if at, err = icalDateTime(p); err == nil {
+				t.DueAt = &at
+			}
+		case "CREATED":
+			t.CreatedAt, err = icalDateTime(p)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.ToLower(p.Name), err)
+		}
+	}
+	return t, nil
+}
+
+// decodeICalendar reads the VTODO components of a VCALENDAR object. The other
+// components, such as events, are ignored.
+func decodeICalendar(r io.Reader) ([]Entry, error) {
+	calendar, err := ParseICalendar(r)
+	if err != nil {
+		return nil, err
+	}
+	if calendar.Name != "VCALENDAR" {
+		return nil, errInvalidICalendar
+	}
+
+	var entries []Entry
+	for _, component := range calendar.Components {
+		if component.Name == "VTODO" {
+			t, err := vtodoTask(component)
+			entries = append(entries, Entry{Line: component.Line, Task: t, Err: err})
+		}
+	}
+	return entries, nil
+}
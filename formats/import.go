@@ -0,0 +1,65 @@
+package formats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// errDryRun rolls back the imports which are only tried.
+var errDryRun = errors.New("dry run")
+
+// LineError reports why the Task at a line of a file was not imported.
+type LineError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Report tells what an import did, or would do in a dry run.
+type Report struct {
+	DryRun   bool `json:"dry_run"`
+	Imported int  `json:"imported"`
+
+	// Duplicates are the lines of the Tasks whose ID is already used, which
+	// are skipped.
+	Duplicates []int       `json:"duplicates"`
+	Errors     []LineError `json:"errors"`
+}
+
+// Import adds the valid Tasks of the entries to the repository, in a single
+// unit of work, and reports the others. A dry run reports the same without
+// keeping any Task.
+func Import(ctx context.Context, repo task.TaskRepository, entries []Entry, dryRun bool) (Report, error) {
+	var report Report
+	err := repo.WithTx(ctx, func(tx task.TaskRepository) error {
+		report = Report{DryRun: dryRun, Duplicates: []int{}, Errors: []LineError{}}
+		for _, entry := range entries {
+			if entry.Err != nil {
+				report.Errors = append(report.Errors, LineError{Line: entry.Line, Message: entry.Err.Error()})
+				continue
+			}
+
+			err := tx.Post(ctx, entry.Task)
+			switch {
+			case errors.Is(err, entity.ErrTaskUniqueConstraint):
+				report.Duplicates = append(report.Duplicates, entry.Line)
+			case err != nil:
+				return fmt.Errorf("line %d: %w", entry.Line, err)
+			default:
+				report.Imported++
+			}
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return report, err
+}
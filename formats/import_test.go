@@ -0,0 +1,228 @@
+package formats_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/formats"
+	"github.com/stretchr/testify/assert"
+)
+
+// day drops the time of day, which todo.txt does not keep.
+func day(at time.Time) string {
+	return at.UTC().Format("2006-01-02")
+}
+
+// roundTrip decodes the export of the Tasks in a format.
+func roundTrip(t *testing.T, format formats.Format) []formats.Entry {
+	var out bytes.Buffer
+	assert.NoError(t, formats.Encode(&out, format, exported()))
+	entries, err := formats.Decode(&out, format)
+	assert.NoError(t, err)
+	return entries
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	for _, format := range []formats.Format{formats.CSV, formats.JSON, formats.TodoTxt, formats.ICalendar} {
+		t.Run(string(format), func(t *testing.T) {
+			entries := roundTrip(t, format)
+			if !assert.Len(t, entries, 2) {
+				return
+			}
+			for i, want := range exported() {
+				got := entries[i].Task
+				assert.NoError(t, entries[i].Err)
+				assert.Equal(t, want.ID, got.ID)
+				assert.Equal(t, want.Description, got.Description)
+				assert.Equal(t, want.Priority, got.Priority)
+				assert.Equal(t, want.Completed, got.Completed)
+				assert.Equal(t, day(want.CreatedAt), day(got.CreatedAt))
+			}
+			assert.Equal(t, day(completed), day(*entries[1].Task.CompletedAt))
+		})
+	}
+
+	_, err := formats.Decode(strings.NewReader(""), formats.Markdown)
+	assert.ErrorIs(t, err, formats.ErrNotImportable)
+}
+
+func TestDecodeTodoTxt(t *testing.T) {
+	entries, err := formats.Decode(strings.NewReader(
+		"(B) Call Mom +family @phone due:2024-04-01\n"+
+			"\n"+
+			"x Mow the lawn\n"+
+			"(Z) 2024-01-02 Someday\n"+
+			"Broken id:nope\n"), formats.TodoTxt)
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 4) {
+		return
+	}
+
+	assert.Equal(t, 1, entries[0].Line)
+	assert.Equal(t, "Call Mom +family @phone due:2024-04-01", entries[0].Task.Description)
+	assert.Equal(t, entity.PriorityMedium, entries[0].Task.Priority)
+
+	assert.Equal(t, 3, entries[1].Line)
+	assert.True(t, entries[1].Task.Completed)
+	assert.Nil(t, entries[1].Task.CompletedAt)
+
+	assert.Equal(t, entity.PriorityLow, entries[2].Task.Priority)
+	assert.Equal(t, 2024, entries[2].Task.CreatedAt.Year())
+
+	assert.Equal(t, 5, entries[3].Line)
+	assert.Error(t, entries[3].Err)
+	assert.Nil(t, entries[3].Task)
+}
+
+func TestDecodeCSV(t *testing.T) {
+	entries, err := formats.Decode(strings.NewReader("\ufeffDescription,Priority,Completed\n"+
+		"Water the plants,medium,true\n"+
+		",high,false\n"+
+		"Feed the cat,urgent,false\n"+
+		"Walk the dog,2,maybe\n"+
+		"\"Read a \"book\",1,false\n"), formats.CSV)
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 5) {
+		return
+	}
+
+	assert.Equal(t, 2, entries[0].Line)
+	assert.NoError(t, entries[0].Err)
+	assert.Equal(t, entity.PriorityMedium, entries[0].Task.Priority)
+	assert.True(t, entries[0].Task.Completed)
+
+	assert.ErrorIs(t, entries[1].Err, entity.ErrInvalidTaskDescription)
+	assert.ErrorIs(t, entries[2].Err, entity.ErrInvalidPriorityLevel)
+	assert.Equal(t, 5, entries[3].Line)
+	assert.Error(t, entries[3].Err)
+	assert.Equal(t, 6, entries[4].Line)
+	assert.Error(t, entries[4].Err)
+
+	_, err = formats.Decode(strings.NewReader("title\nWater the plants\n"), formats.CSV)
+	assert.Error(t, err, "the description column is required")
+}
+
+func TestDecodeJSON(t *testing.T) {
+	entries, err := formats.Decode(strings.NewReader(`[
+  {"description": "Water the plants", "priority": "high"},
+  {"description": "Feed the cat", "priority": 2, "completed": "yes"},
+  {
+    "description": "",
+    "priority": 1
+  }
+]`), formats.JSON)
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+	assert.Equal(t, 2, entries[0].Line)
+	assert.Equal(t, entity.PriorityHigh, entries[0].Task.Priority)
+	assert.Equal(t, 3, entries[1].Line)
+	assert.Error(t, entries[1].Err)
+	assert.Equal(t, 4, entries[2].Line)
+	assert.ErrorIs(t, entries[2].Err, entity.ErrInvalidTaskDescription)
+
+	_, err = formats.Decode(strings.NewReader(`{"description": "Water the plants"}`), formats.JSON)
+	assert.Error(t, err)
+}
+
+func TestImport(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	ctx := task.WithOwner(context.Background(), uuid.New())
+
+	existing := exported()[1]
+	assert.NoError(t, repo.Post(ctx, &existing))
+
+	entries := roundTrip(t, formats.CSV)
+	entries = append(entries, formats.Entry{Line: 4, Err: entity.ErrInvalidTaskDescription})
+
+	report, err := formats.Import(ctx, repo, entries, true)
+	assert.NoError(t, err)
+	assert.Equal(t, formats.Report{
+		DryRun:     true,
+		Imported:   1,
+		Duplicates: []int{3},
+		Errors:     []formats.LineError{{Line: 4, Message: entity.ErrInvalidTaskDescription.Error()}},
+	}, report)
+	all, _ := repo.All(ctx)
+	assert.Len(t, all, 1, "a dry run keeps no Task")
+
+	entries = roundTrip(t, formats.CSV)
+	report, err = formats.Import(ctx, repo, entries, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, []int{3}, report.Duplicates)
+
+	imported, err := repo.Get(ctx, exported()[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Pack, then *leave*", imported.Description)
+	assert.True(t, created.Equal(imported.CreatedAt), "the creation date is kept")
+
+	report, err = formats.Import(ctx, repo, roundTrip(t, formats.CSV), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Imported, "importing again adds nothing")
+	assert.Equal(t, []int{2, 3}, report.Duplicates)
+}
+
+func TestDecodeICalendar(t *testing.T) {
+	entries, err := formats.Decode(strings.NewReader("BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:meeting\r\n"+
+		"SUMMARY:Not a task\r\n"+
+		"END:VEVENT\r\n"+
+		"BEGIN:VTODO\r\n"+
+		"UID:20240301T093000Z-42@example.com\r\n"+
+		"SUMMARY:Buy milk\\, eggs\r\n"+
+		"  and bread\r\n"+
+		"PRIORITY:3\r\n"+
+		"DUE;VALUE=DATE:20240401\r\n"+
+		"BEGIN:VALARM\r\n"+
+		"ACTION:DISPLAY\r\n"+
+		"SUMMARY:Reminder\r\n"+
+		"END:VALARM\r\n"+
+		"END:VTODO\r\n"+
+		"BEGIN:VTODO\r\n"+
+		"UID:11111111-1111-1111-1111-111111111111\r\n"+
+		"SUMMARY;LANGUAGE=\"en:US\":Call Mom\r\n"+
+		"STATUS:COMPLETED\r\n"+
+		"DUE;TZID=Europe/Paris:20240401T090000\r\n"+
+		"END:VTODO\r\n"+
+		"BEGIN:VTODO\r\n"+
+		"SUMMARY:Overflow\r\n"+
+		"PRIORITY:10\r\n"+
+		"END:VTODO\r\n"+
+		"END:VCALENDAR\r\n"), formats.ICalendar)
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+
+	milk := entries[0]
+	assert.Equal(t, 7, milk.Line)
+	assert.Equal(t, formats.TaskID("20240301T093000Z-42@example.com"), milk.Task.ID)
+	assert.Equal(t, milk.Task.ID, formats.TaskID("20240301T093000Z-42@example.com"), "the IDs of other UIDs are stable")
+	assert.Equal(t, "20240301T093000Z-42@example.com", formats.UID(*milk.Task), "the other UIDs are kept")
+	assert.Equal(t, "Buy milk, eggs and bread", milk.Task.Description)
+	assert.Equal(t, entity.PriorityHigh, milk.Task.Priority)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), *milk.Task.DueAt)
+
+	mom := entries[1].Task
+	assert.Equal(t, uuid.MustParse("11111111-1111-1111-1111-111111111111"), mom.ID)
+	assert.Empty(t, mom.CalendarUID)
+	assert.Equal(t, "Call Mom", mom.Description)
+	assert.True(t, mom.Completed)
+	assert.Equal(t, time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC), mom.DueAt.UTC())
+
+	assert.ErrorIs(t, entries[2].Err, entity.ErrInvalidPriorityLevel)
+
+	_, err = formats.Decode(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VCALENDAR\r\n"), formats.ICalendar)
+	assert.Error(t, err, "the components must be nested")
+}
@@ -0,0 +1,115 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+var errNotJSONArray = errors.New("the JSON must be an array of tasks")
+
+type jsonEncoder struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *jsonEncoder) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonEncoder) task(t entity.Task) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if e.written {
+		if _, err := io.WriteString(e.w, ",\n"); err != nil {
+			return err
+		}
+	}
+	e.written = true
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonEncoder) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// jsonTask holds the fields of a Task which are imported from JSON. The
+// priority is either a level or its name.
+type jsonTask struct {
+	ID          uuid.UUID       `json:"id"`
+	Description string          `json:"description"`
+	Priority    json.RawMessage `json:"priority"`
+	Completed   bool            `json:"completed"`
+	Archived    bool            `json:"archived"`
+	CreatedAt   *time.Time      `json:"created_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	ArchivedAt  *time.Time      `json:"archived_at"`
+}
+
+func (jt jsonTask) task() (*entity.Task, error) {
+	t := entity.NewTask(jt.Description)
+	if jt.ID != uuid.Nil {
+		t.ID = jt.ID
+	}
+	if priority := strings.Trim(string(jt.Priority), `"`); priority != "" && priority != "null" {
+		var err error
+		if t.Priority, err = entity.ParsePriority(priority); err != nil {
+			return nil, err
+		}
+	}
+	t.Completed, t.CompletedAt = jt.Completed, jt.CompletedAt
+	t.Archived, t.ArchivedAt = jt.Archived, jt.ArchivedAt
+	if jt.CreatedAt != nil {
+		t.CreatedAt = *jt.CreatedAt
+	}
+	return t, nil
+}
+
+// decodeJSON reads an array of Tasks. The Line of an Entry is the one where
+// its Task begins.
+func decodeJSON(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errNotJSONArray
+	}
+
+	var entries []Entry
+	for decoder.More() {
+		start := int(decoder.InputOffset())
+		start += len(data[start:]) - len(bytes.TrimLeft(data[start:], " \t\r\n,"))
+		line := 1 + bytes.Count(data[:start], []byte("\n"))
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		var jt jsonTask
+		entry := Entry{Line: line}
+		if entry.Err = json.Unmarshal(raw, &jt); entry.Err == nil {
+			entry.Task, entry.Err = jt.task()
+		}
+		entries = append(entries, entry)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
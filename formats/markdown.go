@@ -0,0 +1,52 @@
+package formats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// markdownEscaper escapes the characters which Markdown would format.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`, `~`, `\~`,
+)
+
+// checkbox is the task list marker of a Task or checklist item.
+func checkbox(done bool) string {
+	if done {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+type markdownEncoder struct {
+	w io.Writer
+}
+
+func (e *markdownEncoder) begin() error {
+	_, err := io.WriteString(e.w, "# Tasks\n\n")
+	return err
+}
+
+func (e *markdownEncoder) task(t entity.Task) error {
+	_, err := fmt.Fprintf(e.w, "- %s %s *(%s)*\n",
+		checkbox(t.Completed), markdownEscaper.Replace(singleLine(t.Description)), t.Priority)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range t.Checklist {
+		_, err := fmt.Fprintf(e.w, "  - %s %s\n", checkbox(item.Done), markdownEscaper.Replace(singleLine(item.Text)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *markdownEncoder) end() error {
+	return nil
+}
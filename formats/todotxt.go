@@ -0,0 +1,147 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// todoTxtDate is the layout of the dates of todo.txt.
+const todoTxtDate = "2006-01-02"
+
+// todoTxtPriority maps the priorities of the Tasks onto the letters of
+// todo.txt, where (A) comes first.
+var todoTxtPriority = map[entity.Priority]string{
+	entity.PriorityHigh:   "A",
+	entity.PriorityMedium: "B",
+	entity.PriorityLow:    "C",
+}
+
+// todoTxtLine returns the todo.txt line of a Task. Completed Tasks lose their
+// priority, which is kept as a pri: tag as is customary, and every Task keeps
+// its ID as an id: tag.
+func todoTxtLine(t entity.Task) string {
+	var fields []string
+	if t.Completed {
+		fields = append(fields, "x", completedAt(t).UTC().Format(todoTxtDate))
+	} else if letter, ok := todoTxtPriority[t.Priority]; ok {
+		fields = append(fields, "("+letter+")")
+	}
+	if !t.CreatedAt.IsZero() {
+		fields = append(fields, t.CreatedAt.UTC().Format(todoTxtDate))
+	}
+
+	fields = append(fields, singleLine(t.Description))
+	if letter, ok := todoTxtPriority[t.Priority]; ok && t.Completed {
+		fields = append(fields, "pri:"+letter)
+	}
+	fields = append(fields, "id:"+t.ID.String())
+	return strings.Join(fields, " ")
+}
+
+type todoTxtEncoder struct {
+	w io.Writer
+}
+
+func (e *todoTxtEncoder) begin() error {
+	return nil
+}
+
+func (e *todoTxtEncoder) task(t entity.Task) error {
+	_, err := io.WriteString(e.w, todoTxtLine(t)+"\n")
+	return err
+}
+
+func (e *todoTxtEncoder) end() error {
+	return nil
+}
+
+// todoTxtLetter returns the priority of a todo.txt letter. The letters after
+// (C) are low priorities too.
+func todoTxtLetter(letter string) (entity.Priority, bool) {
+	if len(letter) != 1 || letter[0] < 'A' || letter[0] > 'Z' {
+		return 0, false
+	}
+	for priority, l := range todoTxtPriority {
+		if l == letter {
+			return priority, true
+		}
+	}
+	return entity.PriorityLow, true
+}
+
+// todoTxtTask returns the Task of a todo.txt line. The id: and pri: tags are
+// read back into the Task, and the other tags, projects and contexts are left
+// in its description.
+func todoTxtTask(line string) (*entity.Task, error) {
+	fields := strings.Fields(line)
+	t := entity.NewTask("")
+
+	// date reads the date in front of the remaining fields, if any.
+	date := func() (time.Time, bool) {
+		if len(fields) == 0 {
+			return time.Time{}, false
+		}
+		at, err := time.Parse(todoTxtDate, fields[0])
+		if err == nil {
+			fields = fields[1:]
+		}
+		return at, err == nil
+	}
+
+	if len(fields) > 0 && fields[0] == "x" {
+		fields = fields[1:]
+		t.Completed = true
+		if at, ok := date(); ok {
+			t.CompletedAt = &at
+		}
+	} else if len(fields) > 0 && strings.HasPrefix(fields[0], "(") && strings.HasSuffix(fields[0], ")") {
+		if priority, ok := todoTxtLetter(strings.Trim(fields[0], "()")); ok {
+			fields = fields[1:]
+			t.Priority = priority
+		}
+	}
+	if at, ok := date(); ok {
+		t.CreatedAt = at
+	}
+
+	description := fields[:0]
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, ":")
+		switch key {
+		case "id":
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("id: %w", err)
+			}
+			t.ID = id
+			continue
+		case "pri":
+			if priority, ok := todoTxtLetter(value); ok {
+				t.Priority = priority
+				continue
+			}
+		}
+		description = append(description, field)
+	}
+	t.Description = strings.Join(description, " ")
+	return t, nil
+}
+
+// decodeTodoTxt reads a Task from every line which is not blank.
+func decodeTodoTxt(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if text := strings.TrimSpace(scanner.Text()); text != "" {
+			t, err := todoTxtTask(text)
+			entries = append(entries, Entry{Line: line, Task: t, Err: err})
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+const (
+	// DefaultPageSize is the number of Tasks in a page unless asked otherwise.
+	DefaultPageSize = 50
+
+	// MaxPageSize is the largest number of Tasks in a page.
+	MaxPageSize = 200
+
+	// DefaultArchiveAfterDays is how long ago Tasks must have been completed
+	// to be archived, unless asked otherwise.
+	DefaultArchiveAfterDays = 30
+)
+
+// TaskPage is a page of Tasks along with the total number of Tasks.
+type TaskPage struct {
+	Tasks  []entity.Task `json:"tasks"`
+	Total  int64         `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// pagination reads the limit and offset query parameters.
+func pagination(c *fiber.Ctx) (int, int, error) {
+	limit := c.QueryInt("limit", DefaultPageSize)
+	if limit < 1 || limit > MaxPageSize {
+		return 0, 0, fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	}
+
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		return 0, 0, fmt.Errorf("offset cannot be negative")
+	}
+	return limit, offset, nil
+}
+
+func ArchivedTasks(c *fiber.Ctx) error {
+	limit, offset, err := pagination(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	tasks, total, err := database.Repo.Find(c.UserContext(), task.Filter{
+		Archived: true,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(TaskPage{Tasks: tasks, Total: total, Limit: limit, Offset: offset})
+}
+
+func ArchiveCompletedTasks(c *fiber.Ctx) error {
+	days := c.QueryInt("older_than_days", DefaultArchiveAfterDays)
+	if days < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "older_than_days cannot be negative"})
+	}
+
+	before := time.Now().AddDate(0, 0, -days)
+	archived, err := database.Repo.ArchiveCompleted(c.UserContext(), before)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"archived": archived})
+}
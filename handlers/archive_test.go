@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveCompletedTasks(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	done := entity.NewTask("Completed task").WithCompleted(true)
+	open := entity.NewTask("Open task")
+	assert.NoError(t, database.Repo.PostMany(testCtx, []*entity.Task{done, open}))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodPost, "/archive?older_than_days=1", nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body map[string]int
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 0, body["archived"], "the task was completed too recently")
+
+	req = httptest.NewRequest(http.MethodPost, "/archive?older_than_days=0", nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 1, body["archived"])
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	resp, _ = app.Test(authorized(req), -1)
+	var tasks []entity.Task
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&tasks))
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, open.ID, tasks[0].ID)
+
+	req = httptest.NewRequest(http.MethodPost, "/archive?older_than_days=-1", nil)
+	resp, _ = app.Test(authorized(req), -1)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestArchivedTasks(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	for i := 0; i < 3; i++ {
+		task := entity.NewTask(GENERIC_TASK_NAME)
+		task.Archived = true
+		assert.NoError(t, database.Repo.Post(testCtx, task))
+	}
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/archive?limit=2&offset=1", nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var page handlers.TaskPage
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, 2, page.Limit)
+	assert.Equal(t, 1, page.Offset)
+	assert.Len(t, page.Tasks, 2)
+	assert.True(t, page.Tasks[0].Archived)
+
+	req = httptest.NewRequest(http.MethodGet, "/archive?limit=1000", nil)
+	resp, _ = app.Test(authorized(req), -1)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
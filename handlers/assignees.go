@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+)
+
+// Assignment names the User a Task is assigned to.
+type Assignment struct {
+	Username string `json:"username"`
+}
+
+func AssignTask(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	assignment := new(Assignment)
+	if err := c.BodyParser(assignment); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	user, err := member(c, assignment.Username)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Repo.Assign(c.UserContext(), uuid, user.ID); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return GetTask(c)
+}
+
+func UnassignTask(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	if err := database.Repo.Unassign(c.UserContext(), uuid); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return GetTask(c)
+}
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignTask(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+
+	owner := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &owner))
+	outsider := entity.User{ID: uuid.New(), Username: "outsider", WorkspaceID: uuid.New()}
+	assert.NoError(t, repo.CreateUser(testCtx, &outsider))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	assigned, unassigned := entity.NewTask("Assigned"), entity.NewTask("Unassigned")
+	assert.NoError(t, database.Repo.Post(testCtx, assigned))
+	assert.NoError(t, database.Repo.Post(testCtx, unassigned))
+
+	send := func(method, path string, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		resp, err := app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		return resp
+	}
+	path := "/task/" + assigned.ID.String() + "/assignee"
+
+	assert.Equal(t, fiber.StatusNotFound, send(http.MethodPut, path, handlers.Assignment{Username: "outsider"}).StatusCode,
+		"Users of another workspace cannot be assigned")
+
+	resp := send(http.MethodPut, path, handlers.Assignment{Username: testUser.Username})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var task entity.Task
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&task))
+	if assert.NotNil(t, task.AssigneeID) {
+		assert.Equal(t, testUser.ID, *task.AssigneeID)
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expected     []uuid.UUID
+	}{
+		{"List every Task", "", fiber.StatusOK, []uuid.UUID{assigned.ID, unassigned.ID}},
+		{"List the Tasks assigned to me", "?assignee=me", fiber.StatusOK, []uuid.UUID{assigned.ID}},
+		{"List the unassigned Tasks", "?assignee=none", fiber.StatusOK, []uuid.UUID{unassigned.ID}},
+		{"Reject an unknown assignee filter", "?assignee=everybody", fiber.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := send(http.MethodGet, "/"+tt.query, nil)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			if tt.expected == nil {
+				return
+			}
+
+			var tasks []entity.Task
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&tasks))
+			ids := make([]uuid.UUID, 0, len(tasks))
+			for _, task := range tasks {
+				ids = append(ids, task.ID)
+			}
+			assert.ElementsMatch(t, tt.expected, ids)
+		})
+	}
+
+	t.Run("Assign another member", func(t *testing.T) {
+		member := entity.User{ID: uuid.New(), Username: "member", WorkspaceID: testUser.WorkspaceID}
+		assert.NoError(t, repo.CreateUser(testCtx, &member))
+		token, _, _ := auth.Default.Issue(member)
+		asMember := func(method, path string) *http.Response {
+			data, _ := json.Marshal(entity.Task{ID: assigned.ID, Description: "Taken over"})
+			req := httptest.NewRequest(method, path, bytes.NewBuffer(data))
+			req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			return resp
+		}
+
+		assert.Equal(t, fiber.StatusNoContent, asMember(http.MethodGet, "/task/"+assigned.ID.String()).StatusCode)
+		assert.Equal(t, fiber.StatusOK, send(http.MethodPut, path, handlers.Assignment{Username: "member"}).StatusCode)
+
+		assert.Equal(t, fiber.StatusOK, asMember(http.MethodGet, "/task/"+assigned.ID.String()).StatusCode,
+			"the assignee can see the Task")
+		var mine []entity.Task
+		assert.NoError(t, json.NewDecoder(asMember(http.MethodGet, "/?assignee=me").Body).Decode(&mine))
+		if assert.Len(t, mine, 1) {
+			assert.Equal(t, assigned.ID, mine[0].ID)
+		}
+		assert.Equal(t, fiber.StatusForbidden, asMember(http.MethodPut, "/task/"+assigned.ID.String()).StatusCode,
+			"the assignee only views the Task")
+		assert.Equal(t, fiber.StatusNoContent, asMember(http.MethodGet, "/task/"+unassigned.ID.String()).StatusCode)
+	})
+
+	resp = send(http.MethodDelete, path, nil)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&task))
+	assert.Nil(t, task.AssigneeID)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// BodyLimit is the size of the largest request the server must read, leaving
+// room for the multipart encoding of the largest Attachment. Only the uploads
+// of Attachments may be that large: LimitBody holds every other request to
+// fiber.DefaultBodyLimit.
+const BodyLimit = entity.MaxAttachmentSize + 1<<20
+
+// LimitBody rejects the requests whose body is larger than
+// fiber.DefaultBodyLimit, unless they upload an Attachment.
+func LimitBody(c *fiber.Ctx) error {
+	if uploadsAttachment(c) {
+		return c.Next()
+	}
+	if c.Request().Header.ContentLength() > fiber.DefaultBodyLimit || len(c.Request().Body()) > fiber.DefaultBodyLimit {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"message": fiber.ErrRequestEntityTooLarge.Message})
+	}
+	return c.Next()
+}
+
+// uploadsAttachment reports whether the request is routed to UploadAttachment.
+func uploadsAttachment(c *fiber.Ctx) bool {
+	segments := strings.Split(strings.Trim(c.Path(), "/"), "/")
+	return c.Method() == fiber.MethodPost && len(segments) == 3 && segments[0] == "task" && segments[2] == "attachments"
+}
+
+// attachmentIDs reads the IDs of the Task and of the Attachment from the path.
+func attachmentIDs(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	taskID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	id, err := uuid.Parse(c.Params("attachment"))
+	return taskID, id, err
+}
+
+func ListAttachments(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	attachments, err := database.Attachments.Attachments(c.UserContext(), uuid)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(attachments)
+}
+
+// UploadAttachment attaches the file of the "file" field of a multipart form
+// to a Task.
+func UploadAttachment(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(entity.ErrInvalidAttachment))
+	}
+	if header.Size > entity.MaxAttachmentSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(errorBody(entity.ErrAttachmentTooLarge))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+	defer file.Close()
+
+	// The content type declared by the client is ignored in favor of the one
+	// sniffed from the first bytes of the file.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+	head = head[:n]
+
+	attachment, err := entity.NewAttachment(uuid, header.Filename, http.DetectContentType(head), header.Size)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Attachments.AddAttachment(c.UserContext(), attachment); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	contents := io.MultiReader(bytes.NewReader(head), file)
+	if err := blob.Default.Put(c.UserContext(), attachment.Key(), contents, attachment.Size, attachment.ContentType); err != nil {
+		// Without its contents, the Attachment is not kept.
+		_ = database.Attachments.DeleteAttachment(c.UserContext(), uuid, attachment.ID)
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(attachment)
+}
+
+// DownloadAttachment sends the contents of an Attachment, always as a file to
+// save rather than to display.
+func DownloadAttachment(c *fiber.Ctx) error {
+	taskID, id, err := attachmentIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	attachment, err := database.Attachments.GetAttachment(c.UserContext(), taskID, id)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	contents, err := blob.Default.Get(c.UserContext(), attachment.Key())
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentLength, strconv.FormatInt(attachment.Size, 10))
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.Status(fiber.StatusOK).SendStream(contents, int(attachment.Size))
+}
+
+func DeleteAttachment(c *fiber.Ctx) error {
+	taskID, id, err := attachmentIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	attachment, err := database.Attachments.GetAttachment(c.UserContext(), taskID, id)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Attachments.DeleteAttachment(c.UserContext(), taskID, id); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := blob.Default.Delete(c.UserContext(), attachment.Key()); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
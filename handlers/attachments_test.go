@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+// upload creates a request uploading a file as the "file" field of a
+// multipart form.
+func upload(target string, filename string, contents []byte) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", filename)
+	part.Write(contents)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set(HEADER_CONTENT_TYPE, form.FormDataContentType())
+	return req
+}
+
+func TestAttachments(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users, database.Attachments = repo, repo, repo
+	store, err := blob.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	blob.Default = store
+
+	owner := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &owner))
+	viewer := entity.User{ID: uuid.New(), Username: "viewer"}
+	assert.NoError(t, repo.CreateUser(testCtx, &viewer))
+	session, _, _ := auth.Default.Issue(viewer)
+
+	app := fiber.New(fiber.Config{BodyLimit: handlers.BodyLimit})
+	router.SetupRoutes(app)
+
+	documented := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, documented))
+	assert.NoError(t, repo.Share(testCtx, documented.ID, viewer.ID, entity.RoleViewer))
+	path := "/task/" + documented.ID.String() + "/attachments"
+
+	png := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), make([]byte, 64)...)
+	resp, err := app.Test(authorized(upload(path, "screen shot.txt", png)), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var attachment entity.Attachment
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&attachment))
+	assert.Equal(t, "image/png", attachment.ContentType, "the content type is sniffed, not taken from the name")
+	assert.Equal(t, int64(len(png)), attachment.Size)
+	target := path + "/" + attachment.ID.String()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+session)
+	resp, err = app.Test(req, -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, `attachment; filename="screen shot.txt"`, resp.Header.Get(fiber.HeaderContentDisposition))
+	assert.Equal(t, "nosniff", resp.Header.Get(fiber.HeaderXContentTypeOptions))
+	downloaded, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, png, downloaded)
+
+	resp, err = app.Test(authorized(upload(path, "lost.png", png)), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	var lost entity.Attachment
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&lost))
+	assert.NoError(t, store.Delete(testCtx, lost.Key()))
+	resp, err = app.Test(authorized(httptest.NewRequest(http.MethodGet, path+"/"+lost.ID.String(), nil)), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode, "an attachment without its contents is not found")
+	resp, err = app.Test(authorized(httptest.NewRequest(http.MethodDelete, path+"/"+lost.ID.String(), nil)), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Only the uploads may be larger than the default limit.
+	req = httptest.NewRequest(http.MethodPost, "/task", bytes.NewReader(make([]byte, fiber.DefaultBodyLimit+1)))
+	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	withSession := func(req *http.Request) *http.Request {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+session)
+		return req
+	}
+
+	tests := []struct {
+		name         string
+		req          *http.Request
+		expectedCode int
+	}{
+		{"Reject an upload by a viewer", withSession(upload(path, "notes.txt", []byte("notes"))), fiber.StatusForbidden},
+		{"Reject an empty file", authorized(upload(path, "empty.txt", nil)), fiber.StatusBadRequest},
+		{"Reject a request without a file", authorized(httptest.NewRequest(http.MethodPost, path, nil)), fiber.StatusBadRequest},
+		{"Reject a file over the size limit", authorized(upload(path, "huge.bin", make([]byte, entity.MaxAttachmentSize+1))), fiber.StatusRequestEntityTooLarge},
+		{"Upload to an unknown Task", authorized(upload("/task/"+uuid.NewString()+"/attachments", "notes.txt", []byte("notes"))), fiber.StatusNotFound},
+		{"List the attachments", withSession(httptest.NewRequest(http.MethodGet, path, nil)), fiber.StatusOK},
+		{"Download an unknown attachment", authorized(httptest.NewRequest(http.MethodGet, path+"/"+uuid.NewString(), nil)), fiber.StatusNotFound},
+		{"Reject a deletion by a viewer", withSession(httptest.NewRequest(http.MethodDelete, target, nil)), fiber.StatusForbidden},
+		{"Delete an attachment", authorized(httptest.NewRequest(http.MethodDelete, target, nil)), fiber.StatusOK},
+		{"Download a deleted attachment", authorized(httptest.NewRequest(http.MethodGet, target, nil)), fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(tt.req, -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+
+	attachments, _ := repo.Attachments(testCtx, documented.ID)
+	assert.Empty(t, attachments, "rejected uploads are not recorded")
+	_, err = store.Get(testCtx, attachment.Key())
+	assert.ErrorIs(t, err, blob.ErrNotFound, "the contents are deleted with the attachment")
+}
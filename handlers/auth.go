@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Credentials are the username and password of a User.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Session is a token identifying a User until it expires.
+type Session struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	User      entity.User `json:"user"`
+}
+
+// errBearerTokenRequired is reported to the requests which are not authenticated.
+var errBearerTokenRequired = errors.New("a bearer token is required")
+
+// dummyUser has the hash of a random password, so that logging in as an
+// unknown User takes as long as with a wrong password.
+var dummyUser, _ = entity.NewUser("nobody", "not a password")
+
+func Register(c *fiber.Ctx) error {
+	credentials := new(Credentials)
+	if err := c.BodyParser(credentials); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	user, err := entity.NewUser(credentials.Username, credentials.Password)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Users.CreateUser(c.UserContext(), user); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(user)
+}
+
+func Login(c *fiber.Ctx) error {
+	credentials := new(Credentials)
+	if err := c.BodyParser(credentials); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	user, err := database.Users.UserByName(c.UserContext(), credentials.Username)
+	if err != nil {
+		dummyUser.CheckPassword(credentials.Password)
+		return c.Status(fiber.StatusUnauthorized).JSON(errorBody(entity.ErrInvalidCredentials))
+	}
+	if !user.CheckPassword(credentials.Password) {
+		return c.Status(fiber.StatusUnauthorized).JSON(errorBody(entity.ErrInvalidCredentials))
+	}
+
+	token, expires, err := auth.Default.Issue(user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(Session{Token: token, ExpiresAt: expires, User: user})
+}
+
+func CurrentUser(c *fiber.Ctx) error {
+	owner, _ := task.OwnerFromContext(c.UserContext())
+
+	user, err := database.Users.GetUser(c.UserContext(), owner)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// Authenticate rejects the requests without a valid bearer token, which is
+// either a session or an API token. The User it identifies owns the Tasks of
+// the request and is recorded as the actor of their changes.
+func Authenticate(c *fiber.Ctx) error {
+	header := c.Get(fiber.HeaderAuthorization)
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found || token == "" {
+		return unauthorized(c, errBearerTokenRequired)
+	}
+	if entity.IsAPIToken(token) {
+		return authenticateAPIToken(c, token)
+	}
+
+	claims, err := auth.Default.Verify(token)
+	if err != nil {
+		return unauthorized(c, err)
+	}
+
+	id, _ := claims.UserID()
+	return identify(c, id, claims.Username, claims.WorkspaceID)
+}
+
+// authenticateAPIToken accepts the requests allowed by the scope of an active
+// API token.
+func authenticateAPIToken(c *fiber.Ctx, token string) error {
+	user, scope, err := apiTokenUser(c.UserContext(), token, c.Method())
+	if errors.Is(err, entity.ErrInsufficientTokenScope) {
+		return c.Status(fiber.StatusForbidden).JSON(errorBody(err))
+	} else if err != nil {
+		return unauthorized(c, err)
+	}
+
+	c.SetUserContext(withTokenScope(c.UserContext(), scope))
+	return identify(c, user.ID, user.Username, user.WorkspaceID)
+}
+
+// apiTokenUser returns the User and the scope of an active API token, if its
+// scope allows a request with the HTTP method.
+func apiTokenUser(ctx context.Context, token string, method string) (entity.User, entity.TokenScope, error) {
+	user, apiToken, err := lookupAPIToken(ctx, token)
+	if err != nil {
+		return entity.User{}, "", err
+	}
+	if err := useAPIToken(ctx, apiToken, method); err != nil {
+		return entity.User{}, "", err
+	}
+	return user, apiToken.Scope, nil
+}
+
+// lookupAPIToken returns an active API token and its User, without recording
+// its use.
+func lookupAPIToken(ctx context.Context, token string) (entity.User, entity.APIToken, error) {
+	if !entity.IsAPIToken(token) {
+		return entity.User{}, entity.APIToken{}, auth.ErrInvalidToken
+	}
+	apiToken, err := database.Users.TokenByHash(ctx, entity.HashAPIToken(token))
+	if err != nil || !apiToken.Active(time.Now()) {
+		return entity.User{}, entity.APIToken{}, auth.ErrInvalidToken
+	}
+
+	user, err := database.Users.GetUser(ctx, apiToken.UserID)
+	if err != nil {
+		return entity.User{}, entity.APIToken{}, auth.ErrInvalidToken
+	}
+	return user, apiToken, nil
+}
+
+// useAPIToken records the use of an API token for a request with the HTTP
+// method, if its scope allows it.
+func useAPIToken(ctx context.Context, apiToken entity.APIToken, method string) error {
+	if !apiToken.Scope.Allows(method) {
+		return entity.ErrInsufficientTokenScope
+	}
+
+	now := time.Now()
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= TokenUsageResolution {
+		if err := database.Users.TouchToken(ctx, apiToken.ID, now); err != nil {
+			log.Println("Failed to record the use of an API token. \n", err)
+		}
+	}
+	return nil
+}
+
+// scopeKey is the context key of the scope of the API token authenticating a
+// request. The requests authenticated by a session have none.
+type scopeKey struct{}
+
+// withTokenScope records the scope of the API token authenticating a request.
+func withTokenScope(ctx context.Context, scope entity.TokenScope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// tokenScope returns the scope of the API token authenticating a request, if
+// it was authenticated by one.
+func tokenScope(ctx context.Context) (entity.TokenScope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(entity.TokenScope)
+	return scope, ok
+}
+
+// RequireSession rejects the requests authenticated by an API token rather
+// than a session, so that the API tokens cannot create or revoke API tokens,
+// nor manage the members of the workspace. It must be used after
+// Authenticate.
+func RequireSession(c *fiber.Ctx) error {
+	if _, ok := tokenScope(c.UserContext()); ok {
+		return c.Status(fiber.StatusForbidden).JSON(errorBody(entity.ErrSessionRequired))
+	}
+	return c.Next()
+}
+
+// identify restricts the request to the Tasks of the User, in its workspace.
+func identify(c *fiber.Ctx, id uuid.UUID, username string, workspace uuid.UUID) error {
+	c.SetUserContext(identified(c.UserContext(), id, username, workspace))
+	return c.Next()
+}
+
+// identified is the context of the requests of the User.
+func identified(ctx context.Context, id uuid.UUID, username string, workspace uuid.UUID) context.Context {
+	ctx = task.WithOwner(ctx, id)
+	ctx = task.WithWorkspace(ctx, workspace)
+	return task.WithActor(ctx, username)
+}
+
+func unauthorized(c *fiber.Ctx, err error) error {
+	c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	return c.Status(fiber.StatusUnauthorized).JSON(errorBody(err))
+}
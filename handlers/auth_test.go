@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+// testUser owns the Tasks of the handler tests.
+var testUser = entity.User{ID: uuid.New(), Username: "tester"}
+
+// testCtx sees the Tasks of testUser, as the handlers do for its requests.
+var testCtx = task.WithOwner(context.Background(), testUser.ID)
+
+// authorized authenticates a request as testUser.
+func authorized(req *http.Request) *http.Request {
+	token, _, _ := auth.Default.Issue(testUser)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	return req
+}
+
+func postJSON(app *fiber.App, path string, body interface{}) (*http.Response, error) {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(data))
+	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+	return app.Test(req, -1)
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	credentials := handlers.Credentials{Username: "alice", Password: "correct horse"}
+
+	resp, err := postJSON(app, "/auth/register", credentials)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var user map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+	assert.Equal(t, "alice", user["username"])
+	assert.NotContains(t, user, "password_hash", "the password hash must never be returned")
+
+	tests := []struct {
+		name         string
+		path         string
+		credentials  handlers.Credentials
+		expectedCode int
+	}{
+		{"Reject a taken username", "/auth/register", credentials, fiber.StatusConflict},
+		{"Reject an invalid username", "/auth/register", handlers.Credentials{Username: "a", Password: "correct horse"}, fiber.StatusBadRequest},
+		{"Reject a short password", "/auth/register", handlers.Credentials{Username: "bob", Password: "short"}, fiber.StatusBadRequest},
+		{"Reject a wrong password", "/auth/login", handlers.Credentials{Username: "alice", Password: "wrong horse"}, fiber.StatusUnauthorized},
+		{"Reject an unknown user", "/auth/login", handlers.Credentials{Username: "carol", Password: "correct horse"}, fiber.StatusUnauthorized},
+		{"Log in", "/auth/login", credentials, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := postJSON(app, tt.path, tt.credentials)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+
+	resp, err = postJSON(app, "/auth/login", credentials)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+
+	var session handlers.Session
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&session))
+	assert.NotEmpty(t, session.Token)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+session.Token)
+	resp, err = app.Test(req, -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var me entity.User
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&me))
+	assert.Equal(t, session.User.ID, me.ID)
+}
+
+func TestAuthenticate(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	forged, _, _ := auth.NewTokens([]byte("not the secret"), auth.DefaultTokenTTL).Issue(testUser)
+	expired, _, _ := auth.NewTokens([]byte("not the secret"), -auth.DefaultTokenTTL).Issue(testUser)
+
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{"Reject a request without a token", ""},
+		{"Reject a request without a bearer token", "Basic dGVzdGVyOnRlc3Rlcg=="},
+		{"Reject a malformed token", "Bearer not-a-token"},
+		{"Reject a token signed with another secret", "Bearer " + forged},
+		{"Reject an expired token", "Bearer " + expired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+			assert.Equal(t, "Bearer", resp.Header.Get(fiber.HeaderWWWAuthenticate))
+		})
+	}
+}
+
+func TestTaskOwnership(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	mine := entity.NewTask("Mine")
+	assert.NoError(t, database.Repo.Post(testCtx, mine))
+
+	other := entity.User{ID: uuid.New(), Username: "other"}
+	theirs := entity.NewTask("Theirs")
+	assert.NoError(t, database.Repo.Post(task.WithOwner(context.Background(), other.ID), theirs))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+
+	var tasks []entity.Task
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&tasks))
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, mine.ID, tasks[0].ID)
+		assert.Equal(t, testUser.ID, tasks[0].OwnerID)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/task/"+theirs.ID.String(), nil)
+	_, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+
+	_, err = database.Repo.Get(context.Background(), theirs.ID)
+	assert.NoError(t, err, "the Task of another User must not be deleted")
+}
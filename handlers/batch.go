@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+const (
+	// BatchModeAtomic applies either every operation of a batch or none.
+	BatchModeAtomic = "atomic"
+
+	// BatchModeBestEffort applies as many operations of a batch as possible.
+	BatchModeBestEffort = "best_effort"
+
+	// MaxBatchOperations is the largest number of operations in a batch.
+	MaxBatchOperations = 1000
+)
+
+// BatchRequest is the body accepted by BatchTasks.
+type BatchRequest struct {
+	Mode       string           `json:"mode"`
+	Operations []task.Operation `json:"operations"`
+}
+
+// BatchResult describes the outcome of a single operation in a batch.
+type BatchResult struct {
+	Index  int          `json:"index"`
+	Op     task.Action  `json:"op"`
+	ID     uuid.UUID    `json:"id"`
+	Status int          `json:"status"`
+	Task   *entity.Task `json:"task,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Code   string       `json:"code,omitempty"`
+}
+
+func newBatchResult(index int, op task.Operation, err error) BatchResult {
+	result := BatchResult{Index: index, Op: op.Action, ID: op.TaskID()}
+	switch {
+	case err != nil:
+		result.Status = statusFor(err)
+		result.Error = err.Error()
+		result.Code = entity.ErrorCode(err)
+	case op.Action == task.ActionCreate:
+		result.Status = fiber.StatusCreated
+		result.Task = op.Task
+	case op.Action == task.ActionUpdate:
+		result.Status = fiber.StatusOK
+		result.Task = op.Task
+	default:
+		result.Status = fiber.StatusOK
+	}
+	return result
+}
+
+func BatchTasks(c *fiber.Ctx) error {
+	req := new(BatchRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	if len(req.Operations) == 0 || len(req.Operations) > MaxBatchOperations {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": fmt.Sprintf("a batch must contain between 1 and %d operations", MaxBatchOperations),
+		})
+	}
+
+	// Assign IDs upfront so that every result can report the Task it created.
+	for _, op := range req.Operations {
+		if op.Action == task.ActionCreate && op.Task != nil && op.Task.ID == uuid.Nil {
+			op.Task.ID = uuid.New()
+		}
+	}
+
+	results := make([]BatchResult, 0, len(req.Operations))
+
+	switch req.Mode {
+	case "", BatchModeAtomic:
+		if err := task.Batch(c.UserContext(), database.Repo, req.Operations); err != nil {
+			var opErr *task.OperationError
+			if errors.As(err, &opErr) {
+				body := errorBody(err)
+				body["index"] = opErr.Index
+				return c.Status(statusFor(opErr.Err)).JSON(body)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+		}
+
+		for i, op := range req.Operations {
+			results = append(results, newBatchResult(i, op, nil))
+		}
+		return c.Status(fiber.StatusOK).JSON(results)
+
+	case BatchModeBestEffort:
+		for i, op := range req.Operations {
+			err := task.Apply(c.UserContext(), database.Repo, op)
+			results = append(results, newBatchResult(i, op, err))
+		}
+		return c.Status(fiber.StatusMultiStatus).JSON(results)
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"message": fmt.Sprintf("unknown mode %q, expected %q or %q", req.Mode, BatchModeAtomic, BatchModeBestEffort),
+	})
+}
@@ -0,0 +1,138 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+const API_PATH_BATCH string = "/tasks:batch"
+
+func postBatch(t *testing.T, app *fiber.App, batch handlers.BatchRequest) *http.Response {
+	body, _ := json.Marshal(batch)
+	req := httptest.NewRequest(http.MethodPost, API_PATH_BATCH, bytes.NewBuffer(body))
+	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	return resp
+}
+
+func TestBatchTasksAtomic(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	existing := entity.NewTask(GENERIC_TASK_NAME)
+	obsolete := entity.NewTask("Obsolete task")
+	assert.NoError(t, database.Repo.Post(testCtx, existing))
+	assert.NoError(t, database.Repo.Post(testCtx, obsolete))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	updated := *existing
+	updated.Completed = true
+	resp := postBatch(t, app, handlers.BatchRequest{
+		Operations: []task.Operation{
+			{Action: task.ActionCreate, Task: &entity.Task{Description: "New task", Priority: entity.PriorityHigh}},
+			{Action: task.ActionUpdate, Task: &updated},
+			{Action: task.ActionDelete, ID: obsolete.ID},
+		},
+	})
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var results []handlers.BatchResult
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+	assert.Len(t, results, 3)
+	assert.Equal(t, fiber.StatusCreated, results[0].Status)
+
+	created, err := database.Repo.Get(testCtx, results[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "New task", created.Description)
+
+	record, _ := database.Repo.Get(testCtx, existing.ID)
+	assert.True(t, record.Completed)
+
+	_, err = database.Repo.Get(testCtx, obsolete.ID)
+	assert.ErrorIs(t, err, entity.ErrTaskNotFound)
+}
+
+func TestBatchTasksAtomicRollback(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	missing := entity.NewTask("Missing task")
+	resp := postBatch(t, app, handlers.BatchRequest{
+		Mode: handlers.BatchModeAtomic,
+		Operations: []task.Operation{
+			{Action: task.ActionCreate, Task: entity.NewTask("New task")},
+			{Action: task.ActionDelete, ID: missing.ID},
+		},
+	})
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, float64(1), body["index"])
+
+	tasks, _ := database.Repo.All(testCtx)
+	assert.Len(t, tasks, 0, "the create operation should have been rolled back")
+}
+
+func TestBatchTasksBestEffort(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	missing := entity.NewTask("Missing task")
+	resp := postBatch(t, app, handlers.BatchRequest{
+		Mode: handlers.BatchModeBestEffort,
+		Operations: []task.Operation{
+			{Action: task.ActionCreate, Task: entity.NewTask("New task")},
+			{Action: task.ActionUpdate, Task: missing},
+			{Action: task.ActionCreate, Task: &entity.Task{Priority: entity.PriorityLow}},
+			{Action: "archive", ID: missing.ID},
+		},
+	})
+	assert.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+
+	var results []handlers.BatchResult
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+	assert.Len(t, results, 4)
+	assert.Equal(t, fiber.StatusCreated, results[0].Status)
+	assert.Equal(t, fiber.StatusNotFound, results[1].Status)
+	assert.Equal(t, fiber.StatusBadRequest, results[2].Status)
+	assert.Equal(t, fiber.StatusBadRequest, results[3].Status)
+	assert.NotEmpty(t, results[3].Error)
+
+	tasks, _ := database.Repo.All(testCtx)
+	assert.Len(t, tasks, 1)
+}
+
+func TestBatchTasksInvalidRequests(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	resp := postBatch(t, app, handlers.BatchRequest{})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = postBatch(t, app, handlers.BatchRequest{
+		Mode:       "sometimes",
+		Operations: []task.Operation{{Action: task.ActionCreate, Task: entity.NewTask("New task")}},
+	})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/caldav"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/formats"
+)
+
+// CalDAVPrefix is where the Tasks are served to calendar clients. It is both
+// the principal of the User and the home of its only calendar, whose objects
+// are the Tasks.
+const CalDAVPrefix = "/caldav"
+
+const (
+	calendarPath = CalDAVPrefix + "/tasks/"
+
+	// calendarObjectType is the media type of the Tasks, as calendar objects.
+	calendarObjectType = "text/calendar; charset=utf-8; component=VTODO"
+)
+
+var errSingleVTODO = errors.New("the calendar object must hold a single VTODO")
+
+// RequestMethods are the HTTP methods which the API serves, with the WebDAV
+// ones of CalDAV.
+var RequestMethods = append(append([]string{}, fiber.DefaultMethods...), caldav.MethodPropfind, caldav.MethodReport)
+
+// WellKnownCalDAV sends the calendar clients to CalDAVPrefix, as in RFC 6764.
+func WellKnownCalDAV(c *fiber.Ctx) error {
+	return c.Redirect(CalDAVPrefix+"/", fiber.StatusMovedPermanently)
+}
+
+// AuthenticateCalDAV is Authenticate for calendar clients, which rather send
+// a username and password with basic authentication. The password is an API
+// token of the User, which is only used once the username matches.
+func AuthenticateCalDAV(c *fiber.Ctx) error {
+	header := c.Get(fiber.HeaderAuthorization)
+	if strings.HasPrefix(header, "Bearer ") {
+		return Authenticate(c)
+	}
+
+	encoded, _ := strings.CutPrefix(header, "Basic ")
+	credentials, _ := base64.StdEncoding.DecodeString(encoded)
+	username, token, _ := strings.Cut(string(credentials), ":")
+
+	user, apiToken, err := lookupAPIToken(c.UserContext(), token)
+	if err != nil || user.Username != username {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="GoDoIt"`)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "a username and API token are required"})
+	}
+	if err := useAPIToken(c.UserContext(), apiToken, c.Method()); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	c.SetUserContext(withTokenScope(c.UserContext(), apiToken.Scope))
+	return identify(c, user.ID, user.Username, user.WorkspaceID)
+}
+
+// CalDAV serves the calendar of the Tasks, dispatching on the resource and
+// the method since Fiber cannot route the WebDAV methods unless they are
+// configured in RequestMethods.
+func CalDAV(c *fiber.Ctx) error {
+	if c.Method() == fiber.MethodOptions {
+		c.Set("DAV", "1, 3, calendar-access")
+		c.Set(fiber.HeaderAllow, "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	resource := strings.TrimPrefix(c.Path(), CalDAVPrefix)
+	switch {
+	case resource == "" || resource == "/":
+		if c.Method() == caldav.MethodPropfind {
+			return propfindPrincipal(c)
+		}
+	case resource == "/tasks" || resource == "/tasks/":
+		switch c.Method() {
+		case caldav.MethodPropfind:
+			return propfindCalendar(c)
+		case caldav.MethodReport:
+			return reportCalendar(c)
+		}
+	default:
+		name, ok := calendarObjectName(c.Path())
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(errorBody(entity.ErrTaskNotFound))
+		}
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead:
+			return getCalendarObject(c, name)
+		case fiber.MethodPut:
+			return putCalendarObject(c, name)
+		case fiber.MethodDelete:
+			return deleteCalendarObject(c, name)
+		case caldav.MethodPropfind:
+			return propfindCalendarObject(c, name)
+		}
+	}
+	return c.SendStatus(fiber.StatusMethodNotAllowed)
+}
+
+// calendarObjectName returns the name of the calendar object at a path or URL,
+// which is the UID of the VTODO for the usual clients.
+func calendarObjectName(href string) (string, bool) {
+	location, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	dir, file := path.Split(location.Path)
+	name, found := strings.CutSuffix(file, ".ics")
+	return name, found && name != "" && dir == calendarPath
+}
+
+// calendarObject is a Task as the resource of a calendar object.
+type calendarObject struct {
+	task entity.Task
+	data []byte
+	etag string
+}
+
+func newCalendarObject(t entity.Task) (calendarObject, error) {
+	var data bytes.Buffer
+	if err := formats.Encode(&data, formats.ICalendar, []entity.Task{t}); err != nil {
+		return calendarObject{}, err
+	}
+	sum := sha256.Sum256(data.Bytes())
+	return calendarObject{task: t, data: data.Bytes(), etag: `"` + hex.EncodeToString(sum[:16]) + `"`}, nil
+}
+
+// href returns the path of the calendar object, under the name given by the
+// client which created it.
+func (o calendarObject) href() string {
+	name := o.task.CalendarName
+	if name == "" {
+		name = o.task.ID.String()
+	}
+	return calendarPath + url.PathEscape(name) + ".ics"
+}
+
+// properties returns the properties of the calendar object. Its data is left
+// out unless asked for by name.
+func (o calendarObject) properties(request caldav.Propfind) []caldav.Property {
+	properties := []caldav.Property{
+		caldav.Elements(caldav.DAV("resourcetype")),
+		caldav.Text(caldav.DAV("getetag"), o.etag),
+		caldav.Text(caldav.DAV("getcontenttype"), calendarObjectType),
+		caldav.Text(caldav.DAV("getlastmodified"), o.task.UpdatedAt.UTC().Format(http.TimeFormat)),
+	}
+	if !request.AllProp {
+		properties = append(properties, caldav.Text(caldav.CalDAV("calendar-data"), string(o.data)))
+	}
+	return properties
+}
+
+// calendarObjects returns every Task which the User may see, apart from the
+// archived ones.
+func calendarObjects(c *fiber.Ctx) ([]calendarObject, error) {
+	tasks, err := database.Repo.All(c.UserContext())
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]calendarObject, len(tasks))
+	for i, t := range tasks {
+		if objects[i], err = newCalendarObject(t); err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// calendarObjectByName returns the Task of a calendar object, if the User may
+// see it.
+func calendarObjectByName(c *fiber.Ctx, name string) (calendarObject, error) {
+	t, err := database.Repo.Get(c.UserContext(), formats.TaskID(name))
+	if err != nil {
+		return calendarObject{}, entity.ErrTaskNotFound
+	}
+	return newCalendarObject(t)
+}
+
+// depth returns the Depth header of a PROPFIND request, where infinity is the
+// default.
+func depth(c *fiber.Ctx) int {
+	switch c.Get("Depth") {
+	case "0":
+		return 0
+	case "1":
+		return 1
+	}
+	return 2
+}
+
+func multistatus(c *fiber.Ctx, responses []caldav.Response) error {
+	body, err := xml.Marshal(caldav.Multistatus{Responses: responses})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	c.Set(fiber.HeaderContentType, "application/xml; charset=utf-8")
+	return c.Status(fiber.StatusMultiStatus).Send(append([]byte(xml.Header), body...))
+}
+
+func propfindPrincipal(c *fiber.Ctx) error {
+	request, err := caldav.ParsePropfind(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	principal := CalDAVPrefix + "/"
+	responses := []caldav.Response{caldav.NewResponse(principal, request, []caldav.Property{
+		caldav.Elements(caldav.DAV("resourcetype"), caldav.DAV("collection"), caldav.DAV("principal")),
+		caldav.Text(caldav.DAV("displayname"), task.ActorFromContext(c.UserContext())),
+		caldav.Href(caldav.DAV("current-user-principal"), principal),
+		caldav.Href(caldav.DAV("principal-URL"), principal),
+		caldav.Href(caldav.CalDAV("calendar-home-set"), principal),
+	})}
+	if depth(c) == 0 {
+		return multistatus(c, responses)
+	}
+
+	calendar, err := calendarResponses(c, request, depth(c)-1)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	return multistatus(c, append(responses, calendar...))
+}
+
+func propfindCalendar(c *fiber.Ctx) error {
+	request, err := caldav.ParsePropfind(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	responses, err := calendarResponses(c, request, depth(c))
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	return multistatus(c, responses)
+}
+
+// calendarResponses returns the properties of the calendar, followed by the
+// ones of its objects unless depth is 0.
+func calendarResponses(c *fiber.Ctx, request caldav.Propfind, depth int) ([]caldav.Response, error) {
+	objects, err := calendarObjects(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ctag changes along with any of the objects, telling the clients
+	// when to look for changes.
+	ctag := sha256.New()
+	for _, object := range objects {
+		ctag.Write([]byte(object.href() + object.etag))
+	}
+
+	responses := []caldav.Response{caldav.NewResponse(calendarPath, request, []caldav.Property{
+		caldav.Elements(caldav.DAV("resourcetype"), caldav.DAV("collection"), caldav.CalDAV("calendar")),
+		caldav.Text(caldav.DAV("displayname"), "Tasks"),
+		caldav.Href(caldav.DAV("owner"), CalDAVPrefix+"/"),
+		caldav.SupportedComponents("VTODO"),
+		caldav.SupportedReports(),
+		caldav.Privileges(caldav.DAV("read"), caldav.DAV("write")),
+		caldav.Text(caldav.CalendarServer("getctag"), `"`+hex.EncodeToString(ctag.Sum(nil)[:16])+`"`),
+	})}
+	if depth > 0 {
+		for _, object := range objects {
+			responses = append(responses, caldav.NewResponse(object.href(), request, object.properties(request)))
+		}
+	}
+	return responses, nil
+}
+
+// reportCalendar answers the calendar-query reports with the objects selected
+// by their filter, and the calendar-multiget ones with the objects they list.
+func reportCalendar(c *fiber.Ctx) error {
+	request, err := caldav.ParseReport(c.Body())
+	if errors.Is(err, caldav.ErrUnsupportedReport) {
+		return c.Status(fiber.StatusForbidden).JSON(errorBody(err))
+	} else if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	responses := []caldav.Response{}
+	if request.Name == caldav.CalendarMultiget {
+		for _, href := range request.Hrefs {
+			name, ok := calendarObjectName(href)
+			object, err := calendarObjectByName(c, name)
+			if !ok || err != nil {
+				responses = append(responses, caldav.Response{Href: href, Status: caldav.Status(fiber.StatusNotFound)})
+				continue
+			}
+			responses = append(responses, caldav.NewResponse(href, request.Propfind, object.properties(request.Propfind)))
+		}
+		return multistatus(c, responses)
+	}
+
+	objects, err := calendarObjects(c)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	for _, object := range objects {
+		calendar, err := formats.ParseICalendar(bytes.NewReader(object.data))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+		}
+		if request.Filter.Match(calendar) {
+			responses = append(responses, caldav.NewResponse(object.href(), request.Propfind, object.properties(request.Propfind)))
+		}
+	}
+	return multistatus(c, responses)
+}
+
+func propfindCalendarObject(c *fiber.Ctx, name string) error {
+	request, err := caldav.ParsePropfind(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	object, err := calendarObjectByName(c, name)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	return multistatus(c, []caldav.Response{caldav.NewResponse(c.Path(), request, object.properties(request))})
+}
+
+func getCalendarObject(c *fiber.Ctx, name string) error {
+	object, err := calendarObjectByName(c, name)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	c.Set(fiber.HeaderETag, object.etag)
+	c.Set(fiber.HeaderContentType, calendarObjectType)
+	return c.Status(fiber.StatusOK).Send(object.data)
+}
+
+// preconditionFailed reports whether the If-Match and If-None-Match headers
+// of a request rule out changing the calendar object, which exists unless
+// etag is empty.
+func preconditionFailed(c *fiber.Ctx, etag string) bool {
+	if match := c.Get(fiber.HeaderIfMatch); match != "" && (etag == "" || (match != "*" && match != etag)) {
+		return true
+	}
+	return c.Get(fiber.HeaderIfNoneMatch) == "*" && etag != ""
+}
+
+// putCalendarObject creates or replaces the Task of a calendar object with
+// the VTODO of the body. The summary, priority, completion and due date of
+// the VTODO replace the ones of an existing Task.
+func putCalendarObject(c *fiber.Ctx, name string) error {
+	entries, err := formats.Decode(bytes.NewReader(c.Body()), formats.ICalendar)
+	if err == nil && len(entries) != 1 {
+		err = errSingleVTODO
+	} else if err == nil {
+		err = entries[0].Err
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+	vtodo := entries[0].Task
+
+	ctx, id := c.UserContext(), formats.TaskID(name)
+	existing, err := calendarObjectByName(c, name)
+	if preconditionFailed(c, existing.etag) {
+		return c.SendStatus(fiber.StatusPreconditionFailed)
+	}
+
+	status := fiber.StatusNoContent
+	if err != nil {
+		// The Task is found by the name of its object, and keeps the UID and
+		// the name which the client gave.
+		uid := formats.UID(*vtodo)
+		vtodo.ID, vtodo.CalendarUID, vtodo.CalendarName = id, "", ""
+		if uid != id.String() {
+			vtodo.CalendarUID = uid
+		}
+		if name != id.String() {
+			vtodo.CalendarName = name
+		}
+		err = database.Repo.Post(ctx, vtodo)
+		status = fiber.StatusCreated
+	} else {
+		t := existing.task
+		t.Description, t.Priority, t.DueAt = vtodo.Description, vtodo.Priority, vtodo.DueAt
+		if !vtodo.Completed || vtodo.CompletedAt != nil {
+			t.CompletedAt = vtodo.CompletedAt
+		}
+		t.Completed = vtodo.Completed
+		err = database.Repo.Put(ctx, &t)
+	}
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if object, err := calendarObjectByName(c, name); err == nil {
+		c.Set(fiber.HeaderETag, object.etag)
+	}
+	return c.SendStatus(status)
+}
+
+func deleteCalendarObject(c *fiber.Ctx, name string) error {
+	object, err := calendarObjectByName(c, name)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	if preconditionFailed(c, object.etag) {
+		return c.SendStatus(fiber.StatusPreconditionFailed)
+	}
+
+	if err := database.Repo.Delete(c.UserContext(), object.task.ID); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,270 @@
+package handlers_test
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/caldav"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/formats"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+// davMultistatus holds what the tests read from the multi-status responses.
+type davMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Status   string `xml:"status"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ETag      string `xml:"getetag"`
+				CTag      string `xml:"getctag"`
+				Data      string `xml:"calendar-data"`
+				Principal string `xml:"current-user-principal>href"`
+				Home      string `xml:"calendar-home-set>href"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func TestCalDAV(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+	user := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &user))
+	token, secret, _ := entity.NewAPIToken(user.ID, "phone", entity.ScopeWrite, nil)
+	assert.NoError(t, repo.CreateToken(testCtx, token))
+	readOnly, readSecret, _ := entity.NewAPIToken(user.ID, "watch", entity.ScopeRead, nil)
+	assert.NoError(t, repo.CreateToken(testCtx, readOnly))
+
+	app := fiber.New(fiber.Config{RequestMethods: handlers.RequestMethods})
+	router.SetupRoutes(app)
+
+	existing := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, existing))
+
+	// dav sends a request as a calendar client would, with basic
+	// authentication and header pairs.
+	dav := func(method, target, body string, headers ...string) (*http.Response, string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username+":"+secret)))
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+	multistatus := func(body string) davMultistatus {
+		var ms davMultistatus
+		assert.NoError(t, xml.Unmarshal([]byte(body), &ms))
+		return ms
+	}
+
+	t.Run("Discover the calendar", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/.well-known/caldav", nil), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusMovedPermanently, resp.StatusCode)
+		assert.Equal(t, "/caldav/", resp.Header.Get(fiber.HeaderLocation))
+
+		resp, body := dav("PROPFIND", "/caldav/", `<propfind xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <prop><current-user-principal/><C:calendar-home-set/></prop>
+</propfind>`, "Depth", "0")
+		assert.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+		ms := multistatus(body)
+		if assert.Len(t, ms.Responses, 1) {
+			assert.Equal(t, "/caldav/", ms.Responses[0].Propstat[0].Prop.Principal)
+			assert.Equal(t, "/caldav/", ms.Responses[0].Propstat[0].Prop.Home)
+		}
+
+		resp, body = dav("PROPFIND", "/caldav/", "", "Depth", "1")
+		assert.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+		assert.Len(t, multistatus(body).Responses, 2, "the principal and its calendar")
+		assert.Contains(t, body, `<comp xmlns="urn:ietf:params:xml:ns:caldav" name="VTODO"/>`)
+	})
+
+	t.Run("Reject the requests without a valid API token", func(t *testing.T) {
+		unused, unusedSecret, _ := entity.NewAPIToken(user.ID, "tablet", entity.ScopeWrite, nil)
+		assert.NoError(t, repo.CreateToken(testCtx, unused))
+		req := httptest.NewRequest("PROPFIND", "/caldav/", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte("someone:"+unusedSecret)))
+		resp, _ := app.Test(req, -1)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, `Basic realm="GoDoIt"`, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+		found, err := repo.TokenByHash(testCtx, unused.Hash)
+		assert.NoError(t, err)
+		assert.Nil(t, found.LastUsedAt, "a token sent with another username must not count as used")
+
+		req = httptest.NewRequest(http.MethodDelete, "/caldav/tasks/"+existing.ID.String()+".ics", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username+":"+readSecret)))
+		resp, _ = app.Test(req, -1)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+
+	vtodo := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\n" +
+		"UID:milk@example.com\r\nSUMMARY:Buy milk\r\nPRIORITY:1\r\nDUE:20240401T090000Z\r\n%s" +
+		"END:VTODO\r\nEND:VCALENDAR\r\n"
+	object := "/caldav/tasks/milk@example.com.ics"
+	var etag string
+
+	t.Run("Create a Task", func(t *testing.T) {
+		resp, _ := dav(http.MethodPut, object, strings.Replace(vtodo, "%s", "", 1), fiber.HeaderIfNoneMatch, "*")
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		etag = resp.Header.Get(fiber.HeaderETag)
+		assert.NotEmpty(t, etag)
+
+		created, err := database.Repo.Get(testCtx, formats.TaskID("milk@example.com"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Buy milk", created.Description)
+		assert.Equal(t, entity.PriorityHigh, created.Priority)
+		assert.Equal(t, time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC), *created.DueAt)
+
+		resp, _ = dav(http.MethodPut, object, strings.Replace(vtodo, "%s", "", 1), fiber.HeaderIfNoneMatch, "*")
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode, "the object exists already")
+
+		resp, body := dav(http.MethodGet, object, "")
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+		assert.Contains(t, body, "\r\nDUE:20240401T090000Z\r\n")
+		assert.Contains(t, body, "\r\nUID:milk@example.com\r\n", "the UID is served as the client gave it")
+	})
+
+	t.Run("List the Tasks", func(t *testing.T) {
+		resp, body := dav("PROPFIND", "/caldav/tasks/", `<propfind xmlns="DAV:"><prop><getetag/></prop></propfind>`, "Depth", "1")
+		assert.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+		ms := multistatus(body)
+		if assert.Len(t, ms.Responses, 3) {
+			assert.Equal(t, "/caldav/tasks/", ms.Responses[0].Href)
+			assert.Equal(t, "HTTP/1.1 404 Not Found", ms.Responses[0].Propstat[0].Status, "the calendar has no etag")
+			assert.Equal(t, "/caldav/tasks/"+existing.ID.String()+".ics", ms.Responses[1].Href)
+			assert.Equal(t, object, ms.Responses[2].Href, "the object keeps the name the client gave it")
+			assert.Equal(t, etag, ms.Responses[2].Propstat[0].Prop.ETag)
+		}
+	})
+
+	ctag := func() string {
+		_, body := dav("PROPFIND", "/caldav/tasks/", `<propfind xmlns="DAV:"><prop><getctag xmlns="http://calendarserver.org/ns/"/></prop></propfind>`, "Depth", "0")
+		return multistatus(body).Responses[0].Propstat[0].Prop.CTag
+	}
+	before := ctag()
+	assert.NotEmpty(t, before)
+
+	t.Run("Complete a Task", func(t *testing.T) {
+		completed := strings.Replace(vtodo, "%s", "STATUS:COMPLETED\r\nCOMPLETED:20240330T120000Z\r\n", 1)
+		resp, _ := dav(http.MethodPut, object, completed, fiber.HeaderIfMatch, `"stale"`)
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+
+		resp, _ = dav(http.MethodPut, object, completed, fiber.HeaderIfMatch, etag)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		assert.NotEqual(t, etag, resp.Header.Get(fiber.HeaderETag))
+		etag = resp.Header.Get(fiber.HeaderETag)
+
+		done, _ := database.Repo.Get(testCtx, formats.TaskID("milk@example.com"))
+		assert.True(t, done.Completed)
+		assert.Equal(t, time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC), done.CompletedAt.UTC())
+		assert.NotEqual(t, before, ctag(), "the ctag follows the changes")
+	})
+
+	t.Run("Query the open Tasks", func(t *testing.T) {
+		resp, body := dav("REPORT", "/caldav/tasks/", `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO">
+    <C:prop-filter name="COMPLETED"><C:is-not-defined/></C:prop-filter>
+  </C:comp-filter></C:comp-filter></C:filter>
+</C:calendar-query>`, "Depth", "1")
+		assert.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+		ms := multistatus(body)
+		if assert.Len(t, ms.Responses, 1) {
+			assert.Contains(t, ms.Responses[0].Propstat[0].Prop.Data, "SUMMARY:"+GENERIC_TASK_NAME)
+		}
+	})
+
+	t.Run("Read the calendar with a read API token", func(t *testing.T) {
+		read := func(method, target, body string) int {
+			req := httptest.NewRequest(method, target, strings.NewReader(body))
+			req.Header.Set(fiber.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username+":"+readSecret)))
+			req.Header.Set("Depth", "1")
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			return resp.StatusCode
+		}
+		assert.Equal(t, fiber.StatusMultiStatus, read(caldav.MethodPropfind, "/caldav/tasks/", ""))
+		assert.Equal(t, fiber.StatusMultiStatus, read(caldav.MethodReport, "/caldav/tasks/", `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/></D:prop>
+</C:calendar-query>`))
+		assert.Equal(t, fiber.StatusOK, read(http.MethodGet, object, ""))
+		assert.Equal(t, fiber.StatusForbidden, read(http.MethodPut, object, strings.Replace(vtodo, "%s", "", 1)))
+	})
+
+	t.Run("Get several Tasks", func(t *testing.T) {
+		resp, body := dav("REPORT", "/caldav/tasks/", `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <D:href>`+object+`</D:href>
+  <D:href>/caldav/tasks/missing.ics</D:href>
+</C:calendar-multiget>`)
+		assert.Equal(t, fiber.StatusMultiStatus, resp.StatusCode)
+		ms := multistatus(body)
+		if assert.Len(t, ms.Responses, 2) {
+			assert.Equal(t, etag, ms.Responses[0].Propstat[0].Prop.ETag)
+			assert.Contains(t, ms.Responses[0].Propstat[0].Prop.Data, "STATUS:COMPLETED")
+			assert.Equal(t, "HTTP/1.1 404 Not Found", ms.Responses[1].Status)
+		}
+
+		resp, _ = dav("REPORT", "/caldav/tasks/", `<D:sync-collection xmlns:D="DAV:"/>`)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Delete a Task", func(t *testing.T) {
+		resp, _ := dav(http.MethodDelete, object, "", fiber.HeaderIfMatch, `"stale"`)
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+
+		resp, _ = dav(http.MethodDelete, object, "", fiber.HeaderIfMatch, etag)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+		resp, _ = dav(http.MethodGet, object, "")
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Keep the name and the UID given by the client", func(t *testing.T) {
+		uid := "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+		named := "/caldav/tasks/Reminder-42.ics"
+		resp, _ := dav(http.MethodPut, named, strings.Replace(strings.Replace(vtodo, "%s", "", 1), "milk@example.com", uid, 1))
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+		// The Tasks replaced through the API keep them too.
+		created, err := database.Repo.Get(testCtx, formats.TaskID("Reminder-42"))
+		assert.NoError(t, err)
+		created.CalendarUID, created.CalendarName = "", ""
+		assert.NoError(t, database.Repo.Put(testCtx, &created))
+
+		_, body := dav(http.MethodGet, named, "")
+		assert.Contains(t, body, "\r\nUID:"+uid+"\r\n")
+		_, body = dav("PROPFIND", "/caldav/tasks/", `<propfind xmlns="DAV:"><prop><getetag/></prop></propfind>`, "Depth", "1")
+		var hrefs []string
+		for _, response := range multistatus(body).Responses {
+			hrefs = append(hrefs, response.Href)
+		}
+		assert.Contains(t, hrefs, named)
+	})
+
+	t.Run("Reject invalid calendar objects", func(t *testing.T) {
+		resp, _ := dav(http.MethodPut, "/caldav/tasks/empty.ics", strings.Replace(strings.Replace(vtodo, "%s", "", 1), "SUMMARY:Buy milk\r\n", "", 1))
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = dav(http.MethodPut, "/caldav/tasks/empty.ics", "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
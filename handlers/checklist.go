@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// ChecklistItemRequest is the text of a new or edited checklist item.
+type ChecklistItemRequest struct {
+	Text string `json:"text"`
+}
+
+// ChecklistOrder lists the IDs of every item of a checklist in their new
+// order.
+type ChecklistOrder struct {
+	Order []uuid.UUID `json:"order"`
+}
+
+// checklistIDs reads the IDs of the Task and of the checklist item from the
+// path.
+func checklistIDs(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	taskID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	id, err := uuid.Parse(c.Params("item"))
+	return taskID, id, err
+}
+
+func AddChecklistItem(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	request := new(ChecklistItemRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	item, err := entity.NewChecklistItem(uuid, request.Text)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Repo.AddChecklistItem(c.UserContext(), item); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+func EditChecklistItem(c *fiber.Ctx) error {
+	taskID, id, err := checklistIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	request := new(ChecklistItemRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	item, err := database.Repo.EditChecklistItem(c.UserContext(), taskID, id, request.Text)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(item)
+}
+
+func ToggleChecklistItem(c *fiber.Ctx) error {
+	taskID, id, err := checklistIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	item, err := database.Repo.ToggleChecklistItem(c.UserContext(), taskID, id)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(item)
+}
+
+// ReorderChecklist puts the items of the checklist of a Task in the order of
+// a ChecklistOrder.
+func ReorderChecklist(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	request := new(ChecklistOrder)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	checklist, err := database.Repo.ReorderChecklist(c.UserContext(), uuid, request.Order)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(checklist)
+}
+
+func DeleteChecklistItem(c *fiber.Ctx) error {
+	taskID, id, err := checklistIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	if err := database.Repo.DeleteChecklistItem(c.UserContext(), taskID, id); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
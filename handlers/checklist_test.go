@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecklist(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+	user := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &user))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	packing := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, packing))
+	path := "/task/" + packing.ID.String() + "/checklist"
+
+	send := func(method string, target string, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		resp, err := app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		return resp
+	}
+
+	var items []entity.ChecklistItem
+	for _, text := range []string{"Passport", "Charger"} {
+		resp := send(http.MethodPost, path, handlers.ChecklistItemRequest{Text: text})
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var item entity.ChecklistItem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&item))
+		items = append(items, item)
+	}
+	first := path + "/" + items[0].ID.String()
+
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         interface{}
+		expectedCode int
+	}{
+		{"Reject an empty item", http.MethodPost, path, handlers.ChecklistItemRequest{}, fiber.StatusBadRequest},
+		{"Toggle an item", http.MethodPost, first + "/toggle", nil, fiber.StatusOK},
+		{"Toggle an unknown item", http.MethodPost, path + "/" + uuid.NewString() + "/toggle", nil, fiber.StatusNotFound},
+		{"Edit an item", http.MethodPut, first, handlers.ChecklistItemRequest{Text: "Passports"}, fiber.StatusOK},
+		{"Reject an incomplete order", http.MethodPut, path, handlers.ChecklistOrder{Order: []uuid.UUID{items[1].ID}}, fiber.StatusBadRequest},
+		{"Reorder the checklist", http.MethodPut, path, handlers.ChecklistOrder{Order: []uuid.UUID{items[1].ID, items[0].ID}}, fiber.StatusOK},
+		{"Reject an invalid item ID", http.MethodDelete, path + "/invalid", nil, fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := send(tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+
+	resp := send(http.MethodGet, "/task/"+packing.ID.String(), nil)
+	var found map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&found))
+	assert.Equal(t, float64(50), found["checklist_completion"])
+	if checklist, ok := found["checklist"].([]interface{}); assert.True(t, ok) && assert.Len(t, checklist, 2) {
+		assert.Equal(t, "Charger", checklist[0].(map[string]interface{})["text"])
+	}
+
+	resp = send(http.MethodDelete, first, nil)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	task, _ := database.Repo.Get(testCtx, packing.ID)
+	assert.Len(t, task.Checklist, 1)
+	assert.Equal(t, 0, task.ChecklistCompletion())
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// CommentRequest is the Markdown body of a new or edited Comment.
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+// commentIDs reads the IDs of the Task and of the Comment from the path.
+func commentIDs(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	taskID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	id, err := uuid.Parse(c.Params("comment"))
+	return taskID, id, err
+}
+
+func ListComments(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	comments, err := database.Comments.Comments(c.UserContext(), uuid)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(comments)
+}
+
+func AddComment(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	request := new(CommentRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	comment, err := entity.NewComment(uuid, request.Body)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Comments.AddComment(c.UserContext(), comment); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(comment)
+}
+
+func GetComment(c *fiber.Ctx) error {
+	taskID, id, err := commentIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	comment, err := database.Comments.GetComment(c.UserContext(), taskID, id)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(comment)
+}
+
+func EditComment(c *fiber.Ctx) error {
+	taskID, id, err := commentIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	request := new(CommentRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	comment, err := database.Comments.EditComment(c.UserContext(), taskID, id, request.Body)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(comment)
+}
+
+func DeleteComment(c *fiber.Ctx) error {
+	taskID, id, err := commentIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	if err := database.Comments.DeleteComment(c.UserContext(), taskID, id); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func CommentEdits(c *fiber.Ctx) error {
+	taskID, id, err := commentIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	edits, err := database.Comments.CommentEdits(c.UserContext(), taskID, id)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(edits)
+}
@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComments(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users, database.Comments = repo, repo, repo
+
+	owner := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &owner))
+	reviewer := entity.User{ID: uuid.New(), Username: "reviewer"}
+	assert.NoError(t, repo.CreateUser(testCtx, &reviewer))
+	session, _, _ := auth.Default.Issue(reviewer)
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	discussed := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, discussed))
+	assert.NoError(t, repo.Share(testCtx, discussed.ID, reviewer.ID, entity.RoleViewer))
+	path := "/task/" + discussed.ID.String() + "/comments"
+
+	// send makes a request as the owner, or as the reviewer.
+	send := func(method string, target string, body interface{}, asReviewer bool) *http.Response {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		if asReviewer {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+session)
+		} else {
+			authorized(req)
+		}
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		return resp
+	}
+
+	resp := send(http.MethodPost, path, handlers.CommentRequest{Body: "Looks **good**"}, true)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var comment entity.Comment
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&comment))
+	assert.Equal(t, reviewer.ID, comment.AuthorID)
+	target := path + "/" + comment.ID.String()
+
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         interface{}
+		asReviewer   bool
+		expectedCode int
+	}{
+		{"Reject an empty comment", http.MethodPost, path, handlers.CommentRequest{}, false, fiber.StatusBadRequest},
+		{"List the comments", http.MethodGet, path, nil, false, fiber.StatusOK},
+		{"Get a comment", http.MethodGet, target, nil, false, fiber.StatusOK},
+		{"Forbid editing the comment of another User", http.MethodPut, target, handlers.CommentRequest{Body: "Nope"}, false, fiber.StatusForbidden},
+		{"Edit a comment", http.MethodPut, target, handlers.CommentRequest{Body: "Looks good"}, true, fiber.StatusOK},
+		{"List the edits of a comment", http.MethodGet, target + "/edits", nil, true, fiber.StatusOK},
+		{"Reject an invalid comment ID", http.MethodGet, path + "/invalid", nil, false, fiber.StatusBadRequest},
+		{"Get an unknown comment", http.MethodGet, path + "/" + uuid.NewString(), nil, false, fiber.StatusNotFound},
+		{"Delete the comment as the owner of the Task", http.MethodDelete, target, nil, false, fiber.StatusOK},
+		{"Get a deleted comment", http.MethodGet, target, nil, true, fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := send(tt.method, tt.target, tt.body, tt.asReviewer)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+
+	edits, err := repo.CommentEdits(testCtx, discussed.ID, comment.ID)
+	assert.ErrorIs(t, err, entity.ErrCommentNotFound)
+	assert.Empty(t, edits)
+}
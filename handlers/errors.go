@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// statusFor maps repository and validation errors onto HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrTaskNotFound),
+		errors.Is(err, entity.ErrVersionNotFound),
+		errors.Is(err, entity.ErrUserNotFound),
+		errors.Is(err, entity.ErrTokenNotFound),
+		errors.Is(err, entity.ErrShareNotFound),
+		errors.Is(err, entity.ErrCommentNotFound),
+		errors.Is(err, entity.ErrAttachmentNotFound),
+		errors.Is(err, entity.ErrChecklistItemNotFound),
+		errors.Is(err, entity.ErrWebhookNotFound),
+		errors.Is(err, entity.ErrDeliveryNotFound),
+		errors.Is(err, blob.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, entity.ErrTaskUniqueConstraint),
+		errors.Is(err, entity.ErrUsernameTaken),
+		errors.Is(err, entity.ErrDeliveryNotDead):
+		return fiber.StatusConflict
+	case errors.Is(err, entity.ErrInvalidOperation),
+		errors.Is(err, entity.ErrInvalidTaskDescription),
+		errors.Is(err, entity.ErrInvalidPriorityLevel),
+		errors.Is(err, entity.ErrInvalidUsername),
+		errors.Is(err, entity.ErrInvalidPassword),
+		errors.Is(err, entity.ErrInvalidTokenName),
+		errors.Is(err, entity.ErrInvalidTokenScope),
+		errors.Is(err, entity.ErrInvalidTokenExpiry),
+		errors.Is(err, entity.ErrInvalidRole),
+		errors.Is(err, entity.ErrInvalidShare),
+		errors.Is(err, entity.ErrInvalidAssignee),
+		errors.Is(err, entity.ErrInvalidComment),
+		errors.Is(err, entity.ErrInvalidAttachment),
+		errors.Is(err, entity.ErrInvalidChecklistItem),
+		errors.Is(err, entity.ErrInvalidChecklistOrder),
+		errors.Is(err, entity.ErrInvalidWebhookURL),
+		errors.Is(err, entity.ErrInvalidWebhookEvents),
+		errors.Is(err, entity.ErrInvalidWorkspaceRole),
+		errors.Is(err, entity.ErrCannotRemoveSelf):
+		return fiber.StatusBadRequest
+	case errors.Is(err, entity.ErrAttachmentTooLarge):
+		return fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, entity.ErrInvalidCredentials),
+		errors.Is(err, auth.ErrInvalidToken):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, entity.ErrInsufficientTokenScope),
+		errors.Is(err, entity.ErrSessionRequired),
+		errors.Is(err, entity.ErrAdminRequired),
+		errors.Is(err, entity.ErrForbidden):
+		return fiber.StatusForbidden
+	}
+	return fiber.StatusInternalServerError
+}
+
+// errorBody is the JSON body of an error response. Besides the message, it
+// holds the code of the error when it has one, which clients match instead.
+func errorBody(err error) fiber.Map {
+	body := fiber.Map{"message": err.Error()}
+	if code := entity.ErrorCode(err); code != "" {
+		body["code"] = code
+	}
+	return body
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/domain/task"
+)
+
+// EventsHeartbeat is how often the streams of changes are kept alive when
+// nothing changes, so that idle connections are not closed by proxies and
+// closed connections are noticed.
+var EventsHeartbeat = 30 * time.Second
+
+// reset is sent to the clients which cannot resume their stream, so that they
+// reload the Tasks.
+type reset struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Events streams the changes to the Tasks the User can see, as server-sent
+// events, or over a WebSocket when the request asks for an upgrade. Clients
+// resume their stream after the ID of the last change they received, from the
+// Last-Event-ID header or ?last_event_id=.
+func Events(c *fiber.Ctx) error {
+	var after uint64
+	if last := c.Get("Last-Event-ID", c.Query("last_event_id")); last != "" {
+		var err error
+		if after, err = strconv.ParseUint(last, 10, 64); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "the last event ID must be the ID of a change"})
+		}
+	}
+
+	user, _ := task.OwnerFromContext(c.UserContext())
+	subscription, missed, err := changes.Default.Subscribe(user, after)
+	var first *reset
+	if err != nil {
+		first = &reset{Type: "reset", Message: err.Error()}
+	}
+
+	if websocket.IsWebSocketUpgrade(c) {
+		c.Locals(eventStreamKey, eventStream{subscription: subscription, first: first, missed: missed})
+		if err := upgrade(c, eventsWebSocket); err != nil {
+			subscription.Close()
+			return err
+		}
+		return nil
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamEvents(w, subscription, first, missed)
+	})
+	return nil
+}
+
+// writeEvent writes a server-sent event, whose data is the JSON of value.
+func writeEvent(w *bufio.Writer, id uint64, event string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if id != 0 {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return w.Flush()
+}
+
+// streamEvents writes the changes as server-sent events, until the client
+// goes away or falls too far behind, and then reconnects.
+func streamEvents(w *bufio.Writer, subscription *changes.Subscription, first *reset, missed []changes.Change) {
+	defer subscription.Close()
+
+	if first != nil {
+		if writeEvent(w, 0, first.Type, first) != nil {
+			return
+		}
+	}
+	for _, change := range missed {
+		if writeEvent(w, change.ID, string(change.Type), change) != nil {
+			return
+		}
+	}
+	// The headers are only sent with the first write.
+	fmt.Fprint(w, ": connected\n\n")
+	if w.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(EventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				return
+			}
+			if writeEvent(w, change.ID, string(change.Type), change) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			if w.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventStreamKey is the local holding the eventStream of a WebSocket opened
+// by Events.
+const eventStreamKey = "events"
+
+// eventStream is what Events sends over a WebSocket.
+type eventStream struct {
+	subscription *changes.Subscription
+	first        *reset
+	missed       []changes.Change
+}
+
+// eventsWebSocket upgrades the requests of Events to WebSockets.
+var eventsWebSocket = websocket.New(streamWebSocket)
+
+// eventsReadLimit is the size of the largest message read from the
+// WebSockets of Events, whose messages are ignored.
+const eventsReadLimit = 1 << 10
+
+// streamWebSocket sends the changes as text messages, until the client closes
+// the WebSocket or falls too far behind, and then reconnects. What the client
+// sends is ignored, besides the control messages.
+func streamWebSocket(conn *websocket.Conn) {
+	stream := conn.Locals(eventStreamKey).(eventStream)
+	defer stream.subscription.Close()
+
+	ws := &socket{conn: conn}
+	conn.SetReadLimit(eventsReadLimit)
+	stopPings := ws.keepAlive()
+	defer stopPings()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	defer func() {
+		conn.Close()
+		<-closed
+	}()
+
+	if stream.first != nil {
+		if ws.writeJSON(stream.first) != nil {
+			return
+		}
+	}
+	for _, change := range stream.missed {
+		if ws.writeJSON(change) != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case change, ok := <-stream.subscription.Changes():
+			if !ok {
+				_ = ws.close(websocket.CloseTryAgainLater, "resume from the last change")
+				return
+			}
+			if ws.writeJSON(change) != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+// sseEvent is a server-sent event, without its comments.
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// nextEvent reads the next server-sent event of a stream.
+func nextEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	var event sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err, NO_ERROR_EXPECTED) {
+			return event
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.Event != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// description returns the description of the Task of a change.
+func description(t *testing.T, data []byte) string {
+	var change changes.Change
+	assert.NoError(t, json.Unmarshal(data, &change))
+	return change.Task.Description
+}
+
+func TestEvents(t *testing.T) {
+	database.Repo = changes.Watch(memory.NewMemoryRepository(), changes.Default)
+	otherCtx := task.WithOwner(context.Background(), uuid.New())
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	router.SetupRoutes(app)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	defer listener.Close()
+	go func() { _ = app.Listener(listener) }()
+	server := "http://" + listener.Addr().String()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	// stream opens the stream of the changes, after the given event ID if any.
+	stream := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		req, _ := http.NewRequest(http.MethodGet, server+"/events", nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := client.Do(authorized(req))
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get(fiber.HeaderContentType))
+		return resp, bufio.NewReader(resp.Body)
+	}
+
+	var lastEventID string
+
+	t.Run("Stream the changes as server-sent events", func(t *testing.T) {
+		resp, events := stream("")
+		defer resp.Body.Close()
+
+		assert.NoError(t, database.Repo.Post(otherCtx, entity.NewTask("Not ours")))
+		created := entity.NewTask(GENERIC_TASK_NAME)
+		assert.NoError(t, database.Repo.Post(testCtx, created))
+		created.Completed = true
+		assert.NoError(t, database.Repo.Put(testCtx, created))
+		assert.NoError(t, database.Repo.Delete(testCtx, created.ID))
+
+		for _, expected := range []string{"created", "updated", "deleted"} {
+			event := nextEvent(t, events)
+			assert.Equal(t, expected, event.Event)
+			assert.Equal(t, GENERIC_TASK_NAME, description(t, []byte(event.Data)))
+			assert.NotEmpty(t, event.ID)
+			lastEventID = event.ID
+		}
+	})
+
+	t.Run("Resume from the last event", func(t *testing.T) {
+		assert.NoError(t, database.Repo.Post(testCtx, entity.NewTask("While away")))
+
+		resp, events := stream(lastEventID)
+		defer resp.Body.Close()
+		event := nextEvent(t, events)
+		assert.Equal(t, "created", event.Event)
+		assert.Equal(t, "While away", description(t, []byte(event.Data)))
+	})
+
+	t.Run("Resume from a forgotten event", func(t *testing.T) {
+		resp, events := stream("1")
+		defer resp.Body.Close()
+		assert.Equal(t, "reset", nextEvent(t, events).Event)
+	})
+
+	t.Run("Invalid last event ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/events?last_event_id=latest", nil)
+		resp, err := app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Stream the changes over a WebSocket", func(t *testing.T) {
+		req := authorized(httptest.NewRequest(http.MethodGet, "/events", nil))
+		ws, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server, "http")+"/events", req.Header)
+		if !assert.NoError(t, err, NO_ERROR_EXPECTED) {
+			return
+		}
+		defer ws.Close()
+		assert.Equal(t, fiber.StatusSwitchingProtocols, resp.StatusCode)
+		_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		assert.NoError(t, database.Repo.Post(otherCtx, entity.NewTask("Not ours")))
+		assert.NoError(t, database.Repo.Post(testCtx, entity.NewTask("Over the socket")))
+		kind, data, err := ws.ReadMessage()
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, websocket.TextMessage, kind)
+		assert.Equal(t, "Over the socket", description(t, data))
+
+		pongs := make(chan string, 1)
+		ws.SetPongHandler(func(data string) error {
+			pongs <- data
+			return nil
+		})
+		assert.NoError(t, ws.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(time.Second)))
+		assert.NoError(t, ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+		_, _, err = ws.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "the server answers the close message")
+		assert.Equal(t, "ping", <-pongs, "the server answers the pings")
+	})
+
+	t.Run("Disconnect the silent WebSocket clients", func(t *testing.T) {
+		heartbeat := handlers.EventsHeartbeat
+		handlers.EventsHeartbeat = 50 * time.Millisecond
+		defer func() { handlers.EventsHeartbeat = heartbeat }()
+
+		req := authorized(httptest.NewRequest(http.MethodGet, "/events", nil))
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server, "http")+"/events", req.Header)
+		if !assert.NoError(t, err, NO_ERROR_EXPECTED) {
+			return
+		}
+		defer ws.Close()
+
+		// The pings are only answered while reading.
+		time.Sleep(300 * time.Millisecond)
+		_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for err == nil {
+			_, _, err = ws.ReadMessage()
+		}
+		var timeout net.Error
+		assert.False(t, errors.As(err, &timeout) && timeout.Timeout(), "the server closes the connection")
+	})
+
+	t.Run("Unsupported WebSocket version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/events", nil)
+		req.Header.Set(fiber.HeaderConnection, "Upgrade")
+		req.Header.Set(fiber.HeaderUpgrade, "websocket")
+		req.Header.Set(fiber.HeaderSecWebSocketKey, "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set(fiber.HeaderSecWebSocketVersion, "8")
+		resp, err := app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusUpgradeRequired, resp.StatusCode)
+		assert.Equal(t, "13", resp.Header.Get(fiber.HeaderSecWebSocketVersion))
+	})
+}
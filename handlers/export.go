@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bufio"
+	"log"
+	"mime"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/formats"
+)
+
+// ExportTasks streams the Tasks in the format of ?format=, filtered by
+// ?assignee= as AllTasks, or the archived ones with ?archived=true.
+func ExportTasks(c *fiber.Ctx) error {
+	format, err := formats.ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	filter, err := assigneeFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+	filter.Archived = c.QueryBool("archived")
+	filter.Limit = MaxPageSize
+
+	// The first page is read before anything is sent, so that its errors
+	// still have a status code.
+	ctx, repo := c.UserContext(), database.Repo
+	tasks, total, err := repo.Find(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": "tasks" + format.Extension()}))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		encoder, _ := formats.NewEncoder(w, format)
+		for {
+			for _, t := range tasks {
+				if err := encoder.Encode(t); err != nil {
+					return
+				}
+			}
+
+			filter.Offset += len(tasks)
+			if len(tasks) == 0 || int64(filter.Offset) >= total {
+				break
+			}
+			if tasks, _, err = repo.Find(ctx, filter); err != nil {
+				// The status is already sent, so the export is cut short.
+				log.Println("Failed to export the tasks. \n", err)
+				return
+			}
+		}
+		_ = encoder.Close()
+	})
+	return nil
+}
@@ -0,0 +1,68 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTasks(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+	user := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &user))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	// More Tasks than fit in a page, so that the export reads several.
+	for i := 0; i < handlers.MaxPageSize+1; i++ {
+		assert.NoError(t, database.Repo.Post(testCtx, entity.NewTask(GENERIC_TASK_NAME)))
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		contentType  string
+		lines        int
+	}{
+		{"Export as CSV", "?format=csv", fiber.StatusOK, "text/csv; charset=utf-8", handlers.MaxPageSize + 2},
+		{"Export as JSON", "?format=json", fiber.StatusOK, "application/json", handlers.MaxPageSize + 1},
+		{"Export as todo.txt", "?format=todotxt", fiber.StatusOK, "text/plain; charset=utf-8", handlers.MaxPageSize + 1},
+		{"Export as Markdown", "?format=md", fiber.StatusOK, "text/markdown; charset=utf-8", handlers.MaxPageSize + 3},
+		{"Export as iCalendar", "?format=ics", fiber.StatusOK, "text/calendar; charset=utf-8", 0},
+		{"Export the archived Tasks", "?format=todotxt&archived=true", fiber.StatusOK, "text/plain; charset=utf-8", 0},
+		{"Reject an unknown format", "?format=xlsx", fiber.StatusBadRequest, "", 0},
+		{"Reject an unknown assignee filter", "?format=csv&assignee=them", fiber.StatusBadRequest, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/export"+tt.query, nil)
+			resp, err := app.Test(authorized(req), -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			if tt.expectedCode != fiber.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.contentType, resp.Header.Get(fiber.HeaderContentType))
+			assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), "attachment")
+			body, _ := io.ReadAll(resp.Body)
+			if tt.lines > 0 {
+				assert.Equal(t, tt.lines, strings.Count(string(body), "\n"))
+			}
+		})
+	}
+}
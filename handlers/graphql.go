@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// GraphQLProtocol is the subprotocol of the WebSockets serving the GraphQL
+// subscriptions.
+const GraphQLProtocol = "graphql-transport-ws"
+
+// graphQLReadLimit is the size of the largest message of the GraphQL
+// WebSockets.
+const graphQLReadLimit = 1 << 16
+
+// GraphQLInitTimeout is how long the clients of the GraphQL WebSockets have to
+// initialise their connection.
+var GraphQLInitTimeout = 10 * time.Second
+
+// The status codes closing the GraphQL WebSockets, as defined by
+// GraphQLProtocol.
+const (
+	closeInvalidMessage    = 4400
+	closeUnauthorized      = 4401
+	closeInitTimeout       = 4408
+	closeSubscriberExists  = 4409
+	closeTooManyInitialise = 4429
+)
+
+// graphQLRequest is a GraphQL request, as POSTed as JSON or sent in the
+// payload of a subscribe message.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// graphQLError is a GraphQL request which cannot be executed, with the status
+// of its response.
+type graphQLError struct {
+	status int
+	errors []gqlerrors.FormattedError
+}
+
+// failed is a graphQLError with a single error.
+func failed(status int, message string) *graphQLError {
+	return &graphQLError{status: status, errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)}}
+}
+
+// prepareGraphQL parses and validates the document of a request against
+// taskSchema, and finds the type of the operation to execute. The API tokens
+// which only read cannot run mutations.
+func prepareGraphQL(ctx context.Context, request graphQLRequest) (graphql.ExecuteParams, string, *graphQLError) {
+	params := graphql.ExecuteParams{
+		Schema:        taskSchema,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(request.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return params, "", &graphQLError{status: fiber.StatusBadRequest, errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := graphql.ValidateDocument(&taskSchema, doc, nil); !validation.IsValid {
+		return params, "", &graphQLError{status: fiber.StatusBadRequest, errors: validation.Errors}
+	}
+	params.AST = doc
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		if op, ok := definition.(*ast.OperationDefinition); ok {
+			if request.OperationName == "" && operation != nil {
+				return params, "", failed(fiber.StatusBadRequest, "Must provide operation name if query contains multiple operations.")
+			}
+			if request.OperationName == "" || (op.Name != nil && op.Name.Value == request.OperationName) {
+				operation = op
+			}
+		}
+	}
+	if operation == nil {
+		return params, "", failed(fiber.StatusBadRequest, fmt.Sprintf("Unknown operation named %q.", request.OperationName))
+	}
+
+	if scope, ok := tokenScope(ctx); ok && !scope.Allows(fiber.MethodPost) && operation.Operation == ast.OperationTypeMutation {
+		return params, "", failed(fiber.StatusForbidden, "Mutations are not allowed with read-only access.")
+	}
+	return params, operation.Operation, nil
+}
+
+// GraphQL executes the GraphQL requests POSTed as JSON, and the queries sent
+// as GET parameters. Subscriptions are served over a WebSocket when the request
+// asks for an upgrade.
+func GraphQL(c *fiber.Ctx) error {
+	if websocket.IsWebSocketUpgrade(c) {
+		return graphQLWebSocket(c)
+	}
+
+	var request graphQLRequest
+	if c.Method() == fiber.MethodGet {
+		request.Query, request.OperationName = c.Query("query"), c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "the variables must be a JSON object"})
+			}
+		}
+	} else if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	if strings.TrimSpace(request.Query) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "a query is required"})
+	}
+
+	params, operation, failure := prepareGraphQL(c.UserContext(), request)
+	switch {
+	case failure != nil:
+		return c.Status(failure.status).JSON(graphql.Result{Errors: failure.errors})
+	case operation == ast.OperationTypeSubscription:
+		return c.Status(fiber.StatusBadRequest).JSON(graphql.Result{Errors: []gqlerrors.FormattedError{
+			gqlerrors.NewFormattedError("Subscriptions are only served over a WebSocket."),
+		}})
+	case c.Method() == fiber.MethodGet && operation != ast.OperationTypeQuery:
+		// Reading must not change anything, so only the queries are executed.
+		c.Set(fiber.HeaderAllow, fiber.MethodPost)
+		return c.Status(fiber.StatusMethodNotAllowed).JSON(fiber.Map{"message": "only queries can be sent with GET"})
+	}
+	return c.Status(fiber.StatusOK).JSON(graphql.Execute(params))
+}
+
+// graphQLContextKey is the local holding the context of the requests
+// upgraded to GraphQL WebSockets.
+const graphQLContextKey = "graphql"
+
+// graphQLUpgrade upgrades the requests to WebSockets speaking
+// GraphQLProtocol.
+var graphQLUpgrade = websocket.New(func(conn *websocket.Conn) {
+	serveGraphQL(conn.Locals(graphQLContextKey).(context.Context), conn)
+}, websocket.Config{Subprotocols: []string{GraphQLProtocol}})
+
+// graphQLWebSocket upgrades the request to a WebSocket speaking
+// GraphQLProtocol.
+func graphQLWebSocket(c *fiber.Ctx) error {
+	offered := false
+	for _, protocol := range strings.Split(c.Get(fiber.HeaderSecWebSocketProtocol), ",") {
+		offered = offered || strings.TrimSpace(protocol) == GraphQLProtocol
+	}
+	if !offered {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "the websocket subprotocol must be " + GraphQLProtocol})
+	}
+
+	c.Locals(graphQLContextKey, c.UserContext())
+	return upgrade(c, graphQLUpgrade)
+}
+
+// graphQLMessage is a message of GraphQLProtocol.
+type graphQLMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// graphQLReply is a message of GraphQLProtocol sent by the server.
+type graphQLReply struct {
+	ID      string      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// serveGraphQL runs the operations the client subscribes to over the
+// WebSocket, each until it completes or the client completes it, and until the
+// WebSocket is closed.
+func serveGraphQL(ctx context.Context, conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	var running sync.WaitGroup
+	ws := &socket{conn: conn}
+	conn.SetReadLimit(graphQLReadLimit)
+	stopPings := ws.keepAlive()
+	defer stopPings()
+	defer running.Wait()
+	defer cancel()
+
+	var initialised atomic.Bool
+	timeout := time.AfterFunc(GraphQLInitTimeout, func() {
+		if !initialised.Load() {
+			_ = ws.close(closeInitTimeout, "Connection initialisation timeout")
+			conn.Close()
+		}
+	})
+	defer timeout.Stop()
+
+	var mu sync.Mutex
+	operations := make(map[string]context.CancelFunc)
+	for {
+		kind, payload, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if kind != websocket.TextMessage {
+			continue
+		}
+
+		var message graphQLMessage
+		if json.Unmarshal(payload, &message) != nil {
+			_ = ws.close(closeInvalidMessage, "Invalid message")
+			return
+		}
+		switch message.Type {
+		case "connection_init":
+			if initialised.Swap(true) {
+				_ = ws.close(closeTooManyInitialise, "Too many initialisation requests")
+				return
+			}
+			_ = ws.writeJSON(graphQLReply{Type: "connection_ack"})
+
+		case "ping":
+			_ = ws.writeJSON(graphQLReply{Type: "pong"})
+
+		case "pong":
+
+		case "subscribe":
+			if !initialised.Load() {
+				_ = ws.close(closeUnauthorized, "Unauthorized")
+				return
+			}
+			var request graphQLRequest
+			if message.ID == "" || json.Unmarshal(message.Payload, &request) != nil {
+				_ = ws.close(closeInvalidMessage, "Invalid message")
+				return
+			}
+
+			mu.Lock()
+			if _, ok := operations[message.ID]; ok {
+				mu.Unlock()
+				_ = ws.close(closeSubscriberExists, "Subscriber for "+message.ID+" already exists")
+				return
+			}
+			operation, stop := context.WithCancel(ctx)
+			operations[message.ID] = stop
+			mu.Unlock()
+
+			running.Add(1)
+			go func(id string) {
+				defer running.Done()
+				runGraphQL(operation, ws, id, request)
+				mu.Lock()
+				defer mu.Unlock()
+				stop()
+				delete(operations, id)
+			}(message.ID)
+
+		case "complete":
+			mu.Lock()
+			if stop, ok := operations[message.ID]; ok {
+				stop()
+			}
+			mu.Unlock()
+
+		default:
+			_ = ws.close(closeInvalidMessage, "Invalid message")
+			return
+		}
+	}
+}
+
+// runGraphQL sends the results of an operation, and completes it unless the
+// client did.
+func runGraphQL(ctx context.Context, ws *socket, id string, request graphQLRequest) {
+	params, operation, failure := prepareGraphQL(ctx, request)
+	if failure != nil {
+		_ = ws.writeJSON(graphQLReply{ID: id, Type: "error", Payload: failure.errors})
+		return
+	}
+
+	if operation != ast.OperationTypeSubscription {
+		_ = ws.writeJSON(graphQLReply{ID: id, Type: "next", Payload: graphql.Execute(params)})
+	} else {
+		// The results are sent until the context is done, so it is cancelled
+		// and the results left drained for the subscription to stop.
+		ctx, cancel := context.WithCancel(ctx)
+		params.Context = ctx
+		results := graphql.ExecuteSubscription(params)
+		defer func() {
+			cancel()
+			for range results {
+			}
+		}()
+
+		first := true
+		for result := range results {
+			// A subscription which cannot start fails before its first event.
+			if first && result.Data == nil {
+				_ = ws.writeJSON(graphQLReply{ID: id, Type: "error", Payload: result.Errors})
+				return
+			}
+			first = false
+			if ws.writeJSON(graphQLReply{ID: id, Type: "next", Payload: result}) != nil {
+				return
+			}
+		}
+	}
+
+	if ctx.Err() == nil {
+		_ = ws.writeJSON(graphQLReply{ID: id, Type: "complete"})
+	}
+}
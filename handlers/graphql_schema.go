@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// taskSchema is the schema of the GraphQL API, resolved with the repositories
+// of the database package, as the other handlers are.
+var taskSchema = newTaskSchema()
+
+// timeScalar is an RFC 3339 time.
+var timeScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Time",
+	Serialize: func(value interface{}) interface{} {
+		switch t := value.(type) {
+		case time.Time:
+			return t.Format(time.RFC3339Nano)
+		case *time.Time:
+			if t != nil {
+				return t.Format(time.RFC3339Nano)
+			}
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		if s, ok := value.(*ast.StringValue); ok {
+			if t, err := time.Parse(time.RFC3339, s.Value); err == nil {
+				return t
+			}
+		}
+		return nil
+	},
+})
+
+// taskPage is a page of the Tasks, along with how many there are in total.
+type taskPage struct {
+	Items []entity.Task
+	Total int64
+}
+
+// idArg reads an ID argument as a UUID.
+func idArg(p graphql.ResolveParams, name string) (uuid.UUID, error) {
+	s, _ := p.Args[name].(string)
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%s must be a UUID", name)
+	}
+	return id, nil
+}
+
+// user resolves a User from one of their IDs, which may be nil.
+func user(p graphql.ResolveParams, id *uuid.UUID) (interface{}, error) {
+	if id == nil || *id == uuid.Nil {
+		return nil, nil
+	}
+	u, err := database.Users.GetUser(p.Context, *id)
+	if errors.Is(err, entity.ErrUserNotFound) {
+		return nil, nil
+	}
+	return u, err
+}
+
+func newTaskSchema() graphql.Schema {
+	nonNull := graphql.NewNonNull
+	list := func(t graphql.Type) graphql.Output {
+		return nonNull(graphql.NewList(nonNull(t)))
+	}
+
+	priority := graphql.NewEnum(graphql.EnumConfig{Name: "Priority", Values: graphql.EnumValueConfigMap{
+		"LOW":    {Value: entity.PriorityLow},
+		"MEDIUM": {Value: entity.PriorityMedium},
+		"HIGH":   {Value: entity.PriorityHigh},
+	}})
+	role := graphql.NewEnum(graphql.EnumConfig{Name: "Role", Values: graphql.EnumValueConfigMap{
+		"OWNER":  {Value: entity.RoleOwner},
+		"EDITOR": {Value: entity.RoleEditor},
+		"VIEWER": {Value: entity.RoleViewer},
+	}})
+	changeType := graphql.NewEnum(graphql.EnumConfig{Name: "ChangeType", Values: graphql.EnumValueConfigMap{
+		"CREATED": {Value: changes.Created},
+		"UPDATED": {Value: changes.Updated},
+		"DELETED": {Value: changes.Deleted},
+	}})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User", Fields: graphql.Fields{
+		"id":          {Type: nonNull(graphql.ID)},
+		"username":    {Type: nonNull(graphql.String)},
+		"workspaceId": {Type: nonNull(graphql.ID)},
+		"createdAt":   {Type: nonNull(timeScalar)},
+	}})
+
+	checklistItemType := graphql.NewObject(graphql.ObjectConfig{Name: "ChecklistItem", Fields: graphql.Fields{
+		"id":        {Type: nonNull(graphql.ID)},
+		"position":  {Type: nonNull(graphql.Int)},
+		"text":      {Type: nonNull(graphql.String)},
+		"done":      {Type: nonNull(graphql.Boolean)},
+		"createdAt": {Type: nonNull(timeScalar)},
+	}})
+
+	commentType := graphql.NewObject(graphql.ObjectConfig{Name: "Comment", Fields: graphql.Fields{
+		"id":   {Type: nonNull(graphql.ID)},
+		"body": {Type: nonNull(graphql.String)},
+		"author": {Type: userType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			comment := p.Source.(entity.Comment)
+			return user(p, &comment.AuthorID)
+		}},
+		"createdAt": {Type: nonNull(timeScalar)},
+		"editedAt":  {Type: timeScalar},
+	}})
+
+	attachmentType := graphql.NewObject(graphql.ObjectConfig{Name: "Attachment", Fields: graphql.Fields{
+		"id":          {Type: nonNull(graphql.ID)},
+		"filename":    {Type: nonNull(graphql.String)},
+		"contentType": {Type: nonNull(graphql.String)},
+		"size":        {Type: nonNull(graphql.Float)},
+		"uploader": {Type: userType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			attachment := p.Source.(entity.Attachment)
+			return user(p, &attachment.UploaderID)
+		}},
+		"createdAt": {Type: nonNull(timeScalar)},
+	}})
+
+	shareType := graphql.NewObject(graphql.ObjectConfig{Name: "Share", Fields: graphql.Fields{
+		"user": {Type: userType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			share := p.Source.(entity.Share)
+			return user(p, &share.UserID)
+		}},
+		"role":      {Type: nonNull(role)},
+		"createdAt": {Type: nonNull(timeScalar)},
+	}})
+
+	taskType := graphql.NewObject(graphql.ObjectConfig{Name: "Task", Fields: graphql.Fields{
+		"id":          {Type: nonNull(graphql.ID)},
+		"description": {Type: nonNull(graphql.String)},
+		"priority":    {Type: nonNull(priority)},
+		"completed":   {Type: nonNull(graphql.Boolean)},
+		"archived":    {Type: nonNull(graphql.Boolean)},
+		"dueAt":       {Type: timeScalar},
+		"createdAt":   {Type: nonNull(timeScalar)},
+		"updatedAt":   {Type: nonNull(timeScalar)},
+		"completedAt": {Type: timeScalar},
+		"archivedAt":  {Type: timeScalar},
+		"owner": {Type: userType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			t := p.Source.(entity.Task)
+			return user(p, &t.OwnerID)
+		}},
+		"assignee": {Type: userType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return user(p, p.Source.(entity.Task).AssigneeID)
+		}},
+		"checklist": {Type: list(checklistItemType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if checklist := p.Source.(entity.Task).Checklist; checklist != nil {
+				return checklist, nil
+			}
+			return []entity.ChecklistItem{}, nil
+		}},
+		"checklistCompletion": {Type: nonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			t := p.Source.(entity.Task)
+			return t.ChecklistCompletion(), nil
+		}},
+		"comments": {Type: list(commentType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return database.Comments.Comments(p.Context, p.Source.(entity.Task).ID)
+		}},
+		"attachments": {Type: list(attachmentType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return database.Attachments.Attachments(p.Context, p.Source.(entity.Task).ID)
+		}},
+		"shares": {Type: list(shareType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return database.Repo.Shares(p.Context, p.Source.(entity.Task).ID)
+		}},
+	}})
+
+	taskPageType := graphql.NewObject(graphql.ObjectConfig{Name: "TaskPage", Fields: graphql.Fields{
+		"items": {Type: list(taskType)},
+		"total": {Type: nonNull(graphql.Int)},
+	}})
+
+	taskChangeType := graphql.NewObject(graphql.ObjectConfig{Name: "TaskChange", Fields: graphql.Fields{
+		"id": {Type: nonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return strconv.FormatUint(p.Source.(changes.Change).ID, 10), nil
+		}},
+		"type": {Type: nonNull(changeType)},
+		"task": {Type: nonNull(taskType)},
+	}})
+
+	taskFilter := graphql.NewInputObject(graphql.InputObjectConfig{Name: "TaskFilter", Fields: graphql.InputObjectConfigFieldMap{
+		"archived":   {Type: graphql.Boolean, DefaultValue: false},
+		"completed":  {Type: graphql.Boolean},
+		"priority":   {Type: priority},
+		"assignee":   {Type: graphql.ID},
+		"unassigned": {Type: graphql.Boolean, DefaultValue: false},
+	}})
+	newTask := graphql.NewInputObject(graphql.InputObjectConfig{Name: "NewTask", Fields: graphql.InputObjectConfigFieldMap{
+		"description": {Type: nonNull(graphql.String)},
+		"priority":    {Type: priority},
+		"completed":   {Type: graphql.Boolean},
+		"dueAt":       {Type: timeScalar},
+	}})
+	taskChanges := graphql.NewInputObject(graphql.InputObjectConfig{Name: "TaskChanges", Fields: graphql.InputObjectConfigFieldMap{
+		"description": {Type: graphql.String},
+		"priority":    {Type: priority},
+		"completed":   {Type: graphql.Boolean},
+		"archived":    {Type: graphql.Boolean},
+		"dueAt":       {Type: timeScalar},
+		"clearDueAt":  {Type: graphql.Boolean, DefaultValue: false},
+	}})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.Fields{
+			"me": {Type: nonNull(userType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				owner, _ := task.OwnerFromContext(p.Context)
+				return database.Users.GetUser(p.Context, owner)
+			}},
+			"task": {
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					t, err := database.Repo.Get(p.Context, id)
+					if errors.Is(err, entity.ErrTaskNotFound) {
+						return nil, nil
+					}
+					return t, err
+				},
+			},
+			"tasks": {
+				Type: nonNull(taskPageType),
+				Args: graphql.FieldConfigArgument{
+					"filter": {Type: taskFilter},
+					"limit":  {Type: graphql.Int, DefaultValue: 0},
+					"offset": {Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: findTasks,
+			},
+		}}),
+
+		Mutation: graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: graphql.Fields{
+			"createTask": {
+				Type:    nonNull(taskType),
+				Args:    graphql.FieldConfigArgument{"input": {Type: nonNull(newTask)}},
+				Resolve: createTask,
+			},
+			"updateTask": {
+				Type: nonNull(taskType),
+				Args: graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.ID)}, "input": {Type: nonNull(taskChanges)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return updateTask(p, p.Args["input"].(map[string]interface{}))
+				},
+			},
+			"completeTask": {
+				Type: nonNull(taskType),
+				Args: graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.ID)}, "completed": {Type: graphql.Boolean, DefaultValue: true}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return updateTask(p, map[string]interface{}{"completed": p.Args["completed"]})
+				},
+			},
+			"deleteTask": {
+				Type: nonNull(graphql.ID),
+				Args: graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					if _, err := database.Repo.Get(p.Context, id); err != nil {
+						return nil, err
+					}
+					return id, database.Repo.Delete(p.Context, id)
+				},
+			},
+		}}),
+
+		Subscription: graphql.NewObject(graphql.ObjectConfig{Name: "Subscription", Fields: graphql.Fields{
+			"taskChanged": {
+				Type:      nonNull(taskChangeType),
+				Args:      graphql.FieldConfigArgument{"after": {Type: graphql.ID}},
+				Subscribe: subscribeTaskChanges,
+				// Each change is resolved as the root of the subscription.
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		}}),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// The enums build the lookups of their values on first use, which the
+	// requests run concurrently must not race to do.
+	for _, enum := range []*graphql.Enum{priority, role, changeType} {
+		enum.Serialize(nil)
+		enum.ParseValue("")
+	}
+	return schema
+}
+
+// findTasks returns a page of the Tasks. The filters on the completion and on
+// the priority are applied to the Tasks found, so the pages are cut here
+// rather than by the repository.
+func findTasks(p graphql.ResolveParams) (interface{}, error) {
+	limit, _ := p.Args["limit"].(int)
+	offset, _ := p.Args["offset"].(int)
+	if limit < 0 || offset < 0 {
+		return nil, errors.New("limit and offset cannot be negative")
+	}
+
+	var filter task.Filter
+	conditions, _ := p.Args["filter"].(map[string]interface{})
+	if conditions != nil {
+		filter.Archived, _ = conditions["archived"].(bool)
+		filter.Unassigned, _ = conditions["unassigned"].(bool)
+		if assignee, ok := conditions["assignee"].(string); ok {
+			id, err := uuid.Parse(assignee)
+			if err != nil {
+				return nil, errors.New("assignee must be a UUID")
+			}
+			filter.AssignedTo = id
+		}
+	}
+	completed, byCompletion := conditions["completed"].(bool)
+	level, byPriority := conditions["priority"].(entity.Priority)
+	if !byCompletion && !byPriority {
+		filter.Limit, filter.Offset = limit, offset
+		tasks, total, err := database.Repo.Find(p.Context, filter)
+		if tasks == nil {
+			tasks = []entity.Task{}
+		}
+		return taskPage{Items: tasks, Total: total}, err
+	}
+
+	found, _, err := database.Repo.Find(p.Context, filter)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]entity.Task, 0, len(found))
+	for _, t := range found {
+		if (!byCompletion || t.Completed == completed) && (!byPriority || t.Priority == level) {
+			tasks = append(tasks, t)
+		}
+	}
+	page := taskPage{Items: []entity.Task{}, Total: int64(len(tasks))}
+	if offset < len(tasks) {
+		tasks = tasks[offset:]
+		if limit > 0 && limit < len(tasks) {
+			tasks = tasks[:limit]
+		}
+		page.Items = tasks
+	}
+	return page, nil
+}
+
+func createTask(p graphql.ResolveParams) (interface{}, error) {
+	input := p.Args["input"].(map[string]interface{})
+	t := entity.NewTask(input["description"].(string))
+	if level, ok := input["priority"].(entity.Priority); ok {
+		t.Priority = level
+	}
+	if completed, ok := input["completed"].(bool); ok {
+		t.Completed = completed
+	}
+	if due, ok := input["dueAt"].(time.Time); ok {
+		t.DueAt = &due
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := database.Repo.Post(p.Context, t); err != nil {
+		return nil, err
+	}
+	return database.Repo.Get(p.Context, t.ID)
+}
+
+// updateTask applies the changes given to the Task of the id argument. The
+// fields which are absent are left as they are, and clearDueAt removes the due
+// date.
+func updateTask(p graphql.ResolveParams, input map[string]interface{}) (interface{}, error) {
+	id, err := idArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+	t, err := database.Repo.Get(p.Context, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if description, ok := input["description"].(string); ok {
+		t.Description = description
+	}
+	if level, ok := input["priority"].(entity.Priority); ok {
+		t.Priority = level
+	}
+	if completed, ok := input["completed"].(bool); ok {
+		t.Completed = completed
+	}
+	if archived, ok := input["archived"].(bool); ok {
+		t.Archived = archived
+	}
+	if due, ok := input["dueAt"].(time.Time); ok {
+		t.DueAt = &due
+	} else if clear, _ := input["clearDueAt"].(bool); clear {
+		t.DueAt = nil
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := database.Repo.Put(p.Context, &t); err != nil {
+		return nil, err
+	}
+	return database.Repo.Get(p.Context, id)
+}
+
+// subscribeTaskChanges sends the changes to the Tasks the User can see, after
+// the change of the after argument if it is given, as GET /events does. They are
+// sent on a chan interface{}, which is what graphql.ExecuteSubscription reads
+// the events of a subscription from.
+func subscribeTaskChanges(p graphql.ResolveParams) (interface{}, error) {
+	var after uint64
+	if last, ok := p.Args["after"].(string); ok {
+		var err error
+		if after, err = strconv.ParseUint(last, 10, 64); err != nil {
+			return nil, errors.New("after must be the ID of a change")
+		}
+	}
+
+	user, _ := task.OwnerFromContext(p.Context)
+	subscription, missed, err := changes.Default.Subscribe(user, after)
+	if err != nil {
+		subscription.Close()
+		return nil, err
+	}
+
+	events := make(chan interface{})
+	go func() {
+		defer close(events)
+		defer subscription.Close()
+		for _, change := range missed {
+			select {
+			case events <- change:
+			case <-p.Context.Done():
+				return
+			}
+		}
+		for {
+			select {
+			case change, ok := <-subscription.Changes():
+				if !ok {
+					return
+				}
+				select {
+				case events <- change:
+				case <-p.Context.Done():
+					return
+				}
+			case <-p.Context.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,311 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+// graphQLRequest is the JSON of a GraphQL request.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphQLResponse is the JSON of a GraphQL result.
+type graphQLResponse struct {
+	Data   map[string]interface{}     `json:"data"`
+	Errors []gqlerrors.FormattedError `json:"errors"`
+}
+
+func TestGraphQL(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users, database.Comments, database.Attachments = repo, repo, repo, repo
+
+	owner := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &owner))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	// query POSTs a request, and decodes its result.
+	query := func(request graphQLRequest) (int, graphQLResponse) {
+		data, _ := json.Marshal(request)
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		resp, err := app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		var result graphQLResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+
+	status, created := query(graphQLRequest{
+		Query: `mutation ($input: NewTask!) {
+			createTask(input: $input) { id description priority completed dueAt owner { username } }
+		}`,
+		Variables: map[string]interface{}{"input": map[string]interface{}{
+			"description": GENERIC_TASK_NAME, "priority": "HIGH", "dueAt": "2024-04-01T09:00:00Z",
+		}},
+	})
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Empty(t, created.Errors)
+	task := created.Data["createTask"].(map[string]interface{})
+	assert.Equal(t, "HIGH", task["priority"])
+	assert.Equal(t, "2024-04-01T09:00:00Z", task["dueAt"])
+	assert.Equal(t, testUser.Username, task["owner"].(map[string]interface{})["username"])
+	id := task["id"].(string)
+
+	discussed, err := entity.NewComment(uuid.MustParse(id), "Looks good")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.AddComment(testCtx, discussed))
+	assert.NoError(t, repo.Post(testCtx, entity.NewTask("Done already").WithCompleted(true)))
+
+	t.Run("Fetch the Tasks with their comments in one request", func(t *testing.T) {
+		status, result := query(graphQLRequest{Query: `{
+			tasks(filter: {completed: false}, limit: 10) {
+				total
+				items { description checklistCompletion comments { body author { username } } }
+			}
+			me { username }
+		}`})
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Empty(t, result.Errors)
+		page := result.Data["tasks"].(map[string]interface{})
+		assert.EqualValues(t, 1, page["total"])
+		items := page["items"].([]interface{})
+		if assert.Len(t, items, 1) {
+			comments := items[0].(map[string]interface{})["comments"].([]interface{})
+			if assert.Len(t, comments, 1) {
+				comment := comments[0].(map[string]interface{})
+				assert.Equal(t, "Looks good", comment["body"])
+				assert.Equal(t, testUser.Username, comment["author"].(map[string]interface{})["username"])
+			}
+		}
+		assert.Equal(t, testUser.Username, result.Data["me"].(map[string]interface{})["username"])
+	})
+
+	t.Run("Paginate the Tasks", func(t *testing.T) {
+		_, result := query(graphQLRequest{Query: `{ tasks(limit: 1, offset: 1) { total items { description } } }`})
+		assert.Empty(t, result.Errors)
+		page := result.Data["tasks"].(map[string]interface{})
+		assert.EqualValues(t, 2, page["total"])
+		assert.Len(t, page["items"], 1)
+	})
+
+	t.Run("Update and complete a Task", func(t *testing.T) {
+		_, result := query(graphQLRequest{
+			Query: `mutation ($id: ID!) {
+				updateTask(id: $id, input: {description: "Renamed", clearDueAt: true}) { description dueAt }
+				completeTask(id: $id) { completed completedAt }
+			}`,
+			Variables: map[string]interface{}{"id": id},
+		})
+		assert.Empty(t, result.Errors)
+		updated := result.Data["updateTask"].(map[string]interface{})
+		assert.Equal(t, "Renamed", updated["description"])
+		assert.Nil(t, updated["dueAt"])
+		completed := result.Data["completeTask"].(map[string]interface{})
+		assert.Equal(t, true, completed["completed"])
+		assert.NotNil(t, completed["completedAt"])
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		_, result := query(graphQLRequest{Query: `mutation { updateTask(id: "not-a-uuid", input: {}) { id } }`})
+		assert.Nil(t, result.Data)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, []interface{}{"updateTask"}, result.Errors[0].Path)
+		}
+
+		status, result = query(graphQLRequest{Query: `{ tasks { items { id }`})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.NotEmpty(t, result.Errors)
+
+		_, result = query(graphQLRequest{Query: `mutation { createTask(input: {description: ""}) { id } }`})
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, entity.ErrInvalidTaskDescription.Error(), result.Errors[0].Message)
+		}
+	})
+
+	t.Run("Query with GET", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`query ($id: ID!) { task(id: $id) { description } }`)+
+			"&variables="+url.QueryEscape(`{"id":"`+id+`"}`), nil)
+		resp, err := app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		req = httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { deleteTask(id: "`+id+`") }`), nil)
+		resp, err = app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusMethodNotAllowed, resp.StatusCode, "mutations are not sent with GET")
+	})
+
+	t.Run("Read API tokens cannot run mutations", func(t *testing.T) {
+		reader, secret, _ := entity.NewAPIToken(owner.ID, "dashboard", entity.ScopeRead, nil)
+		assert.NoError(t, repo.CreateToken(testCtx, reader))
+		data, _ := json.Marshal(graphQLRequest{Query: `mutation { createTask(input: {description: "Sneaked in"}) { id } }`})
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+secret)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Delete a Task", func(t *testing.T) {
+		_, result := query(graphQLRequest{Query: `mutation ($id: ID!) { deleteTask(id: $id) }`, Variables: map[string]interface{}{"id": id}})
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, id, result.Data["deleteTask"])
+
+		_, result = query(graphQLRequest{Query: `query ($id: ID!) { task(id: $id) { id } }`, Variables: map[string]interface{}{"id": id}})
+		assert.Empty(t, result.Errors)
+		assert.Nil(t, result.Data["task"], "deleted Tasks are not found")
+	})
+}
+
+func TestGraphQLSubscriptions(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = changes.Watch(repo, changes.Default), repo
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	router.SetupRoutes(app)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	defer listener.Close()
+	go func() { _ = app.Listener(listener) }()
+
+	owner := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &owner))
+	reader, readSecret, _ := entity.NewAPIToken(owner.ID, "dashboard", entity.ScopeRead, nil)
+	assert.NoError(t, repo.CreateToken(testCtx, reader))
+
+	// dialAs opens a WebSocket offering the given subprotocol, authorized by
+	// the given header.
+	dialAs := func(protocol, authorization string) (*websocket.Conn, *http.Response) {
+		header := http.Header{}
+		header.Set(fiber.HeaderSecWebSocketProtocol, protocol)
+		header.Set(fiber.HeaderAuthorization, authorization)
+		ws, resp, err := websocket.DefaultDialer.Dial("ws://"+listener.Addr().String()+"/graphql", header)
+		if err != nil {
+			return nil, resp
+		}
+		t.Cleanup(func() { ws.Close() })
+		_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		return ws, resp
+	}
+	// dial opens a WebSocket with a session.
+	dial := func(protocol string) (*websocket.Conn, *http.Response) {
+		return dialAs(protocol, authorized(httptest.NewRequest(http.MethodGet, "/", nil)).Header.Get(fiber.HeaderAuthorization))
+	}
+	send := func(ws *websocket.Conn, message string) {
+		assert.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(message)))
+	}
+	// receive reads the next message, or the status code the WebSocket is
+	// closed with.
+	receive := func(ws *websocket.Conn) map[string]interface{} {
+		_, data, err := ws.ReadMessage()
+		var closed *websocket.CloseError
+		if errors.As(err, &closed) {
+			return map[string]interface{}{"close": closed.Code}
+		}
+		if !assert.NoError(t, err, NO_ERROR_EXPECTED) {
+			return nil
+		}
+		var message map[string]interface{}
+		assert.NoError(t, json.Unmarshal(data, &message))
+		return message
+	}
+
+	t.Run("Subscribe to the changes", func(t *testing.T) {
+		ws, resp := dial("other, " + handlers.GraphQLProtocol)
+		assert.Equal(t, fiber.StatusSwitchingProtocols, resp.StatusCode)
+		assert.Equal(t, handlers.GraphQLProtocol, resp.Header.Get(fiber.HeaderSecWebSocketProtocol))
+
+		send(ws, `{"type":"connection_init"}`)
+		assert.Equal(t, "connection_ack", receive(ws)["type"])
+
+		send(ws, `{"id":"changes","type":"subscribe","payload":{"query":"subscription { taskChanged { type task { description } } }"}}`)
+		send(ws, `{"id":"me","type":"subscribe","payload":{"query":"{ me { username } }"}}`)
+		var replies []string
+		for len(replies) < 2 {
+			message := receive(ws)
+			replies = append(replies, message["id"].(string)+" "+message["type"].(string))
+		}
+		assert.Equal(t, []string{"me next", "me complete"}, replies, "queries complete after their result")
+
+		assert.NoError(t, database.Repo.Post(testCtx, entity.NewTask("Over GraphQL")))
+		message := receive(ws)
+		assert.Equal(t, "changes", message["id"])
+		assert.Equal(t, "next", message["type"])
+		change := message["payload"].(map[string]interface{})["data"].(map[string]interface{})["taskChanged"].(map[string]interface{})
+		assert.Equal(t, "CREATED", change["type"])
+		assert.Equal(t, "Over GraphQL", change["task"].(map[string]interface{})["description"])
+
+		send(ws, `{"type":"ping"}`)
+		assert.Equal(t, "pong", receive(ws)["type"])
+
+		send(ws, `{"id":"changes","type":"subscribe","payload":{"query":"subscription { taskChanged { id } }"}}`)
+		assert.Equal(t, 4409, receive(ws)["close"], "the ids of the operations are unique")
+	})
+
+	t.Run("Subscribe before initialising", func(t *testing.T) {
+		ws, _ := dial(handlers.GraphQLProtocol)
+		send(ws, `{"id":"1","type":"subscribe","payload":{"query":"{ me { username } }"}}`)
+		assert.Equal(t, 4401, receive(ws)["close"], "subscribing requires a connection_init")
+	})
+
+	t.Run("Invalid subscriptions", func(t *testing.T) {
+		ws, _ := dial(handlers.GraphQLProtocol)
+		send(ws, `{"type":"connection_init"}`)
+		receive(ws)
+		send(ws, `{"id":"1","type":"subscribe","payload":{"query":"subscription { taskChanged(after: \"soon\") { id } }"}}`)
+		message := receive(ws)
+		assert.Equal(t, "error", message["type"])
+		assert.NotEmpty(t, message["payload"])
+	})
+
+	t.Run("Read API tokens cannot run mutations", func(t *testing.T) {
+		ws, resp := dialAs(handlers.GraphQLProtocol, "Bearer "+readSecret)
+		assert.Equal(t, fiber.StatusSwitchingProtocols, resp.StatusCode)
+		send(ws, `{"type":"connection_init"}`)
+		assert.Equal(t, "connection_ack", receive(ws)["type"])
+
+		send(ws, `{"id":"create","type":"subscribe","payload":{"query":"mutation { createTask(input: {description: \"Sneaked in\"}) { id } }"}}`)
+		message := receive(ws)
+		assert.Equal(t, "create", message["id"])
+		assert.Equal(t, "error", message["type"])
+		tasks, err := repo.All(testCtx)
+		assert.NoError(t, err)
+		for _, task := range tasks {
+			assert.NotEqual(t, "Sneaked in", task.Description)
+		}
+
+		send(ws, `{"id":"me","type":"subscribe","payload":{"query":"{ me { username } }"}}`)
+		message = receive(ws)
+		assert.Equal(t, "me", message["id"])
+		assert.Equal(t, "next", message["type"], "the queries are still run")
+	})
+
+	t.Run("Unsupported subprotocol", func(t *testing.T) {
+		_, resp := dial("graphql-ws")
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/taskpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewGRPCServer serves the TaskService of tasks.proto over database.Repo, to
+// the callers sending a session or an API token as Authenticate accepts.
+func NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(authenticateUnary),
+		grpc.StreamInterceptor(authenticateStream),
+	)
+	s := grpc.NewServer(opts...)
+	taskpb.RegisterTaskServiceServer(s, taskService{})
+	return s
+}
+
+// readMethods are the methods allowed to the API tokens which only read.
+var readMethods = map[string]bool{
+	taskpb.TaskService_Get_FullMethodName:   true,
+	taskpb.TaskService_List_FullMethodName:  true,
+	taskpb.TaskService_Watch_FullMethodName: true,
+}
+
+// authenticateUnary authenticates the unary calls with authenticateCall.
+func authenticateUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticateCall(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authenticatedStream is a stream whose context identifies its caller.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticateStream authenticates the streaming calls with authenticateCall.
+func authenticateStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := authenticateCall(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticateCall identifies the User of the bearer token of the
+// authorization metadata of a call.
+func authenticateCall(ctx context.Context, method string) (context.Context, error) {
+	var header string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(fiber.HeaderAuthorization); len(values) == 1 {
+		header = values[0]
+	}
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "a bearer token is required")
+	}
+
+	if entity.IsAPIToken(token) {
+		verb := http.MethodPost
+		if readMethods[method] {
+			verb = http.MethodGet
+		}
+		user, scope, err := apiTokenUser(ctx, token, verb)
+		if err != nil {
+			return nil, rpcError(err)
+		}
+		return identified(withTokenScope(ctx, scope), user.ID, user.Username, user.WorkspaceID), nil
+	}
+
+	claims, err := auth.Default.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	id, _ := claims.UserID()
+	return identified(ctx, id, claims.Username, claims.WorkspaceID), nil
+}
+
+// rpcError maps repository and validation errors onto the codes of gRPC, as
+// statusFor does onto HTTP status codes.
+func rpcError(err error) error {
+	code := codes.Internal
+	switch statusFor(err) {
+	case fiber.StatusNotFound:
+		code = codes.NotFound
+	case fiber.StatusConflict:
+		code = codes.AlreadyExists
+	case fiber.StatusBadRequest, fiber.StatusRequestEntityTooLarge:
+		code = codes.InvalidArgument
+	case fiber.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case fiber.StatusForbidden:
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, err.Error())
+}
+
+// taskID parses the ID of a Task in a request.
+func taskID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "the id must be a UUID")
+	}
+	return parsed, nil
+}
+
+// timestamp is the message of an optional time.
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// timeOf is the time of an optional timestamp message.
+func timeOf(ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	t := ts.AsTime()
+	return &t, nil
+}
+
+// taskMessage is the message of a Task.
+func taskMessage(t entity.Task) *taskpb.Task {
+	m := &taskpb.Task{
+		Id:          t.ID.String(),
+		Description: t.Description,
+		Priority:    taskpb.Priority(t.Priority),
+		Completed:   t.Completed,
+		Archived:    t.Archived,
+		OwnerId:     t.OwnerID.String(),
+		DueAt:       timestamp(t.DueAt),
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+		CompletedAt: timestamp(t.CompletedAt),
+		ArchivedAt:  timestamp(t.ArchivedAt),
+	}
+	if t.AssigneeID != nil {
+		m.AssigneeId = t.AssigneeID.String()
+	}
+	return m
+}
+
+// changeTypes are the types of the messages of the changes.
+var changeTypes = map[changes.Type]taskpb.ChangeType{
+	changes.Created: taskpb.ChangeType_CHANGE_TYPE_CREATED,
+	changes.Updated: taskpb.ChangeType_CHANGE_TYPE_UPDATED,
+	changes.Deleted: taskpb.ChangeType_CHANGE_TYPE_DELETED,
+}
+
+// taskService implements the TaskService with database.Repo.
+type taskService struct {
+	taskpb.UnimplementedTaskServiceServer
+}
+
+func (taskService) Create(ctx context.Context, in *taskpb.CreateTaskRequest) (*taskpb.Task, error) {
+	due, err := timeOf(in.DueAt)
+	if err != nil {
+		return nil, err
+	}
+	t := entity.NewTask(in.Description)
+	if in.Priority != taskpb.Priority_PRIORITY_UNSPECIFIED {
+		t.Priority = entity.Priority(in.Priority)
+	}
+	t.Completed, t.DueAt = in.Completed, due
+	if err := t.Validate(); err != nil {
+		return nil, rpcError(err)
+	}
+
+	if err := database.Repo.Post(ctx, t); err != nil {
+		return nil, rpcError(err)
+	}
+	created, err := database.Repo.Get(ctx, t.ID)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return taskMessage(created), nil
+}
+
+func (taskService) Get(ctx context.Context, in *taskpb.GetTaskRequest) (*taskpb.Task, error) {
+	id, err := taskID(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	t, err := database.Repo.Get(ctx, id)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return taskMessage(t), nil
+}
+
+func (taskService) Update(ctx context.Context, in *taskpb.UpdateTaskRequest) (*taskpb.Task, error) {
+	id, err := taskID(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	t, err := database.Repo.Get(ctx, id)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	if in.Description != nil {
+		t.Description = *in.Description
+	}
+	if in.Priority != nil {
+		t.Priority = entity.Priority(*in.Priority)
+	}
+	if in.Completed != nil {
+		t.Completed = *in.Completed
+	}
+	if in.Archived != nil {
+		t.Archived = *in.Archived
+	}
+	if in.DueAt != nil {
+		if t.DueAt, err = timeOf(in.DueAt); err != nil {
+			return nil, err
+		}
+	} else if in.ClearDueAt {
+		t.DueAt = nil
+	}
+	if err := t.Validate(); err != nil {
+		return nil, rpcError(err)
+	}
+
+	if err := database.Repo.Put(ctx, &t); err != nil {
+		return nil, rpcError(err)
+	}
+	if t, err = database.Repo.Get(ctx, id); err != nil {
+		return nil, rpcError(err)
+	}
+	return taskMessage(t), nil
+}
+
+func (taskService) Delete(ctx context.Context, in *taskpb.DeleteTaskRequest) (*taskpb.DeleteTaskResponse, error) {
+	id, err := taskID(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := database.Repo.Get(ctx, id); err != nil {
+		return nil, rpcError(err)
+	}
+	if err := database.Repo.Delete(ctx, id); err != nil {
+		return nil, rpcError(err)
+	}
+	return &taskpb.DeleteTaskResponse{}, nil
+}
+
+func (taskService) List(in *taskpb.ListTasksRequest, stream taskpb.TaskService_ListServer) error {
+	if in.Limit < 0 || in.Offset < 0 {
+		return status.Error(codes.InvalidArgument, "limit and offset cannot be negative")
+	}
+	filter := task.Filter{
+		Archived:   in.Archived,
+		Limit:      int(in.Limit),
+		Offset:     int(in.Offset),
+		Unassigned: in.Unassigned,
+	}
+	if in.AssigneeId != "" {
+		assignee, err := uuid.Parse(in.AssigneeId)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "the assignee_id must be a UUID")
+		}
+		filter.AssignedTo = assignee
+	}
+
+	tasks, _, err := database.Repo.Find(stream.Context(), filter)
+	if err != nil {
+		return rpcError(err)
+	}
+	for _, t := range tasks {
+		if err := stream.Send(taskMessage(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Watch sends the changes to the Tasks the User can see, as GET /events does,
+// until the call is cancelled or the client falls too far behind.
+func (taskService) Watch(in *taskpb.WatchTasksRequest, stream taskpb.TaskService_WatchServer) error {
+	ctx := stream.Context()
+	user, _ := task.OwnerFromContext(ctx)
+	subscription, missed, err := changes.Default.Subscribe(user, in.After)
+	defer subscription.Close()
+	if errors.Is(err, changes.ErrBacklogExceeded) {
+		if err := stream.Send(&taskpb.TaskChange{Type: taskpb.ChangeType_CHANGE_TYPE_RESET}); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return rpcError(err)
+	}
+	// The headers tell the caller that the changes are now watched.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for _, change := range missed {
+		if err := stream.Send(changeMessage(change)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				return status.Error(codes.Unavailable, "resume from the last change")
+			}
+			if err := stream.Send(changeMessage(change)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// changeMessage is the message of a change.
+func changeMessage(change changes.Change) *taskpb.TaskChange {
+	return &taskpb.TaskChange{
+		Id:   change.ID,
+		Type: changeTypes[change.Type],
+		Task: taskMessage(change.Task),
+	}
+}
@@ -0,0 +1,169 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/omaciel/GoDoIt/taskpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// bearer adds the authorization metadata of a token to the calls made with
+// a context.
+func bearer(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, fiber.HeaderAuthorization, "Bearer "+token)
+}
+
+// serveGRPC serves the TaskService in memory, and returns a client of it.
+func serveGRPC(t *testing.T) taskpb.TaskServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := handlers.NewGRPCServer()
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	t.Cleanup(func() { conn.Close() })
+	return taskpb.NewTaskServiceClient(conn)
+}
+
+func TestGRPC(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = changes.Watch(repo, changes.Default), repo
+	user := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &user))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+	reader := createToken(t, app, handlers.TokenRequest{Name: "reports", Scope: entity.ScopeRead})
+
+	tasks := serveGRPC(t)
+	session, _, _ := auth.Default.Issue(testUser)
+	ctx, cancel := context.WithCancel(bearer(context.Background(), session))
+	defer cancel()
+
+	watch, err := tasks.Watch(ctx, &taskpb.WatchTasksRequest{})
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	// The headers are sent once the changes are watched.
+	_, err = watch.Header()
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+
+	due := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	created, err := tasks.Create(ctx, &taskpb.CreateTaskRequest{Description: GENERIC_TASK_NAME, Priority: taskpb.Priority_PRIORITY_HIGH, DueAt: timestamppb.New(due)})
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, GENERIC_TASK_NAME, created.Description)
+	assert.Equal(t, taskpb.Priority_PRIORITY_HIGH, created.Priority)
+	assert.Equal(t, testUser.ID.String(), created.OwnerId)
+	if assert.NotNil(t, created.DueAt) {
+		assert.True(t, due.Equal(created.DueAt.AsTime()))
+	}
+
+	t.Run("Watch the changes", func(t *testing.T) {
+		change, err := watch.Recv()
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, taskpb.ChangeType_CHANGE_TYPE_CREATED, change.Type)
+		assert.Equal(t, created.Id, change.Task.Id)
+	})
+
+	t.Run("Get a Task", func(t *testing.T) {
+		found, err := tasks.Get(ctx, &taskpb.GetTaskRequest{Id: created.Id})
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.True(t, proto.Equal(created, found))
+	})
+
+	t.Run("Update a Task", func(t *testing.T) {
+		description, completed := "Renamed", true
+		updated, err := tasks.Update(ctx, &taskpb.UpdateTaskRequest{
+			Id: created.Id, Description: &description, Completed: &completed, ClearDueAt: true,
+		})
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, "Renamed", updated.Description)
+		assert.Equal(t, taskpb.Priority_PRIORITY_HIGH, updated.Priority, "the fields which are not set are kept")
+		assert.True(t, updated.Completed)
+		assert.NotNil(t, updated.CompletedAt)
+		assert.Nil(t, updated.DueAt)
+	})
+
+	t.Run("List the Tasks", func(t *testing.T) {
+		for _, description := range []string{"Second", "Third"} {
+			_, err := tasks.Create(ctx, &taskpb.CreateTaskRequest{Description: description})
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+		}
+
+		stream, err := tasks.List(ctx, &taskpb.ListTasksRequest{Limit: 2, Offset: 1})
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		var descriptions []string
+		for listed, err := stream.Recv(); err != io.EOF; listed, err = stream.Recv() {
+			if !assert.NoError(t, err, NO_ERROR_EXPECTED) {
+				break
+			}
+			descriptions = append(descriptions, listed.Description)
+		}
+		assert.Equal(t, []string{"Second", "Third"}, descriptions)
+	})
+
+	t.Run("Delete a Task", func(t *testing.T) {
+		_, err := tasks.Delete(ctx, &taskpb.DeleteTaskRequest{Id: created.Id})
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+
+		_, err = tasks.Get(ctx, &taskpb.GetTaskRequest{Id: created.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = tasks.Delete(ctx, &taskpb.DeleteTaskRequest{Id: created.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		_, err := tasks.Create(ctx, &taskpb.CreateTaskRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, entity.ErrInvalidTaskDescription.Error(), status.Convert(err).Message())
+		_, err = tasks.Create(ctx, &taskpb.CreateTaskRequest{Description: GENERIC_TASK_NAME, Priority: 7})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = tasks.Create(ctx, &taskpb.CreateTaskRequest{Description: GENERIC_TASK_NAME, DueAt: &timestamppb.Timestamp{Nanos: -1}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = tasks.Get(ctx, &taskpb.GetTaskRequest{Id: "not-a-uuid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Authentication", func(t *testing.T) {
+		_, err := tasks.Get(context.Background(), &taskpb.GetTaskRequest{Id: created.Id})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		anonymous, err := tasks.List(bearer(context.Background(), "forged"), &taskpb.ListTasksRequest{})
+		if assert.NoError(t, err, "streams fail when receiving") {
+			_, err = anonymous.Recv()
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		}
+
+		readOnly := bearer(context.Background(), reader.Token)
+		stream, err := tasks.List(readOnly, &taskpb.ListTasksRequest{})
+		if assert.NoError(t, err, NO_ERROR_EXPECTED) {
+			_, err = stream.Recv()
+			assert.NoError(t, err, "read-only tokens list the Tasks")
+		}
+		_, err = tasks.Create(readOnly, &taskpb.CreateTaskRequest{Description: GENERIC_TASK_NAME})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "read-only tokens cannot create Tasks")
+	})
+}
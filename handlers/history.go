@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+func TaskHistory(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	entries, err := database.Repo.History(c.UserContext(), uuid)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+	if len(entries) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(errorBody(entity.ErrTaskNotFound))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entries)
+}
+
+func RevertTask(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	version, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	reverted, err := task.Revert(c.UserContext(), database.Repo, uuid, version)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(reverted)
+}
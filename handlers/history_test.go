@@ -0,0 +1,90 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	API_PATH_HISTORY string = "/task/%s/history"
+	API_PATH_REVERT  string = "/task/%s/history/%d/revert"
+)
+
+func TestTaskHistory(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	task := entity.NewTask(GENERIC_TASK_NAME)
+	taskJSON, _ := json.Marshal(task)
+	req := httptest.NewRequest(http.MethodPost, "/task", bytes.NewBuffer(taskJSON))
+	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+	_, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+
+	task.Priority = entity.PriorityHigh
+	taskJSON, _ = json.Marshal(task)
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf(API_PATH_WITH_ID, task.ID), bytes.NewBuffer(taskJSON))
+	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+	_, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf(API_PATH_HISTORY, task.ID), nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var entries []entity.HistoryEntry
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+	assert.Len(t, entries, 2)
+	assert.Equal(t, entity.HistoryCreated, entries[0].Action)
+	assert.Equal(t, testUser.Username, entries[0].Actor)
+	assert.Equal(t, entity.HistoryUpdated, entries[1].Action)
+	assert.Equal(t, testUser.Username, entries[1].Actor)
+	assert.Contains(t, entries[1].Changes, "priority")
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf(API_PATH_HISTORY, uuid.New()), nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestRevertTask(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	task := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, task))
+	updated := *task
+	updated.Description = "Renamed task"
+	assert.NoError(t, database.Repo.Put(testCtx, &updated))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(API_PATH_REVERT, task.ID, 1), nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var reverted entity.Task
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&reverted))
+	assert.Equal(t, GENERIC_TASK_NAME, reverted.Description)
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf(API_PATH_REVERT, task.ID, 9), nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
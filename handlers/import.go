@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/formats"
+)
+
+// ImportTasks adds the Tasks of a file in the format of ?format=, sent either
+// as the body or as the "file" field of a multipart form. With ?dry_run=true
+// the report is the same but no Task is kept.
+func ImportTasks(c *fiber.Ctx) error {
+	format, err := formats.ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	var body io.Reader = bytes.NewReader(c.Body())
+	if header, err := c.FormFile("file"); err == nil {
+		file, err := header.Open()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+		}
+		defer file.Close()
+		body = file
+	}
+
+	entries, err := formats.Decode(body, format)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	report, err := formats.Import(c.UserContext(), database.Repo, entries, c.QueryBool("dry_run"))
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/formats"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportTasks(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+	user := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &user))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	todoTxt := "(A) Water the plants id:11111111-1111-1111-1111-111111111111\n" +
+		"x Feed the cat\n" +
+		"Broken id:nope\n"
+	raw := func(query string, body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/import"+query, strings.NewReader(body))
+	}
+
+	tests := []struct {
+		name         string
+		req          *http.Request
+		expectedCode int
+		expected     formats.Report
+		tasks        int
+	}{
+		{
+			name:         "Try the import of a todo.txt file",
+			req:          raw("?format=todotxt&dry_run=true", todoTxt),
+			expectedCode: fiber.StatusOK,
+			expected:     formats.Report{DryRun: true, Imported: 2, Duplicates: []int{}, Errors: []formats.LineError{{Line: 3, Message: `id: invalid UUID length: 4`}}},
+			tasks:        0,
+		},
+		{
+			name:         "Import a todo.txt file",
+			req:          raw("?format=todotxt", todoTxt),
+			expectedCode: fiber.StatusOK,
+			expected:     formats.Report{Imported: 2, Duplicates: []int{}, Errors: []formats.LineError{{Line: 3, Message: `id: invalid UUID length: 4`}}},
+			tasks:        2,
+		},
+		{
+			name:         "Skip the Tasks imported already",
+			req:          upload("/import?format=csv", "tasks.csv", []byte("id,description\n11111111-1111-1111-1111-111111111111,Water the plants\n,Walk the dog\n")),
+			expectedCode: fiber.StatusOK,
+			expected:     formats.Report{Imported: 1, Duplicates: []int{2}, Errors: []formats.LineError{}},
+			tasks:        3,
+		},
+		{
+			name:         "Reject an unknown format",
+			req:          raw("?format=xlsx", todoTxt),
+			expectedCode: fiber.StatusBadRequest,
+			tasks:        3,
+		},
+		{
+			name:         "Reject a format which is only exported",
+			req:          raw("?format=md", "# Tasks\n"),
+			expectedCode: fiber.StatusBadRequest,
+			tasks:        3,
+		},
+		{
+			name:         "Reject an unreadable file",
+			req:          raw("?format=json", `{"description": "Water the plants"}`),
+			expectedCode: fiber.StatusBadRequest,
+			tasks:        3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(authorized(tt.req), -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			if tt.expectedCode == fiber.StatusOK {
+				var report formats.Report
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+				assert.Equal(t, tt.expected, report)
+			}
+
+			tasks, _ := database.Repo.All(testCtx)
+			assert.Len(t, tasks, tt.tasks)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// ShareRequest invites a collaborator, by username, to a Task.
+type ShareRequest struct {
+	Username string      `json:"username"`
+	Role     entity.Role `json:"role"`
+}
+
+func ShareTask(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	request := new(ShareRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	user, err := member(c, request.Username)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Repo.Share(c.UserContext(), uuid, user.ID, request.Role); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return TaskShares(c)
+}
+
+func TaskShares(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	shares, err := database.Repo.Shares(c.UserContext(), uuid)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(shares)
+}
+
+func UnshareTask(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	user, err := member(c, c.Params("username"))
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Repo.Unshare(c.UserContext(), uuid, user.ID); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,106 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/auth"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareTask(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+
+	collaborator := entity.User{ID: uuid.New(), Username: "collaborator"}
+	assert.NoError(t, repo.CreateUser(testCtx, &collaborator))
+	owner := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &owner))
+	session, _, _ := auth.Default.Issue(collaborator)
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	shared := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, shared))
+	path := "/task/" + shared.ID.String()
+
+	share := func(request handlers.ShareRequest, asCollaborator bool) *http.Response {
+		data, _ := json.Marshal(request)
+		req := httptest.NewRequest(http.MethodPost, path+"/shares", bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		if asCollaborator {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+session)
+		} else {
+			authorized(req)
+		}
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		return resp
+	}
+
+	// visit sends a request as the collaborator.
+	visit := func(method string, target string, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+session)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		return resp
+	}
+
+	assert.Equal(t, fiber.StatusNotFound, visit(http.MethodGet, path+"/shares", nil).StatusCode,
+		"a Task which is not shared must not be found")
+
+	tests := []struct {
+		name           string
+		request        handlers.ShareRequest
+		asCollaborator bool
+		expectedCode   int
+	}{
+		{"Reject an unknown user", handlers.ShareRequest{Username: "nobody", Role: entity.RoleViewer}, false, fiber.StatusNotFound},
+		{"Reject an invalid role", handlers.ShareRequest{Username: "collaborator", Role: entity.RoleOwner}, false, fiber.StatusBadRequest},
+		{"Reject sharing with the owner", handlers.ShareRequest{Username: testUser.Username, Role: entity.RoleViewer}, false, fiber.StatusBadRequest},
+		{"Share with a viewer", handlers.ShareRequest{Username: "collaborator", Role: entity.RoleViewer}, false, fiber.StatusOK},
+		{"Reject sharing by a viewer", handlers.ShareRequest{Username: "collaborator", Role: entity.RoleEditor}, true, fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedCode, share(tt.request, tt.asCollaborator).StatusCode)
+		})
+	}
+
+	var tasks []entity.Task
+	resp := visit(http.MethodGet, "/", nil)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&tasks))
+	assert.Len(t, tasks, 1, "shared Tasks are listed")
+
+	update := *shared
+	update.Description = "Edited"
+	assert.Equal(t, fiber.StatusForbidden, visit(http.MethodPut, path, update).StatusCode)
+
+	assert.Equal(t, fiber.StatusOK, share(handlers.ShareRequest{Username: "collaborator", Role: entity.RoleEditor}, false).StatusCode)
+	assert.Equal(t, fiber.StatusCreated, visit(http.MethodPut, path, update).StatusCode)
+	assert.Equal(t, fiber.StatusForbidden, visit(http.MethodDelete, path, nil).StatusCode)
+
+	req := httptest.NewRequest(http.MethodDelete, path+"/shares/collaborator", nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp = visit(http.MethodGet, "/", nil)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&tasks))
+	assert.Empty(t, tasks, "revoked collaborators no longer see the Task")
+}
@@ -1,31 +1,91 @@
 package handlers
 
 import (
-	"context"
+	"errors"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
 	"github.com/omaciel/GoDoIt/entity"
 )
 
+// HeaderTotalCount is the number of Tasks listed by GET /, of which the
+// response may only hold a page.
+const HeaderTotalCount = "X-Total-Count"
+
 func AllTasks(c *fiber.Ctx) error {
-	var tasks []entity.Task
-	tasks, _ = database.Repo.All(context.Background())
+	repo := database.Repo
+
+	// ?as_of= lists the Tasks as they were at an RFC 3339 time.
+	if asOf := c.Query("as_of"); asOf != "" {
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+		}
+
+		historical, ok := repo.(task.HistoricalRepository)
+		if !ok {
+			return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{"message": "the repository does not keep past states"})
+		}
+		if repo, err = historical.AsOf(c.UserContext(), at); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+		}
+	}
+
+	filter, err := assigneeFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	// ?limit= and ?offset= page through the Tasks, which are all listed
+	// otherwise.
+	if c.Query("limit") != "" || c.Query("offset") != "" {
+		if filter.Limit, filter.Offset, err = pagination(c); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+		}
+	}
+
+	tasks, total, err := repo.Find(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+	if tasks == nil {
+		tasks = []entity.Task{}
+	}
 
+	c.Set(HeaderTotalCount, strconv.FormatInt(total, 10))
 	return c.Status(fiber.StatusOK).JSON(tasks)
 }
 
+// assigneeFilter reads ?assignee=me, for the Tasks assigned to the User, or
+// ?assignee=none, for the unassigned ones.
+func assigneeFilter(c *fiber.Ctx) (task.Filter, error) {
+	var filter task.Filter
+	switch c.Query("assignee") {
+	case "":
+	case "me":
+		filter.AssignedTo, _ = task.OwnerFromContext(c.UserContext())
+	case "none":
+		filter.Unassigned = true
+	default:
+		return filter, errors.New("assignee must be me or none")
+	}
+	return filter, nil
+}
+
 func PostTask(c *fiber.Ctx) error {
 	task := new(entity.Task)
 
 	if err := c.BodyParser(task); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
 	}
 
-	err := database.Repo.Post(context.Background(), task)
+	err := database.Repo.Post(c.UserContext(), task)
 	if err != nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": err})
+		return c.Status(fiber.StatusConflict).JSON(errorBody(err))
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(task)
@@ -34,12 +94,12 @@ func PostTask(c *fiber.Ctx) error {
 func GetTask(c *fiber.Ctx) error {
 	uuid, err := uuid.Parse(c.Params("uuid"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err})
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
 	}
 
-	task, err := database.Repo.Get(context.Background(), uuid)
+	task, err := database.Repo.Get(c.UserContext(), uuid)
 	if err != nil {
-		return c.Status(fiber.StatusNoContent).JSON(fiber.Map{"message": err})
+		return c.Status(fiber.StatusNoContent).JSON(errorBody(err))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(task)
@@ -50,19 +110,24 @@ func PutTask(c *fiber.Ctx) error {
 
 	uuid, err := uuid.Parse(c.Params("uuid"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err})
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
 	}
 
 	if err := c.BodyParser(task); err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": err})
+		return c.Status(fiber.StatusServiceUnavailable).JSON(errorBody(err))
 	}
 
-	if _, err = database.Repo.Get(context.Background(), uuid); err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": err})
+	existing, err := database.Repo.Get(c.UserContext(), uuid)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(errorBody(err))
 	}
+	task.CreatedAt = existing.CreatedAt
 
-	if err = database.Repo.Put(context.Background(), task); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err})
+	if err = database.Repo.Put(c.UserContext(), task); err != nil {
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorBody(err))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(task)
@@ -71,20 +136,37 @@ func PutTask(c *fiber.Ctx) error {
 func DeleteTask(c *fiber.Ctx) error {
 	uuid, err := uuid.Parse(c.Params("uuid"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err})
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
 	}
 
 	// Check that Task exists first.
-	_, err = database.Repo.Get(context.Background(), uuid)
+	_, err = database.Repo.Get(c.UserContext(), uuid)
 	if err != nil {
-		return c.Status(fiber.StatusNoContent).JSON(fiber.Map{"message": err})
+		return c.Status(fiber.StatusNoContent).JSON(errorBody(err))
 	}
 
 	// Delete the Task.
-	err = database.Repo.Delete(context.Background(), uuid)
+	err = database.Repo.Delete(c.UserContext(), uuid)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err})
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorBody(err))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
 	}
 
 	return c.SendStatus(fiber.StatusOK)
 }
+
+func SearchTasks(c *fiber.Ctx) error {
+	query, err := entity.ParseSearchQuery(c.Query("q"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	results, err := database.Repo.Search(c.UserContext(), query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(results)
+}
@@ -2,16 +2,18 @@ package handlers_test
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/eventsource"
 	"github.com/omaciel/GoDoIt/domain/memory"
 	"github.com/omaciel/GoDoIt/entity"
 	"github.com/omaciel/GoDoIt/router"
@@ -35,7 +37,7 @@ func TestGetTaskInvalidUUID(t *testing.T) {
 	router.SetupTaskRoutes(app)
 
 	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf(API_PATH_WITH_ID, taskUuid), nil)
-	resp, _ := app.Test(req, -1)
+	resp, _ := app.Test(authorized(req), -1)
 	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "Should return HTTP 400 code")
 }
 
@@ -44,7 +46,7 @@ func TestGetTaskContentDoesNotExist(t *testing.T) {
 	taskUuid := uuid.New()
 
 	defer func() {
-		err := database.Repo.Delete(context.Background(), taskUuid)
+		err := database.Repo.Delete(testCtx, taskUuid)
 		if err != nil {
 			return
 		}
@@ -54,7 +56,7 @@ func TestGetTaskContentDoesNotExist(t *testing.T) {
 	router.SetupTaskRoutes(app)
 
 	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf(API_PATH_WITH_ID, taskUuid), nil)
-	resp, _ := app.Test(req, -1)
+	resp, _ := app.Test(authorized(req), -1)
 	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode, "Should return HTTP 204 code")
 }
 
@@ -62,14 +64,14 @@ func TestGetTaskValidContent(t *testing.T) {
 	database.Repo = memory.NewMemoryRepository()
 
 	task := entity.NewTask(GENERIC_TASK_NAME)
-	err := database.Repo.Post(context.Background(), task)
+	err := database.Repo.Post(testCtx, task)
 	assert.NoError(t, err, NO_ERROR_EXPECTED)
 
 	app := fiber.New()
 	router.SetupTaskRoutes(app)
 
 	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/task/%s", task.ID), nil)
-	resp, _ := app.Test(req, -1)
+	resp, _ := app.Test(authorized(req), -1)
 	assert.Equal(t, fiber.StatusOK, resp.StatusCode, "Should return HTTP 200 code")
 	assert.Equal(t, task.Description, "Test Task", "Should return correct description")
 }
@@ -80,14 +82,14 @@ func TestListTasks(t *testing.T) {
 
 	task1 := entity.NewTask("Test Task 1").WithPriority(entity.PriorityHigh)
 	task2 := entity.NewTask("Test Task 2").WithPriority(entity.PriorityMedium)
-	err := database.Repo.Post(context.Background(), task1)
+	err := database.Repo.Post(testCtx, task1)
 	assert.NoError(t, err, NO_ERROR_EXPECTED)
-	err = database.Repo.Post(context.Background(), task2)
+	err = database.Repo.Post(testCtx, task2)
 	assert.NoError(t, err, NO_ERROR_EXPECTED)
 
 	defer func(uuids ...uuid.UUID) {
 		for _, id := range uuids {
-			err := database.Repo.Delete(context.Background(), id)
+			err := database.Repo.Delete(testCtx, id)
 			if err != nil {
 				return
 			}
@@ -98,7 +100,7 @@ func TestListTasks(t *testing.T) {
 	router.SetupTaskRoutes(app)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	resp, _ := app.Test(req, -1)
+	resp, _ := app.Test(authorized(req), -1)
 	assert.Equal(t, fiber.StatusOK, resp.StatusCode, "Should return HTTP 200 code")
 
 	var tasks []entity.Task
@@ -126,7 +128,7 @@ func TestPostTaskInvalidJSON(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/task", bytes.NewBuffer([]byte("invalid json")))
 	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
-	resp, err := app.Test(req, -1)
+	resp, err := app.Test(authorized(req), -1)
 	assert.NoError(t, err)
 
 	// Check the response status code and body
@@ -136,6 +138,7 @@ func TestPostTaskInvalidJSON(t *testing.T) {
 	err = json.NewDecoder(resp.Body).Decode(&errorMessage)
 	assert.NoError(t, err)
 	assert.Contains(t, errorMessage, "message")
+	assert.NotContains(t, errorMessage, "code", "only the errors of entity have a code")
 }
 
 func TestPostTaskInvalidTask(t *testing.T) {
@@ -143,7 +146,7 @@ func TestPostTaskInvalidTask(t *testing.T) {
 
 	// Add a new Task
 	task := entity.NewTask(GENERIC_TASK_NAME)
-	err := database.Repo.Post(context.Background(), task)
+	err := database.Repo.Post(testCtx, task)
 	assert.NoError(t, err, NO_ERROR_EXPECTED)
 
 	taskJSON, _ := json.Marshal(task)
@@ -154,11 +157,16 @@ func TestPostTaskInvalidTask(t *testing.T) {
 	// Try to create the same Task with same ID
 	req := httptest.NewRequest(http.MethodPost, "/task", bytes.NewBuffer(taskJSON))
 	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
-	resp, err := app.Test(req, -1)
+	resp, err := app.Test(authorized(req), -1)
 	assert.NoError(t, err)
 
 	// Check the response status code and body
 	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	var failure map[string]string
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&failure))
+	assert.Equal(t, "task_unique_constraint", failure["code"])
+	assert.Equal(t, entity.ErrTaskUniqueConstraint.Error(), failure["message"])
 }
 
 func TestPostTaskSuccess(t *testing.T) {
@@ -166,7 +174,7 @@ func TestPostTaskSuccess(t *testing.T) {
 
 	task := entity.NewTask("New Task").WithCompleted(false)
 	defer func() {
-		err := database.Repo.Delete(context.Background(), task.ID)
+		err := database.Repo.Delete(testCtx, task.ID)
 		if err != nil {
 			return
 		}
@@ -180,7 +188,7 @@ func TestPostTaskSuccess(t *testing.T) {
 	taskJSON, _ := json.Marshal(task)
 	req := httptest.NewRequest(http.MethodPost, "/task", bytes.NewBuffer(taskJSON))
 	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
-	resp, err := app.Test(req, -1)
+	resp, err := app.Test(authorized(req), -1)
 	assert.NoError(t, err)
 
 	// Check the response status code and body
@@ -198,12 +206,12 @@ func TestUpdateTask(t *testing.T) {
 	database.Repo = memory.NewMemoryRepository()
 
 	task := entity.NewTask(GENERIC_TASK_NAME).WithPriority(entity.PriorityMedium)
-	err := database.Repo.Post(context.Background(), task)
+	err := database.Repo.Post(testCtx, task)
 	assert.ErrorIs(t, err, nil, NO_ERROR_EXPECTED)
 	assert.Equal(t, task.Priority, entity.PriorityMedium, "expected Priority to be PriorityMedium")
 	assert.False(t, task.Completed, "expected Completed to be false")
 	defer func() {
-		err := database.Repo.Delete(context.Background(), task.ID)
+		err := database.Repo.Delete(testCtx, task.ID)
 		if err != nil {
 			return
 		}
@@ -219,7 +227,7 @@ func TestUpdateTask(t *testing.T) {
 	updatedTaskJSON, _ := json.Marshal(task)
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf(API_PATH_WITH_ID, task.ID), bytes.NewBuffer(updatedTaskJSON))
 	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
-	resp, err := app.Test(req, -1)
+	resp, err := app.Test(authorized(req), -1)
 	assert.NoError(t, err)
 
 	// Check the response status code and body
@@ -242,7 +250,7 @@ func TestDeleteTaskInvalidUUID(t *testing.T) {
 	router.SetupTaskRoutes(app)
 
 	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf(API_PATH_WITH_ID, taskUuid), nil)
-	resp, err := app.Test(req, -1)
+	resp, err := app.Test(authorized(req), -1)
 	assert.NoError(t, err)
 
 	// Check the response status code and body
@@ -257,7 +265,7 @@ func TestDeleteTaskContentDoesNotExist(t *testing.T) {
 	router.SetupTaskRoutes(app)
 
 	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf(API_PATH_WITH_ID, taskUuid), nil)
-	resp, err := app.Test(req, -1)
+	resp, err := app.Test(authorized(req), -1)
 	assert.NoError(t, err)
 
 	// Check the response status code and body
@@ -267,10 +275,10 @@ func TestDeleteTaskSuccess(t *testing.T) {
 	database.Repo = memory.NewMemoryRepository()
 
 	task := entity.NewTask(GENERIC_TASK_NAME)
-	err := database.Repo.Post(context.Background(), task)
+	err := database.Repo.Post(testCtx, task)
 	assert.ErrorIs(t, err, nil, NO_ERROR_EXPECTED)
 	defer func() {
-		err := database.Repo.Delete(context.Background(), task.ID)
+		err := database.Repo.Delete(testCtx, task.ID)
 		if err != nil {
 			return
 		}
@@ -280,9 +288,93 @@ func TestDeleteTaskSuccess(t *testing.T) {
 	router.SetupTaskRoutes(app)
 
 	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf(API_PATH_WITH_ID, task.ID), nil)
-	resp, err := app.Test(req, -1)
+	resp, err := app.Test(authorized(req), -1)
 	assert.NoError(t, err)
 
 	// Check the response status code and body
 	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
 }
+
+// Tests for SearchTasks method
+func TestSearchTasksMissingQuery(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=%20", nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSearchTasks(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	task1 := entity.NewTask("Write the weekly report")
+	task2 := entity.NewTask("Buy groceries")
+	assert.NoError(t, database.Repo.Post(testCtx, task1))
+	assert.NoError(t, database.Repo.Post(testCtx, task2))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=%22weekly+rep%22*", nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var results []entity.SearchResult
+	err = json.NewDecoder(resp.Body).Decode(&results)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, task1.ID, results[0].Task.ID)
+	assert.Equal(t, "Write the <mark>weekly</mark> <mark>report</mark>", results[0].Snippet)
+}
+
+func TestAllTasksAsOf(t *testing.T) {
+	repo, err := eventsource.NewEventSourcedRepository(eventsource.NewMemoryStore())
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	database.Repo = repo
+
+	task := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, repo.Post(testCtx, task))
+	asOf := time.Now()
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, repo.Delete(testCtx, task.ID))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedSize int
+	}{
+		{"List the current Tasks", "", fiber.StatusOK, 0},
+		{"List the Tasks as of a past time", "?as_of=" + url.QueryEscape(asOf.Format(time.RFC3339Nano)), fiber.StatusOK, 1},
+		{"Reject an invalid time", "?as_of=yesterday", fiber.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			resp, err := app.Test(authorized(req), -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			if resp.StatusCode == fiber.StatusOK {
+				var tasks []entity.Task
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&tasks))
+				assert.Len(t, tasks, tt.expectedSize)
+			}
+		})
+	}
+
+	database.Repo = memory.NewMemoryRepository()
+	req := httptest.NewRequest(http.MethodGet, "/?as_of="+url.QueryEscape(asOf.Format(time.RFC3339Nano)), nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusNotImplemented, resp.StatusCode)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// TokenUsageResolution is how precisely the last use of API tokens is known,
+// sparing a write on every request.
+const TokenUsageResolution = time.Minute
+
+// TokenRequest describes the API token to create. Tokens without an expiry
+// never expire.
+type TokenRequest struct {
+	Name      string            `json:"name"`
+	Scope     entity.TokenScope `json:"scope"`
+	ExpiresAt *time.Time        `json:"expires_at"`
+}
+
+// CreatedToken is a new API token, returned along with the token itself as
+// it cannot be recovered later.
+type CreatedToken struct {
+	entity.APIToken
+	Token string `json:"token"`
+}
+
+func CreateToken(c *fiber.Ctx) error {
+	request := new(TokenRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	owner, _ := task.OwnerFromContext(c.UserContext())
+	apiToken, secret, err := entity.NewAPIToken(owner, request.Name, request.Scope, request.ExpiresAt)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Users.CreateToken(c.UserContext(), apiToken); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(CreatedToken{APIToken: *apiToken, Token: secret})
+}
+
+func ListTokens(c *fiber.Ctx) error {
+	owner, _ := task.OwnerFromContext(c.UserContext())
+
+	tokens, err := database.Users.Tokens(c.UserContext(), owner)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func RevokeToken(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	owner, _ := task.OwnerFromContext(c.UserContext())
+	if err := database.Users.RevokeToken(c.UserContext(), owner, uuid); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
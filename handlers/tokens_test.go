@@ -0,0 +1,144 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+// withToken authenticates a request with an API token.
+func withToken(req *http.Request, token string) *http.Request {
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	return req
+}
+
+func createToken(t *testing.T, app *fiber.App, request handlers.TokenRequest) handlers.CreatedToken {
+	data, _ := json.Marshal(request)
+	req := httptest.NewRequest(http.MethodPost, "/auth/tokens", bytes.NewBuffer(data))
+	req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var created handlers.CreatedToken
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	return created
+}
+
+func TestAPITokens(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+	user := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &user))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	reader := createToken(t, app, handlers.TokenRequest{Name: "backup", Scope: entity.ScopeRead})
+	writer := createToken(t, app, handlers.TokenRequest{Name: "import", Scope: entity.ScopeWrite})
+	assert.True(t, entity.IsAPIToken(reader.Token))
+	assert.Equal(t, reader.Prefix, reader.Token[:len(reader.Prefix)])
+
+	tests := []struct {
+		name         string
+		method       string
+		token        string
+		expectedCode int
+	}{
+		{"Read with a read-only token", http.MethodGet, reader.Token, fiber.StatusOK},
+		{"Reject a write with a read-only token", http.MethodPost, reader.Token, fiber.StatusForbidden},
+		{"Write with a read-write token", http.MethodPost, writer.Token, fiber.StatusCreated},
+		{"Reject an unknown token", http.MethodGet, entity.APITokenPrefix + "unknown", fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(entity.NewTask(GENERIC_TASK_NAME))
+			path := "/"
+			if tt.method == http.MethodPost {
+				path = "/task"
+			}
+			req := httptest.NewRequest(tt.method, path, bytes.NewBuffer(data))
+			req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+			resp, err := app.Test(withToken(req, tt.token), -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+
+	tasks, _ := database.Repo.All(testCtx)
+	assert.Len(t, tasks, 1, "the Task created with the token belongs to its User")
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/tokens", nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+
+	var listed []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&listed))
+	if assert.Len(t, listed, 2) {
+		assert.NotContains(t, listed[0], "token", "tokens must not be listed")
+		assert.NotContains(t, listed[0], "hash")
+		assert.NotNil(t, listed[0]["last_used_at"])
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/auth/tokens", nil),
+		httptest.NewRequest(http.MethodPost, "/auth/tokens", bytes.NewBufferString(`{"name": "minted", "scope": "write"}`)),
+		httptest.NewRequest(http.MethodDelete, "/auth/tokens/"+reader.ID.String(), nil),
+	} {
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		resp, err = app.Test(withToken(req, writer.Token), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, "the API tokens cannot manage the API tokens")
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/auth/tokens/"+reader.ID.String(), nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	resp, err = app.Test(withToken(req, reader.Token), -1)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, "a revoked token must be rejected")
+}
+
+func TestCreateTokenValidation(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name    string
+		request handlers.TokenRequest
+	}{
+		{"Reject a token without a name", handlers.TokenRequest{Scope: entity.ScopeRead}},
+		{"Reject an unknown scope", handlers.TokenRequest{Name: "admin", Scope: "admin"}},
+		{"Reject an expiry in the past", handlers.TokenRequest{Name: "old", Scope: entity.ScopeRead, ExpiresAt: &past}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(tt.request)
+			req := httptest.NewRequest(http.MethodPost, "/auth/tokens", bytes.NewBuffer(data))
+			req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+			resp, err := app.Test(authorized(req), -1)
+			assert.NoError(t, err, NO_ERROR_EXPECTED)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/attachment"
+)
+
+func TrashedTasks(c *fiber.Ctx) error {
+	tasks, err := database.Repo.Trash(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tasks)
+}
+
+func RestoreTask(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	if err = database.Repo.Restore(c.UserContext(), uuid); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	task, err := database.Repo.Get(c.UserContext(), uuid)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(task)
+}
+
+func PurgeTask(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	if err = database.Repo.Purge(c.UserContext(), uuid); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	// The Task is purged even if the contents of its attachments are not
+	// deleted yet, which the trash job tries again.
+	if _, err := attachment.DeletePurged(c.UserContext(), database.Attachments, blob.Default); err != nil {
+		log.Println("Failed to delete the contents of the purged attachments. \n", err)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,122 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	API_PATH_TRASH         string = "/trash"
+	API_PATH_TRASH_WITH_ID string = "/trash/%s"
+	API_PATH_RESTORE       string = "/trash/%s/restore"
+)
+
+func TestDeleteTaskMovesToTrash(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	task := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, task))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf(API_PATH_WITH_ID, task.ID), nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, API_PATH_TRASH, nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var trash []entity.Task
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&trash))
+	assert.Len(t, trash, 1)
+	assert.Equal(t, task.ID, trash[0].ID)
+	assert.True(t, trash[0].IsDeleted())
+}
+
+func TestRestoreTask(t *testing.T) {
+	database.Repo = memory.NewMemoryRepository()
+
+	task := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, task))
+	assert.NoError(t, database.Repo.Delete(testCtx, task.ID))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(API_PATH_RESTORE, task.ID), nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var restored entity.Task
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&restored))
+	assert.Equal(t, task.ID, restored.ID)
+	assert.False(t, restored.IsDeleted())
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf(API_PATH_RESTORE, task.ID), nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode, "only tasks in the trash can be restored")
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf(API_PATH_RESTORE, "aaa"), nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestPurgeTask(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Attachments = repo, repo
+	store, err := blob.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	blob.Default = store
+
+	task := entity.NewTask(GENERIC_TASK_NAME)
+	assert.NoError(t, database.Repo.Post(testCtx, task))
+	attached := &entity.Attachment{TaskID: task.ID, Filename: "notes.txt", Size: 5}
+	assert.NoError(t, repo.AddAttachment(testCtx, attached))
+	assert.NoError(t, store.Put(testCtx, attached.Key(), strings.NewReader("notes"), 5, "text/plain"))
+
+	app := fiber.New()
+	router.SetupTaskRoutes(app)
+
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf(API_PATH_TRASH_WITH_ID, task.ID), nil)
+	resp, err := app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode, "only tasks in the trash can be purged")
+
+	assert.NoError(t, database.Repo.Delete(testCtx, task.ID))
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf(API_PATH_TRASH_WITH_ID, task.ID), nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	trash, _ := database.Repo.Trash(testCtx)
+	assert.Len(t, trash, 0)
+	_, err = store.Get(testCtx, attached.Key())
+	assert.ErrorIs(t, err, blob.ErrNotFound, "the contents of the attachments are deleted too")
+	purged, _ := repo.PurgedAttachments(testCtx)
+	assert.Empty(t, purged)
+
+	req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf(API_PATH_TRASH_WITH_ID, uuid.New()), nil)
+	resp, err = app.Test(authorized(req), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
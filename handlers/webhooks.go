@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// WebhookRequest describes the webhook to create. Webhooks without events
+// receive every change, and a secret is generated unless one is given.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// CreatedWebhook is a new webhook, returned along with its secret as it is
+// not shown later.
+type CreatedWebhook struct {
+	entity.Webhook
+	Secret string `json:"secret"`
+}
+
+func CreateWebhook(c *fiber.Ctx) error {
+	request := new(WebhookRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	owner, _ := task.OwnerFromContext(c.UserContext())
+	webhook, err := entity.NewWebhook(owner, request.URL, request.Events, request.Secret)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	if err := database.Webhooks.CreateWebhook(c.UserContext(), webhook); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(CreatedWebhook{Webhook: *webhook, Secret: webhook.Secret})
+}
+
+func ListWebhooks(c *fiber.Ctx) error {
+	owner, _ := task.OwnerFromContext(c.UserContext())
+
+	webhooks, err := database.Webhooks.Webhooks(c.UserContext(), owner)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(webhooks)
+}
+
+func DeleteWebhook(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	owner, _ := task.OwnerFromContext(c.UserContext())
+	if err := database.Webhooks.DeleteWebhook(c.UserContext(), owner, uuid); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// deliveryIDs reads the IDs of the webhook and of the delivery from the path.
+func deliveryIDs(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	webhookID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	id, err := uuid.Parse(c.Params("delivery"))
+	return webhookID, id, err
+}
+
+// WebhookDeliveries is the delivery log of a webhook, the latest first.
+func WebhookDeliveries(c *fiber.Ctx) error {
+	uuid, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	owner, _ := task.OwnerFromContext(c.UserContext())
+	webhook, err := database.Webhooks.GetWebhook(c.UserContext(), owner, uuid)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	deliveries, err := database.Webhooks.Deliveries(c.UserContext(), webhook.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(deliveries)
+}
+
+// DeadDeliveries is the dead-letter list of the User: the deliveries to any
+// of their webhooks which gave up.
+func DeadDeliveries(c *fiber.Ctx) error {
+	owner, _ := task.OwnerFromContext(c.UserContext())
+
+	deliveries, err := database.Webhooks.DeadDeliveries(c.UserContext(), owner)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(deliveries)
+}
+
+// Redeliver takes a delivery out of the dead-letter list, to be attempted
+// again as if it were new.
+func Redeliver(c *fiber.Ctx) error {
+	webhookID, id, err := deliveryIDs(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+
+	owner, _ := task.OwnerFromContext(c.UserContext())
+	webhook, err := database.Webhooks.GetWebhook(c.UserContext(), owner, webhookID)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	delivery, err := database.Webhooks.GetDelivery(c.UserContext(), webhook.ID, id)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	if delivery.Status != entity.DeliveryDead {
+		return c.Status(statusFor(entity.ErrDeliveryNotDead)).JSON(errorBody(entity.ErrDeliveryNotDead))
+	}
+
+	now := time.Now()
+	delivery.Status, delivery.Attempts, delivery.NextAttemptAt = entity.DeliveryPending, 0, &now
+	if err := database.Webhooks.SaveDelivery(c.UserContext(), &delivery); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
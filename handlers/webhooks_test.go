@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhooks(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users, database.Webhooks = repo, repo, repo
+
+	owner := testUser
+	assert.NoError(t, repo.CreateUser(testCtx, &owner))
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	send := func(method string, target string, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		resp, err := app.Test(authorized(req), -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/webhooks", handlers.WebhookRequest{URL: "https://ci.example.com/hooks", Events: []string{"created"}})
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var created handlers.CreatedWebhook
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.Contains(t, created.Secret, entity.WebhookSecretPrefix, "the secret is shown once")
+
+	resp = send(http.MethodGet, "/webhooks", nil)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var listed []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&listed))
+	if assert.Len(t, listed, 1) {
+		assert.NotContains(t, listed[0], "secret")
+	}
+
+	hook, err := repo.GetWebhook(testCtx, testUser.ID, created.ID)
+	assert.NoError(t, err)
+	dead := entity.NewWebhookDelivery(hook, "created", `{"event":"created"}`)
+	dead.Status, dead.Attempts, dead.NextAttemptAt = entity.DeliveryDead, 8, nil
+	assert.NoError(t, repo.SaveDelivery(testCtx, dead))
+
+	path := "/webhooks/" + created.ID.String()
+	redeliver := path + "/deliveries/" + dead.ID.String() + "/redeliver"
+
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         interface{}
+		expectedCode int
+	}{
+		{"Reject an invalid URL", http.MethodPost, "/webhooks", handlers.WebhookRequest{URL: "ftp://ci.example.com"}, fiber.StatusBadRequest},
+		{"Reject an unknown event", http.MethodPost, "/webhooks", handlers.WebhookRequest{URL: "https://ci.example.com", Events: []string{"archived"}}, fiber.StatusBadRequest},
+		{"List the deliveries", http.MethodGet, path + "/deliveries", nil, fiber.StatusOK},
+		{"List the dead letters", http.MethodGet, "/webhooks/dead-letters", nil, fiber.StatusOK},
+		{"Reject an invalid delivery ID", http.MethodPost, path + "/deliveries/invalid/redeliver", nil, fiber.StatusBadRequest},
+		{"Redeliver an unknown delivery", http.MethodPost, path + "/deliveries/" + uuid.NewString() + "/redeliver", nil, fiber.StatusNotFound},
+		{"Redeliver a dead delivery", http.MethodPost, redeliver, nil, fiber.StatusAccepted},
+		{"Redeliver a pending delivery", http.MethodPost, redeliver, nil, fiber.StatusConflict},
+		{"Delete the webhook", http.MethodDelete, path, nil, fiber.StatusNoContent},
+		{"Delete a deleted webhook", http.MethodDelete, path, nil, fiber.StatusNotFound},
+		{"List the deliveries of a deleted webhook", http.MethodGet, path + "/deliveries", nil, fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := send(tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+
+	letters, err := repo.DeadDeliveries(testCtx, testUser.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, letters, "redelivered deliveries leave the dead letters")
+}
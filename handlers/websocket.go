@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// webSocketVersion is the version of the WebSocket protocol, which the
+// clients failing the handshake are told to use.
+const webSocketVersion = "13"
+
+// WebSocketWriteTimeout is how long the writes to the WebSockets may take
+// before the client is given up on.
+var WebSocketWriteTimeout = 10 * time.Second
+
+// upgrade upgrades the request to a WebSocket with the handler of
+// websocket.New, telling the clients which fail the handshake which version
+// of the protocol to use.
+func upgrade(c *fiber.Ctx, handler fiber.Handler) error {
+	if err := handler(c); err != nil {
+		c.Set(fiber.HeaderSecWebSocketVersion, webSocketVersion)
+		return err
+	}
+	return nil
+}
+
+// socket serializes the writes to a WebSocket, which only allows one writer
+// at a time.
+type socket struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// writeJSON writes a text message holding the JSON of value.
+func (s *socket) writeJSON(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.conn.SetWriteDeadline(time.Now().Add(WebSocketWriteTimeout))
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// close writes a close message with a status code, after which nothing else
+// may be written.
+func (s *socket) close(code int, reason string) error {
+	message := websocket.FormatCloseMessage(code, reason)
+	return s.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(WebSocketWriteTimeout))
+}
+
+// keepAlive pings the client every EventsHeartbeat until stopped, and has the
+// reads fail when the client has not answered for two heartbeats. It must be
+// called before reading, and stopped before the handler of the WebSocket
+// returns.
+func (s *socket) keepAlive() (stop func()) {
+	interval := EventsHeartbeat
+	extend := func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(2 * interval))
+	}
+	_ = extend("")
+	s.conn.SetPongHandler(extend)
+
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		heartbeat := time.NewTicker(interval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-heartbeat.C:
+				if s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WebSocketWriteTimeout)) != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
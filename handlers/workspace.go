@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// MemberRequest adds a User to a workspace. The Role defaults to member.
+type MemberRequest struct {
+	Credentials
+	Role entity.WorkspaceRole `json:"role"`
+}
+
+// member returns the User with the given username if it belongs to the
+// workspace of the request. Users of other workspaces are not found.
+func member(c *fiber.Ctx, username string) (entity.User, error) {
+	user, err := database.Users.UserByName(c.UserContext(), username)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if workspace, _ := task.WorkspaceFromContext(c.UserContext()); user.WorkspaceID != workspace {
+		return entity.User{}, entity.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// RequireAdmin rejects the requests of the Users who are not admins of their
+// workspace. It must be used after Authenticate.
+func RequireAdmin(c *fiber.Ctx) error {
+	owner, _ := task.OwnerFromContext(c.UserContext())
+	user, err := database.Users.GetUser(c.UserContext(), owner)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	if user.WorkspaceRole != entity.WorkspaceAdmin {
+		return c.Status(fiber.StatusForbidden).JSON(errorBody(entity.ErrAdminRequired))
+	}
+	return c.Next()
+}
+
+// AddMember creates a User in the workspace of the request, which is how
+// teams are built: registering always creates a new workspace.
+func AddMember(c *fiber.Ctx) error {
+	request := new(MemberRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorBody(err))
+	}
+	if request.Role == "" {
+		request.Role = entity.WorkspaceMember
+	}
+	if err := request.Role.Validate(); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	user, err := entity.NewUser(request.Username, request.Password)
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	user.WorkspaceID, _ = task.WorkspaceFromContext(c.UserContext())
+	user.WorkspaceRole = request.Role
+
+	if err := database.Users.CreateUser(c.UserContext(), user); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(user)
+}
+
+// RemoveMember deletes a User of the workspace of the request, along with its
+// API tokens. Its Tasks are kept.
+func RemoveMember(c *fiber.Ctx) error {
+	user, err := member(c, c.Params("username"))
+	if err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+	if owner, _ := task.OwnerFromContext(c.UserContext()); user.ID == owner {
+		return c.Status(statusFor(entity.ErrCannotRemoveSelf)).JSON(errorBody(entity.ErrCannotRemoveSelf))
+	}
+
+	if err := database.Users.RemoveUser(c.UserContext(), user.ID); err != nil {
+		return c.Status(statusFor(err)).JSON(errorBody(err))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
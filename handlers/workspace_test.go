@@ -0,0 +1,120 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/handlers"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceIsolation(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = repo, repo
+
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	// login registers a User in a new workspace and returns its session.
+	login := func(username string) handlers.Session {
+		credentials := handlers.Credentials{Username: username, Password: "correct horse"}
+		resp, err := postJSON(app, "/auth/register", credentials)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+		resp, err = postJSON(app, "/auth/login", credentials)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+
+		var session handlers.Session
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&session))
+		return session
+	}
+	send := func(session handlers.Session, method, path string, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(data))
+		req.Header.Set(HEADER_CONTENT_TYPE, HEADER_APPLICATION_FORMAT)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+session.Token)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err, NO_ERROR_EXPECTED)
+		return resp
+	}
+
+	acme, globex := login("acme"), login("globex")
+	assert.NotEqual(t, acme.User.WorkspaceID, globex.User.WorkspaceID)
+
+	resp := send(acme, http.MethodPost, "/task", entity.NewTask("Acme roadmap"))
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var roadmap entity.Task
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&roadmap))
+	assert.Equal(t, acme.User.WorkspaceID, roadmap.WorkspaceID)
+	path := "/task/" + roadmap.ID.String()
+
+	var tasks []entity.Task
+	assert.NoError(t, json.NewDecoder(send(globex, http.MethodGet, "/", nil).Body).Decode(&tasks))
+	assert.Empty(t, tasks, "Tasks must not leak to another workspace")
+
+	var results []entity.SearchResult
+	assert.NoError(t, json.NewDecoder(send(globex, http.MethodGet, "/search?q=roadmap", nil).Body).Decode(&results))
+	assert.Empty(t, results)
+
+	assert.Equal(t, fiber.StatusNotFound, send(globex, http.MethodGet, path+"/history", nil).StatusCode)
+	assert.Equal(t, fiber.StatusNotFound, send(acme, http.MethodPost, path+"/shares",
+		handlers.ShareRequest{Username: "globex", Role: entity.RoleViewer}).StatusCode,
+		"Users of another workspace must not be found")
+
+	// Members added by a User join its workspace.
+	member := handlers.Credentials{Username: "wile", Password: "correct horse"}
+	resp = send(acme, http.MethodPost, "/workspace/members", member)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var wile entity.User
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&wile))
+	assert.Equal(t, acme.User.WorkspaceID, wile.WorkspaceID)
+
+	assert.Equal(t, fiber.StatusOK, send(acme, http.MethodPost, path+"/shares",
+		handlers.ShareRequest{Username: "wile", Role: entity.RoleViewer}).StatusCode)
+	assert.Equal(t, entity.WorkspaceAdmin, acme.User.WorkspaceRole)
+	assert.Equal(t, entity.WorkspaceMember, wile.WorkspaceRole)
+
+	// Only the admins manage the members, and only with a session.
+	resp, err := postJSON(app, "/auth/login", member)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	var wileSession handlers.Session
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&wileSession))
+	roadrunner := handlers.MemberRequest{Credentials: handlers.Credentials{Username: "roadrunner", Password: "correct horse"}}
+	assert.Equal(t, fiber.StatusForbidden, send(wileSession, http.MethodPost, "/workspace/members", roadrunner).StatusCode)
+	assert.Equal(t, fiber.StatusForbidden, send(wileSession, http.MethodDelete, "/workspace/members/acme", nil).StatusCode)
+
+	resp = send(acme, http.MethodPost, "/auth/tokens", handlers.TokenRequest{Name: "ci", Scope: entity.ScopeWrite})
+	var token handlers.CreatedToken
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+	apiToken := handlers.Session{Token: token.Token}
+	assert.Equal(t, fiber.StatusForbidden, send(apiToken, http.MethodPost, "/workspace/members", roadrunner).StatusCode)
+	assert.Equal(t, fiber.StatusForbidden, send(apiToken, http.MethodDelete, "/workspace/members/wile", nil).StatusCode)
+
+	roadrunner.Role = "owner"
+	assert.Equal(t, fiber.StatusBadRequest, send(acme, http.MethodPost, "/workspace/members", roadrunner).StatusCode)
+	roadrunner.Role = entity.WorkspaceAdmin
+	resp = send(acme, http.MethodPost, "/workspace/members", roadrunner)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var admin entity.User
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&admin))
+	assert.Equal(t, entity.WorkspaceAdmin, admin.WorkspaceRole)
+
+	assert.Equal(t, fiber.StatusBadRequest, send(acme, http.MethodDelete, "/workspace/members/acme", nil).StatusCode,
+		"Admins cannot remove themselves")
+	assert.Equal(t, fiber.StatusNotFound, send(acme, http.MethodDelete, "/workspace/members/globex", nil).StatusCode,
+		"Users of another workspace must not be found")
+	assert.Equal(t, fiber.StatusOK, send(acme, http.MethodDelete, "/workspace/members/wile", nil).StatusCode)
+	assert.Equal(t, fiber.StatusNotFound, send(acme, http.MethodDelete, "/workspace/members/wile", nil).StatusCode)
+	resp, err = postJSON(app, "/auth/login", member)
+	assert.NoError(t, err, NO_ERROR_EXPECTED)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, "Removed members cannot log in")
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/domain/attachment"
+	"github.com/omaciel/GoDoIt/domain/task"
+)
+
+const (
+	// DefaultTrashRetention is how long deleted Tasks stay in the trash.
+	DefaultTrashRetention = 30 * 24 * time.Hour
+
+	// DefaultTrashPurgeInterval is how often the trash is emptied of expired
+	// Tasks.
+	DefaultTrashPurgeInterval = time.Hour
+)
+
+// DurationFromEnv reads a duration such as "720h" from the environment,
+// returning fallback when the variable is unset or invalid.
+func DurationFromEnv(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration %q for %s, using %s.", value, key, fallback)
+		return fallback
+	}
+	return d
+}
+
+// PurgeTrash permanently deletes the Tasks which have been in the trash for
+// longer than retention, every interval, until ctx is done, along with the
+// contents of their attachments in the store. A retention of zero keeps
+// deleted Tasks forever, and an interval which is not positive is replaced by
+// DefaultTrashPurgeInterval.
+func PurgeTrash(ctx context.Context, repo task.TaskRepository, attachments attachment.AttachmentRepository, store blob.Store, retention, interval time.Duration) {
+	if retention <= 0 {
+		log.Println("Trash retention is disabled, deleted tasks are kept forever.")
+		return
+	}
+	if interval <= 0 {
+		log.Printf("Invalid trash purge interval %s, using %s.", interval, DefaultTrashPurgeInterval)
+		interval = DefaultTrashPurgeInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := repo.PurgeDeletedBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Println("Failed to purge the trash.", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d task(s) from the trash.", purged)
+		}
+		if deleted, err := attachment.DeletePurged(ctx, attachments, store); err != nil {
+			log.Println("Failed to delete the contents of the purged attachments.", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted the contents of %d purged attachment(s).", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package jobs_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omaciel/GoDoIt/blob"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"Valid duration", "2h", 2 * time.Hour},
+		{"Invalid duration", "two hours", time.Minute},
+		{"Disabled", "0", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GODOIT_TEST_DURATION", tt.value)
+			assert.Equal(t, tt.expected, jobs.DurationFromEnv("GODOIT_TEST_DURATION", time.Minute))
+		})
+	}
+
+	assert.Equal(t, time.Minute, jobs.DurationFromEnv("GODOIT_TEST_UNSET_DURATION", time.Minute))
+}
+
+func TestPurgeTrash(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	store, err := blob.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	task := entity.NewTask("task 0")
+	assert.NoError(t, repo.Post(context.Background(), task))
+	attached := &entity.Attachment{TaskID: task.ID, Filename: "notes.txt", Size: 5}
+	assert.NoError(t, repo.AddAttachment(context.Background(), attached))
+	assert.NoError(t, store.Put(context.Background(), attached.Key(), strings.NewReader("notes"), 5, "text/plain"))
+	assert.NoError(t, repo.Delete(context.Background(), task.ID))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		jobs.PurgeTrash(ctx, repo, repo, store, time.Nanosecond, time.Millisecond)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		trash, _ := repo.Trash(context.Background())
+		purged, _ := repo.PurgedAttachments(context.Background())
+		return len(trash) == 0 && len(purged) == 0
+	}, time.Second, time.Millisecond)
+	_, err = store.Get(context.Background(), attached.Key())
+	assert.ErrorIs(t, err, blob.ErrNotFound, "the contents of the attachments are deleted too")
+
+	cancel()
+	<-done
+}
+
+func TestPurgeTrashInvalidInterval(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		assert.NotPanics(t, func() { jobs.PurgeTrash(ctx, repo, repo, nil, time.Hour, interval) })
+	}
+}
@@ -5,15 +5,90 @@ import (
 	"github.com/omaciel/GoDoIt/handlers"
 )
 
+func SetupAuthRoutes(app *fiber.App) {
+	app.Post("/auth/register", handlers.Register)
+	app.Post("/auth/login", handlers.Login)
+}
+
+// SetupCalDAVRoutes serves the Tasks to calendar clients, which authenticate
+// on their own, so it must be called before SetupTaskRoutes. Only apps
+// configured with handlers.RequestMethods accept the WebDAV methods.
+func SetupCalDAVRoutes(app *fiber.App) {
+	app.Use("/.well-known/caldav", handlers.WellKnownCalDAV)
+	app.Use(handlers.CalDAVPrefix, handlers.AuthenticateCalDAV, handlers.CalDAV)
+}
+
+// SetupTaskRoutes registers the routes which need an authenticated User, so it
+// must be called after SetupAuthRoutes.
 func SetupTaskRoutes(app *fiber.App) {
+	app.Use(handlers.Authenticate)
+
+	app.Get("/auth/me", handlers.CurrentUser)
+	app.Get("/auth/tokens", handlers.RequireSession, handlers.ListTokens)
+	app.Post("/auth/tokens", handlers.RequireSession, handlers.CreateToken)
+	app.Delete("/auth/tokens/:uuid", handlers.RequireSession, handlers.RevokeToken)
+	app.Post("/workspace/members", handlers.RequireSession, handlers.RequireAdmin, handlers.AddMember)
+	app.Delete("/workspace/members/:username", handlers.RequireSession, handlers.RequireAdmin, handlers.RemoveMember)
+
+	app.Get("/webhooks", handlers.ListWebhooks)
+	app.Post("/webhooks", handlers.CreateWebhook)
+	app.Get("/webhooks/dead-letters", handlers.DeadDeliveries)
+	app.Delete("/webhooks/:uuid", handlers.DeleteWebhook)
+	app.Get("/webhooks/:uuid/deliveries", handlers.WebhookDeliveries)
+	app.Post("/webhooks/:uuid/deliveries/:delivery/redeliver", handlers.Redeliver)
+
 	app.Get("/", handlers.AllTasks)
+	app.Get("/search", handlers.SearchTasks)
+	app.Get("/export", handlers.ExportTasks)
+	app.Post("/import", handlers.ImportTasks)
+	app.Get("/events", handlers.Events)
+	app.Get("/graphql", handlers.GraphQL)
+	app.Post("/graphql", handlers.GraphQL)
 
 	app.Post("/task", handlers.PostTask)
 	app.Get("/task/:uuid", handlers.GetTask)
 	app.Put("/task/:uuid", handlers.PutTask)
 	app.Delete("/task/:uuid", handlers.DeleteTask)
+	app.Get("/task/:uuid/history", handlers.TaskHistory)
+	app.Post("/task/:uuid/history/:version/revert", handlers.RevertTask)
+	app.Get("/task/:uuid/shares", handlers.TaskShares)
+	app.Post("/task/:uuid/shares", handlers.ShareTask)
+	app.Delete("/task/:uuid/shares/:username", handlers.UnshareTask)
+	app.Put("/task/:uuid/assignee", handlers.AssignTask)
+	app.Delete("/task/:uuid/assignee", handlers.UnassignTask)
+	app.Get("/task/:uuid/comments", handlers.ListComments)
+	app.Post("/task/:uuid/comments", handlers.AddComment)
+	app.Get("/task/:uuid/comments/:comment", handlers.GetComment)
+	app.Put("/task/:uuid/comments/:comment", handlers.EditComment)
+	app.Delete("/task/:uuid/comments/:comment", handlers.DeleteComment)
+	app.Get("/task/:uuid/comments/:comment/edits", handlers.CommentEdits)
+	app.Post("/task/:uuid/checklist", handlers.AddChecklistItem)
+	app.Put("/task/:uuid/checklist", handlers.ReorderChecklist)
+	app.Put("/task/:uuid/checklist/:item", handlers.EditChecklistItem)
+	app.Post("/task/:uuid/checklist/:item/toggle", handlers.ToggleChecklistItem)
+	app.Delete("/task/:uuid/checklist/:item", handlers.DeleteChecklistItem)
+	app.Get("/task/:uuid/attachments", handlers.ListAttachments)
+	app.Post("/task/:uuid/attachments", handlers.UploadAttachment)
+	app.Get("/task/:uuid/attachments/:attachment", handlers.DownloadAttachment)
+	app.Delete("/task/:uuid/attachments/:attachment", handlers.DeleteAttachment)
+
+	// The colon is escaped so that Fiber does not treat it as a parameter.
+	app.Post("/tasks\\:batch", handlers.BatchTasks)
+
+	app.Get("/trash", handlers.TrashedTasks)
+	app.Post("/trash/:uuid/restore", handlers.RestoreTask)
+	app.Delete("/trash/:uuid", handlers.PurgeTask)
+
+	app.Get("/archive", handlers.ArchivedTasks)
+	app.Post("/archive", handlers.ArchiveCompletedTasks)
 }
 
+// SetupRoutes registers every route. Apps configured with handlers.BodyLimit
+// accept the uploads of Attachments up to that size, and every other request
+// up to fiber.DefaultBodyLimit.
 func SetupRoutes(app *fiber.App) {
+	app.Use(handlers.LimitBody)
+	SetupAuthRoutes(app)
+	SetupCalDAVRoutes(app)
 	SetupTaskRoutes(app)
 }
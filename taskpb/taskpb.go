@@ -0,0 +1,6 @@
+// Package taskpb holds the messages and the gRPC stubs of the TaskService
+// defined in tasks.proto. They are generated by protoc-gen-go and
+// protoc-gen-go-grpc.
+package taskpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative tasks.proto
@@ -0,0 +1,62 @@
+package taskpb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/omaciel/GoDoIt/taskpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMessages(t *testing.T) {
+	due := timestamppb.New(time.Date(2024, 4, 1, 9, 30, 0, 500, time.UTC))
+	description, priority, completed := "", taskpb.Priority_PRIORITY_UNSPECIFIED, false
+
+	tests := []struct {
+		name    string
+		message proto.Message
+		decoded proto.Message
+	}{
+		{
+			"Task",
+			&taskpb.Task{Id: "1", Description: "Write", Priority: taskpb.Priority_PRIORITY_HIGH, Completed: true, DueAt: due},
+			new(taskpb.Task),
+		},
+		{
+			"Optional fields set to their default",
+			&taskpb.UpdateTaskRequest{Id: "1", Description: &description, Priority: &priority, Completed: &completed},
+			new(taskpb.UpdateTaskRequest),
+		},
+		{
+			"Optional fields left out",
+			&taskpb.UpdateTaskRequest{Id: "1", ClearDueAt: true},
+			new(taskpb.UpdateTaskRequest),
+		},
+		{
+			"Negative integers",
+			&taskpb.ListTasksRequest{Limit: -1, Offset: 2},
+			new(taskpb.ListTasksRequest),
+		},
+		{
+			"Embedded messages",
+			&taskpb.TaskChange{Id: 42, Type: taskpb.ChangeType_CHANGE_TYPE_UPDATED, Task: &taskpb.Task{Id: "1"}},
+			new(taskpb.TaskChange),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := proto.Marshal(tt.message)
+			assert.NoError(t, err)
+			assert.NoError(t, proto.Unmarshal(encoded, tt.decoded))
+			assert.True(t, proto.Equal(tt.message, tt.decoded))
+		})
+	}
+
+	// The timestamps are google.protobuf.Timestamp messages.
+	encoded, err := proto.Marshal(&taskpb.Task{DueAt: timestamppb.New(time.Unix(1, 2))})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x42, 4, 0x08, 1, 0x10, 2}, encoded)
+}
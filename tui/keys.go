@@ -0,0 +1,78 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyType tells which key was pressed.
+type KeyType int
+
+const (
+	// KeyRune is a printable character, held by the Rune of the Key.
+	KeyRune KeyType = iota
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyCtrlC
+)
+
+// Key is a key pressed on the terminal.
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// arrows are the last bytes of the escape sequences of the arrow keys.
+var arrows = map[byte]KeyType{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+}
+
+// ParseKeys returns the keys typed on a terminal in raw mode. The unknown
+// escape sequences and control characters are skipped.
+func ParseKeys(data []byte) []Key {
+	var keys []Key
+	for len(data) > 0 {
+		switch b := data[0]; {
+		case b == 0x1b && len(data) >= 3 && (data[1] == '[' || data[1] == 'O'):
+			// An escape sequence ends with its first letter.
+			end := 2
+			for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+				end++
+			}
+			if end < len(data) {
+				if key, ok := arrows[data[end]]; ok && end == 2 {
+					keys = append(keys, Key{Type: key})
+				}
+				end++
+			}
+			data = data[end:]
+			continue
+		case b == 0x1b:
+			keys = append(keys, Key{Type: KeyEscape})
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Type: KeyEnter})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Type: KeyBackspace})
+		case b == '\t':
+			keys = append(keys, Key{Type: KeyTab})
+		case b == 0x03:
+			keys = append(keys, Key{Type: KeyCtrlC})
+		case b < 0x20:
+		default:
+			r, size := utf8.DecodeRune(data)
+			if r != utf8.RuneError {
+				keys = append(keys, Key{Type: KeyRune, Rune: r})
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
@@ -0,0 +1,419 @@
+// Package tui browses and edits the Tasks of a Store full screen on a
+// terminal, in the style of the Elm architecture: the Model is updated by
+// messages, such as the keys pressed or the Tasks loaded, and asks for the
+// slow work to be done with commands, whose results are messages too.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// Msg is something which happened, for the Model to handle.
+type Msg interface{}
+
+// Cmd does the slow work asked for by the Model, such as calling the Store,
+// and returns its result as a Msg.
+type Cmd func() Msg
+
+// ResizeMsg is sent when the terminal is resized.
+type ResizeMsg struct {
+	Width, Height int
+}
+
+// ChangedMsg is sent when the Tasks of the Store may have changed.
+type ChangedMsg struct{}
+
+// loadedMsg holds the Tasks listed by the Store.
+type loadedMsg struct {
+	tasks []entity.Task
+	err   error
+}
+
+// writtenMsg is the outcome of a write to the Store.
+type writtenMsg struct {
+	status string
+	err    error
+}
+
+// Filter selects the Tasks shown by their completion.
+type Filter int
+
+const (
+	FilterAll Filter = iota
+	FilterOpen
+	FilterDone
+)
+
+func (f Filter) String() string {
+	switch f {
+	case FilterOpen:
+		return "open"
+	case FilterDone:
+		return "done"
+	}
+	return "all"
+}
+
+// mode tells what the keys do.
+type mode int
+
+const (
+	browsing mode = iota
+	searching
+	adding
+	editing
+	deleting
+)
+
+// prompts are the prompts of the modes reading a line.
+var prompts = map[mode]string{
+	searching: "Search: ",
+	adding:    "New task: ",
+	editing:   "Description: ",
+}
+
+// help lists the shortcuts of the modes.
+var help = map[mode]string{
+	browsing:  "j/k move  space done  +/- priority  a add  e edit  d delete  / search  f filter  r reload  q quit",
+	searching: "enter keep  esc clear",
+	adding:    "enter add  esc cancel",
+	editing:   "enter save  esc cancel",
+	deleting:  "y delete  n keep",
+}
+
+// Model is the state of the terminal UI.
+type Model struct {
+	ctx   context.Context
+	store Store
+
+	tasks   []entity.Task
+	visible []entity.Task
+	cursor  int
+	top     int
+
+	filter Filter
+	query  string
+	mode   mode
+	input  []rune
+	status string
+
+	width, height int
+	quitting      bool
+}
+
+// NewModel returns a Model of the Tasks of a Store, which calls it with the
+// context.
+func NewModel(ctx context.Context, store Store) *Model {
+	return &Model{ctx: ctx, store: store, width: 80, height: 24}
+}
+
+// Init returns the command loading the Tasks.
+func (m *Model) Init() Cmd {
+	return m.load
+}
+
+// Quitting reports whether the User asked to quit.
+func (m *Model) Quitting() bool {
+	return m.quitting
+}
+
+// Selected returns the Task under the cursor, if any.
+func (m *Model) Selected() (entity.Task, bool) {
+	if m.cursor < len(m.visible) {
+		return m.visible[m.cursor], true
+	}
+	return entity.Task{}, false
+}
+
+func (m *Model) load() Msg {
+	tasks, err := m.store.List(m.ctx)
+	return loadedMsg{tasks: tasks, err: err}
+}
+
+// write returns the command running a write to the Store, which reports the
+// status when it succeeds.
+func (m *Model) write(status string, fn func(ctx context.Context) error) Cmd {
+	return func() Msg {
+		return writtenMsg{status: status, err: fn(m.ctx)}
+	}
+}
+
+// Update handles a Msg, and returns the command to run next, if any.
+func (m *Model) Update(msg Msg) Cmd {
+	switch msg := msg.(type) {
+	case Key:
+		return m.key(msg)
+	case ResizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case ChangedMsg:
+		return m.load
+	case loadedMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return nil
+		}
+		m.tasks = msg.tasks
+		m.refresh()
+	case writtenMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return nil
+		}
+		m.status = msg.status
+		return m.load
+	}
+	return nil
+}
+
+// refresh selects the visible Tasks, keeping the cursor on the same Task if
+// it is still visible.
+func (m *Model) refresh() {
+	selected, ok := m.Selected()
+	query := strings.ToLower(m.query)
+	m.visible = m.visible[:0]
+	for _, t := range m.tasks {
+		if (m.filter == FilterOpen && t.Completed) || (m.filter == FilterDone && !t.Completed) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(t.Description), query) {
+			continue
+		}
+		if ok && t.ID == selected.ID {
+			m.cursor = len(m.visible)
+		}
+		m.visible = append(m.visible, t)
+	}
+	m.move(0)
+}
+
+// move moves the cursor by delta Tasks, within the visible ones.
+func (m *Model) move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m *Model) key(key Key) Cmd {
+	if key.Type == KeyCtrlC {
+		m.quitting = true
+		return nil
+	}
+	switch m.mode {
+	case browsing:
+		return m.browse(key)
+	case deleting:
+		m.mode = browsing
+		selected, ok := m.Selected()
+		if !ok || key.Type != KeyRune || (key.Rune != 'y' && key.Rune != 'Y') {
+			m.status = ""
+			return nil
+		}
+		return m.write("Deleted "+selected.Description, func(ctx context.Context) error {
+			return m.store.Delete(ctx, selected.ID)
+		})
+	}
+	return m.edit(key)
+}
+
+// browse handles the shortcuts of the list of Tasks.
+func (m *Model) browse(key Key) Cmd {
+	selected, ok := m.Selected()
+	switch {
+	case key.Type == KeyDown || key.Rune == 'j':
+		m.move(1)
+	case key.Type == KeyUp || key.Rune == 'k':
+		m.move(-1)
+	case key.Rune == 'g':
+		m.move(-len(m.visible))
+	case key.Rune == 'G':
+		m.move(len(m.visible))
+	case key.Rune == 'q' || key.Type == KeyEscape:
+		m.quitting = true
+	case key.Rune == 'r':
+		return m.load
+	case key.Rune == 'f' || key.Type == KeyTab:
+		m.filter = (m.filter + 1) % 3
+		m.refresh()
+	case key.Rune == '/':
+		m.mode, m.input = searching, []rune(m.query)
+	case key.Rune == 'a':
+		m.mode, m.input = adding, nil
+	case key.Rune == 'e' && ok:
+		m.mode, m.input = editing, []rune(selected.Description)
+	case key.Rune == 'd' && ok:
+		m.mode = deleting
+		m.status = fmt.Sprintf("Delete %q? (y/n)", selected.Description)
+	case (key.Rune == ' ' || key.Rune == 'x') && ok:
+		selected.Completed = !selected.Completed
+		status := "Completed "
+		if !selected.Completed {
+			status = "Reopened "
+		}
+		return m.update(selected, status+selected.Description)
+	case (key.Rune == '+' || key.Rune == '=') && ok && selected.Priority < entity.PriorityHigh:
+		selected.Priority++
+		return m.update(selected, fmt.Sprintf("Raised %s to %s", selected.Description, selected.Priority))
+	case key.Rune == '-' && ok && selected.Priority > entity.PriorityLow:
+		selected.Priority--
+		return m.update(selected, fmt.Sprintf("Lowered %s to %s", selected.Description, selected.Priority))
+	}
+	return nil
+}
+
+// update returns the command updating a Task, once valid.
+func (m *Model) update(t entity.Task, status string) Cmd {
+	if err := t.Validate(); err != nil {
+		m.status = "Error: " + err.Error()
+		return nil
+	}
+	return m.write(status, func(ctx context.Context) error {
+		return m.store.Update(ctx, t)
+	})
+}
+
+// edit handles the keys typed in the line of the searching, adding and
+// editing modes.
+func (m *Model) edit(key Key) Cmd {
+	switch key.Type {
+	case KeyEscape:
+		if m.mode == searching {
+			m.query = ""
+			m.refresh()
+		}
+		m.mode = browsing
+		return nil
+	case KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case KeyRune:
+		m.input = append(m.input, key.Rune)
+	case KeyEnter:
+		return m.submit(strings.TrimSpace(string(m.input)))
+	}
+
+	// The Tasks are searched as the query is typed.
+	if m.mode == searching {
+		m.query = string(m.input)
+		m.refresh()
+	}
+	return nil
+}
+
+// submit handles the line typed in the searching, adding and editing modes.
+func (m *Model) submit(line string) Cmd {
+	mode := m.mode
+	m.mode = browsing
+	switch mode {
+	case adding:
+		if line == "" {
+			return nil
+		}
+		t := entity.NewTask(line)
+		return m.write("Added "+line, func(ctx context.Context) error {
+			return m.store.Create(ctx, *t)
+		})
+	case editing:
+		selected, ok := m.Selected()
+		if !ok || line == selected.Description {
+			return nil
+		}
+		selected.Description = line
+		return m.update(selected, "Renamed to "+line)
+	}
+	return nil
+}
+
+// View renders the Model as the lines of the screen, of at most its width.
+func (m *Model) View() string {
+	lines := []string{m.title(), fmt.Sprintf("    %-3s  %-8s  %-6s  %-10s  %s", "", "ID", "PRI", "DUE", "DESCRIPTION")}
+
+	// The list scrolls to keep the cursor in view, between the header and
+	// the status and help lines.
+	rows := m.height - len(lines) - 2
+	if rows < 1 {
+		rows = 1
+	}
+	if m.cursor < m.top {
+		m.top = m.cursor
+	} else if m.cursor >= m.top+rows {
+		m.top = m.cursor - rows + 1
+	}
+	for i := m.top; i < len(m.visible) && i < m.top+rows; i++ {
+		lines = append(lines, m.row(i))
+	}
+	if len(m.visible) == 0 {
+		lines = append(lines, "    No tasks.")
+	}
+	for len(lines) < m.height-2 {
+		lines = append(lines, "")
+	}
+
+	if prompt, ok := prompts[m.mode]; ok {
+		lines = append(lines, prompt+string(m.input)+"_")
+	} else {
+		lines = append(lines, m.status)
+	}
+	lines = append(lines, help[m.mode])
+
+	for i, line := range lines {
+		lines[i] = truncate(line, m.width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// title summarizes the Tasks shown.
+func (m *Model) title() string {
+	title := fmt.Sprintf("GoDoIt — %d of %d tasks (%s)", len(m.visible), len(m.tasks), m.filter)
+	if m.query != "" {
+		title += fmt.Sprintf(" matching %q", m.query)
+	}
+	return title
+}
+
+// row renders the visible Task at index i, in reverse video under the cursor.
+func (m *Model) row(i int) string {
+	t := m.visible[i]
+	done := " "
+	if t.Completed {
+		done = "x"
+	}
+	due := "-"
+	if t.DueAt != nil {
+		due = t.DueAt.Local().Format("2006-01-02")
+	}
+	row := fmt.Sprintf("  [%s]  %-8s  %-6s  %-10s  %s", done, shortID(t.ID), t.Priority, due, t.Description)
+	if i == m.cursor {
+		return reverse + truncate(row, m.width) + reset
+	}
+	return row
+}
+
+// The escape sequences of the terminal.
+const (
+	reverse = "\x1b[7m"
+	reset   = "\x1b[0m"
+)
+
+// shortID returns the beginning of an ID, as the godoit command shows it.
+func shortID(id uuid.UUID) string {
+	return id.String()[:8]
+}
+
+// truncate cuts a line to a number of characters, unless it is styled.
+func truncate(line string, width int) string {
+	if strings.HasPrefix(line, reverse) || utf8.RuneCountInString(line) <= width {
+		return line
+	}
+	return string([]rune(line)[:width])
+}
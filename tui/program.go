@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+)
+
+// The escape sequences of the full-screen mode.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	home        = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+)
+
+// Run shows the Model full screen on the terminal, until the User quits or
+// the context is done. The Model is updated with the keys pressed, the size
+// of the terminal and the changes watched in its Store.
+func Run(ctx context.Context, m *Model, terminal *os.File) error {
+	fd := int(terminal.Fd())
+	restore, err := makeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	out := bufio.NewWriter(terminal)
+	out.WriteString(enterScreen)
+	defer func() {
+		out.WriteString(leaveScreen)
+		out.Flush()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs := make(chan Msg)
+	send := func(msg Msg) {
+		select {
+		case msgs <- msg:
+		case <-ctx.Done():
+		}
+	}
+	run := func(cmd Cmd) {
+		if cmd != nil {
+			go func() { send(cmd()) }()
+		}
+	}
+
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := terminal.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range ParseKeys(buf[:n]) {
+				send(key)
+			}
+		}
+	}()
+	go func() {
+		for range m.store.Watch(ctx) {
+			send(ChangedMsg{})
+		}
+	}()
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	// The first size is read as if the terminal had been resized.
+	select {
+	case resized <- os.Interrupt:
+	default:
+	}
+	go func() {
+		for {
+			select {
+			case <-resized:
+				// Some terminals do not know their size, which is then zero.
+				if width, height, err := size(fd); err == nil && width > 0 && height > 0 {
+					send(ResizeMsg{Width: width, Height: height})
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	run(m.Init())
+	for {
+		// The screen is redrawn over the previous one, line by line, so that
+		// it does not flicker.
+		out.WriteString(home)
+		out.WriteString(strings.ReplaceAll(m.View(), "\n", clearLine+"\r\n"))
+		out.WriteString(clearLine + clearBelow)
+		if err := out.Flush(); err != nil {
+			return err
+		}
+
+		select {
+		case msg := <-msgs:
+			run(m.Update(msg))
+			if m.Quitting() {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
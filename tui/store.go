@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/client"
+	"github.com/omaciel/GoDoIt/domain/eventsource"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/domain/user"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+// ReconnectDelay is how long the RemoteStores wait before opening another
+// stream of changes when one fails.
+var ReconnectDelay = 2 * time.Second
+
+// Store holds the Tasks of the User shown by the Model.
+type Store interface {
+	List(ctx context.Context) ([]entity.Task, error)
+	Create(ctx context.Context, t entity.Task) error
+	Update(ctx context.Context, t entity.Task) error
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Watch signals on the channel whenever the Tasks may have changed, and
+	// closes it when the context is done.
+	Watch(ctx context.Context) <-chan struct{}
+}
+
+// notify signals on a channel, unless a signal is already pending.
+func notify(c chan<- struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// RemoteStore holds the Tasks on a server, and watches the changes it sends.
+type RemoteStore struct {
+	Client *client.Client
+}
+
+func (s *RemoteStore) List(ctx context.Context) ([]entity.Task, error) {
+	var tasks []entity.Task
+	it := s.Client.Tasks(ctx, client.ListOptions{})
+	for it.Next() {
+		tasks = append(tasks, it.Task())
+	}
+	return tasks, it.Err()
+}
+
+func (s *RemoteStore) Create(ctx context.Context, t entity.Task) error {
+	_, err := s.Client.CreateTask(ctx, t)
+	return err
+}
+
+func (s *RemoteStore) Update(ctx context.Context, t entity.Task) error {
+	_, err := s.Client.UpdateTask(ctx, t)
+	return err
+}
+
+func (s *RemoteStore) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.Client.DeleteTask(ctx, id)
+}
+
+// Watch reads the stream of changes of the server, and opens another one
+// after ReconnectDelay whenever it fails, resuming after the last change.
+func (s *RemoteStore) Watch(ctx context.Context) <-chan struct{} {
+	changed := make(chan struct{}, 1)
+	go func() {
+		defer close(changed)
+		var last uint64
+		for ctx.Err() == nil {
+			if stream, err := s.Client.Changes(ctx, last); err == nil {
+				for {
+					change, err := stream.Next()
+					if errors.Is(err, changes.ErrBacklogExceeded) {
+						last = 0
+						notify(changed)
+						continue
+					} else if err != nil {
+						break
+					}
+					last = change.ID
+					notify(changed)
+				}
+				stream.Close()
+			}
+
+			select {
+			case <-time.After(ReconnectDelay):
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return changed
+}
+
+// LocalStore holds the Tasks of a User in a repository, usually on the disk,
+// and watches the changes published to the Hub.
+type LocalStore struct {
+	Repo task.TaskRepository
+	Hub  *changes.Hub
+	User entity.User
+
+	files *eventsource.FileStore
+}
+
+// OpenLocalStore opens the Tasks of a User in the event store of a
+// directory, as the server keeps them with DATABASE=eventsource. The store is
+// created on first use, and locked until the LocalStore is closed. The User
+// is created when create is set, and must exist otherwise, so that a
+// mistyped name is not taken for a new User.
+func OpenLocalStore(dir, username string, create bool) (*LocalStore, error) {
+	files, err := eventsource.NewFileStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openLocalStore(files, username, create)
+	if err != nil {
+		files.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func openLocalStore(files *eventsource.FileStore, username string, create bool) (*LocalStore, error) {
+	repo, err := eventsource.NewEventSourcedRepository(files)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var user entity.User
+	if create {
+		user, err = createLocalUser(ctx, repo, username)
+	} else {
+		user, err = repo.UserByName(ctx, username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hub := changes.NewHub(changes.DefaultBacklog)
+	return &LocalStore{Repo: changes.Watch(repo, hub), Hub: hub, User: user, files: files}, nil
+}
+
+// Close releases the event store opened by OpenLocalStore.
+func (s *LocalStore) Close() error {
+	if s.files == nil {
+		return nil
+	}
+	return s.files.Close()
+}
+
+// createLocalUser creates the User of a local store. The local Users do not
+// log in, so their password is random.
+func createLocalUser(ctx context.Context, users user.UserRepository, username string) (entity.User, error) {
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return entity.User{}, err
+	}
+	created, err := entity.NewUser(username, hex.EncodeToString(secret))
+	if err != nil {
+		return entity.User{}, err
+	}
+	if err := users.CreateUser(ctx, created); err != nil {
+		return entity.User{}, err
+	}
+	return *created, nil
+}
+
+// context restricts a context to the Tasks of the User, as the server does
+// for its requests.
+func (s *LocalStore) context(ctx context.Context) context.Context {
+	ctx = task.WithOwner(ctx, s.User.ID)
+	ctx = task.WithWorkspace(ctx, s.User.WorkspaceID)
+	return task.WithActor(ctx, s.User.Username)
+}
+
+func (s *LocalStore) List(ctx context.Context) ([]entity.Task, error) {
+	tasks, _, err := s.Repo.Find(s.context(ctx), task.Filter{})
+	return tasks, err
+}
+
+func (s *LocalStore) Create(ctx context.Context, t entity.Task) error {
+	return s.Repo.Post(s.context(ctx), &t)
+}
+
+func (s *LocalStore) Update(ctx context.Context, t entity.Task) error {
+	return s.Repo.Put(s.context(ctx), &t)
+}
+
+func (s *LocalStore) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.Repo.Delete(s.context(ctx), id)
+}
+
+// Watch signals the changes published to the Hub which the User can see.
+func (s *LocalStore) Watch(ctx context.Context) <-chan struct{} {
+	changed := make(chan struct{}, 1)
+	subscription, _, _ := s.Hub.Subscribe(s.User.ID, 0)
+	go func() {
+		defer close(changed)
+		defer func() { subscription.Close() }()
+		for {
+			select {
+			case _, ok := <-subscription.Changes():
+				// The subscriptions which fall behind are dropped.
+				if !ok {
+					subscription, _, _ = s.Hub.Subscribe(s.User.ID, 0)
+				}
+				notify(changed)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changed
+}
@@ -0,0 +1,21 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+// errUnsupported is returned on the platforms without termios.
+var errUnsupported = errors.New("the terminal UI is not supported on this platform")
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errUnsupported
+}
+
+func size(fd int) (int, int, error) {
+	return 0, 0, errUnsupported
+}
+
+func notifyResize(c chan<- os.Signal) {}
@@ -0,0 +1,222 @@
+package tui_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/client"
+	"github.com/omaciel/GoDoIt/database"
+	"github.com/omaciel/GoDoIt/domain/eventsource"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/router"
+	"github.com/omaciel/GoDoIt/tui"
+	"github.com/stretchr/testify/assert"
+)
+
+// drive updates the Model with the messages, and then with the results of
+// its commands, as Run does.
+func drive(m *tui.Model, msgs ...tui.Msg) {
+	for _, msg := range msgs {
+		for cmd := m.Update(msg); cmd != nil; cmd = m.Update(cmd()) {
+		}
+	}
+}
+
+// typed returns the keys typed on a terminal.
+func typed(text string) []tui.Msg {
+	var msgs []tui.Msg
+	for _, key := range tui.ParseKeys([]byte(text)) {
+		msgs = append(msgs, key)
+	}
+	return msgs
+}
+
+// descriptions returns the descriptions of the Tasks of a Store.
+func descriptions(t *testing.T, store tui.Store) map[string]entity.Task {
+	tasks, err := store.List(context.Background())
+	assert.NoError(t, err)
+	found := make(map[string]entity.Task)
+	for _, task := range tasks {
+		found[task.Description] = task
+	}
+	return found
+}
+
+func TestParseKeys(t *testing.T) {
+	assert.Equal(t, []tui.Key{
+		{Type: tui.KeyRune, Rune: 'a'},
+		{Type: tui.KeyEnter},
+		{Type: tui.KeyUp},
+		{Type: tui.KeyDown},
+		{Type: tui.KeyBackspace},
+		{Type: tui.KeyCtrlC},
+		{Type: tui.KeyRune, Rune: 'é'},
+		{Type: tui.KeyEscape},
+	}, tui.ParseKeys([]byte("a\r\x1b[A\x1bOB\x1b[3~\x7f\x03\x01é\x1b")), "unknown sequences and control characters are skipped")
+}
+
+func TestModel(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	ctx := context.Background()
+	created, _ := entity.NewUser("terminal", "a password")
+	assert.NoError(t, repo.CreateUser(ctx, created))
+	user, err := repo.UserByName(ctx, "terminal")
+	assert.NoError(t, err)
+	hub := changes.NewHub(changes.DefaultBacklog)
+	store := &tui.LocalStore{Repo: changes.Watch(repo, hub), Hub: hub, User: user}
+
+	m := tui.NewModel(ctx, store)
+	drive(m, m.Init()())
+	assert.Contains(t, m.View(), "No tasks.")
+
+	t.Run("Add Tasks", func(t *testing.T) {
+		drive(m, typed("aWrite report\r")...)
+		drive(m, typed("aRead mail\r")...)
+		drive(m, typed("a\r")...)
+		assert.Len(t, descriptions(t, store), 2, "empty Tasks are not added")
+		assert.Contains(t, m.View(), "Added Read mail")
+		assert.Contains(t, m.View(), "GoDoIt — 2 of 2 tasks (all)")
+	})
+
+	t.Run("Complete a Task", func(t *testing.T) {
+		drive(m, typed("j ")...)
+		selected, _ := m.Selected()
+		assert.Equal(t, "Read mail", selected.Description)
+		assert.True(t, descriptions(t, store)["Read mail"].Completed)
+		assert.Contains(t, m.View(), "[x]")
+	})
+
+	t.Run("Reprioritize a Task", func(t *testing.T) {
+		drive(m, typed("k+++")...)
+		assert.Equal(t, entity.PriorityHigh, descriptions(t, store)["Write report"].Priority, "the priority stops at high")
+		drive(m, typed("-")...)
+		assert.Equal(t, entity.PriorityMedium, descriptions(t, store)["Write report"].Priority)
+	})
+
+	t.Run("Edit a Task", func(t *testing.T) {
+		drive(m, typed("e\x7f\x7f\x7f\x7f\x7f\x7fmemo\r")...)
+		assert.Contains(t, descriptions(t, store), "Write memo")
+		drive(m, typed("eignored\x1b")...)
+		assert.Contains(t, descriptions(t, store), "Write memo", "cancelled edits are not saved")
+	})
+
+	t.Run("Filter the Tasks", func(t *testing.T) {
+		drive(m, typed("f")...)
+		assert.Contains(t, m.View(), "1 of 2 tasks (open)")
+		selected, _ := m.Selected()
+		assert.Equal(t, "Write memo", selected.Description)
+		drive(m, typed("f")...)
+		assert.Contains(t, m.View(), "1 of 2 tasks (done)")
+		selected, _ = m.Selected()
+		assert.Equal(t, "Read mail", selected.Description)
+		drive(m, typed("f")...)
+
+		drive(m, typed("/MEMO")...)
+		assert.Contains(t, m.View(), "1 of 2 tasks (all) matching \"MEMO\"")
+		drive(m, typed("\x1b")...)
+		assert.Contains(t, m.View(), "2 of 2 tasks")
+	})
+
+	t.Run("Watch the changes", func(t *testing.T) {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		changed := store.Watch(watchCtx)
+		assert.NoError(t, store.Create(ctx, *entity.NewTask("Added elsewhere")))
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the change was not signalled")
+		}
+		drive(m, tui.ChangedMsg{})
+		assert.Contains(t, m.View(), "Added elsewhere")
+	})
+
+	t.Run("Scroll the Tasks", func(t *testing.T) {
+		drive(m, tui.ResizeMsg{Width: 40, Height: 5})
+		drive(m, typed("G")...)
+		lines := strings.Split(m.View(), "\n")
+		assert.Len(t, lines, 5)
+		selected, _ := m.Selected()
+		assert.Equal(t, "Added elsewhere", selected.Description)
+		assert.Contains(t, lines[2], selected.ID.String()[:8], "the cursor is kept in view")
+		for _, line := range lines {
+			assert.LessOrEqual(t, len([]rune(strings.TrimPrefix(strings.TrimSuffix(line, "\x1b[0m"), "\x1b[7m"))), 40)
+		}
+		drive(m, tui.ResizeMsg{Width: 120, Height: 24})
+	})
+
+	t.Run("Delete a Task", func(t *testing.T) {
+		drive(m, typed("gdn")...)
+		assert.Len(t, descriptions(t, store), 3)
+		drive(m, typed("dy")...)
+		assert.NotContains(t, descriptions(t, store), "Write memo")
+	})
+
+	t.Run("Quit", func(t *testing.T) {
+		assert.False(t, m.Quitting())
+		drive(m, typed("q")...)
+		assert.True(t, m.Quitting())
+	})
+}
+
+func TestOpenLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	_, err := tui.OpenLocalStore(dir, "offline", false)
+	assert.ErrorIs(t, err, entity.ErrUserNotFound, "the Users are only created when asked to")
+
+	store, err := tui.OpenLocalStore(dir, "offline", true)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Create(context.Background(), *entity.NewTask("Kept on the disk")))
+	_, err = tui.OpenLocalStore(dir, "offline", false)
+	assert.ErrorIs(t, err, eventsource.ErrStoreLocked, "the store is used by one process at a time")
+	assert.NoError(t, store.Close())
+
+	_, err = tui.OpenLocalStore(dir, "offline", true)
+	assert.ErrorIs(t, err, entity.ErrUsernameTaken)
+	_, err = tui.OpenLocalStore(dir, "ofline", false)
+	assert.ErrorIs(t, err, entity.ErrUserNotFound, "a mistyped User is not created")
+
+	reopened, err := tui.OpenLocalStore(dir, "offline", false)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, store.User.ID, reopened.User.ID)
+	assert.Contains(t, descriptions(t, reopened), "Kept on the disk")
+}
+
+func TestRemoteStore(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	database.Repo, database.Users = changes.Watch(repo, changes.Default), repo
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	router.SetupRoutes(app)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() { _ = app.Listener(listener) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := client.New("http://"+listener.Addr().String(), "")
+	_, err = c.Register(ctx, "remote", "a password")
+	assert.NoError(t, err)
+	_, err = c.Login(ctx, "remote", "a password")
+	assert.NoError(t, err)
+	store := &tui.RemoteStore{Client: c}
+
+	changed := store.Watch(ctx)
+	// The stream of changes is opened in the background, and only the changes
+	// made after are sent.
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, store.Create(ctx, *entity.NewTask("Sent by the server")))
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not signalled")
+	}
+	assert.Contains(t, descriptions(t, store), "Sent by the server")
+}
@@ -0,0 +1,442 @@
+// Package webhooks delivers the changes to the Tasks to the webhooks of the
+// Users who can see them, signed with the secrets of the webhooks.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/domain/webhook"
+	"github.com/omaciel/GoDoIt/entity"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a delivery is attempted before it
+	// gives up and goes to the dead-letter list.
+	DefaultMaxAttempts = 8
+
+	// DefaultBackoff is how long the first retry waits. Every retry waits
+	// twice as long as the previous one, up to DefaultMaxBackoff.
+	DefaultBackoff    = 30 * time.Second
+	DefaultMaxBackoff = 6 * time.Hour
+
+	// DefaultTimeout is how long the receivers have to answer.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultRetryInterval is how often the deliveries due for a retry are
+	// attempted.
+	DefaultRetryInterval = 10 * time.Second
+
+	// DefaultWorkers is how many deliveries are attempted at once, and
+	// DefaultReceiverConcurrency how many of them may go to the same
+	// receiver.
+	DefaultWorkers             = 16
+	DefaultReceiverConcurrency = 2
+)
+
+// The headers of the deliveries.
+const (
+	HeaderEvent     = "X-GoDoIt-Event"
+	HeaderDelivery  = "X-GoDoIt-Delivery"
+	HeaderSignature = "X-GoDoIt-Signature"
+)
+
+// ErrForbiddenAddress is returned for the webhooks whose host is a private,
+// loopback or link-local address, which the deliveries must not reach unless
+// the operator allows it.
+var ErrForbiddenAddress = errors.New("the webhook cannot be delivered to a private, loopback or link-local address")
+
+// maxResponseSize is how much of the responses is read, so that the
+// connections are reused.
+const maxResponseSize = 64 << 10
+
+// Payload is the body of the deliveries.
+type Payload struct {
+	Event      string      `json:"event"`
+	ChangeID   uint64      `json:"change_id"`
+	Task       entity.Task `json:"task"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// Sign returns the signature of a body, as sent in the HeaderSignature of the
+// deliveries: the hex-encoded HMAC-SHA256 of the body keyed with the secret,
+// prefixed with "sha256=".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature of a delivery matches its body, in
+// constant time.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Dispatcher records a delivery of every Change to the webhooks which want
+// it, and attempts them until they succeed or give up.
+type Dispatcher struct {
+	Repo        webhook.WebhookRepository
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+
+	// Workers is how many deliveries are attempted at once, and
+	// ReceiverConcurrency how many of them may go to the same receiver, the
+	// host and port of a webhook, so that a slow receiver cannot hold up
+	// the others.
+	Workers             int
+	ReceiverConcurrency int
+
+	// AllowedNetworks are the private, loopback and link-local networks
+	// which the Client of NewDispatcher may deliver to. The others are
+	// refused, so that the webhooks cannot reach the internal services.
+	AllowedNetworks []*net.IPNet
+}
+
+// NewDispatcher returns a Dispatcher with the default retries and timeout,
+// whose Client only connects to the public addresses and AllowedNetworks.
+func NewDispatcher(repo webhook.WebhookRepository) *Dispatcher {
+	d := &Dispatcher{
+		Repo:        repo,
+		MaxAttempts: DefaultMaxAttempts,
+		Backoff:     DefaultBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+
+		Workers:             DefaultWorkers,
+		ReceiverConcurrency: DefaultReceiverConcurrency,
+	}
+
+	// The addresses are checked once resolved, right before connecting, so
+	// that the hosts cannot resolve to others in between. The receivers are
+	// never reached through a proxy, which would connect instead.
+	dialer := &net.Dialer{Timeout: DefaultTimeout, Control: d.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy, transport.DialContext = nil, dialer.DialContext
+	d.Client = &http.Client{Timeout: DefaultTimeout, Transport: transport}
+	return d
+}
+
+// control refuses the connections to the private, loopback and link-local
+// addresses outside of the AllowedNetworks.
+func (d *Dispatcher) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ErrForbiddenAddress
+	}
+	for _, allowed := range d.AllowedNetworks {
+		if allowed.Contains(ip) {
+			return nil
+		}
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// NetworksFromEnv reads the comma-separated CIDR networks of an environment
+// variable, such as the AllowedNetworks. The invalid networks are skipped.
+func NetworksFromEnv(key string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			log.Printf("Invalid network %q for %s, skipping it.", value, key)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// Enqueue records a pending delivery of the Change to every webhook of the
+// Users who can see it which wants its type.
+func (d *Dispatcher) Enqueue(ctx context.Context, change changes.Change) error {
+	body, err := json.Marshal(Payload{
+		Event:      string(change.Type),
+		ChangeID:   change.ID,
+		Task:       change.Task,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range change.Audience {
+		hooks, err := d.Repo.Webhooks(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, hook := range hooks {
+			if !hook.Wants(string(change.Type)) {
+				continue
+			}
+			delivery := entity.NewWebhookDelivery(hook, string(change.Type), string(body))
+			if err := d.Repo.SaveDelivery(ctx, delivery); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backoff returns how long to wait after the given number of attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.Backoff
+	for i := 1; i < attempts && wait < d.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.MaxBackoff {
+		wait = d.MaxBackoff
+	}
+	return wait
+}
+
+// Attempt sends a delivery to its webhook once, and records the outcome:
+// the delivery succeeds on a 2xx response, and is otherwise retried later,
+// unless it was its last attempt.
+func (d *Dispatcher) Attempt(ctx context.Context, delivery entity.WebhookDelivery) error {
+	hook, err := d.Repo.GetWebhook(ctx, delivery.UserID, delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+	return d.attempt(ctx, hook, delivery)
+}
+
+// attempt is Attempt with the webhook of the delivery.
+func (d *Dispatcher) attempt(ctx context.Context, hook entity.Webhook, delivery entity.WebhookDelivery) error {
+	delivery.Attempts++
+	delivery.ResponseStatus, delivery.LastError = 0, ""
+	if status, err := d.send(ctx, hook, delivery); err != nil {
+		delivery.LastError = err.Error()
+	} else if status < 200 || status > 299 {
+		delivery.ResponseStatus = status
+		delivery.LastError = fmt.Sprintf("the receiver answered %d %s", status, http.StatusText(status))
+	} else {
+		delivery.ResponseStatus = status
+	}
+
+	now := time.Now()
+	switch {
+	case delivery.LastError == "":
+		delivery.Status, delivery.DeliveredAt, delivery.NextAttemptAt = entity.DeliverySucceeded, &now, nil
+	case delivery.Attempts >= d.MaxAttempts:
+		delivery.Status, delivery.NextAttemptAt = entity.DeliveryDead, nil
+	default:
+		next := now.Add(d.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+	return d.Repo.SaveDelivery(ctx, &delivery)
+}
+
+// send posts the payload of a delivery to its webhook, and returns the status
+// of the response.
+func (d *Dispatcher) send(ctx context.Context, hook entity.Webhook, delivery entity.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoDoIt-Webhooks")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	return resp.StatusCode, nil
+}
+
+// pending is a delivery to attempt, along with its webhook.
+type pending struct {
+	hook     entity.Webhook
+	delivery entity.WebhookDelivery
+}
+
+// receiver returns the host and port which the deliveries of a webhook go to.
+func receiver(hook entity.Webhook) string {
+	u, err := url.Parse(hook.URL)
+	if err != nil {
+		return hook.URL
+	}
+	return strings.ToLower(u.Host)
+}
+
+// DeliverDue attempts the deliveries which are due at now, up to Workers at
+// once and up to ReceiverConcurrency at once for each receiver, and returns
+// how many were attempted.
+func (d *Dispatcher) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := d.Repo.DueDeliveries(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	hooks := make(map[uuid.UUID]entity.Webhook)
+	queues := make(map[string][]pending)
+	for _, delivery := range due {
+		hook, ok := hooks[delivery.WebhookID]
+		if !ok {
+			if hook, err = d.Repo.GetWebhook(ctx, delivery.UserID, delivery.WebhookID); err != nil {
+				log.Println("Failed to record a webhook delivery. \n", err)
+				continue
+			}
+			hooks[hook.ID] = hook
+		}
+		queues[receiver(hook)] = append(queues[receiver(hook)], pending{hook: hook, delivery: delivery})
+	}
+
+	// Every receiver is served in order by up to ReceiverConcurrency lanes,
+	// which take turns at the Workers.
+	workers := make(chan struct{}, max(d.Workers, 1))
+	var wg sync.WaitGroup
+	for _, queue := range queues {
+		next := make(chan pending, len(queue))
+		for _, p := range queue {
+			next <- p
+		}
+		close(next)
+
+		for i := 0; i < min(max(d.ReceiverConcurrency, 1), len(queue)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for p := range next {
+					workers <- struct{}{}
+					if err := d.attempt(ctx, p.hook, p.delivery); err != nil {
+						log.Println("Failed to record a webhook delivery. \n", err)
+					}
+					<-workers
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	return len(due), nil
+}
+
+// Start subscribes to the Hub, so that every Change published from then on is
+// delivered, and enqueues and delivers the changes in the background,
+// retrying the failed deliveries every interval, until ctx is done. An
+// interval which is not positive is replaced by DefaultRetryInterval.
+func (d *Dispatcher) Start(ctx context.Context, hub *changes.Hub, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Invalid webhook retry interval %s, using %s.", interval, DefaultRetryInterval)
+		interval = DefaultRetryInterval
+	}
+
+	subscription, _, _ := hub.SubscribeAll(0)
+	due := make(chan struct{}, 1)
+	go d.run(ctx, hub, subscription, due)
+	go d.deliver(ctx, due, interval)
+}
+
+// run listens to the Subscription, signalling due whenever deliveries are
+// enqueued. When it falls behind the Hub, it resumes after the last Change it
+// enqueued.
+func (d *Dispatcher) run(ctx context.Context, hub *changes.Hub, subscription *changes.Subscription, due chan<- struct{}) {
+	var last uint64
+	for d.listen(ctx, subscription, due, &last) {
+		var missed []changes.Change
+		var err error
+		subscription, missed, err = hub.SubscribeAll(last)
+		if err != nil {
+			log.Println("Some changes were lost before reaching the webhooks. \n", err)
+		}
+		for _, change := range missed {
+			d.enqueue(ctx, change)
+			last = change.ID
+		}
+		signal(due)
+	}
+}
+
+// listen enqueues the changes of the Subscription, and reports whether it
+// should be resumed once it is dropped. It never waits for the deliveries, so
+// that slow receivers do not make it fall behind the Hub.
+func (d *Dispatcher) listen(ctx context.Context, subscription *changes.Subscription, due chan<- struct{}, last *uint64) bool {
+	defer subscription.Close()
+	for {
+		select {
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				return true
+			}
+			d.enqueue(ctx, change)
+			*last = change.ID
+			signal(due)
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// deliver attempts the due deliveries whenever due is signalled, and every
+// interval for the retries, until ctx is done.
+func (d *Dispatcher) deliver(ctx context.Context, due <-chan struct{}, interval time.Duration) {
+	retries := time.NewTicker(interval)
+	defer retries.Stop()
+
+	d.deliverDue(ctx)
+	for {
+		select {
+		case <-due:
+		case <-retries.C:
+		case <-ctx.Done():
+			return
+		}
+		d.deliverDue(ctx)
+	}
+}
+
+// signal signals on a channel, unless a signal is already pending.
+func signal(c chan<- struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, change changes.Change) {
+	if err := d.Enqueue(ctx, change); err != nil {
+		log.Println("Failed to enqueue the webhook deliveries. \n", err)
+	}
+}
+
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	if _, err := d.DeliverDue(ctx, time.Now()); err != nil {
+		log.Println("Failed to deliver to the webhooks. \n", err)
+	}
+}
@@ -0,0 +1,366 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/omaciel/GoDoIt/changes"
+	"github.com/omaciel/GoDoIt/domain/memory"
+	"github.com/omaciel/GoDoIt/domain/task"
+	"github.com/omaciel/GoDoIt/entity"
+	"github.com/omaciel/GoDoIt/webhooks"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver is a webhook receiver which checks the signatures of the
+// deliveries, and answers them with status.
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	payloads []webhooks.Payload
+	invalid  int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !webhooks.Verify(r.secret, body, req.Header.Get(webhooks.HeaderSignature)) {
+		r.invalid++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var payload webhooks.Payload
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Event == req.Header.Get(webhooks.HeaderEvent) {
+		r.payloads = append(r.payloads, payload)
+	}
+	w.WriteHeader(r.status)
+}
+
+// newDispatcher returns a Dispatcher which may deliver to the receivers of
+// httptest, on the loopback network.
+func newDispatcher(repo *memory.MemoryRepository) *webhooks.Dispatcher {
+	dispatcher := webhooks.NewDispatcher(repo)
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	dispatcher.AllowedNetworks = []*net.IPNet{loopback}
+	return dispatcher
+}
+
+func (r *receiver) received() []webhooks.Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhooks.Payload(nil), r.payloads...)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"created"}`)
+	signature := webhooks.Sign("whsec_test", body)
+
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.True(t, webhooks.Verify("whsec_test", body, signature))
+	assert.False(t, webhooks.Verify("whsec_other", body, signature))
+	assert.False(t, webhooks.Verify("whsec_test", []byte(`{"event":"deleted"}`), signature))
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	change := changes.Change{ID: 42, Type: changes.Created, Task: *entity.NewTask("Ship it"), Audience: []uuid.UUID{userID}}
+
+	t.Run("Deliveries are signed and succeed on a 2xx", func(t *testing.T) {
+		repo := memory.NewMemoryRepository()
+		dispatcher := newDispatcher(repo)
+		ok := &receiver{secret: "whsec_ok", status: http.StatusNoContent}
+		server := httptest.NewServer(ok)
+		defer server.Close()
+
+		wanted, err := entity.NewWebhook(userID, server.URL, []string{"created"}, ok.secret)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateWebhook(ctx, wanted))
+		unwanted, err := entity.NewWebhook(userID, server.URL, []string{"deleted"}, ok.secret)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateWebhook(ctx, unwanted))
+
+		assert.NoError(t, dispatcher.Enqueue(ctx, change))
+		attempted, err := dispatcher.DeliverDue(ctx, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted, "only the webhooks which want the event receive it")
+
+		if payloads := ok.received(); assert.Len(t, payloads, 1) {
+			assert.Equal(t, uint64(42), payloads[0].ChangeID)
+			assert.Equal(t, "Ship it", payloads[0].Task.Description)
+		}
+
+		deliveries, err := repo.Deliveries(ctx, wanted.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, deliveries, 1) {
+			assert.Equal(t, entity.DeliverySucceeded, deliveries[0].Status)
+			assert.Equal(t, http.StatusNoContent, deliveries[0].ResponseStatus)
+			assert.Equal(t, 1, deliveries[0].Attempts)
+			assert.NotNil(t, deliveries[0].DeliveredAt)
+		}
+	})
+
+	t.Run("Failed deliveries back off and then go to the dead letters", func(t *testing.T) {
+		repo := memory.NewMemoryRepository()
+		dispatcher := newDispatcher(repo)
+		dispatcher.MaxAttempts, dispatcher.Backoff, dispatcher.MaxBackoff = 3, time.Minute, 90*time.Second
+		failing := &receiver{secret: "whsec_failing", status: http.StatusInternalServerError}
+		server := httptest.NewServer(failing)
+		defer server.Close()
+
+		hook, err := entity.NewWebhook(userID, server.URL, nil, failing.secret)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateWebhook(ctx, hook))
+		assert.NoError(t, dispatcher.Enqueue(ctx, change))
+
+		now := time.Now()
+		for _, wait := range []time.Duration{time.Minute, 90 * time.Second} {
+			attempted, err := dispatcher.DeliverDue(ctx, now)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, attempted)
+
+			attempted, err = dispatcher.DeliverDue(ctx, now)
+			assert.NoError(t, err)
+			assert.Zero(t, attempted, "the retry waits for its backoff")
+
+			deliveries, err := repo.Deliveries(ctx, hook.ID)
+			assert.NoError(t, err)
+			if assert.Len(t, deliveries, 1) && assert.NotNil(t, deliveries[0].NextAttemptAt) {
+				assert.Equal(t, entity.DeliveryPending, deliveries[0].Status)
+				assert.Equal(t, http.StatusInternalServerError, deliveries[0].ResponseStatus)
+				assert.WithinDuration(t, time.Now().Add(wait), *deliveries[0].NextAttemptAt, 5*time.Second)
+				now = *deliveries[0].NextAttemptAt
+			}
+		}
+
+		attempted, err := dispatcher.DeliverDue(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+		assert.Len(t, failing.received(), 3)
+
+		dead, err := repo.DeadDeliveries(ctx, userID)
+		assert.NoError(t, err)
+		if assert.Len(t, dead, 1) {
+			assert.Equal(t, 3, dead[0].Attempts)
+			assert.Nil(t, dead[0].NextAttemptAt)
+			assert.Contains(t, dead[0].LastError, "500")
+		}
+	})
+
+	t.Run("Unreachable receivers are retried", func(t *testing.T) {
+		repo := memory.NewMemoryRepository()
+		dispatcher := newDispatcher(repo)
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		hook, err := entity.NewWebhook(userID, server.URL, nil, "")
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateWebhook(ctx, hook))
+		assert.NoError(t, dispatcher.Enqueue(ctx, change))
+
+		_, err = dispatcher.DeliverDue(ctx, time.Now())
+		assert.NoError(t, err)
+		deliveries, err := repo.Deliveries(ctx, hook.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, deliveries, 1) {
+			assert.Equal(t, entity.DeliveryPending, deliveries[0].Status)
+			assert.Zero(t, deliveries[0].ResponseStatus)
+			assert.NotEmpty(t, deliveries[0].LastError)
+		}
+	})
+
+	t.Run("Private addresses are refused unless allowed", func(t *testing.T) {
+		repo := memory.NewMemoryRepository()
+		dispatcher := webhooks.NewDispatcher(repo)
+		internal := &receiver{secret: "whsec_internal", status: http.StatusOK}
+		server := httptest.NewServer(internal)
+		defer server.Close()
+
+		hook, err := entity.NewWebhook(userID, server.URL, nil, internal.secret)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateWebhook(ctx, hook))
+		assert.NoError(t, dispatcher.Enqueue(ctx, change))
+
+		_, err = dispatcher.DeliverDue(ctx, time.Now())
+		assert.NoError(t, err)
+		assert.Empty(t, internal.received())
+		deliveries, err := repo.Deliveries(ctx, hook.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, deliveries, 1) {
+			assert.Equal(t, entity.DeliveryPending, deliveries[0].Status)
+			assert.Contains(t, deliveries[0].LastError, webhooks.ErrForbiddenAddress.Error())
+		}
+	})
+}
+
+// concurrency counts the requests handled at once by a set of receivers.
+type concurrency struct {
+	mu            sync.Mutex
+	now, max      int
+	perHost, most map[string]int
+}
+
+func (c *concurrency) handler(host string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.mu.Lock()
+		c.now++
+		c.perHost[host]++
+		c.max = max(c.max, c.now)
+		c.most[host] = max(c.most[host], c.perHost[host])
+		c.mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		c.mu.Lock()
+		c.now--
+		c.perHost[host]--
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestDispatcherConcurrency(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := memory.NewMemoryRepository()
+	dispatcher := newDispatcher(repo)
+	dispatcher.Workers, dispatcher.ReceiverConcurrency = 3, 2
+
+	counts := &concurrency{perHost: make(map[string]int), most: make(map[string]int)}
+	for _, host := range []string{"a", "b"} {
+		server := httptest.NewServer(counts.handler(host))
+		defer server.Close()
+		for i := 0; i < 4; i++ {
+			hook, err := entity.NewWebhook(userID, server.URL, nil, "whsec_"+host)
+			assert.NoError(t, err)
+			assert.NoError(t, repo.CreateWebhook(ctx, hook))
+		}
+	}
+
+	change := changes.Change{ID: 1, Type: changes.Created, Task: *entity.NewTask("Ship it"), Audience: []uuid.UUID{userID}}
+	assert.NoError(t, dispatcher.Enqueue(ctx, change))
+	attempted, err := dispatcher.DeliverDue(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 8, attempted)
+
+	due, err := repo.DueDeliveries(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, due, "every delivery succeeds")
+	assert.LessOrEqual(t, counts.max, 3, "at most Workers deliveries are attempted at once")
+	assert.LessOrEqual(t, counts.most["a"], 2, "at most ReceiverConcurrency deliveries go to a receiver at once")
+	assert.LessOrEqual(t, counts.most["b"], 2)
+	assert.Greater(t, counts.max, 1, "the receivers are delivered to concurrently")
+}
+
+func TestNetworksFromEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOWED_NETWORKS", "10.0.0.0/8, invalid,,fd00::/8")
+	networks := webhooks.NetworksFromEnv("WEBHOOK_ALLOWED_NETWORKS")
+	if assert.Len(t, networks, 2, "the invalid networks are skipped") {
+		assert.True(t, networks[0].Contains(net.ParseIP("10.1.2.3")))
+		assert.True(t, networks[1].Contains(net.ParseIP("fd00::1")))
+	}
+	assert.Empty(t, webhooks.NetworksFromEnv("WEBHOOK_UNSET_NETWORKS"))
+}
+
+func TestDispatcherStart(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	hub := changes.NewHub(changes.DefaultBacklog)
+	tasks := changes.Watch(repo, hub)
+
+	userID := uuid.New()
+	ok := &receiver{secret: "whsec_run", status: http.StatusOK}
+	server := httptest.NewServer(ok)
+	defer server.Close()
+
+	hook, err := entity.NewWebhook(userID, server.URL, []string{"created", "deleted"}, ok.secret)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateWebhook(context.Background(), hook))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newDispatcher(repo).Start(ctx, hub, time.Hour)
+
+	owner := task.WithOwner(context.Background(), userID)
+	chore := entity.NewTask("Water the plants")
+	assert.NoError(t, tasks.Post(owner, chore))
+	chore.Completed = true
+	assert.NoError(t, tasks.Put(owner, chore))
+	assert.NoError(t, tasks.Delete(owner, chore.ID))
+	assert.NoError(t, tasks.Post(task.WithOwner(context.Background(), uuid.New()), entity.NewTask("Not mine")))
+
+	assert.Eventually(t, func() bool {
+		return len(ok.received()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var events []string
+	for _, payload := range ok.received() {
+		events = append(events, payload.Event)
+	}
+	assert.ElementsMatch(t, []string{"created", "deleted"}, events)
+	assert.Zero(t, ok.invalid)
+}
+
+func TestDispatcherStartInvalidInterval(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	hub := changes.NewHub(changes.DefaultBacklog)
+	userID := uuid.New()
+	ok := &receiver{secret: "whsec_interval", status: http.StatusOK}
+	server := httptest.NewServer(ok)
+	defer server.Close()
+
+	hook, err := entity.NewWebhook(userID, server.URL, nil, ok.secret)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateWebhook(context.Background(), hook))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newDispatcher(repo).Start(ctx, hub, 0)
+
+	assert.NoError(t, changes.Watch(repo, hub).Post(task.WithOwner(context.Background(), userID), entity.NewTask("Still delivered")))
+	assert.Eventually(t, func() bool {
+		return len(ok.received()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDispatcherSlowReceivers(t *testing.T) {
+	repo := memory.NewMemoryRepository()
+	hub := changes.NewHub(changes.DefaultBacklog)
+	tasks := changes.Watch(repo, hub)
+
+	userID := uuid.New()
+	released := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-released
+	}))
+	defer server.Close()
+	defer close(released)
+
+	hook, err := entity.NewWebhook(userID, server.URL, nil, "whsec_slow")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateWebhook(context.Background(), hook))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newDispatcher(repo).Start(ctx, hub, time.Hour)
+
+	// The changes keep being enqueued while the receiver hangs.
+	owner := task.WithOwner(context.Background(), userID)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, tasks.Post(owner, entity.NewTask("Queued")))
+	}
+	assert.Eventually(t, func() bool {
+		deliveries, err := repo.Deliveries(context.Background(), hook.ID)
+		return err == nil && len(deliveries) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}